@@ -183,4 +183,10 @@ export interface FeatureToggles {
   groupByVariable?: boolean;
   alertingUpgradeDryrunOnStart?: boolean;
   prometheusAzureOverrideAudience?: boolean;
+  sqlExpressions?: boolean;
+  anomalyDetectionExpression?: boolean;
+  recoveryConditions?: boolean;
+  grafanaManagedRecordingRules?: boolean;
+  alertRuleDependencies?: boolean;
+  livePipeline?: boolean;
 }
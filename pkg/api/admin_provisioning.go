@@ -3,11 +3,18 @@ package api
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/web"
 )
 
+// maxGitWebhookBodySize is the maximum size of the body of git webhook requests.
+const maxGitWebhookBodySize = 1 << 20
+
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
 //
 // Reload dashboard provisioning configurations.
@@ -107,3 +114,53 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// AdminProvisioningAlertingDrift reports the differences between the alerting resources provisioned from files
+// and the provisioning files. The differences can be reverted by reloading the alerting provisioning configurations.
+func (hs *HTTPServer) AdminProvisioningAlertingDrift(c *contextmodel.ReqContext) response.Response {
+	report, err := hs.ProvisioningService.DetectAlertingDrift(c.Req.Context(), false)
+	if err != nil {
+		return response.Error(500, "Failed to detect drift of alerting resources", err)
+	}
+	return response.JSON(200, report)
+}
+
+// swagger:route POST /provisioning/dashboards/git/{name}/webhook admin_provisioning provisioningDashboardsGitWebhook
+//
+// Sync a git repository of dashboard provisioning.
+//
+// Makes the git dashboard provider with the specified name pull its repository and provision its dashboards without waiting for the next poll. The request doesn't require authentication, instead the `X-Hub-Signature-256` header must contain the hex encoded HMAC-SHA256 of the body with the `webhookSecret` of the provider, optionally prefixed with `sha256=`.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) ProvisioningDashboardsGitWebhook(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxGitWebhookBodySize))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read request body", err)
+	}
+
+	err = hs.ProvisioningService.TriggerDashboardGitSync(web.Params(c.Req)[":name"], body, c.Req.Header.Get("X-Hub-Signature-256"))
+	switch {
+	case errors.Is(err, dashboards.ErrGitProviderNotFound):
+		return response.Error(http.StatusNotFound, "Git dashboard provider not found", err)
+	case errors.Is(err, dashboards.ErrInvalidWebhookSignature):
+		return response.Error(http.StatusUnauthorized, "Invalid webhook signature", err)
+	case err != nil:
+		return response.Error(http.StatusInternalServerError, "Failed to sync git repository", err)
+	}
+	return response.Success("Git repository sync triggered")
+}
+
+// swagger:parameters provisioningDashboardsGitWebhook
+type ProvisioningDashboardsGitWebhookParams struct {
+	// in:path
+	// required:true
+	Name string `json:"name"`
+	// HMAC-SHA256 of the body with the webhook secret of the provider.
+	// in:header
+	// required:true
+	Signature string `json:"X-Hub-Signature-256"`
+}
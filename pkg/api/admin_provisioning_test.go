@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -185,3 +186,44 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningAlertingDrift(t *testing.T) {
+	report := alerting.DriftReport{Drifts: []alerting.Drift{
+		{OrgID: 1, ResourceType: "alertRule", ResourceID: "rule", Kind: alerting.DriftModified, Filename: "rules.yaml", Provenance: "file", Diff: []string{"Title"}},
+	}}
+
+	t.Run("should return the drift report", func(t *testing.T) {
+		pService := provisioning.NewProvisioningServiceMock(context.Background())
+		pService.DetectAlertingDriftFunc = func(ctx context.Context, revert bool) (alerting.DriftReport, error) {
+			return report, nil
+		}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.ProvisioningService = pService
+		})
+		permissions := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersAlertRules}}
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/alerting/drift"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.JSONEq(t, `{"drifts":[{"orgId":1,"resourceType":"alertRule","resourceId":"rule","kind":"modified","filename":"rules.yaml","provenance":"file","diff":["Title"]}],"reverted":false}`, string(body))
+		assert.Equal(t, []any{false}, pService.Calls.DetectAlertingDrift, "the report should not revert the drift")
+	})
+
+	t.Run("should fail without permission", func(t *testing.T) {
+		pService := provisioning.NewProvisioningServiceMock(context.Background())
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.ProvisioningService = pService
+		})
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/alerting/drift"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Empty(t, pService.Calls.DetectAlertingDrift)
+	})
+}
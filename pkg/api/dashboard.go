@@ -211,6 +211,7 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 			// is for better UX, showing in Save/Delete dialogs and so it won't break anything if it is empty.
 			hs.log.Warn("Failed to create ProvisionedExternalId", "err", err)
 		}
+		meta.ProvisionedCommitSha = provisioningData.CommitSHA
 	}
 
 	// make sure db version is in sync with json model version
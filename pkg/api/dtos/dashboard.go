@@ -33,6 +33,7 @@ type DashboardMeta struct {
 	FolderUrl              string                             `json:"folderUrl"`
 	Provisioned            bool                               `json:"provisioned"`
 	ProvisionedExternalId  string                             `json:"provisionedExternalId"`
+	ProvisionedCommitSha   string                             `json:"provisionedCommitSha,omitempty"`
 	AnnotationsPermissions *dashboardsV0.AnnotationPermission `json:"annotationsPermissions"`
 	PublicDashboardUID     string                             `json:"publicDashboardUid,omitempty"`
 	PublicDashboardEnabled bool                               `json:"publicDashboardEnabled,omitempty"`
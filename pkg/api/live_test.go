@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_LivePipeline_AccessControl(t *testing.T) {
+	setup := func(t *testing.T, features featuremgmt.FeatureToggles) *webtest.Server {
+		cfg := setting.NewCfg()
+		cfg.AppURL = "http://localhost:3000/"
+		cfg.DataPath = t.TempDir()
+		gLive, err := live.ProvideService(nil, cfg,
+			routing.NewRouteRegister(),
+			nil, nil, nil, nil,
+			nil,
+			nil,
+			&usagestats.UsageStatsMock{T: t},
+			nil,
+			features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		require.NoError(t, err)
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = cfg
+			hs.Features = features
+			hs.Live = gLive
+			hs.LivePushGateway = pushhttp.ProvideService(cfg, gLive)
+		})
+	}
+	send := func(t *testing.T, server *webtest.Server, req *http.Request, role org.RoleType) int {
+		t.Helper()
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: role}))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode
+	}
+
+	t.Run("should not register pipeline routes without the feature toggle", func(t *testing.T) {
+		server := setup(t, featuremgmt.WithFeatures())
+		assert.Equal(t, http.StatusNotFound, send(t, server, server.NewGetRequest("/api/live/channel-rules"), org.RoleAdmin))
+		assert.Equal(t, http.StatusNotFound, send(t, server, server.NewPostRequest("/api/live/pipeline/push/stream/test/a", strings.NewReader(`{"value": 1}`)), org.RoleAdmin))
+	})
+
+	t.Run("should allow only organization admins to manage channel rules and write configs", func(t *testing.T) {
+		server := setup(t, featuremgmt.WithFeatures(featuremgmt.FlagLivePipeline))
+		for _, url := range []string{"/api/live/channel-rules", "/api/live/write-configs", "/api/live/pipeline-entities", "/api/live/pipeline-export"} {
+			assert.Equal(t, http.StatusForbidden, send(t, server, server.NewGetRequest(url), org.RoleEditor), url)
+			assert.Equal(t, http.StatusOK, send(t, server, server.NewGetRequest(url), org.RoleAdmin), url)
+		}
+	})
+
+	t.Run("should allow only organization admins to push data to channel rules", func(t *testing.T) {
+		server := setup(t, featuremgmt.WithFeatures(featuremgmt.FlagLivePipeline))
+		assert.Equal(t, http.StatusForbidden, send(t, server, server.NewPostRequest("/api/live/pipeline/push/stream/test/a", strings.NewReader(`{"value": 1}`)), org.RoleEditor))
+		// There is no channel rule for the channel.
+		assert.Equal(t, http.StatusNotFound, send(t, server, server.NewPostRequest("/api/live/pipeline/push/stream/test/a", strings.NewReader(`{"value": 1}`)), org.RoleAdmin))
+	})
+}
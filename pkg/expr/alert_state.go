@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AlertStateInstance is the state of an alert instance of the rule that is read by an AlertStateCommand.
+type AlertStateInstance struct {
+	Labels data.Labels `json:"labels"`
+	Firing bool        `json:"firing"`
+	// Value is the recorded value of the instance if the rule is a recording rule. If it is set, it is returned
+	// instead of the state of the instance.
+	Value *float64 `json:"value,omitempty"`
+}
+
+// AlertStateCommand is an expression command that returns the state of the alert instances of another alert rule.
+// The result contains a number per instance, labeled with the labels of the instance, that is 1 if the instance is
+// firing and 0 otherwise, or the value of the instance if it has one. If the rule has no instances, the result is a
+// single number without labels that is 0.
+//
+// The command does not read the state itself: the instances are set to the field "instances" of the query by the
+// alerting evaluator before the pipeline is built, see SetInstancesToAlertStateCommand.
+type AlertStateCommand struct {
+	RefID     string
+	RuleUID   string
+	Instances []AlertStateInstance
+}
+
+// AlertStateCommandConfig is the model of the alert state command.
+type AlertStateCommandConfig struct {
+	RuleUID   string               `json:"ruleUid"`
+	Instances []AlertStateInstance `json:"instances,omitempty"`
+}
+
+// NewAlertStateCommand creates a new AlertStateCommand.
+func NewAlertStateCommand(refID, ruleUID string, instances []AlertStateInstance) (*AlertStateCommand, error) {
+	if ruleUID == "" {
+		return nil, errors.New("alert state command requires the UID of the rule to read")
+	}
+	return &AlertStateCommand{
+		RefID:     refID,
+		RuleUID:   ruleUID,
+		Instances: instances,
+	}, nil
+}
+
+// UnmarshalAlertStateCommand creates an AlertStateCommand from Grafana's frontend query.
+func UnmarshalAlertStateCommand(rn *rawNode) (*AlertStateCommand, error) {
+	cfg := AlertStateCommandConfig{}
+	if err := json.Unmarshal(rn.QueryRaw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the alert state command: %w", err)
+	}
+	return NewAlertStateCommand(rn.RefID, cfg.RuleUID, cfg.Instances)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (c *AlertStateCommand) NeedsVars() []string {
+	return []string{}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (c *AlertStateCommand) Execute(_ context.Context, _ time.Time, _ mathexp.Vars, _ tracing.Tracer) (mathexp.Results, error) {
+	if len(c.Instances) == 0 {
+		n := mathexp.NewNumber(c.RefID, nil)
+		var v float64
+		n.SetValue(&v)
+		return mathexp.Results{Values: mathexp.Values{n}}, nil
+	}
+
+	result := mathexp.Results{Values: make(mathexp.Values, 0, len(c.Instances))}
+	for _, instance := range c.Instances {
+		n := mathexp.NewNumber(c.RefID, instance.Labels.Copy())
+		var v float64
+		switch {
+		case instance.Value != nil:
+			v = *instance.Value
+		case instance.Firing:
+			v = 1
+		}
+		n.SetValue(&v)
+		result.Values = append(result.Values, n)
+	}
+	return result, nil
+}
+
+// AlertStateRuleUID returns the UID of the rule which state is read by the query if the raw model describes an alert
+// state command. Otherwise, it returns an empty string.
+func AlertStateRuleUID(query map[string]any) string {
+	t, err := GetExpressionCommandType(query)
+	if err != nil || t != TypeAlertState {
+		return ""
+	}
+	uid, _ := query["ruleUid"].(string)
+	return uid
+}
+
+// SetInstancesToAlertStateCommand mutates the input map and sets field "instances" with the provided instances.
+func SetInstancesToAlertStateCommand(query map[string]any, instances []AlertStateInstance) error {
+	t, err := GetExpressionCommandType(query)
+	if err != nil {
+		return err
+	}
+	if t != TypeAlertState {
+		return errors.New("not an alert state command")
+	}
+	if instances == nil {
+		instances = []AlertStateInstance{}
+	}
+	query["instances"] = instances
+	return nil
+}
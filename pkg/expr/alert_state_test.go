@@ -0,0 +1,108 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+func TestUnmarshalAlertStateCommand(t *testing.T) {
+	t.Run("should read instances set to the query", func(t *testing.T) {
+		query := map[string]any{
+			"type":    "alert_state",
+			"ruleUid": "upstream",
+		}
+		require.Equal(t, "upstream", AlertStateRuleUID(query))
+		instances := []AlertStateInstance{
+			{Labels: data.Labels{"service": "db"}, Firing: true},
+			{Labels: data.Labels{"service": "cache"}},
+		}
+		require.NoError(t, SetInstancesToAlertStateCommand(query, instances))
+		raw, err := json.Marshal(query)
+		require.NoError(t, err)
+
+		cmd, err := UnmarshalAlertStateCommand(&rawNode{RefID: "B", QueryRaw: raw})
+		require.NoError(t, err)
+		require.Equal(t, "upstream", cmd.RuleUID)
+		require.Equal(t, instances, cmd.Instances)
+		require.Empty(t, cmd.NeedsVars())
+	})
+
+	t.Run("should fail if rule UID is missing", func(t *testing.T) {
+		_, err := UnmarshalAlertStateCommand(&rawNode{RefID: "B", QueryRaw: []byte(`{"type":"alert_state"}`)})
+		require.ErrorContains(t, err, "requires the UID of the rule")
+	})
+
+	t.Run("should not set instances to other commands", func(t *testing.T) {
+		query := map[string]any{"type": "math", "expression": "$A > 1"}
+		require.Empty(t, AlertStateRuleUID(query))
+		require.Error(t, SetInstancesToAlertStateCommand(query, nil))
+	})
+
+	t.Run("should be disabled without feature toggle", func(t *testing.T) {
+		rn := &rawNode{RefID: "B", Query: map[string]any{"type": "alert_state", "ruleUid": "upstream"}, QueryRaw: []byte(`{"type":"alert_state","ruleUid":"upstream"}`)}
+		_, err := buildCMDNode(rn, featuremgmt.WithFeatures())
+		require.ErrorContains(t, err, featuremgmt.FlagAlertRuleDependencies)
+
+		node, err := buildCMDNode(rn, featuremgmt.WithFeatures(featuremgmt.FlagAlertRuleDependencies))
+		require.NoError(t, err)
+		require.Equal(t, TypeAlertState, node.CMDType)
+	})
+}
+
+func TestAlertStateCommandExecute(t *testing.T) {
+	valueOf := func(t *testing.T, v mathexp.Value) float64 {
+		n, ok := v.(mathexp.Number)
+		require.Truef(t, ok, "expected a number but got %T", v)
+		require.NotNil(t, n.GetFloat64Value())
+		return *n.GetFloat64Value()
+	}
+
+	t.Run("should return a number per instance", func(t *testing.T) {
+		cmd, err := NewAlertStateCommand("B", "upstream", []AlertStateInstance{
+			{Labels: data.Labels{"service": "db"}, Firing: true},
+			{Labels: data.Labels{"service": "cache"}},
+		})
+		require.NoError(t, err)
+
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 2)
+		require.Equal(t, data.Labels{"service": "db"}, results.Values[0].GetLabels())
+		require.Equal(t, 1.0, valueOf(t, results.Values[0]))
+		require.Equal(t, data.Labels{"service": "cache"}, results.Values[1].GetLabels())
+		require.Equal(t, 0.0, valueOf(t, results.Values[1]))
+	})
+
+	t.Run("should return the value of instances of recording rules", func(t *testing.T) {
+		value := 4.5
+		cmd, err := NewAlertStateCommand("B", "upstream", []AlertStateInstance{
+			{Labels: data.Labels{"service": "db"}, Value: &value},
+		})
+		require.NoError(t, err)
+
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+		require.Equal(t, 4.5, valueOf(t, results.Values[0]))
+	})
+
+	t.Run("should return zero without labels if rule has no instances", func(t *testing.T) {
+		cmd, err := NewAlertStateCommand("B", "upstream", nil)
+		require.NoError(t, err)
+
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+		require.Empty(t, results.Values[0].GetLabels())
+		require.Equal(t, 0.0, valueOf(t, results.Values[0]))
+	})
+}
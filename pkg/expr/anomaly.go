@@ -0,0 +1,349 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	// AnomalyAlgorithmZScore compares each point with the mean and standard deviation of the baseline.
+	AnomalyAlgorithmZScore = "zscore"
+	// AnomalyAlgorithmMAD compares each point with the median and the median absolute deviation of the baseline.
+	// It is less sensitive to outliers in the baseline than AnomalyAlgorithmZScore.
+	AnomalyAlgorithmMAD = "mad"
+	// AnomalyAlgorithmSeasonal compares each point with the points at the same time in previous seasons.
+	AnomalyAlgorithmSeasonal = "seasonal"
+
+	// AnomalyOutputAnomalies returns a series per input series with 1 for anomalous points and 0 otherwise.
+	AnomalyOutputAnomalies = "anomalies"
+	// AnomalyOutputBands returns the upper and lower bands of the expected values.
+	AnomalyOutputBands = "bands"
+	// AnomalyOutputAll returns both the anomalies and the bands.
+	AnomalyOutputAll = "all"
+
+	// AnomalyBandLabel is the label added to the band series to tell the upper band from the lower one.
+	AnomalyBandLabel = "anomaly_band"
+
+	defaultAnomalySensitivity = 3.0
+	// madScale makes the median absolute deviation a consistent estimator of the standard deviation of normally distributed data.
+	madScale = 1.4826
+	// minBaselinePoints is the number of points the baseline needs before a point can be checked for anomalies.
+	minBaselinePoints = 2
+)
+
+var (
+	supportedAnomalyAlgorithms = []string{AnomalyAlgorithmZScore, AnomalyAlgorithmMAD, AnomalyAlgorithmSeasonal}
+	supportedAnomalyOutputs    = []string{AnomalyOutputAnomalies, AnomalyOutputBands, AnomalyOutputAll}
+)
+
+// AnomalyCommand is an expression command that detects anomalies in time series without any external service.
+// For every point of a series it builds a baseline from other points of the same series, and marks the point
+// as anomalous when it is outside the band of expected values: center ± sensitivity × spread of the baseline.
+//
+// The baseline of a point is:
+//   - all points of the series, or the points in the Window before the point, for AnomalyAlgorithmZScore and AnomalyAlgorithmMAD.
+//   - the points at the same time in each previous Season for AnomalyAlgorithmSeasonal. Window is then the tolerance used to
+//     find these points, and defaults to half of the median interval of the series.
+//
+// Points without enough baseline are null in all result series.
+type AnomalyCommand struct {
+	RefID        string
+	ReferenceVar string
+	Algorithm    string
+	Sensitivity  float64
+	Window       time.Duration
+	Season       time.Duration
+	Output       string
+}
+
+// AnomalyCommandConfig is the model of the anomaly command sent by the frontend.
+type AnomalyCommandConfig struct {
+	Expression  string   `json:"expression"`
+	Algorithm   string   `json:"algorithm"`
+	Sensitivity *float64 `json:"sensitivity,omitempty"`
+	Window      string   `json:"window,omitempty"`
+	Season      string   `json:"season,omitempty"`
+	Output      string   `json:"output,omitempty"`
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand. Empty algorithm and output default to
+// AnomalyAlgorithmZScore and AnomalyOutputAnomalies.
+func NewAnomalyCommand(refID, referenceVar, algorithm string, sensitivity float64, window, season time.Duration, output string) (*AnomalyCommand, error) {
+	if algorithm == "" {
+		algorithm = AnomalyAlgorithmZScore
+	}
+	if !isOneOf(algorithm, supportedAnomalyAlgorithms) {
+		return nil, fmt.Errorf("expected anomaly algorithm to be one of [%s], got %s", strings.Join(supportedAnomalyAlgorithms, ", "), algorithm)
+	}
+	if output == "" {
+		output = AnomalyOutputAnomalies
+	}
+	if !isOneOf(output, supportedAnomalyOutputs) {
+		return nil, fmt.Errorf("expected anomaly output to be one of [%s], got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	if sensitivity <= 0 || math.IsNaN(sensitivity) || math.IsInf(sensitivity, 0) {
+		return nil, fmt.Errorf("anomaly sensitivity must be a positive number, got %v", sensitivity)
+	}
+	if window < 0 {
+		return nil, fmt.Errorf("anomaly window must not be negative, got %s", window)
+	}
+	if algorithm == AnomalyAlgorithmSeasonal && season <= 0 {
+		return nil, fmt.Errorf("the %s anomaly algorithm requires a positive season, got %s", AnomalyAlgorithmSeasonal, season)
+	}
+
+	return &AnomalyCommand{
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Algorithm:    algorithm,
+		Sensitivity:  sensitivity,
+		Window:       window,
+		Season:       season,
+		Output:       output,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	cfg := AnomalyCommandConfig{}
+	if err := json.Unmarshal(rn.QueryRaw, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar := strings.TrimPrefix(cfg.Expression, "$")
+	if referenceVar == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+
+	sensitivity := defaultAnomalySensitivity
+	if cfg.Sensitivity != nil {
+		sensitivity = *cfg.Sensitivity
+	}
+
+	var window, season time.Duration
+	var err error
+	if cfg.Window != "" {
+		window, err = gtime.ParseDuration(cfg.Window)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, cfg.Window, err)
+		}
+	}
+	if cfg.Season != "" {
+		season, err = gtime.ParseDuration(cfg.Season)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, cfg.Season, err)
+		}
+	}
+
+	return NewAnomalyCommand(rn.RefID, referenceVar, cfg.Algorithm, sensitivity, window, season, cfg.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	span.SetAttributes(attribute.String("algorithm", ac.Algorithm))
+	defer span.End()
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			newRes.Values = append(newRes.Values, ac.detect(v)...)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// detect returns the series the command outputs for the input series.
+func (ac *AnomalyCommand) detect(s mathexp.Series) []mathexp.Value {
+	points := make([]anomalyPoint, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		points = append(points, anomalyPoint{t: t, v: v})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+
+	labels := s.GetLabels()
+	anomalies := mathexp.NewSeries(ac.RefID, labels.Copy(), len(points))
+	upper := mathexp.NewSeries(ac.RefID, bandLabels(labels, "upper"), len(points))
+	lower := mathexp.NewSeries(ac.RefID, bandLabels(labels, "lower"), len(points))
+
+	tolerance := ac.Window
+	if ac.Algorithm == AnomalyAlgorithmSeasonal && tolerance == 0 {
+		tolerance = medianInterval(points) / 2
+	}
+
+	for i, p := range points {
+		var isAnomaly, upperBand, lowerBand *float64
+		if center, spread, ok := ac.baselineStats(ac.baseline(points, i, tolerance)); ok {
+			u, l := center+ac.Sensitivity*spread, center-ac.Sensitivity*spread
+			upperBand, lowerBand = &u, &l
+			if p.v != nil && !math.IsNaN(*p.v) {
+				a := 0.0
+				if *p.v > u || *p.v < l {
+					a = 1
+				}
+				isAnomaly = &a
+			}
+		}
+		anomalies.SetPoint(i, p.t, isAnomaly)
+		upper.SetPoint(i, p.t, upperBand)
+		lower.SetPoint(i, p.t, lowerBand)
+	}
+
+	switch ac.Output {
+	case AnomalyOutputBands:
+		return []mathexp.Value{upper, lower}
+	case AnomalyOutputAll:
+		return []mathexp.Value{anomalies, upper, lower}
+	default:
+		return []mathexp.Value{anomalies}
+	}
+}
+
+type anomalyPoint struct {
+	t time.Time
+	v *float64
+}
+
+// baseline returns the values the point at index idx is compared with. The points must be sorted by time.
+func (ac *AnomalyCommand) baseline(points []anomalyPoint, idx int, tolerance time.Duration) []float64 {
+	var values []float64
+	add := func(p anomalyPoint) {
+		if p.v != nil && !math.IsNaN(*p.v) {
+			values = append(values, *p.v)
+		}
+	}
+
+	switch {
+	case ac.Algorithm == AnomalyAlgorithmSeasonal:
+		first := points[0].t
+		for at := points[idx].t.Add(-ac.Season); !at.Before(first.Add(-tolerance)); at = at.Add(-ac.Season) {
+			if j, ok := nearestPoint(points, at, tolerance); ok {
+				add(points[j])
+			}
+		}
+	case ac.Window > 0:
+		from := points[idx].t.Add(-ac.Window)
+		for j := idx - 1; j >= 0 && !points[j].t.Before(from); j-- {
+			add(points[j])
+		}
+	default:
+		for _, p := range points {
+			add(p)
+		}
+	}
+	return values
+}
+
+// baselineStats returns the center and the spread of the baseline, or false if the baseline has too few values.
+func (ac *AnomalyCommand) baselineStats(values []float64) (float64, float64, bool) {
+	if len(values) < minBaselinePoints {
+		return 0, 0, false
+	}
+	if ac.Algorithm == AnomalyAlgorithmMAD {
+		median := medianOf(values)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - median)
+		}
+		return median, madScale * medianOf(deviations), true
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+	return mean, math.Sqrt(variance), true
+}
+
+// nearestPoint returns the index of the point closest to t, if it is not further than tolerance.
+func nearestPoint(points []anomalyPoint, t time.Time, tolerance time.Duration) (int, bool) {
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].t.Before(t)
+	})
+	best, bestDiff := -1, time.Duration(math.MaxInt64)
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(points) {
+			continue
+		}
+		diff := points[j].t.Sub(t)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= tolerance && diff < bestDiff {
+			best, bestDiff = j, diff
+		}
+	}
+	return best, best >= 0
+}
+
+// medianInterval returns the median of the intervals between consecutive points.
+func medianInterval(points []anomalyPoint) time.Duration {
+	if len(points) < 2 {
+		return 0
+	}
+	intervals := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		intervals = append(intervals, float64(points[i].t.Sub(points[i-1].t)))
+	}
+	return time.Duration(medianOf(intervals))
+}
+
+// medianOf returns the median of the values. It sorts the values in place.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func bandLabels(labels data.Labels, band string) data.Labels {
+	result := labels.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	result[AnomalyBandLabel] = band
+	return result
+}
+
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	type testCase struct {
+		description string
+		query       string
+		assert      func(*testing.T, *AnomalyCommand, error)
+	}
+
+	cases := []testCase{
+		{
+			description: "should use defaults",
+			query:       `{ "expression": "$A" }`,
+			assert: func(t *testing.T, cmd *AnomalyCommand, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{"A"}, cmd.NeedsVars())
+				require.Equal(t, AnomalyAlgorithmZScore, cmd.Algorithm)
+				require.Equal(t, AnomalyOutputAnomalies, cmd.Output)
+				require.Equal(t, defaultAnomalySensitivity, cmd.Sensitivity)
+			},
+		},
+		{
+			description: "should parse durations",
+			query:       `{ "expression": "A", "algorithm": "seasonal", "season": "1d", "window": "5m", "sensitivity": 2, "output": "all" }`,
+			assert: func(t *testing.T, cmd *AnomalyCommand, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 24*time.Hour, cmd.Season)
+				require.Equal(t, 5*time.Minute, cmd.Window)
+				require.Equal(t, 2.0, cmd.Sensitivity)
+				require.Equal(t, AnomalyOutputAll, cmd.Output)
+			},
+		},
+		{
+			description: "should fail if expression is missing",
+			query:       `{ "algorithm": "mad" }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, "no variable specified")
+			},
+		},
+		{
+			description: "should fail on unknown algorithm",
+			query:       `{ "expression": "A", "algorithm": "prophet" }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, "expected anomaly algorithm to be one of")
+			},
+		},
+		{
+			description: "should fail on unknown output",
+			query:       `{ "expression": "A", "output": "score" }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, "expected anomaly output to be one of")
+			},
+		},
+		{
+			description: "should fail if sensitivity is not positive",
+			query:       `{ "expression": "A", "sensitivity": 0 }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, "sensitivity must be a positive number")
+			},
+		},
+		{
+			description: "should fail if seasonal algorithm has no season",
+			query:       `{ "expression": "A", "algorithm": "seasonal" }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, "requires a positive season")
+			},
+		},
+		{
+			description: "should fail on invalid window",
+			query:       `{ "expression": "A", "window": "abc" }`,
+			assert: func(t *testing.T, _ *AnomalyCommand, err error) {
+				require.ErrorContains(t, err, `failed to parse anomaly "window"`)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:    "B",
+				QueryRaw: []byte(tc.query),
+			})
+			tc.assert(t, cmd, err)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	start := time.Unix(0, 0)
+	newSeries := func(step time.Duration, values ...*float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*step), v)
+		}
+		return s
+	}
+	values := func(s mathexp.Series) []*float64 {
+		result := make([]*float64, 0, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			result = append(result, s.GetValue(i))
+		}
+		return result
+	}
+	p := util.Pointer[float64]
+
+	type testCase struct {
+		description string
+		cmd         *AnomalyCommand
+		input       mathexp.Series
+		expected    []*float64
+	}
+
+	cases := []testCase{
+		{
+			description: "zscore should mark points far from the mean",
+			cmd:         &AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmZScore, Sensitivity: 2},
+			input:       newSeries(time.Minute, p(10), p(10), p(10), p(10), p(10), p(10), p(10), p(10), p(10), p(100)),
+			expected:    []*float64{p(0), p(0), p(0), p(0), p(0), p(0), p(0), p(0), p(0), p(1)},
+		},
+		{
+			description: "mad should not be skewed by outliers in the baseline",
+			cmd:         &AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmMAD, Sensitivity: 3},
+			input:       newSeries(time.Minute, p(10), p(11), p(9), p(10), p(1000), p(10), p(11), p(9)),
+			expected:    []*float64{p(0), p(0), p(0), p(0), p(1), p(0), p(0), p(0)},
+		},
+		{
+			description: "rolling window should need enough points before the first result",
+			cmd:         &AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmZScore, Sensitivity: 3, Window: 3 * time.Minute},
+			input:       newSeries(time.Minute, p(10), p(12), p(11), p(10), p(50), nil),
+			expected:    []*float64{nil, nil, p(0), p(0), p(1), nil},
+		},
+		{
+			description: "seasonal should compare points with the same time in previous seasons",
+			cmd:         &AnomalyCommand{RefID: "B", ReferenceVar: "A", Algorithm: AnomalyAlgorithmSeasonal, Sensitivity: 3, Season: 3 * time.Minute},
+			input:       newSeries(time.Minute, p(1), p(50), p(5), p(2), p(52), p(6), p(1), p(51), p(5), p(1), p(5), p(5)),
+			expected:    []*float64{nil, nil, nil, nil, nil, nil, p(0), p(0), p(0), p(0), p(1), p(0)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{tc.input}}}
+			res, err := tc.cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			s, ok := res.Values[0].(mathexp.Series)
+			require.True(t, ok)
+			require.Equal(t, data.Labels{"host": "a"}, s.GetLabels())
+			require.Equal(t, tc.expected, values(s))
+		})
+	}
+
+	t.Run("should return bands labeled by band", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyAlgorithmZScore, 1, 0, 0, AnomalyOutputAll)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{newSeries(time.Minute, p(1), p(3))}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, res.Values[1].GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, res.Values[2].GetLabels())
+		require.Equal(t, []*float64{p(3), p(3)}, values(res.Values[1].(mathexp.Series)))
+		require.Equal(t, []*float64{p(1), p(1)}, values(res.Values[2].(mathexp.Series)))
+	})
+
+	t.Run("should return NoData if input is NoData", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyAlgorithmMAD, 3, 0, 0, "")
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}}
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("should fail if input is a number", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyAlgorithmMAD, 3, 0, 0, "")
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}}}
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "can only detect anomalies in type series")
+	})
+}
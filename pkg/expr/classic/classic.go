@@ -36,6 +36,8 @@ import (
 type ConditionsCmd struct {
 	Conditions []condition
 	RefID      string
+	// Invert inverts the outcome of ConditionsCmd when it is not no data.
+	Invert bool
 }
 
 // condition is a single condition in ConditionsCmd.
@@ -100,6 +102,10 @@ func (cmd *ConditionsCmd) Execute(ctx context.Context, t time.Time, vars mathexp
 		matches = append(matches, condMatches...)
 	}
 
+	if cmd.Invert {
+		isFiring = !isFiring
+	}
+
 	// Start to prepare the result of the ConditionsCmd. It contains a mathexp.Number
 	// that has a value of 1, 0, or nil, depending on whether the result is firing, normal,
 	// or no data; and a list of matches for all conditions
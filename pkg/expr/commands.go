@@ -323,6 +323,12 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeSQL is the CMDType for running a SQL statement over the results of other queries and expressions.
+	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series.
+	TypeAnomaly
+	// TypeAlertState is the CMDType for reading the state of the alert instances of another alert rule.
+	TypeAlertState
 )
 
 func (gt CommandType) String() string {
@@ -335,6 +341,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeSQL:
+		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeAlertState:
+		return "alert_state"
 	default:
 		return "unknown"
 	}
@@ -353,6 +365,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "sql":
+		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "alert_state":
+		return TypeAlertState, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -0,0 +1,55 @@
+package expr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type executionStatsKey struct{}
+
+// ExecutionStats collects how much time the execution of a data pipeline spent in the queries to datasources and in
+// the expressions. Callers that are interested in the statistics add them to the context with WithExecutionStats
+// before they execute the pipeline.
+type ExecutionStats struct {
+	mtx                sync.Mutex
+	datasourceDuration time.Duration
+	expressionDuration time.Duration
+}
+
+// WithExecutionStats returns a copy of the context that collects the statistics of the executed pipelines into stats.
+func WithExecutionStats(ctx context.Context, stats *ExecutionStats) context.Context {
+	return context.WithValue(ctx, executionStatsKey{}, stats)
+}
+
+func executionStatsFromContext(ctx context.Context) *ExecutionStats {
+	stats, _ := ctx.Value(executionStatsKey{}).(*ExecutionStats)
+	return stats
+}
+
+// DatasourceDuration returns the time spent in the queries to datasources, including Machine Learning queries.
+func (s *ExecutionStats) DatasourceDuration() time.Duration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.datasourceDuration
+}
+
+// ExpressionDuration returns the time spent in the expressions.
+func (s *ExecutionStats) ExpressionDuration() time.Duration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.expressionDuration
+}
+
+func (s *ExecutionStats) observe(nodeType NodeType, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if nodeType == TypeCMDNode {
+		s.expressionDuration += d
+		return
+	}
+	s.datasourceDuration += d
+}
@@ -0,0 +1,30 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionStats(t *testing.T) {
+	t.Run("should be nil if the context has no stats", func(t *testing.T) {
+		stats := executionStatsFromContext(context.Background())
+		require.Nil(t, stats)
+		require.NotPanics(t, func() {
+			stats.observe(TypeDatasourceNode, time.Second)
+		})
+	})
+
+	t.Run("should add durations by node type", func(t *testing.T) {
+		stats := &ExecutionStats{}
+		ctx := WithExecutionStats(context.Background(), stats)
+		executionStatsFromContext(ctx).observe(TypeDatasourceNode, time.Second)
+		executionStatsFromContext(ctx).observe(TypeMLNode, time.Second)
+		executionStatsFromContext(ctx).observe(TypeCMDNode, time.Millisecond)
+
+		require.Equal(t, 2*time.Second, stats.DatasourceDuration())
+		require.Equal(t, time.Millisecond, stats.ExpressionDuration())
+	})
+}
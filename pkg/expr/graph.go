@@ -60,6 +60,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	stats := executionStatsFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -72,7 +73,9 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			dsNodes = append(dsNodes, node.(*DSNode))
 		}
 
+		start := time.Now()
 		executeDSNodesGrouped(c, now, vars, s, dsNodes)
+		stats.observe(TypeDatasourceNode, time.Since(start))
 	}
 
 	for _, node := range *dp {
@@ -111,7 +114,9 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		start := time.Now()
 		res, err := execNode.Execute(c, now, vars, s)
+		stats.observe(node.NodeType(), time.Since(start))
 		if err != nil {
 			res.Error = err
 		}
@@ -290,6 +295,36 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 			dp.SetEdge(edge)
 		}
 	}
+
+	return markInputsToSQLExpr(dp)
+}
+
+// markInputsToSQLExpr marks the queries which results are consumed by SQL expressions, so they are
+// passed through as tables. A query can't be the input of both a SQL expression and another kind of
+// expression, because the other expressions require the results converted to numbers or series.
+func markInputsToSQLExpr(dp *simple.DirectedGraph) error {
+	nodeIt := dp.Nodes()
+	for nodeIt.Next() {
+		dsNode, ok := nodeIt.Node().(*DSNode)
+		if !ok {
+			continue
+		}
+
+		var sqlConsumer, otherConsumer string
+		consumers := dp.From(dsNode.ID())
+		for consumers.Next() {
+			cmdNode := consumers.Node().(*CMDNode)
+			if cmdNode.CMDType == TypeSQL {
+				sqlConsumer = cmdNode.RefID()
+			} else {
+				otherConsumer = cmdNode.RefID()
+			}
+		}
+		if sqlConsumer != "" && otherConsumer != "" {
+			return fmt.Errorf("query %v can't be the input of both the sql expression %v and the expression %v, use a separate query for each", dsNode.RefID(), sqlConsumer, otherConsumer)
+		}
+		dsNode.isInputToSQLExpr = sqlConsumer != ""
+	}
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func TestServicebuildPipeLine(t *testing.T) {
@@ -230,8 +231,40 @@ func TestServicebuildPipeLine(t *testing.T) {
 			},
 			expectedOrder: []string{"B", "A"},
 		},
+		{
+			name: "query used by sql expression and reduce expression will error",
+			req: &Request{
+				Queries: []Query{
+					{
+						RefID:      "A",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "SELECT * FROM B",
+							"type": "sql"
+						}`),
+					},
+					{
+						RefID:      "C",
+						DataSource: dataSourceModel(),
+						JSON: json.RawMessage(`{
+							"expression": "B",
+							"reducer": "mean",
+							"type": "reduce"
+						}`),
+					},
+					{
+						RefID: "B",
+						DataSource: &datasources.DataSource{
+							UID: "Fake",
+						},
+						TimeRange: AbsoluteTimeRange{},
+					},
+				},
+			},
+			expectErrContains: "can't be the input of both the sql expression A and the expression C",
+		},
 	}
-	s := Service{}
+	s := Service{features: featuremgmt.WithFeatures(featuremgmt.FlagSqlExpressions)}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			nodes, err := s.buildPipeline(tt.req)
@@ -246,6 +279,42 @@ func TestServicebuildPipeLine(t *testing.T) {
 	}
 }
 
+func TestBuildPipelineMarksInputsToSQLExpr(t *testing.T) {
+	s := Service{features: featuremgmt.WithFeatures(featuremgmt.FlagSqlExpressions)}
+	nodes, err := s.buildPipeline(&Request{
+		Queries: []Query{
+			{
+				RefID:      "A",
+				DataSource: dataSourceModel(),
+				JSON: json.RawMessage(`{
+					"expression": "SELECT * FROM B",
+					"type": "sql"
+				}`),
+			},
+			{
+				RefID:      "C",
+				DataSource: dataSourceModel(),
+				JSON: json.RawMessage(`{
+					"expression": "D",
+					"reducer": "mean",
+					"type": "reduce"
+				}`),
+			},
+			{RefID: "B", DataSource: &datasources.DataSource{UID: "Fake"}, TimeRange: AbsoluteTimeRange{}},
+			{RefID: "D", DataSource: &datasources.DataSource{UID: "Fake"}, TimeRange: AbsoluteTimeRange{}},
+		},
+	})
+	require.NoError(t, err)
+
+	inputs := map[string]bool{}
+	for _, node := range nodes {
+		if dsNode, ok := node.(*DSNode); ok {
+			inputs[dsNode.RefID()] = dsNode.isInputToSQLExpr
+		}
+	}
+	require.Equal(t, map[string]bool{"B": true, "D": false}, inputs)
+}
+
 func TestGetCommandsFromPipeline(t *testing.T) {
 	pipeline := DataPipeline{
 		&MLNode{},
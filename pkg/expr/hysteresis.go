@@ -2,13 +2,18 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 type Fingerprints map[data.Fingerprint]struct{}
@@ -87,6 +92,163 @@ func NewHysteresisCommand(refID string, referenceVar string, loadCondition Thres
 	}, nil
 }
 
+// HysteresisConditionCommand generalizes HysteresisCommand to any command that returns 0 or 1 for each metric, such as
+// math expressions and classic conditions:
+// - LoadingCondition is used for the metrics that are not loaded, i.e. are not in LoadedDimensions.
+// - UnloadingCondition is used for the metrics that are loaded. It is supposed to be inverted, i.e. to return 0 when
+// the metric recovers, so the results of both conditions mean the same.
+// Unlike HysteresisCommand, the command cannot split the input of the conditions by the loaded dimensions because the
+// conditions can reference any number of variables. Instead, both conditions are executed and a result is picked for
+// each metric by the fingerprint of its labels.
+type HysteresisConditionCommand struct {
+	RefID              string
+	LoadingCondition   Command
+	UnloadingCondition Command
+	LoadedDimensions   Fingerprints
+}
+
+// NewHysteresisConditionCommand creates a new HysteresisConditionCommand.
+func NewHysteresisConditionCommand(refID string, loadCondition Command, unloadCondition Command, l Fingerprints) *HysteresisConditionCommand {
+	return &HysteresisConditionCommand{
+		RefID:              refID,
+		LoadingCondition:   loadCondition,
+		UnloadingCondition: unloadCondition,
+		LoadedDimensions:   l,
+	}
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (h *HysteresisConditionCommand) NeedsVars() []string {
+	vars := h.LoadingCondition.NeedsVars()
+	seen := make(map[string]struct{}, len(vars))
+	for _, v := range vars {
+		seen[v] = struct{}{}
+	}
+	for _, v := range h.UnloadingCondition.NeedsVars() {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+func (h *HysteresisConditionCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	loadingResults, err := h.LoadingCondition.Execute(ctx, now, vars, tracer)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	if len(h.LoadedDimensions) == 0 || loadingResults.IsNoData() {
+		return loadingResults, nil
+	}
+
+	unloadingResults, err := h.UnloadingCondition.Execute(ctx, now, vars, tracer)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute unloading condition: %w", err)
+	}
+	unloaded := make(map[data.Fingerprint]mathexp.Value, len(unloadingResults.Values))
+	for _, value := range unloadingResults.Values {
+		if value.Type() == parse.TypeNoData {
+			continue
+		}
+		unloaded[value.GetLabels().Fingerprint()] = value
+	}
+
+	result := mathexp.Results{Values: make(mathexp.Values, 0, len(loadingResults.Values))}
+	for _, value := range loadingResults.Values {
+		fp := value.GetLabels().Fingerprint()
+		if _, ok := h.LoadedDimensions[fp]; ok {
+			if u, ok := unloaded[fp]; ok {
+				value = u
+			}
+		}
+		result.Values = append(result.Values, value)
+	}
+	return result, nil
+}
+
+// UnmarshalMathConditionCommand creates a MathCommand from Grafana's frontend query. If the query has
+// an "unloadExpression" and recovery conditions are enabled, it creates a HysteresisConditionCommand
+// that uses the inverted unload expression for the dimensions in "loadedDimensions".
+func UnmarshalMathConditionCommand(rn *rawNode, features featuremgmt.FeatureToggles) (Command, error) {
+	loading, err := UnmarshalMathCommand(rn)
+	if err != nil {
+		return nil, err
+	}
+	rawUnload, ok := rn.Query["unloadExpression"]
+	if !ok || !features.IsEnabledGlobally(featuremgmt.FlagRecoveryConditions) {
+		return loading, nil
+	}
+	unloadExpr, ok := rawUnload.(string)
+	if !ok {
+		return nil, fmt.Errorf("math unload expression is expected to be a string, got %T", rawUnload)
+	}
+	unloading, err := NewMathCommand(rn.RefID, fmt.Sprintf("!(%s)", unloadExpr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid unloadExpression: %w", err)
+	}
+	d, err := loadedDimensionsFromQuery(rn.Query)
+	if err != nil {
+		return nil, err
+	}
+	return NewHysteresisConditionCommand(rn.RefID, loading, unloading, d), nil
+}
+
+// UnmarshalClassicConditionCommand creates a classic.ConditionsCmd from Grafana's frontend query. If the query has
+// "unloadConditions" and recovery conditions are enabled, it creates a HysteresisConditionCommand that uses the
+// inverted unload conditions for the dimensions in "loadedDimensions".
+func UnmarshalClassicConditionCommand(rn *rawNode, features featuremgmt.FeatureToggles) (Command, error) {
+	loading, err := classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	rawUnload, ok := rn.Query["unloadConditions"]
+	if !ok || !features.IsEnabledGlobally(featuremgmt.FlagRecoveryConditions) {
+		return loading, nil
+	}
+	unloading, err := classic.UnmarshalConditionsCmd(map[string]any{"conditions": rawUnload}, rn.RefID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unloadConditions: %w", err)
+	}
+	if len(unloading.Conditions) == 0 {
+		return nil, errors.New("invalid unloadConditions: at least one condition is required")
+	}
+	unloading.Invert = true
+	d, err := loadedDimensionsFromQuery(rn.Query)
+	if err != nil {
+		return nil, err
+	}
+	return NewHysteresisConditionCommand(rn.RefID, loading, unloading, d), nil
+}
+
+// loadedDimensionsFromQuery reads the field "loadedDimensions" of math and classic condition commands.
+func loadedDimensionsFromQuery(query map[string]any) (Fingerprints, error) {
+	raw, ok := query["loadedDimensions"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var frame *data.Frame
+	switch v := raw.(type) {
+	case *data.Frame:
+		frame = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse loaded dimensions: %w", err)
+		}
+		frame = &data.Frame{}
+		if err := json.Unmarshal(b, frame); err != nil {
+			return nil, fmt.Errorf("failed to parse loaded dimensions: %w", err)
+		}
+	}
+	d, err := FingerprintsFromFrame(frame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse loaded dimensions: %w", err)
+	}
+	return d, nil
+}
+
 // FingerprintsFromFrame converts data.Frame to Fingerprints.
 // The input data frame must have a single field of uint64 type.
 // Returns error if the input data frame has invalid format
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func TestHysteresisExecute(t *testing.T) {
@@ -219,3 +220,172 @@ func TestFingerprintsToFrame(t *testing.T) {
 		})
 	}
 }
+
+func TestHysteresisConditionExecute(t *testing.T) {
+	number := func(label string, value float64) mathexp.Number {
+		n := mathexp.NewNumber("A", data.Labels{"label": label})
+		n.SetValue(&value)
+		return n
+	}
+	fingerprint := func(label string) data.Fingerprint {
+		return data.Labels{"label": label}.Fingerprint()
+	}
+	type result struct {
+		label string
+		value float64
+	}
+
+	tracer := tracing.InitializeTracerForTest()
+
+	loading, err := NewMathCommand("B", "$A > 100")
+	require.NoError(t, err)
+	unloading, err := NewMathCommand("B", "!($A < 30)")
+	require.NoError(t, err)
+
+	input := mathexp.Values{
+		number("value1", 101),
+		number("value2", 50),
+		number("value3", 29),
+		number("value4", 50),
+		number("value5", 29),
+	}
+
+	testCases := []struct {
+		name             string
+		loadedDimensions Fingerprints
+		input            mathexp.Values
+		expected         []result
+		expectNoData     bool
+	}{
+		{
+			name:             "return NoData when no data",
+			loadedDimensions: Fingerprints{fingerprint("value1"): {}},
+			input:            mathexp.Values{mathexp.NewNoData()},
+			expectNoData:     true,
+		},
+		{
+			name:             "use only loading condition if no loaded metrics",
+			loadedDimensions: Fingerprints{},
+			input:            input,
+			expected: []result{
+				{"value1", 1}, {"value2", 0}, {"value3", 0}, {"value4", 0}, {"value5", 0},
+			},
+		},
+		{
+			name: "evaluate loaded metrics against unloading condition",
+			loadedDimensions: Fingerprints{
+				fingerprint("value4"): {},
+				fingerprint("value5"): {},
+			},
+			input: input,
+			expected: []result{
+				{"value1", 1}, {"value2", 0}, {"value3", 0}, {"value4", 1}, {"value5", 0},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewHysteresisConditionCommand("B", loading, unloading, tc.loadedDimensions)
+			res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				"A": mathexp.Results{Values: tc.input},
+			}, tracer)
+			require.NoError(t, err)
+			if tc.expectNoData {
+				require.True(t, res.IsNoData())
+				return
+			}
+			actual := make([]result, 0, len(res.Values))
+			for _, v := range res.Values {
+				actual = append(actual, result{label: v.GetLabels()["label"], value: *v.(mathexp.Number).GetFloat64Value()})
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestUnmarshalHysteresisConditions(t *testing.T) {
+	loadedDimensions := func(t *testing.T, fingerprints Fingerprints) any {
+		b, err := json.Marshal(FingerprintsToFrame(fingerprints))
+		require.NoError(t, err)
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(b, &m))
+		return m
+	}
+
+	t.Run("math command with unloadExpression", func(t *testing.T) {
+		query := map[string]any{
+			"type":             "math",
+			"expression":       "$A > 100",
+			"unloadExpression": "$A < 30",
+			"loadedDimensions": loadedDimensions(t, Fingerprints{1: {}}),
+		}
+		require.True(t, IsHysteresisExpression(query, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions)))
+		require.False(t, IsHysteresisExpression(query, featuremgmt.WithFeatures()))
+
+		cmd, err := UnmarshalMathConditionCommand(&rawNode{RefID: "B", Query: query}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions))
+		require.NoError(t, err)
+		require.IsType(t, &HysteresisConditionCommand{}, cmd)
+		h := cmd.(*HysteresisConditionCommand)
+		require.Equal(t, Fingerprints{1: {}}, h.LoadedDimensions)
+		require.Equal(t, "!($A < 30)", h.UnloadingCondition.(*MathCommand).RawExpression)
+		require.Equal(t, []string{"A"}, h.NeedsVars())
+
+		cmd, err = UnmarshalMathConditionCommand(&rawNode{RefID: "B", Query: query}, featuremgmt.WithFeatures())
+		require.NoError(t, err)
+		require.IsType(t, &MathCommand{}, cmd)
+	})
+
+	t.Run("math command with invalid unloadExpression", func(t *testing.T) {
+		query := map[string]any{
+			"type":             "math",
+			"expression":       "$A > 100",
+			"unloadExpression": "$A <",
+		}
+		_, err := UnmarshalMathConditionCommand(&rawNode{RefID: "B", Query: query}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions))
+		require.ErrorContains(t, err, "invalid unloadExpression")
+	})
+
+	t.Run("classic condition with unloadConditions", func(t *testing.T) {
+		condition := func(t string, v float64) map[string]any {
+			return map[string]any{
+				"evaluator": map[string]any{"params": []any{v}, "type": t},
+				"operator":  map[string]any{"type": "and"},
+				"query":     map[string]any{"params": []any{"A"}},
+				"reducer":   map[string]any{"type": "avg"},
+			}
+		}
+		query := map[string]any{
+			"type":             "classic_conditions",
+			"conditions":       []any{condition("gt", 100)},
+			"unloadConditions": []any{condition("lt", 30)},
+		}
+		require.True(t, IsHysteresisExpression(query, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions)))
+		require.False(t, IsHysteresisExpression(query, featuremgmt.WithFeatures()))
+		require.NoError(t, SetLoadedDimensionsToHysteresisCommand(query, Fingerprints{data.Labels(nil).Fingerprint(): {}}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions)))
+
+		cmd, err := UnmarshalClassicConditionCommand(&rawNode{RefID: "B", Query: query}, featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions))
+		require.NoError(t, err)
+		require.IsType(t, &HysteresisConditionCommand{}, cmd)
+
+		series := func(v float64) mathexp.Values {
+			s := mathexp.NewSeries("A", nil, 1)
+			s.SetPoint(0, time.Unix(0, 0), &v)
+			return mathexp.Values{s}
+		}
+		for _, tc := range []struct {
+			value    float64
+			expected float64
+		}{{value: 120, expected: 1}, {value: 50, expected: 1}, {value: 20, expected: 0}} {
+			res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: series(tc.value)}}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			require.Equalf(t, tc.expected, *res.Values[0].(mathexp.Number).GetFloat64Value(), "value %v", tc.value)
+		}
+	})
+
+	t.Run("should not be hysteresis without unload conditions", func(t *testing.T) {
+		features := featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions)
+		require.False(t, IsHysteresisExpression(map[string]any{"type": "math", "expression": "$A > 100"}, features))
+		require.False(t, IsHysteresisExpression(map[string]any{"type": "classic_conditions", "conditions": []any{}}, features))
+	})
+}
@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +73,39 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"delta": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             delta,
+	},
+	"rate": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             rate,
+	},
+	"cumsum": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             cumsum,
+	},
+	"moving_avg": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             movingAvg,
+		Check:         checkDurationArg(1),
+	},
+	"timeshift": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		F:             timeShift,
+		Check:         checkDurationArg(1),
+	},
+	"percentile": {
+		Args:   []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      percentile,
+		Check:  checkPercentileArg(1),
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +345,308 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// delta returns the difference between each value point and the previous one for each result in SeriesSet.
+// The first point, and points where either value is null, are null. Numbers and Scalars have no previous
+// value, so the result is null.
+func delta(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, func(s Series) Series {
+			var prev *float64
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				var nF *float64
+				if f != nil && prev != nil {
+					d := *f - *prev
+					nF = &d
+				}
+				s.SetPoint(i, t, nF)
+				prev = f
+			}
+			return s
+		}, func(*float64) *float64 {
+			return nil
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// rate returns the per-second rate of increase between each value point and the previous one for each
+// result in SeriesSet. The values are treated as a counter: if a value is lower than the previous one,
+// the counter is assumed to have been reset and the value itself is used as the increase.
+// The first point, and points where either value is null, are null. Numbers and Scalars have no previous
+// value, so the result is null.
+func rate(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, func(s Series) Series {
+			var prevT time.Time
+			var prev *float64
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				var nF *float64
+				if f != nil && prev != nil && t.After(prevT) {
+					increase := *f - *prev
+					if *f < *prev {
+						increase = *f
+					}
+					r := increase / t.Sub(prevT).Seconds()
+					nF = &r
+				}
+				s.SetPoint(i, t, nF)
+				prevT, prev = t, f
+			}
+			return s
+		}, func(*float64) *float64 {
+			return nil
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// cumsum returns the cumulative sum of the value points for each result in SeriesSet.
+// Null points stay null and do not change the sum. Numbers and Scalars are returned as is.
+func cumsum(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, func(s Series) Series {
+			sum := float64(0)
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				if f == nil {
+					continue
+				}
+				sum += *f
+				nF := sum
+				s.SetPoint(i, t, &nF)
+			}
+			return s
+		}, copyFloat)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// movingAvg returns the average of the non-null value points within the trailing window
+// (t-window, t] of each point for each result in SeriesSet. Points without any non-null value
+// in their window are null. Numbers and Scalars are returned as is.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := parseDurationArg(rawWindow)
+	if err != nil {
+		return Results{}, err
+	}
+	if window <= 0 {
+		return Results{}, fmt.Errorf("moving_avg: window must be positive, got %q", rawWindow)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, func(s Series) Series {
+			times := make([]time.Time, s.Len())
+			values := make([]*float64, s.Len())
+			for i := 0; i < s.Len(); i++ {
+				times[i], values[i] = s.GetPoint(i)
+			}
+			start := 0
+			sum, count := float64(0), 0
+			for i := range values {
+				if values[i] != nil {
+					sum += *values[i]
+					count++
+				}
+				for start < i && !times[start].After(times[i].Add(-window)) {
+					if values[start] != nil {
+						sum -= *values[start]
+						count--
+					}
+					start++
+				}
+				var nF *float64
+				if count > 0 {
+					avg := sum / float64(count)
+					nF = &avg
+				}
+				s.SetPoint(i, times[i], nF)
+			}
+			return s
+		}, copyFloat)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// timeShift moves the timestamp of every value point forward by the given duration for each result
+// in SeriesSet, e.g. timeshift($A, "1w") aligns last week's data with the current week.
+// A negative duration moves points backward. Numbers and Scalars are returned as is.
+func timeShift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := parseDurationArg(rawDuration)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perSeries(e, res, func(s Series) Series {
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				s.SetPoint(i, t.Add(d), f)
+			}
+			return s
+		}, copyFloat)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// percentile returns the p-th percentile, 0 <= p <= 100, of the non-null value points for each result in
+// SeriesSet as a Number. The result is NaN if the series has no non-null points or any point is NaN.
+// The percentile of a Number or Scalar is its own value, returned as a Number so the result is always
+// a NumberSet.
+func percentile(e *State, varSet Results, pSet Results) (Results, error) {
+	p, err := scalarArg(pSet)
+	if err != nil {
+		return Results{}, fmt.Errorf("percentile: %w", err)
+	}
+	if p < 0 || p > 100 {
+		return Results{}, fmt.Errorf("percentile: expected a percentile between 0 and 100, got %v", p)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		var newVal Value
+		switch res.Type() {
+		case parse.TypeSeriesSet:
+			s := res.(Series)
+			n := NewNumber(e.RefID, s.GetLabels())
+			values := make([]float64, 0, s.Len())
+			for i := 0; i < s.Len(); i++ {
+				if f := s.GetValue(i); f != nil {
+					values = append(values, *f)
+				}
+			}
+			nF := percentileOf(values, p)
+			n.SetValue(&nF)
+			newVal = n
+		case parse.TypeScalar:
+			n := NewNumber(e.RefID, nil)
+			n.SetValue(copyFloat(res.(Scalar).GetFloat64Value()))
+			newVal = n
+		default:
+			newVal, err = perNullableFloat(e, res, copyFloat)
+			if err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// percentileOf returns the p-th percentile of values using linear interpolation between the
+// closest ranks. It returns NaN if values is empty or contains NaN. values is sorted in place.
+func percentileOf(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	for _, v := range values {
+		if math.IsNaN(v) {
+			return math.NaN()
+		}
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	if lower == upper {
+		return values[int(lower)]
+	}
+	return values[int(lower)] + (rank-lower)*(values[int(upper)]-values[int(lower)])
+}
+
+// perSeries passes a copy of each Series, sorted by time from oldest to newest, to seriesF which can modify
+// it in place. Numbers and Scalars have no time dimension and are treated as a single point, so their
+// value is passed to singleF instead. The input float pointer should not be modified in the singleF func.
+func perSeries(e *State, val Value, seriesF func(s Series) Series, singleF func(f *float64) *float64) (Value, error) {
+	switch val.Type() {
+	case parse.TypeSeriesSet:
+		resSeries := val.(Series)
+		newSeries := NewSeries(e.RefID, resSeries.GetLabels(), resSeries.Len())
+		for i := 0; i < resSeries.Len(); i++ {
+			t, f := resSeries.GetPoint(i)
+			newSeries.SetPoint(i, t, f)
+		}
+		newSeries.SortByTime(false)
+		return seriesF(newSeries), nil
+	case parse.TypeNumberSet, parse.TypeScalar, parse.TypeNoData:
+		return perNullableFloat(e, val, singleF)
+	default:
+		return nil, fmt.Errorf("can not apply a series function on type %v", val.Type())
+	}
+}
+
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	nF := *f
+	return &nF
+}
+
+func parseDurationArg(raw string) (time.Duration, error) {
+	d, err := gtime.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration %q: %w", raw, err)
+	}
+	return d, nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("expected a single scalar argument")
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("expected a non-null scalar argument")
+	}
+	return *f, nil
+}
+
+// checkDurationArg returns a parse time check that the argument at idx is a valid duration string.
+func checkDurationArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(_ *parse.Tree, f *parse.FuncNode) error {
+		if arg, ok := f.Args[idx].(*parse.StringNode); ok {
+			if _, err := parseDurationArg(arg.Text); err != nil {
+				return fmt.Errorf("parse: %s: %w", f.Name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// checkPercentileArg returns a parse time check that a constant argument at idx is between 0 and 100.
+func checkPercentileArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(_ *parse.Tree, f *parse.FuncNode) error {
+		if arg, ok := f.Args[idx].(*parse.ScalarNode); ok {
+			if arg.Float64 < 0 || arg.Float64 > 100 {
+				return fmt.Errorf("parse: %s: expected a percentile between 0 and 100, got %v", f.Name, arg.Text)
+			}
+		}
+		return nil
+	}
+}
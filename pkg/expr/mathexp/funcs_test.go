@@ -140,3 +140,155 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	series := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", nil,
+				tp{time.Unix(10, 0), float64Pointer(4)},
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(2)},
+			),
+		),
+	}
+	number := Vars{
+		"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(3))),
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "delta on series",
+			expr: "delta($A)",
+			vars: series,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+				),
+			),
+		},
+		{
+			name:    "delta on number",
+			expr:    "delta($A)",
+			vars:    number,
+			results: resultValuesNoErr(makeNumber("", nil, nil)),
+		},
+		{
+			name: "rate on series handles counter resets",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(30)},
+						tp{time.Unix(20, 0), float64Pointer(5)},
+					),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(0.5)},
+				),
+			),
+		},
+		{
+			name: "cumsum on series",
+			expr: "cumsum($A)",
+			vars: series,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(7)},
+				),
+			),
+		},
+		{
+			name:    "cumsum on scalar",
+			expr:    "cumsum(2)",
+			vars:    Vars{},
+			results: resultValuesNoErr(NewScalar("", float64Pointer(2))),
+		},
+		{
+			name: "moving_avg on series",
+			expr: `moving_avg($A, "15s")`,
+			vars: series,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2.5)},
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+				),
+			),
+		},
+		{
+			name: "timeshift on series",
+			expr: `timeshift($A, "1m")`,
+			vars: series,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(60, 0), float64Pointer(1)},
+					tp{time.Unix(70, 0), float64Pointer(4)},
+					tp{time.Unix(80, 0), nil},
+					tp{time.Unix(90, 0), float64Pointer(2)},
+				),
+			),
+		},
+		{
+			name:    "timeshift on number",
+			expr:    `timeshift($A, "1w")`,
+			vars:    number,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(3))),
+		},
+		{
+			name:    "percentile on series",
+			expr:    "percentile($A, 50)",
+			vars:    series,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:    "percentile on number",
+			expr:    "percentile($A, 95)",
+			vars:    number,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(3))),
+		},
+		{
+			name:    "percentile on scalar",
+			expr:    "percentile(2, 95)",
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+
+	t.Run("should fail to parse invalid arguments", func(t *testing.T) {
+		for _, expr := range []string{
+			`moving_avg($A, "abc")`,
+			`timeshift($A)`,
+			`percentile($A, 101)`,
+			`percentile($A, "50")`,
+		} {
+			_, err := New(expr)
+			require.Errorf(t, err, expr)
+		}
+	})
+}
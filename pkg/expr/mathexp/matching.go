@@ -0,0 +1,159 @@
+package mathexp
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// matchingUnion creates Union objects for a binary operation with vector matching modifiers,
+// e.g. $A / on(host) $B or $A * ignoring(code) group_left $B.
+//
+// Values on each side are matched by the signature of their labels, which is the set of labels named by on(...),
+// or all labels except those named by ignoring(...). Unlike union, ambiguous matches are an error:
+// in a one-to-one match there must not be more than one value with the same signature on either side, and with
+// group_left or group_right there must not be more than one value with the same signature on the "one" side.
+//
+// The labels of the result are the labels of the "many" side, or, for one-to-one matches, the labels of the left
+// side reduced to the matching labels. Labels listed in group_left(...) or group_right(...) are copied from the
+// "one" side.
+//
+// Scalars and NoData do not have labels to match, so binary operations on them fall back to union.
+func (e *State) matchingUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	matching := biNode.Matching
+	if !canMatchByLabels(aResults) || !canMatchByLabels(bResults) {
+		return e.union(aResults, bResults, biNode), nil
+	}
+
+	// With group_right the right side is the "many" side; swap the sides so the
+	// matching below always treats the left side as the "many" side.
+	many, one := aResults, bResults
+	manyVar, oneVar := biNode.Args[0].String(), biNode.Args[1].String()
+	oneSide := "right"
+	if matching.Card == parse.CardOneToMany {
+		many, one = bResults, aResults
+		manyVar, oneVar = oneVar, manyVar
+		oneSide = "left"
+	}
+	oneBySignature := make(map[string]int, len(one.Values))
+	for i, v := range one.Values {
+		sig := matchingSignature(v.GetLabels(), matching)
+		if j, ok := oneBySignature[sig]; ok {
+			return nil, fmt.Errorf("found duplicate values for the match group {%s} on the %s side of %q: {%s} and {%s}, many-to-many matching is not allowed, use on() or ignoring() to make the match unique",
+				sig, oneSide, biNode.String(), one.Values[j].GetLabels(), v.GetLabels())
+		}
+		oneBySignature[sig] = i
+	}
+
+	unions := []*Union{}
+	manyMatched := make([]bool, len(many.Values))
+	oneMatched := make([]bool, len(one.Values))
+	manyBySignature := make(map[string]int, len(many.Values))
+	for i, v := range many.Values {
+		sig := matchingSignature(v.GetLabels(), matching)
+		j, ok := oneBySignature[sig]
+		if !ok {
+			continue
+		}
+		if matching.Card == parse.CardOneToOne {
+			if k, dup := manyBySignature[sig]; dup {
+				return nil, fmt.Errorf("found duplicate values for the match group {%s} on the left side of %q: {%s} and {%s}, use group_left or group_right to allow many-to-one matching",
+					sig, biNode.String(), many.Values[k].GetLabels(), v.GetLabels())
+			}
+			manyBySignature[sig] = i
+		}
+
+		u := &Union{
+			Labels: matchingResultLabels(v.GetLabels(), one.Values[j].GetLabels(), matching),
+			A:      v,
+			B:      one.Values[j],
+		}
+		if matching.Card == parse.CardOneToMany {
+			u.A, u.B = u.B, u.A
+		}
+		unions = append(unions, u)
+		manyMatched[i] = true
+		oneMatched[j] = true
+	}
+
+	e.collectMatchingDrops(biNode, manyVar, many, manyMatched)
+	e.collectMatchingDrops(biNode, oneVar, one, oneMatched)
+	return unions, nil
+}
+
+// canMatchByLabels returns false if the results contain values without labels to match by.
+func canMatchByLabels(r Results) bool {
+	if len(r.Values) == 0 {
+		return false
+	}
+	for _, v := range r.Values {
+		switch v.Type() {
+		case parse.TypeNumberSet, parse.TypeSeriesSet:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// matchingSignature returns a string that is equal for labels that match according to the vector matching modifiers.
+func matchingSignature(labels data.Labels, matching *parse.VectorMatching) string {
+	return matchingLabels(labels, matching).String()
+}
+
+// matchingLabels returns the subset of labels that are used for matching.
+func matchingLabels(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if matching.On {
+		for _, name := range matching.MatchingLabels {
+			if v, ok := labels[name]; ok {
+				result[name] = v
+			}
+		}
+		return result
+	}
+	for k, v := range labels {
+		result[k] = v
+	}
+	for _, name := range matching.MatchingLabels {
+		delete(result, name)
+	}
+	return result
+}
+
+// matchingResultLabels returns the labels of the result of a matched binary operation.
+func matchingResultLabels(manyLabels, oneLabels data.Labels, matching *parse.VectorMatching) data.Labels {
+	if matching.Card == parse.CardOneToOne {
+		return matchingLabels(manyLabels, matching)
+	}
+	result := manyLabels.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	for _, name := range matching.Include {
+		if v, ok := oneLabels[name]; ok {
+			result[name] = v
+		} else {
+			delete(result, name)
+		}
+	}
+	return result
+}
+
+func (e *State) collectMatchingDrops(biNode *parse.BinaryNode, varName string, r Results, matched []bool) {
+	for i, b := range matched {
+		if b || r.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[biNode.String()] == nil {
+			e.Drops[biNode.String()] = make(map[string][]data.Labels)
+		}
+		e.DropCount++
+		e.Drops[biNode.String()][varName] = append(e.Drops[biNode.String()][varName], r.Values[i].GetLabels())
+	}
+}
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -300,6 +301,54 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching holds the optional vector matching modifiers of the operation, e.g. on(host) group_left.
+	Matching *VectorMatching
+}
+
+// VectorMatchCardinality describes the cardinality of the relationship between
+// the numbers or series on each side of a binary operation.
+type VectorMatchCardinality int
+
+const (
+	// CardOneToOne requires that every value on each side matches at most one value on the other side.
+	CardOneToOne VectorMatchCardinality = iota
+	// CardManyToOne allows many values on the left side to match one value on the right side (group_left).
+	CardManyToOne
+	// CardOneToMany allows one value on the left side to match many values on the right side (group_right).
+	CardOneToMany
+)
+
+// VectorMatching describes how numbers or series on each side of a binary operation are matched
+// by their labels, similar to vector matching in PromQL.
+type VectorMatching struct {
+	// On is true if only MatchingLabels are used for matching (on), and false if all labels
+	// except MatchingLabels are used for matching (ignoring).
+	On             bool
+	MatchingLabels []string
+	Card           VectorMatchCardinality
+	// Include holds the labels that are copied from the "one" side to the result with group_left or group_right.
+	Include []string
+}
+
+// String returns the string representation of the VectorMatching as it is written in an expression.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case CardManyToOne:
+		s += " group_left"
+	case CardOneToMany:
+		s += " group_right"
+	default:
+		return s
+	}
+	if len(m.Include) > 0 {
+		s += "(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,6 +357,9 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
@@ -318,6 +370,23 @@ func (b *BinaryNode) StringAST() string {
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	if b.Matching == nil {
+		return nil
+	}
+	if b.Matching.On {
+		for _, l := range b.Matching.Include {
+			for _, m := range b.Matching.MatchingLabels {
+				if l == m {
+					return fmt.Errorf("parse: label %q must not occur in on and group clause at once in %s", l, b)
+				}
+			}
+		}
+	}
+	for _, arg := range b.Args {
+		if _, ok := arg.(*ScalarNode); ok {
+			return fmt.Errorf("parse: vector matching is not allowed with a scalar operand in %s", b)
+		}
+	}
 	return nil
 }
 
@@ -405,6 +474,8 @@ const (
 	TypeVariantSet
 	// TypeNoData is a no data response without a known data type.
 	TypeNoData
+	// TypeTableData is a tabular data frame that has not been converted to numbers or series.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -422,6 +493,8 @@ func (f ReturnType) String() string {
 		return "variant"
 	case TypeNoData:
 		return "noData"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
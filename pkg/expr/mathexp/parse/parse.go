@@ -165,18 +165,77 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+matching -> ( "on" | "ignoring" ) labels [( "group_left" | "group_right" ) [labels]]
+labels -> "(" [label {"," label}] ")"
 */
 
+// binary parses the optional vector matching modifiers that follow the operator
+// of a binary operation, and then the right hand side operand using operand.
+func (t *Tree) binary(operator item, left Node, operand func() Node) Node {
+	matching := t.vectorMatching()
+	n := newBinary(operator, left, operand())
+	n.Matching = matching
+	return n
+}
+
+// vectorMatching parses on(...)/ignoring(...) with an optional group_left(...)/group_right(...).
+// It returns nil if there are no vector matching modifiers.
+func (t *Tree) vectorMatching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{
+		On:             token.val == "on",
+		MatchingLabels: t.labelList(token.val),
+		Card:           CardOneToOne,
+	}
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = CardManyToOne
+	if token.val == "group_right" {
+		m.Card = CardOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labelList(token.val)
+	}
+	return m
+}
+
+// labelList parses a parenthesized, comma separated list of label names.
+func (t *Tree) labelList(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return labels
+	}
+	for {
+		label := t.expect(itemFunc, context)
+		labels = append(labels, label.val)
+		if token := t.next(); token.typ == itemRightParen {
+			return labels
+		} else if token.typ != itemComma {
+			t.unexpected(token, context)
+		}
+	}
+}
+
 // expr:
 
 // O is A {"||" A} in the grammar.
@@ -185,7 +244,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +257,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +270,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +283,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +296,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +309,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -329,6 +388,10 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
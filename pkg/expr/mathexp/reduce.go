@@ -3,6 +3,7 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,7 +82,103 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	f := math.NaN()
+	if first != nil && last != nil {
+		f = *last - *first
+	}
+	return &f
+}
+
+// DiffAbs returns the absolute difference between the last and the first value.
+func DiffAbs(fv *Float64Field) *float64 {
+	f := math.Abs(*Diff(fv))
+	return &f
+}
+
+// CountNonNull returns the number of values that are not null.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if fv.GetValue(i) != nil {
+			f++
+		}
+	}
+	return &f
+}
+
+func Variance(fv *Float64Field) *float64 {
+	f := math.NaN()
+	if fv.Len() == 0 {
+		return &f
+	}
+	mean := *Avg(fv)
+	if math.IsNaN(mean) {
+		return &f
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - mean
+		sum += d * d
+	}
+	f = sum / float64(fv.Len())
+	return &f
+}
+
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer for the p-th percentile, 0 <= p <= 100, of the values
+// using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values := make([]float64, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v := fv.GetValue(i)
+			if v == nil {
+				nan := math.NaN()
+				return &nan
+			}
+			values = append(values, *v)
+		}
+		f := percentileOf(values, p)
+		return &f
+	}
+}
+
+// parsePercentileReducer parses a percentile reducer name in the form pNN, e.g. p95 or p99.9.
+func parsePercentileReducer(rFunc string) (float64, bool) {
+	if len(rFunc) < 2 || rFunc[0] != 'p' {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(rFunc[1:], 64)
+	if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
+	if p, ok := parsePercentileReducer(strings.ToLower(rFunc)); ok {
+		return Percentile(p), nil
+	}
 	switch strings.ToLower(rFunc) {
 	case "sum":
 		return Sum, nil
@@ -95,14 +192,29 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "diff":
+		return Diff, nil
+	case "diff_abs":
+		return DiffAbs, nil
+	case "count_non_null":
+		return CountNonNull, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return StdDev, nil
+	case "variance":
+		return Variance, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Percentiles are supported for any pNN name, e.g. p95 or p99.9, the most common ones are listed.
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "diff", "diff_abs", "count_non_null", "median", "stddev", "variance", "p50", "p75", "p90", "p95", "p99"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
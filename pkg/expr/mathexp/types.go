@@ -246,3 +246,45 @@ func (s NoData) New() NoData {
 func NewNoData() NoData {
 	return NoData{data.NewFrame("no data")}
 }
+
+// TableData is a tabular data frame that is passed through without
+// being converted to numbers or series, e.g. as an input to SQL expressions.
+type TableData struct{ Frame *data.Frame }
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (t TableData) Type() parse.ReturnType { return parse.TypeTableData }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (t TableData) Value() any { return t }
+
+func (t TableData) GetLabels() data.Labels { return nil }
+
+func (t TableData) SetLabels(ls data.Labels) {}
+
+func (t TableData) GetMeta() any {
+	if t.Frame.Meta == nil {
+		return nil
+	}
+	return t.Frame.Meta.Custom
+}
+
+func (t TableData) SetMeta(v any) {
+	m := t.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		t.Frame.SetMeta(m)
+	}
+	m.Custom = v
+}
+
+func (t TableData) AddNotice(notice data.Notice) {
+	m := t.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		t.Frame.SetMeta(m)
+	}
+	m.Notices = append(m.Notices, notice)
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (t TableData) AsDataFrame() *data.Frame { return t.Frame }
@@ -5,7 +5,9 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_union(t *testing.T) {
@@ -300,3 +302,120 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorMatching(t *testing.T) {
+	errors := Results{Values: Values{
+		makeNumber("a", data.Labels{"host": "a", "code": "500"}, float64Pointer(10)),
+		makeNumber("a", data.Labels{"host": "a", "code": "502"}, float64Pointer(6)),
+		makeNumber("a", data.Labels{"host": "b", "code": "500"}, float64Pointer(4)),
+	}}
+	requests := Results{Values: Values{
+		makeNumber("b", data.Labels{"host": "a", "team": "x"}, float64Pointer(2)),
+		makeNumber("b", data.Labels{"host": "b", "team": "y"}, float64Pointer(4)),
+		makeNumber("b", data.Labels{"host": "c", "team": "y"}, float64Pointer(1)),
+	}}
+
+	type result struct {
+		labels data.Labels
+		value  float64
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		errIs   require.ErrorAssertionFunc
+		results []result
+	}{
+		{
+			name:  "one-to-one matching on labels",
+			expr:  "$A / on(host) $B",
+			vars:  Vars{"A": Results{Values: errors.Values[1:]}, "B": requests},
+			errIs: require.NoError,
+			results: []result{
+				{labels: data.Labels{"host": "a"}, value: 3},
+				{labels: data.Labels{"host": "b"}, value: 1},
+			},
+		},
+		{
+			name:  "one-to-one matching ignoring labels",
+			expr:  "$A / ignoring(team) $B",
+			vars:  Vars{"A": requests, "B": requests},
+			errIs: require.NoError,
+			results: []result{
+				{labels: data.Labels{"host": "a"}, value: 1},
+				{labels: data.Labels{"host": "b"}, value: 1},
+				{labels: data.Labels{"host": "c"}, value: 1},
+			},
+		},
+		{
+			name:  "many-to-one matching with group_left",
+			expr:  "$A / on(host) group_left(team) $B",
+			vars:  Vars{"A": errors, "B": requests},
+			errIs: require.NoError,
+			results: []result{
+				{labels: data.Labels{"host": "a", "code": "500", "team": "x"}, value: 5},
+				{labels: data.Labels{"host": "a", "code": "502", "team": "x"}, value: 3},
+				{labels: data.Labels{"host": "b", "code": "500", "team": "y"}, value: 1},
+			},
+		},
+		{
+			name:  "one-to-many matching with group_right",
+			expr:  "$B * on(host) group_right $A",
+			vars:  Vars{"A": errors, "B": requests},
+			errIs: require.NoError,
+			results: []result{
+				{labels: data.Labels{"host": "a", "code": "500"}, value: 20},
+				{labels: data.Labels{"host": "a", "code": "502"}, value: 12},
+				{labels: data.Labels{"host": "b", "code": "500"}, value: 16},
+			},
+		},
+		{
+			name:  "one-to-one matching with duplicates on the left side is an error",
+			expr:  "$A / on(host) $B",
+			vars:  Vars{"A": errors, "B": requests},
+			errIs: require.Error,
+		},
+		{
+			name:  "many-to-one matching with duplicates on the one side is an error",
+			expr:  "$B / on(host) group_left $A",
+			vars:  Vars{"A": errors, "B": requests},
+			errIs: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			actual := make([]result, 0, len(res.Values))
+			for _, v := range res.Values {
+				actual = append(actual, result{labels: v.GetLabels(), value: *v.(Number).GetFloat64Value()})
+			}
+			require.ElementsMatch(t, tt.results, actual)
+		})
+	}
+
+	t.Run("should fail to parse invalid modifiers", func(t *testing.T) {
+		for _, expr := range []string{
+			"$A + on(host) group_left(host) $B",
+			"$A + on(host) 1",
+			"$A + on host $B",
+			"$A + on(host,) $B",
+		} {
+			_, err := New(expr)
+			require.Errorf(t, err, expr)
+		}
+	})
+
+	t.Run("should keep modifiers in the string representation", func(t *testing.T) {
+		e, err := New("$A / ignoring(code) group_left(team, k8s_cluster) $B")
+		require.NoError(t, err)
+		require.Equal(t, "$A / ignoring(code) group_left(team, k8s_cluster) $B", e.Tree.Root.String())
+	})
+}
@@ -14,7 +14,6 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"gonum.org/v1/gonum/graph/simple"
 
-	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -113,15 +112,30 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 
 	switch commandType {
 	case TypeMath:
-		node.Command, err = UnmarshalMathCommand(rn)
+		node.Command, err = UnmarshalMathConditionCommand(rn, toggles)
 	case TypeReduce:
 		node.Command, err = UnmarshalReduceCommand(rn)
 	case TypeResample:
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
-		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+		node.Command, err = UnmarshalClassicConditionCommand(rn, toggles)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
+	case TypeSQL:
+		if !toggles.IsEnabledGlobally(featuremgmt.FlagSqlExpressions) {
+			return nil, fmt.Errorf("sql expressions are disabled, enable the %s feature toggle to use them in expression '%v'", featuremgmt.FlagSqlExpressions, rn.RefID)
+		}
+		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		if !toggles.IsEnabledGlobally(featuremgmt.FlagAnomalyDetectionExpression) {
+			return nil, fmt.Errorf("anomaly detection is disabled, enable the %s feature toggle to use it in expression '%v'", featuremgmt.FlagAnomalyDetectionExpression, rn.RefID)
+		}
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeAlertState:
+		if !toggles.IsEnabledGlobally(featuremgmt.FlagAlertRuleDependencies) {
+			return nil, fmt.Errorf("alert state expressions are disabled, enable the %s feature toggle to use them in expression '%v'", featuremgmt.FlagAlertRuleDependencies, rn.RefID)
+		}
+		node.Command, err = UnmarshalAlertStateCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -149,6 +163,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// isInputToSQLExpr is set when the results of the query are consumed by a SQL expression.
+	// The returned frames are then kept as tables instead of being converted to numbers or series.
+	isInputToSQLExpr bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -290,7 +308,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				}
 
 				var result mathexp.Results
-				responseType, result, err := convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+				responseType, result, err := dn.convertDataFrames(ctx, dataFrames, s, logger)
 				if err != nil {
 					result.Error = makeConversionError(dn.RefID(), err)
 				}
@@ -358,7 +376,7 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 
 	var result mathexp.Results
-	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+	responseType, result, err = dn.convertDataFrames(ctx, dataFrames, s, logger)
 	if err != nil {
 		err = makeConversionError(dn.refID, err)
 	}
@@ -383,6 +401,22 @@ func getResponseFrame(resp *backend.QueryDataResponse, refID string) (data.Frame
 	return response.Frames, nil
 }
 
+// convertDataFrames converts the frames returned by the data source to results. Frames that
+// are an input to a SQL expression are passed through as tables.
+func (dn *DSNode) convertDataFrames(ctx context.Context, frames data.Frames, s *Service, logger log.Logger) (string, mathexp.Results, error) {
+	if !dn.isInputToSQLExpr {
+		return convertDataFramesToResults(ctx, frames, dn.datasource.Type, s, logger)
+	}
+	if len(frames) == 0 {
+		return "no-data", mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	vals := make([]mathexp.Value, 0, len(frames))
+	for _, frame := range frames {
+		vals = append(vals, mathexp.TableData{Frame: frame})
+	}
+	return "table data", mathexp.Results{Values: vals}, nil
+}
+
 func convertDataFramesToResults(ctx context.Context, frames data.Frames, datasourceType string, s *Service, logger log.Logger) (string, mathexp.Results, error) {
 	if len(frames) == 0 {
 		return "no-data", mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
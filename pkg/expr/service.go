@@ -92,6 +92,11 @@ func (s *Service) isDisabled() bool {
 	return !s.cfg.ExpressionsEnabled
 }
 
+// Features returns the feature toggles that the expressions are built with.
+func (s *Service) Features() featuremgmt.FeatureToggles {
+	return s.features
+}
+
 // BuildPipeline builds a pipeline from a request.
 func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 	return s.buildPipeline(req)
@@ -0,0 +1,400 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mattn/go-sqlite3"
+)
+
+// DB is an in-memory SQLite database that data frames are loaded into as
+// tables, so a SQL expression can be evaluated over them.
+type DB struct {
+	db *sql.DB
+}
+
+// NewInMemoryDB creates a new, empty, in-memory database. The database must be
+// closed by the caller once the expression has been evaluated.
+func NewInMemoryDB(ctx context.Context) (*DB, error) {
+	db, err := sql.Open("sqlite3", "file::memory:?mode=memory")
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to an in-memory database is a separate database,
+	// so all statements must go through a single connection.
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &DB{db: db}, nil
+}
+
+// Close releases the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// LoadFrame creates a table with the given name and inserts all rows of the frame into it.
+func (d *DB) LoadFrame(ctx context.Context, table string, frame *data.Frame) error {
+	names := columnNames(frame)
+
+	columns := make([]string, 0, len(frame.Fields))
+	for i, f := range frame.Fields {
+		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(names[i]), columnType(f.Type())))
+	}
+	if len(columns) == 0 {
+		columns = append(columns, fmt.Sprintf("%s REAL", quoteIdent("value")))
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(table), strings.Join(columns, ", "))); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", table, err)
+	}
+
+	if len(frame.Fields) > 0 && frame.Rows() > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(frame.Fields)), ", ")
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(table), placeholders))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = stmt.Close()
+		}()
+
+		row := make([]any, len(frame.Fields))
+		for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+			for i, f := range frame.Fields {
+				v, ok := f.ConcreteAt(rowIdx)
+				if !ok {
+					row[i] = nil
+					continue
+				}
+				if raw, isJSON := v.(json.RawMessage); isJSON {
+					v = string(raw)
+				}
+				row[i] = v
+			}
+			if _, err := stmt.ExecContext(ctx, row...); err != nil {
+				return fmt.Errorf("failed to insert row %d into table %s: %w", rowIdx, table, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query runs the query and returns its result as a single frame with the given refID.
+// The query is compiled by SQLite with only read access to the database, see prepareReadOnly.
+func (d *DB) Query(ctx context.Context, refID string, query string) (*data.Frame, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	stmt, err := prepareReadOnly(ctx, conn, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([][]any, len(names))
+	for rows.Next() {
+		values := make([]any, len(names))
+		pointers := make([]any, len(names))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			columns[i] = append(columns[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(refID)
+	frame.RefID = refID
+	for i, name := range names {
+		frame.Fields = append(frame.Fields, fieldFromColumn(name, columns[i]))
+	}
+	return frame, nil
+}
+
+// Validate checks that SQLite accepts query as a read-only statement over the given tables.
+// The columns of the tables are only known once the data is loaded, so the tables are created
+// with a placeholder column and references to unknown columns are not reported.
+func Validate(ctx context.Context, query string, tables []string) error {
+	d, err := NewInMemoryDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = d.Close()
+	}()
+
+	for _, table := range tables {
+		if _, err := d.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(table), quoteIdent(placeholderColumn))); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+	}
+
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	stmt, err := prepareReadOnly(ctx, conn, query)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no such column") {
+			return nil
+		}
+		return err
+	}
+	return stmt.Close()
+}
+
+// placeholderColumn is the only column of the tables created by Validate.
+const placeholderColumn = "__placeholder__"
+
+// prepareReadOnly compiles query with an authorizer that only allows SQLite to read data. SQLite
+// authorizes statements when they are compiled, so the statement can be run after the authorizer
+// is removed from the connection. Only the first statement of query is compiled.
+func prepareReadOnly(ctx context.Context, conn *sql.Conn, query string) (*sql.Stmt, error) {
+	if err := setAuthorizer(conn, readOnlyAuthorizer); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = setAuthorizer(conn, nil)
+	}()
+
+	stmt, err := conn.PrepareContext(ctx, query)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrAuth {
+			return nil, ErrNotSelect
+		}
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func setAuthorizer(conn *sql.Conn, authorizer func(int, string, string, string) int) error {
+	return conn.Raw(func(driverConn any) error {
+		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected connection type %T", driverConn)
+		}
+		sqliteConn.RegisterAuthorizer(authorizer)
+		return nil
+	})
+}
+
+func readOnlyAuthorizer(action int, _, _, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION:
+		return sqlite3.SQLITE_OK
+	default:
+		return sqlite3.SQLITE_DENY
+	}
+}
+
+// columnNames returns a unique column name for every field of the frame.
+func columnNames(frame *data.Frame) []string {
+	names := make([]string, len(frame.Fields))
+	seen := make(map[string]int, len(frame.Fields))
+	for i, f := range frame.Fields {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("field%d", i)
+		}
+		key := strings.ToLower(name)
+		if n, ok := seen[key]; ok {
+			seen[key] = n + 1
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		} else {
+			seen[key] = 0
+		}
+		names[i] = name
+	}
+	return names
+}
+
+func columnType(t data.FieldType) string {
+	switch {
+	case t == data.FieldTypeTime || t == data.FieldTypeNullableTime:
+		return "TIMESTAMP"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "BOOLEAN"
+	case t == data.FieldTypeFloat32 || t == data.FieldTypeNullableFloat32 ||
+		t == data.FieldTypeFloat64 || t == data.FieldTypeNullableFloat64:
+		return "REAL"
+	case t.Numeric():
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// fieldFromColumn builds a nullable field from the values scanned for a single result column.
+// SQLite is dynamically typed, so the field type is decided from all the values of the column.
+func fieldFromColumn(name string, values []any) *data.Field {
+	var hasString, hasFloat, hasInt, hasBool, hasTime bool
+	for _, v := range values {
+		switch v.(type) {
+		case string, []byte:
+			hasString = true
+		case float64:
+			hasFloat = true
+		case int64:
+			hasInt = true
+		case bool:
+			hasBool = true
+		case time.Time:
+			hasTime = true
+		}
+	}
+
+	switch {
+	case hasString && !hasFloat && !hasInt && !hasBool && !hasTime:
+		if times, ok := parseTimes(values); ok {
+			return data.NewField(name, nil, times)
+		}
+		return stringField(name, values)
+	case hasString || hasBool && (hasFloat || hasInt || hasTime) || hasTime && (hasFloat || hasInt):
+		return stringField(name, values)
+	case hasTime:
+		times := make([]*time.Time, len(values))
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				times[i] = &t
+			}
+		}
+		return data.NewField(name, nil, times)
+	case hasBool:
+		bools := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				bools[i] = &b
+			}
+		}
+		return data.NewField(name, nil, bools)
+	case hasInt && !hasFloat:
+		ints := make([]*int64, len(values))
+		for i, v := range values {
+			if n, ok := v.(int64); ok {
+				ints[i] = &n
+			}
+		}
+		return data.NewField(name, nil, ints)
+	default:
+		floats := make([]*float64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case float64:
+				floats[i] = &n
+			case int64:
+				f := float64(n)
+				floats[i] = &f
+			}
+		}
+		return data.NewField(name, nil, floats)
+	}
+}
+
+func stringField(name string, values []any) *data.Field {
+	strs := make([]*string, len(values))
+	for i, v := range values {
+		var s string
+		switch t := v.(type) {
+		case nil:
+			continue
+		case string:
+			s = t
+		case []byte:
+			s = string(t)
+		case time.Time:
+			s = t.Format(time.RFC3339Nano)
+		default:
+			s = fmt.Sprintf("%v", t)
+		}
+		strs[i] = &s
+	}
+	return data.NewField(name, nil, strs)
+}
+
+// parseTimes tries to read all non-null string values as timestamps in one of the formats SQLite
+// stores them in. This recovers the time type for computed columns such as max(time).
+func parseTimes(values []any) ([]*time.Time, bool) {
+	times := make([]*time.Time, len(values))
+	for i, v := range values {
+		var s string
+		switch t := v.(type) {
+		case nil:
+			continue
+		case string:
+			s = t
+		case []byte:
+			s = string(t)
+		default:
+			return nil, false
+		}
+		parsed, ok := parseTime(s)
+		if !ok {
+			return nil, false
+		}
+		times[i] = &parsed
+	}
+	return times, true
+}
+
+func parseTime(s string) (time.Time, bool) {
+	s = strings.TrimSuffix(s, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
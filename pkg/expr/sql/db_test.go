@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBQuery(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewInMemoryDB(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	require.NoError(t, db.LoadFrame(ctx, "A", data.NewFrame("", data.NewField("value", nil, []float64{1, 2}))))
+
+	t.Run("should return the result of a select", func(t *testing.T) {
+		frame, err := db.Query(ctx, "B", "SELECT sum(value) AS value FROM A")
+		require.NoError(t, err)
+		require.Equal(t, "B", frame.RefID)
+		v, ok := frame.Fields[0].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 3.0, v)
+	})
+
+	t.Run("should not allow statements that do not only read data", func(t *testing.T) {
+		for _, query := range []string{
+			"INSERT INTO A VALUES (3)",
+			"ATTACH DATABASE ':memory:' AS other",
+			"PRAGMA table_info(A)",
+		} {
+			_, err := db.Query(ctx, "B", query)
+			require.ErrorIs(t, err, ErrNotSelect, query)
+		}
+
+		frame, err := db.Query(ctx, "B", "SELECT count(*) AS value FROM A")
+		require.NoError(t, err)
+		v, ok := frame.Fields[0].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, int64(2), v)
+	})
+}
@@ -0,0 +1,63 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/xwb1989/sqlparser"
+)
+
+// ErrNotSelect is returned when a SQL expression is not a read-only SELECT statement.
+var ErrNotSelect = errors.New("only SELECT statements are supported in SQL expressions")
+
+// TablesList returns the sorted, de-duplicated list of table names referenced
+// by the SELECT statement rawSQL. Each table name is expected to be the refID
+// of another query or expression.
+//
+// SQL expressions are evaluated by SQLite, but the tables are found with a parser
+// of the MySQL dialect. The statement must therefore use syntax that both dialects
+// share: statements with syntax only SQLite supports, such as common table
+// expressions, fail to parse, and statements with syntax only MySQL supports are
+// rejected by Validate.
+func TablesList(rawSQL string) ([]string, error) {
+	stmt, err := sqlparser.Parse(rawSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SQL expression, only syntax supported by both SQLite and MySQL can be used: %w", err)
+	}
+
+	switch stmt.(type) {
+	case *sqlparser.Select, *sqlparser.Union, *sqlparser.ParenSelect:
+	default:
+		return nil, ErrNotSelect
+	}
+
+	tables := map[string]struct{}{}
+	err = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if t, ok := node.(sqlparser.TableName); ok && !t.IsEmpty() {
+			if !t.Qualifier.IsEmpty() {
+				return false, fmt.Errorf("qualified table names are not supported, got %q", sqlparser.String(t))
+			}
+			tables[t.Name.String()] = struct{}{}
+		}
+		return true, nil
+	}, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(tables))
+	for t := range tables {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+
+	if err := Validate(context.Background(), rawSQL, result); err != nil {
+		if errors.Is(err, ErrNotSelect) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid SQL expression for SQLite: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablesList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sql      string
+		expected []string
+		err      error
+		errMsg   string
+	}{
+		{
+			name:     "single table",
+			sql:      "SELECT * FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "join",
+			sql:      "SELECT A.value FROM A JOIN B ON A.host = B.host",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "subquery and union",
+			sql:      "SELECT value FROM (SELECT value FROM C) AS x UNION SELECT value FROM A",
+			expected: []string{"A", "C"},
+		},
+		{
+			name: "not a select",
+			sql:  "DROP TABLE A",
+			err:  ErrNotSelect,
+		},
+		{
+			name:     "syntax only supported by SQLite in expressions",
+			sql:      "SELECT host || '-' || value FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:   "syntax only supported by SQLite in statements",
+			sql:    "WITH x AS (SELECT value FROM A) SELECT * FROM x",
+			errMsg: "only syntax supported by both SQLite and MySQL can be used",
+		},
+		{
+			name:   "syntax only supported by MySQL",
+			sql:    "SELECT value FROM A FOR UPDATE",
+			errMsg: "invalid SQL expression for SQLite",
+		},
+		{
+			name:   "function unknown to SQLite",
+			sql:    "SELECT unknown_function(value) FROM A",
+			errMsg: "no such function: unknown_function",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tables, err := TablesList(tc.sql)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, tables)
+		})
+	}
+}
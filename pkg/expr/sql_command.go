@@ -0,0 +1,220 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// SQLCommand is an expression command that runs a SQL SELECT statement over the results
+// of other queries and expressions. The statement is evaluated by an in-memory SQLite database,
+// where the result of every referenced query or expression is available as a table named by its refID, e.g.
+//
+//	SELECT A.host, A.value / B.value AS value FROM A JOIN B ON A.host = B.host
+type SQLCommand struct {
+	query       string
+	varsToQuery []string
+	refID       string
+}
+
+// NewSQLCommand creates a new SQLCommand. It returns an error if the query
+// is not a valid SELECT statement.
+func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+	if rawSQL == "" {
+		return nil, errors.New("sql expression is empty")
+	}
+	tables, err := sql.TablesList(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("sql expression must select from at least one query or expression")
+	}
+	return &SQLCommand{
+		query:       rawSQL,
+		varsToQuery: tables,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("sql command is missing an expression")
+	}
+	expression, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("sql expression is expected to be a string, got %T", rawExpr)
+	}
+	return NewSQLCommand(rn.RefID, expression)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *SQLCommand) NeedsVars() []string {
+	return gr.varsToQuery
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *SQLCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	span.SetAttributes(attribute.String("sql.query", gr.query), attribute.StringSlice("sql.tables", gr.varsToQuery))
+	defer span.End()
+
+	db, err := sql.NewInMemoryDB(ctx)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to create database for sql expression: %w", err)
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	for _, ref := range gr.varsToQuery {
+		frame, err := valuesToTable(ref, vars[ref].Values)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		if err := db.LoadFrame(ctx, ref, frame); err != nil {
+			return mathexp.Results{}, err
+		}
+	}
+
+	frame, err := db.Query(ctx, gr.refID, gr.query)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql expression: %w", err)
+	}
+
+	return sqlFrameToResults(ctx, tracer, frame)
+}
+
+// valuesToTable converts the results of a query or expression to a single table. Series and numbers
+// are converted to the long format, i.e. an optional time column, a value column and a column per label.
+func valuesToTable(refID string, values mathexp.Values) (*data.Frame, error) {
+	var tables []*data.Frame
+	var series []mathexp.Series
+	var numbers []mathexp.Number
+	for _, v := range values {
+		switch val := v.(type) {
+		case mathexp.TableData:
+			tables = append(tables, val.Frame)
+		case mathexp.Series:
+			series = append(series, val)
+		case mathexp.Number:
+			numbers = append(numbers, val)
+		case mathexp.Scalar:
+			numbers = append(numbers, mathexp.Number{Frame: val.Frame})
+		case mathexp.NoData:
+		default:
+			return nil, fmt.Errorf("cannot use %s as a table in a sql expression, got type %v", refID, v.Type())
+		}
+	}
+
+	if len(tables) > 0 {
+		if len(tables) > 1 || len(series) > 0 || len(numbers) > 0 {
+			return nil, fmt.Errorf("sql expressions support a single table per query, but %s returned %d results", refID, len(values))
+		}
+		return tables[0], nil
+	}
+
+	labelKeys := map[string]struct{}{}
+	rows := 0
+	for _, s := range series {
+		for k := range s.GetLabels() {
+			labelKeys[k] = struct{}{}
+		}
+		rows += s.Len()
+	}
+	for _, n := range numbers {
+		for k := range n.GetLabels() {
+			labelKeys[k] = struct{}{}
+		}
+		rows++
+	}
+	keys := make([]string, 0, len(labelKeys))
+	for k := range labelKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	frame := data.NewFrame(refID)
+	if len(series) > 0 {
+		frame.Fields = append(frame.Fields, data.NewField("time", nil, make([]*time.Time, 0, rows)))
+	}
+	frame.Fields = append(frame.Fields, data.NewField("value", nil, make([]*float64, 0, rows)))
+	for _, k := range keys {
+		frame.Fields = append(frame.Fields, data.NewField(k, nil, make([]*string, 0, rows)))
+	}
+
+	appendRow := func(t *time.Time, v *float64, labels data.Labels) {
+		row := make([]any, 0, len(frame.Fields))
+		if len(series) > 0 {
+			row = append(row, t)
+		}
+		row = append(row, v)
+		for _, k := range keys {
+			var lv *string
+			if l, ok := labels[k]; ok {
+				lv = &l
+			}
+			row = append(row, lv)
+		}
+		frame.AppendRow(row...)
+	}
+
+	for _, s := range series {
+		for i := 0; i < s.Len(); i++ {
+			t, v := s.GetPoint(i)
+			appendRow(&t, v, s.GetLabels())
+		}
+	}
+	for _, n := range numbers {
+		appendRow(nil, n.GetFloat64Value(), n.GetLabels())
+	}
+
+	return frame, nil
+}
+
+// sqlFrameToResults converts the result of a SQL query to results. A result with a time column
+// is read as a long time series and one without as long numeric data through the dataplane
+// contract, so string columns become labels. Results without numeric columns are returned as table data.
+func sqlFrameToResults(ctx context.Context, tracer tracing.Tracer, frame *data.Frame) (mathexp.Results, error) {
+	if frame.Rows() == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{Frame: frame}}}, nil
+	}
+
+	hasTime, hasNumber := false, false
+	for _, f := range frame.Fields {
+		switch {
+		case f.Type().Time():
+			hasTime = true
+		case f.Type().Numeric():
+			hasNumber = true
+		}
+	}
+
+	if !hasNumber {
+		return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+	}
+
+	dt := data.FrameTypeNumericLong
+	if hasTime {
+		dt = data.FrameTypeTimeSeriesLong
+	}
+	frame.SetMeta(&data.FrameMeta{
+		Type:        dt,
+		TypeVersion: data.FrameTypeVersion{0, 1},
+	})
+
+	return handleDataplaneFrames(ctx, tracer, dt, data.Frames{frame})
+}
@@ -0,0 +1,104 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewSQLCommand(t *testing.T) {
+	t.Run("should return referenced tables as needed vars", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT A.host, A.value / B.value AS value FROM A JOIN B ON A.host = B.host")
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+	})
+
+	t.Run("should fail if statement is not a select", func(t *testing.T) {
+		_, err := NewSQLCommand("C", "DELETE FROM A")
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if no table is referenced", func(t *testing.T) {
+		_, err := NewSQLCommand("C", "SELECT 1")
+		require.Error(t, err)
+	})
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	t.Run("should join numbers by label", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT A.host, A.value / B.value AS value FROM A JOIN B ON A.host = B.host ORDER BY A.host")
+		require.NoError(t, err)
+
+		number := func(refID string, host string, v float64) mathexp.Value {
+			n := mathexp.NewNumber(refID, data.Labels{"host": host})
+			n.SetValue(util.Pointer(v))
+			return n
+		}
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{number("A", "a", 10), number("A", "b", 6)}},
+			"B": mathexp.Results{Values: mathexp.Values{number("B", "a", 2), number("B", "c", 3)}},
+		}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		n, ok := res.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+		require.Equal(t, 5.0, *n.GetFloat64Value())
+	})
+
+	t.Run("should return series when result has a time column", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT `time`, value * 2 AS value, host FROM A ORDER BY `time`")
+		require.NoError(t, err)
+
+		s := mathexp.NewSeries("A", data.Labels{"host": "a"}, 2)
+		s.SetPoint(0, time.Unix(10, 0), util.Pointer(1.0))
+		s.SetPoint(1, time.Unix(20, 0), util.Pointer(2.0))
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{s}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		series, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, series.GetLabels())
+		require.Equal(t, 2, series.Len())
+		require.Equal(t, 4.0, *series.GetValue(1))
+	})
+
+	t.Run("should return no data if query returns no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A WHERE value > 100")
+		require.NoError(t, err)
+
+		n := mathexp.NewNumber("A", nil)
+		n.SetValue(util.Pointer(1.0))
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{n}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("should query table data as is", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT service, count(*) AS value FROM A GROUP BY service")
+		require.NoError(t, err)
+
+		frame := data.NewFrame("",
+			data.NewField("service", nil, []string{"api", "api", "web"}),
+			data.NewField("status", nil, []string{"500", "502", "500"}),
+		)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+	})
+}
@@ -169,7 +169,11 @@ type ThresholdConditionJSON struct {
 // - field 'type' has value "threshold",
 // - field 'conditions' is array of objects and has exactly one element
 // - field 'conditions[0].unloadEvaluator is not nil
-func IsHysteresisExpression(query map[string]any) bool {
+// or if it describes a math or classic condition command with recovery conditions, see isHysteresisCondition.
+func IsHysteresisExpression(query map[string]any, features featuremgmt.FeatureToggles) bool {
+	if isHysteresisCondition(query, features) {
+		return true
+	}
 	c, err := getConditionForHysteresisCommand(query)
 	if err != nil {
 		return false
@@ -178,7 +182,12 @@ func IsHysteresisExpression(query map[string]any) bool {
 }
 
 // SetLoadedDimensionsToHysteresisCommand mutates the input map and sets field "conditions[0].loadedMetrics" with the data frame created from the provided fingerprints.
-func SetLoadedDimensionsToHysteresisCommand(query map[string]any, fingerprints Fingerprints) error {
+// For math and classic condition commands with recovery conditions it sets the field "loadedDimensions" instead.
+func SetLoadedDimensionsToHysteresisCommand(query map[string]any, fingerprints Fingerprints, features featuremgmt.FeatureToggles) error {
+	if isHysteresisCondition(query, features) {
+		query["loadedDimensions"] = FingerprintsToFrame(fingerprints)
+		return nil
+	}
 	condition, err := getConditionForHysteresisCommand(query)
 	if err != nil {
 		return err
@@ -225,3 +234,25 @@ func getConditionForHysteresisCommand(query map[string]any) (map[string]any, err
 	}
 	return condition, nil
 }
+
+// isHysteresisCondition returns true if the raw model describes a math command with field 'unloadExpression'
+// or a classic condition command with field 'unloadConditions', and recovery conditions are enabled.
+func isHysteresisCondition(query map[string]any, features featuremgmt.FeatureToggles) bool {
+	if !features.IsEnabledGlobally(featuremgmt.FlagRecoveryConditions) {
+		return false
+	}
+	t, err := GetExpressionCommandType(query)
+	if err != nil {
+		return false
+	}
+	switch t {
+	case TypeMath:
+		_, ok := query["unloadExpression"]
+		return ok
+	case TypeClassicConditions:
+		_, ok := query["unloadConditions"]
+		return ok
+	default:
+		return false
+	}
+}
@@ -379,7 +379,7 @@ func TestIsHysteresisExpression(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			query := map[string]any{}
 			require.NoError(t, json.Unmarshal(tc.input, &query))
-			require.Equal(t, tc.expected, IsHysteresisExpression(query))
+			require.Equal(t, tc.expected, IsHysteresisExpression(query, featuremgmt.WithFeatures()))
 		})
 	}
 }
@@ -419,7 +419,7 @@ func TestSetLoadedDimensionsToHysteresisCommand(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			query := map[string]any{}
 			require.NoError(t, json.Unmarshal(tc.input, &query))
-			err := SetLoadedDimensionsToHysteresisCommand(query, Fingerprints{math.MaxUint64: {}, 2: {}, 3: {}})
+			err := SetLoadedDimensionsToHysteresisCommand(query, Fingerprints{math.MaxUint64: {}, 2: {}, 3: {}}, featuremgmt.WithFeatures())
 			require.Error(t, err)
 		})
 	}
@@ -429,7 +429,7 @@ func TestSetLoadedDimensionsToHysteresisCommand(t *testing.T) {
 		input := json.RawMessage(`{ "type": "threshold", "conditions": [{ "evaluator": { "params": [5], "type": "gt" }, "unloadEvaluator" : {"params": [2], "type": "lt"}}], "expression": "A" }`)
 		query := map[string]any{}
 		require.NoError(t, json.Unmarshal(input, &query))
-		require.NoError(t, SetLoadedDimensionsToHysteresisCommand(query, fingerprints))
+		require.NoError(t, SetLoadedDimensionsToHysteresisCommand(query, fingerprints, featuremgmt.WithFeatures()))
 		raw, err := json.Marshal(query)
 		require.NoError(t, err)
 
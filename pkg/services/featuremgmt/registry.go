@@ -1230,6 +1230,42 @@ var (
 			Owner:       grafanaPartnerPluginsSquad,
 			Expression:  "true", // Enabled by default for now
 		},
+		{
+			Name:        "sqlExpressions",
+			Description: "Enables using SQL as a server-side expression to join and filter query results",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "anomalyDetectionExpression",
+			Description: "Enables the anomaly detection server-side expression that detects deviations from a baseline without external services",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "recoveryConditions",
+			Description: "Enables recovery conditions (aka hysteresis) for math and classic condition server-side expressions",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "grafanaManagedRecordingRules",
+			Description: "Enables Grafana-managed recording rules that write the results of queries and expressions via Prometheus remote write",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "alertRuleDependencies",
+			Description: "Enables the alert state expression that lets alert rules use the state of other rules in the same group, which are then evaluated in dependency order",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
+		{
+			Name:        "livePipeline",
+			Description: "Enables the Grafana Live channel rule pipeline, including MQTT, NATS and Kafka sources",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAppPlatformSquad,
+		},
 	}
 )
 
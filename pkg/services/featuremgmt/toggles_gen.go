@@ -666,4 +666,28 @@ const (
 	// FlagPrometheusAzureOverrideAudience
 	// Deprecated. Allow override default AAD audience for Azure Prometheus endpoint. Enabled by default. This feature should no longer be used and will be removed in the future.
 	FlagPrometheusAzureOverrideAudience = "prometheusAzureOverrideAudience"
+
+	// FlagSqlExpressions
+	// Enables using SQL as a server-side expression to join and filter query results
+	FlagSqlExpressions = "sqlExpressions"
+
+	// FlagAnomalyDetectionExpression
+	// Enables the anomaly detection server-side expression that detects deviations from a baseline without external services
+	FlagAnomalyDetectionExpression = "anomalyDetectionExpression"
+
+	// FlagRecoveryConditions
+	// Enables recovery conditions (aka hysteresis) for math and classic condition server-side expressions
+	FlagRecoveryConditions = "recoveryConditions"
+
+	// FlagGrafanaManagedRecordingRules
+	// Enables Grafana-managed recording rules that write the results of queries and expressions via Prometheus remote write
+	FlagGrafanaManagedRecordingRules = "grafanaManagedRecordingRules"
+
+	// FlagAlertRuleDependencies
+	// Enables the alert state expression that lets alert rules use the state of other rules in the same group, which are then evaluated in dependency order
+	FlagAlertRuleDependencies = "alertRuleDependencies"
+
+	// FlagLivePipeline
+	// Enables the Grafana Live channel rule pipeline, including MQTT, NATS and Kafka sources
+	FlagLivePipeline = "livePipeline"
 )
@@ -154,22 +154,32 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	replayConfig := managedstream.ReplayConfig{
+		MaxFrames: g.Cfg.LiveManagedStreamReplayMaxFrames,
+		MaxAge:    g.Cfg.LiveManagedStreamReplayMaxAge,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix),
+			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix, replayConfig),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(replayConfig),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if toggles.IsEnabledGlobally(featuremgmt.FlagLivePipeline) {
+		if err := g.setupPipeline(node); err != nil {
+			return nil, err
+		}
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -332,6 +342,35 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	return g, nil
 }
 
+// setupPipeline creates the channel rule pipeline, with channel rules and write configs stored in files
+// of the data directory, and the runner of its sources. Without the pipeline, data published to
+// channels is not processed by channel rules.
+func (g *GrafanaLive) setupPipeline(node *centrifuge.Node) error {
+	storage := &pipeline.FileStorage{
+		DataPath:       g.Cfg.DataPath,
+		SecretsService: g.SecretsService,
+	}
+	g.pipelineStorage = storage
+	g.pipelineProcessorStates = pipeline.NewProcessorStateStorage()
+	builder := &pipeline.StorageRuleBuilder{
+		Node:                 node,
+		ManagedStream:        g.ManagedStreamRunner,
+		FrameStorage:         pipeline.NewFrameStorage(),
+		Storage:              storage,
+		ChannelHandlerGetter: g,
+		SecretsService:       g.SecretsService,
+		ProcessorStates:      g.pipelineProcessorStates,
+	}
+	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+	p, err := pipeline.New(channelRuleGetter)
+	if err != nil {
+		return err
+	}
+	g.Pipeline = p
+	g.pipelineSourceRunner = pipeline.NewSourceRunner(storage, g.Pipeline, g.orgIDs, g.SecretsService)
+	return nil
+}
+
 func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 	redisAddress := g.Cfg.LiveHAEngineAddress
 	redisPassword := g.Cfg.LiveHAEnginePassword
@@ -403,9 +442,11 @@ type GrafanaLive struct {
 	// The core internal features
 	GrafanaScope CoreGrafanaScope
 
-	ManagedStreamRunner *managedstream.Runner
-	Pipeline            *pipeline.Pipeline
-	pipelineStorage     pipeline.Storage
+	ManagedStreamRunner     *managedstream.Runner
+	Pipeline                *pipeline.Pipeline
+	pipelineStorage         pipeline.Storage
+	pipelineSourceRunner    *pipeline.SourceRunner
+	pipelineProcessorStates *pipeline.ProcessorStateStorage
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -466,9 +507,34 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.pipelineSourceRunner != nil {
+		eGroup.Go(func() error {
+			return g.pipelineSourceRunner.Run(eCtx)
+		})
+	}
+
+	if g.pipelineProcessorStates != nil {
+		eGroup.Go(func() error {
+			return g.Pipeline.RunProcessorStateEviction(eCtx, g.pipelineProcessorStates)
+		})
+	}
+
 	return eGroup.Wait()
 }
 
+// orgIDs returns the IDs of all organizations, pipeline sources are run for each of them.
+func (g *GrafanaLive) orgIDs(ctx context.Context) ([]int64, error) {
+	orgs, err := g.orgService.Search(ctx, &org.SearchOrgsQuery{})
+	if err != nil {
+		return nil, err
+	}
+	orgIDs := make([]int64, 0, len(orgs))
+	for _, o := range orgs {
+		orgIDs = append(orgIDs, o.ID)
+	}
+	return orgIDs, nil
+}
+
 func getCheckOriginFunc(appURL *url.URL, originPatterns []string, originGlobs []glob.Glob) func(r *http.Request) bool {
 	return func(r *http.Request) bool {
 		origin := r.Header.Get("Origin")
@@ -1061,8 +1127,12 @@ func (g *GrafanaLive) HandleChannelRulesListHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to get channel rules", err)
 	}
+	rules := make([]pipeline.ChannelRule, 0, len(result))
+	for _, r := range result {
+		rules = append(rules, pipeline.ChannelRuleToDto(r))
+	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rules": result,
+		"rules": rules,
 	})
 }
 
@@ -1177,7 +1247,7 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -1200,7 +1270,7 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -1233,6 +1303,7 @@ func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *contextmodel.ReqContext)
 		"converters":      pipeline.ConvertersRegistry,
 		"frameProcessors": pipeline.FrameProcessorsRegistry,
 		"frameOutputs":    pipeline.FrameOutputsRegistry,
+		"sources":         pipeline.SourcesRegistry,
 	})
 }
 
@@ -1336,6 +1407,36 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// HandlePipelineExportHTTP ...
+func (g *GrafanaLive) HandlePipelineExportHTTP(c *contextmodel.ReqContext) response.Response {
+	export, err := pipeline.ExportOrg(c.Req.Context(), g.pipelineStorage, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export pipeline", err)
+	}
+	return response.JSON(http.StatusOK, export)
+}
+
+// HandlePipelineImportHTTP ...
+func (g *GrafanaLive) HandlePipelineImportHTTP(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error reading body", err)
+	}
+	var cmd pipeline.ImportCmd
+	err = json.Unmarshal(body, &cmd)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Error decoding pipeline import command", err)
+	}
+	err = pipeline.ImportOrg(c.Req.Context(), g.pipelineStorage, g.SecretsService, c.SignedInUser.GetOrgID(), cmd)
+	if err != nil {
+		if errors.Is(err, pipeline.ErrInvalidImport) {
+			return response.Error(http.StatusBadRequest, "Invalid pipeline import", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to import pipeline", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{})
+}
+
 // Write to the standard log15 logger
 func handleLog(msg centrifuge.LogEntry) {
 	arr := make([]interface{}, 0)
@@ -42,6 +42,38 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_provideLiveService_Pipeline(t *testing.T) {
+	provide := func(t *testing.T, features featuremgmt.FeatureToggles) *GrafanaLive {
+		cfg := setting.NewCfg()
+		cfg.DataPath = t.TempDir()
+		g, err := ProvideService(nil, cfg,
+			routing.NewRouteRegister(),
+			nil, nil, nil, nil,
+			db.InitTestDB(t),
+			nil,
+			&usagestats.UsageStatsMock{T: t},
+			nil,
+			features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		require.NoError(t, err)
+		return g
+	}
+
+	t.Run("should not set up the pipeline without the feature toggle", func(t *testing.T) {
+		g := provide(t, featuremgmt.WithFeatures())
+		require.Nil(t, g.Pipeline)
+		require.Nil(t, g.pipelineStorage)
+		require.Nil(t, g.pipelineSourceRunner)
+	})
+
+	t.Run("should set up the pipeline and its sources with the feature toggle", func(t *testing.T) {
+		g := provide(t, featuremgmt.WithFeatures(featuremgmt.FlagLivePipeline))
+		require.NotNil(t, g.Pipeline)
+		require.NotNil(t, g.pipelineStorage)
+		require.NotNil(t, g.pipelineProcessorStates)
+		require.NotNil(t, g.pipelineSourceRunner)
+	})
+}
+
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
 	doneCh := make(chan struct{})
 	f := func() {
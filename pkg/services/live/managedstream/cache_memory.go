@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -14,14 +15,20 @@ import (
 type MemoryFrameCache struct {
 	mu     sync.RWMutex
 	frames map[int64]map[string]data.FrameJSONCache
+	replay ReplayConfig
+	recent map[int64]map[string]*replayBuffer
 	log    log.Logger
+	now    func() time.Time
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(replay ReplayConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
 		frames: map[int64]map[string]data.FrameJSONCache{},
+		replay: replay,
+		recent: map[int64]map[string]*replayBuffer{},
 		log:    log.New("live.memoryframecache"),
+		now:    time.Now,
 	}
 }
 
@@ -44,6 +51,13 @@ func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel st
 	defer c.mu.RUnlock()
 	cachedFrame, ok := c.frames[orgID][channel]
 	raw := cachedFrame.Bytes(data.IncludeAll)
+	if buffer, exists := c.recent[orgID][channel]; exists {
+		replayed, err := c.replay.replay(buffer.list(), c.now())
+		if err != nil {
+			return nil, false, err
+		}
+		raw = replayed
+	}
 	c.log.Debug("Cache get",
 		"orgId", orgID,
 		"channel", channel,
@@ -61,6 +75,17 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.replay.enabled() {
+		if _, ok := c.recent[orgID]; !ok {
+			c.recent[orgID] = map[string]*replayBuffer{}
+		}
+		buffer, ok := c.recent[orgID][channel]
+		if !ok {
+			buffer = newReplayBuffer(c.replay.MaxFrames)
+			c.recent[orgID][channel] = buffer
+		}
+		buffer.add(replayFrame{time: c.now(), frame: jsonFrame})
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -63,7 +64,50 @@ func testFrameCache(t *testing.T, c FrameCache) {
 }
 
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(ReplayConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestMemoryFrameCacheReplay(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryFrameCache(ReplayConfig{MaxFrames: 3, MaxAge: time.Minute})
+	c.now = func() time.Time { return now }
+	testFrameCacheReplay(t, c, &now)
+}
+
+// testFrameCacheReplay tests a cache with MaxFrames 3 and MaxAge of a minute, which current time is now.
+func testFrameCacheReplay(t *testing.T, c FrameCache, now *time.Time) {
+	update := func(frame *data.Frame) {
+		t.Helper()
+		frameJsonCache, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		_, err = c.Update(context.Background(), 1, "test", frameJsonCache)
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+	}
+	getValues := func() []float64 {
+		t.Helper()
+		frameJSON, ok, err := c.GetFrame(context.Background(), 1, "test")
+		require.NoError(t, err)
+		require.True(t, ok)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		values := make([]float64, 0, f.Fields[0].Len())
+		for i := 0; i < f.Fields[0].Len(); i++ {
+			values = append(values, f.Fields[0].At(i).(float64))
+		}
+		return values
+	}
+
+	for _, v := range []float64{1, 2, 3, 4} {
+		update(data.NewFrame("test", data.NewField("value", nil, []float64{v})))
+	}
+	require.Equal(t, []float64{2, 3, 4}, getValues(), "should only keep the last frames")
+
+	*now = now.Add(time.Minute - 2*time.Second)
+	require.Equal(t, []float64{3, 4}, getValues(), "should skip expired frames")
+
+	update(data.NewFrame("test", data.NewField("value", nil, []float64{5}), data.NewField("extra", nil, []float64{0})))
+	require.Equal(t, []float64{5}, getValues(), "should skip frames with another schema")
+}
@@ -19,14 +19,18 @@ type RedisFrameCache struct {
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
 	keyPrefix   string
+	replay      ReplayConfig
+	now         func() time.Time
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client, keyPrefix string) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, keyPrefix string, replay ReplayConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		keyPrefix:   keyPrefix,
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		replay:      replay,
+		now:         time.Now,
 	}
 }
 
@@ -46,6 +50,12 @@ func (c *RedisFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMes
 
 func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
 	key := c.getCacheKey(orgchannel.PrependOrgID(orgID, channel))
+	if c.replay.enabled() {
+		frameJSON, ok, err := c.getReplayFrame(ctx, key)
+		if err != nil || ok {
+			return frameJSON, ok, err
+		}
+	}
 	cmd := c.redisClient.HGetAll(ctx, key)
 	result, err := cmd.Result()
 	if err != nil {
@@ -82,6 +92,19 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 		"frame":  string(jsonFrame.Bytes(data.IncludeAll)),
 	})
 	pipe.Expire(ctx, key, frameCacheTTL)
+	if c.replay.enabled() {
+		entry, err := json.Marshal(redisReplayEntry{
+			Time:  c.now().UnixMilli(),
+			Frame: jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
+		replayKey := c.getReplayKey(key)
+		pipe.RPush(ctx, replayKey, entry)
+		pipe.LTrim(ctx, replayKey, int64(-c.replay.MaxFrames), -1)
+		pipe.Expire(ctx, replayKey, frameCacheTTL)
+	}
 
 	replies, err := pipe.Exec(ctx)
 	if err != nil {
@@ -112,3 +135,44 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 func (c *RedisFrameCache) getCacheKey(channelID string) string {
 	return c.keyPrefix + ".managed_stream." + channelID
 }
+
+func (c *RedisFrameCache) getReplayKey(cacheKey string) string {
+	return cacheKey + ".replay"
+}
+
+// redisReplayEntry is a frame kept in the Redis replay list of a channel.
+type redisReplayEntry struct {
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+func (c *RedisFrameCache) getReplayFrame(ctx context.Context, key string) (json.RawMessage, bool, error) {
+	result, err := c.redisClient.LRange(ctx, c.getReplayKey(key), 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(result) == 0 {
+		return nil, false, nil
+	}
+	frames := make([]replayFrame, 0, len(result))
+	for _, raw := range result {
+		var entry redisReplayEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			return nil, false, err
+		}
+		var frame data.Frame
+		if err := json.Unmarshal(entry.Frame, &frame); err != nil {
+			return nil, false, err
+		}
+		jsonFrame, err := data.FrameToJSONCache(&frame)
+		if err != nil {
+			return nil, false, err
+		}
+		frames = append(frames, replayFrame{time: time.UnixMilli(entry.Time), frame: jsonFrame})
+	}
+	frameJSON, err := c.replay.replay(frames, c.now())
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
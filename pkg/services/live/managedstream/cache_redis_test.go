@@ -3,6 +3,7 @@ package managedstream
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
@@ -36,9 +37,20 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 
 	t.Cleanup(redisCleanup(t, redisClient, prefix))
 
-	c := NewRedisFrameCache(redisClient, prefix)
+	c := NewRedisFrameCache(redisClient, prefix, ReplayConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
+
+	t.Run("replay", func(t *testing.T) {
+		prefix := uuid.New().String()
+		t.Cleanup(redisCleanup(t, redisClient, prefix))
+
+		// Redis keeps the time of frames in milliseconds.
+		now := time.Now().Truncate(time.Millisecond)
+		c := NewRedisFrameCache(redisClient, prefix, ReplayConfig{MaxFrames: 3, MaxAge: time.Minute})
+		c.now = func() time.Time { return now }
+		testFrameCacheReplay(t, c, &now)
+	})
 }
 
 func redisCleanup(t *testing.T, redisClient *redis.Client, prefix string) func() {
@@ -0,0 +1,99 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ReplayConfig bounds the recent frames kept per channel so new subscribers
+// receive the history of a stream and not only the latest frame.
+type ReplayConfig struct {
+	// MaxFrames is a maximum number of frames kept per channel. Values
+	// lower than 2 only keep the latest frame.
+	MaxFrames int
+	// MaxAge is a maximum age of kept frames. 0 means frames don't expire
+	// and only MaxFrames applies.
+	MaxAge time.Duration
+}
+
+func (c ReplayConfig) enabled() bool {
+	return c.MaxFrames > 1
+}
+
+// replayFrame is a frame kept in the replay buffer of a channel.
+type replayFrame struct {
+	time  time.Time
+	frame data.FrameJSONCache
+}
+
+// replayBuffer is a bounded ring buffer of the recent frames of a channel.
+type replayBuffer struct {
+	frames []replayFrame
+	next   int
+	full   bool
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{frames: make([]replayFrame, size)}
+}
+
+func (b *replayBuffer) add(frame replayFrame) {
+	b.frames[b.next] = frame
+	b.next = (b.next + 1) % len(b.frames)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// list returns the buffered frames from the oldest to the latest.
+func (b *replayBuffer) list() []replayFrame {
+	if !b.full {
+		return append([]replayFrame(nil), b.frames[:b.next]...)
+	}
+	frames := make([]replayFrame, 0, len(b.frames))
+	frames = append(frames, b.frames[b.next:]...)
+	return append(frames, b.frames[:b.next]...)
+}
+
+// replay merges the rows of frames, ordered from the oldest to the latest,
+// into a single JSON frame. Only the latest frames which share the schema
+// of the latest frame and are not older than MaxAge are included.
+func (c ReplayConfig) replay(frames []replayFrame, now time.Time) (json.RawMessage, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	latest := frames[len(frames)-1]
+	start := len(frames) - 1
+	for i := len(frames) - 2; i >= 0; i-- {
+		if c.MaxAge > 0 && now.Sub(frames[i].time) > c.MaxAge {
+			break
+		}
+		if !frames[i].frame.SameSchema(&latest.frame) {
+			break
+		}
+		start = i
+	}
+	if start == len(frames)-1 {
+		return latest.frame.Bytes(data.IncludeAll), nil
+	}
+
+	var merged *data.Frame
+	for _, f := range frames[start:] {
+		var frame data.Frame
+		if err := json.Unmarshal(f.frame.Bytes(data.IncludeAll), &frame); err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = &frame
+			continue
+		}
+		for i, field := range frame.Fields {
+			for row := 0; row < field.Len(); row++ {
+				merged.Fields[i].Append(field.At(row))
+			}
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
@@ -19,13 +19,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(ReplayConfig{}))
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(ReplayConfig{}))
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -47,7 +47,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	frameCache := NewMemoryFrameCache()
+	frameCache := NewMemoryFrameCache(ReplayConfig{})
 	runner := NewRunner(publisher.publish, nil, frameCache)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
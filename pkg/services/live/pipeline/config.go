@@ -26,6 +26,8 @@ type ChannelRuleSettings struct {
 	Converter       *ConverterConfig        `json:"converter,omitempty"`
 	FrameProcessors []*FrameProcessorConfig `json:"frameProcessors,omitempty"`
 	FrameOutputters []*FrameOutputterConfig `json:"frameOutputs,omitempty"`
+	// Sources consume messages from external systems and process them as input of the rule channel.
+	Sources []*SourceConfig `json:"sources,omitempty"`
 }
 
 type ChannelRule struct {
@@ -51,10 +53,70 @@ type KeepFieldsFrameProcessorConfig struct {
 }
 
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                       string                           `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig  *DropFieldsFrameProcessorConfig  `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig  *KeepFieldsFrameProcessorConfig  `json:"keepFields,omitempty"`
+	MultipleProcessorConfig    *MultipleFrameProcessorConfig    `json:"multiple,omitempty"`
+	AggregateProcessorConfig   *AggregateFrameProcessorConfig   `json:"aggregate,omitempty"`
+	DownsampleProcessorConfig  *DownsampleFrameProcessorConfig  `json:"downsample,omitempty"`
+	ComputeProcessorConfig     *ComputeFrameProcessorConfig     `json:"compute,omitempty"`
+	FieldConfigProcessorConfig *FieldConfigFrameProcessorConfig `json:"fieldConfig,omitempty"`
+	PathLabelsProcessorConfig  *PathLabelsFrameProcessorConfig  `json:"pathLabels,omitempty"`
+}
+
+// AggregateFunction is a function that AggregateFrameProcessor applies to the values of a window.
+type AggregateFunction string
+
+const (
+	AggregateFunctionMean  AggregateFunction = "mean"
+	AggregateFunctionMin   AggregateFunction = "min"
+	AggregateFunctionMax   AggregateFunction = "max"
+	AggregateFunctionSum   AggregateFunction = "sum"
+	AggregateFunctionCount AggregateFunction = "count"
+	AggregateFunctionLast  AggregateFunction = "last"
+)
+
+type AggregateField struct {
+	Name      string              `json:"name"`
+	Functions []AggregateFunction `json:"functions"`
+}
+
+type AggregateFrameProcessorConfig struct {
+	// Window is a duration of tumbling windows, for example 1s.
+	Window string `json:"window"`
+	// Fields to aggregate, all other fields except time are dropped.
+	Fields []AggregateField `json:"fields"`
+}
+
+type DownsampleFrameProcessorConfig struct {
+	// Interval is a minimum duration between frames of a channel, for example 1s.
+	Interval string `json:"interval"`
+}
+
+type ComputedField struct {
+	Name string `json:"name"`
+	// Expression is a math expression, other fields are referenced as $name or ${name}.
+	Expression string `json:"expression"`
+}
+
+type ComputeFrameProcessorConfig struct {
+	Fields []ComputedField `json:"fields"`
+}
+
+type FieldConfigOverride struct {
+	FieldName   string `json:"fieldName"`
+	Rename      string `json:"rename,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type FieldConfigFrameProcessorConfig struct {
+	Fields []FieldConfigOverride `json:"fields"`
+}
+
+type PathLabelsFrameProcessorConfig struct {
+	// Params of the channel rule pattern to add as labels, all params are added if empty.
+	Params []string `json:"params,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -88,6 +150,65 @@ type SubscriberConfig struct {
 	MultipleSubscriberConfig *MultipleSubscriberConfig `json:"multiple,omitempty"`
 }
 
+type SourceConfig struct {
+	Type              string             `json:"type" ts_type:"Omit<keyof SourceConfig, 'type'>"`
+	MQTTSourceConfig  *MQTTSourceConfig  `json:"mqtt,omitempty"`
+	NATSSourceConfig  *NATSSourceConfig  `json:"nats,omitempty"`
+	KafkaSourceConfig *KafkaSourceConfig `json:"kafka,omitempty"`
+	// SecureSettings are the secrets of the source set by commands, for example basicAuthPassword.
+	// They are encrypted before the channel rule is saved.
+	SecureSettings map[string]string `json:"secureSettings,omitempty"`
+	// EncryptedSecureSettings are the saved secrets of the source, they are never returned by the API.
+	EncryptedSecureSettings map[string][]byte `json:"encryptedSecureSettings,omitempty"`
+	// SecureFields are the names of the saved secrets of the source, returned instead of the secrets.
+	SecureFields map[string]bool `json:"secureFields,omitempty"`
+}
+
+// SourceBasicAuth is the basic authentication of a source, the password is
+// the basicAuthPassword secure setting of the source.
+type SourceBasicAuth struct {
+	User string `json:"user,omitempty"`
+}
+
+type MQTTSourceConfig struct {
+	// Broker is a broker address, for example tcp://localhost:1883 or ssl://localhost:8883.
+	Broker string `json:"broker"`
+	// Topics to subscribe to, can contain + and # wildcards.
+	Topics []string `json:"topics"`
+	// QoS is the quality of service level of the subscriptions (0, 1 or 2).
+	QoS byte `json:"qos,omitempty"`
+	// ClientID must be unique per broker, it's generated if not set.
+	ClientID string `json:"clientId,omitempty"`
+	// SharedGroup is an optional shared subscription group, messages are then distributed between
+	// Grafana instances of the group instead of being delivered to each of them. The broker must
+	// support shared subscriptions.
+	SharedGroup string           `json:"sharedGroup,omitempty"`
+	BasicAuth   *SourceBasicAuth `json:"basicAuth,omitempty"`
+}
+
+type NATSSourceConfig struct {
+	// URL is a comma-separated list of server URLs, for example nats://localhost:4222.
+	URL string `json:"url"`
+	// Subjects to subscribe to, can contain * and > wildcards.
+	Subjects []string `json:"subjects"`
+	// Queue is an optional queue group, messages are then distributed between Grafana instances
+	// of the group instead of being delivered to each of them.
+	Queue     string           `json:"queue,omitempty"`
+	BasicAuth *SourceBasicAuth `json:"basicAuth,omitempty"`
+}
+
+type KafkaSourceConfig struct {
+	Brokers []string `json:"brokers"`
+	Topics  []string `json:"topics"`
+	// ConsumerGroup is an optional consumer group, partitions are then distributed between
+	// Grafana instances of the group instead of being consumed by each of them.
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+	// TLS enables TLS connections to the brokers.
+	TLS bool `json:"tls,omitempty"`
+	// BasicAuth enables SASL PLAIN authentication.
+	BasicAuth *SourceBasicAuth `json:"basicAuth,omitempty"`
+}
+
 // RedirectDataOutputConfig ...
 type RedirectDataOutputConfig struct {
 	Channel string `json:"channel"`
@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// ErrInvalidImport is returned when an imported channel rule or write config is invalid.
+var ErrInvalidImport = errors.New("invalid import")
+
+// Export is a portable set of the channel rules and write configs of an org.
+// Secure settings of write configs and sources are not exported, only their names.
+type Export struct {
+	ChannelRules []ChannelRule    `json:"channelRules"`
+	WriteConfigs []WriteConfigDto `json:"writeConfigs"`
+}
+
+// ImportCmd creates or updates channel rules and write configs of an org.
+// The result of an export can be imported as is.
+type ImportCmd struct {
+	ChannelRules []ChannelRuleUpdateCmd `json:"channelRules"`
+	WriteConfigs []WriteConfigUpdateCmd `json:"writeConfigs"`
+}
+
+// ExportOrg exports the channel rules and write configs of an org.
+func ExportOrg(ctx context.Context, storage Storage, orgID int64) (Export, error) {
+	rules, err := storage.ListChannelRules(ctx, orgID)
+	if err != nil {
+		return Export{}, fmt.Errorf("can't list channel rules: %w", err)
+	}
+	writeConfigs, err := storage.ListWriteConfigs(ctx, orgID)
+	if err != nil {
+		return Export{}, fmt.Errorf("can't list write configs: %w", err)
+	}
+	export := Export{
+		ChannelRules: make([]ChannelRule, 0, len(rules)),
+		WriteConfigs: make([]WriteConfigDto, 0, len(writeConfigs)),
+	}
+	for _, r := range rules {
+		export.ChannelRules = append(export.ChannelRules, ChannelRuleToDto(r))
+	}
+	for _, c := range writeConfigs {
+		export.WriteConfigs = append(export.WriteConfigs, WriteConfigToDto(c))
+	}
+	return export, nil
+}
+
+// ImportOrg creates or updates the channel rules and write configs of an org.
+// Everything is validated before anything is saved. Write configs are saved
+// before channel rules since rule outputs may refer to them. Secure settings
+// of existing write configs which are not part of the command are kept, as
+// well as secure settings of sources which are marked in their secure fields.
+func ImportOrg(ctx context.Context, storage Storage, secretsService secrets.Service, orgID int64, cmd ImportCmd) error {
+	for _, c := range cmd.WriteConfigs {
+		if ok, reason := (WriteConfig{UID: c.UID, Settings: c.Settings}).Valid(); !ok {
+			return fmt.Errorf("%w: write config %q: %s", ErrInvalidImport, c.UID, reason)
+		}
+	}
+	rules := make([]ChannelRule, 0, len(cmd.ChannelRules))
+	for _, c := range cmd.ChannelRules {
+		rule := ChannelRule{OrgId: orgID, Pattern: c.Pattern, Settings: c.Settings}
+		if ok, reason := rule.Valid(); !ok {
+			return fmt.Errorf("%w: channel rule %q: %s", ErrInvalidImport, c.Pattern, reason)
+		}
+		rules = append(rules, rule)
+	}
+	if ok, reason := checkRulesValid(orgID, rules); !ok {
+		return fmt.Errorf("%w: channel rules: %s", ErrInvalidImport, reason)
+	}
+
+	for _, c := range cmd.WriteConfigs {
+		existing, ok, err := storage.GetWriteConfig(ctx, orgID, WriteConfigGetCmd{UID: c.UID})
+		if err != nil {
+			return fmt.Errorf("can't get write config %q: %w", c.UID, err)
+		}
+		if ok {
+			secureSettings, err := secretsService.DecryptJsonData(ctx, existing.SecureSettings)
+			if err != nil {
+				return fmt.Errorf("can't decrypt secure settings of write config %q: %w", c.UID, err)
+			}
+			merged := make(map[string]string, len(secureSettings)+len(c.SecureSettings))
+			for k, v := range secureSettings {
+				merged[k] = v
+			}
+			for k, v := range c.SecureSettings {
+				merged[k] = v
+			}
+			c.SecureSettings = merged
+		}
+		if _, err := storage.UpdateWriteConfig(ctx, orgID, c); err != nil {
+			return fmt.Errorf("can't save write config %q: %w", c.UID, err)
+		}
+	}
+	for _, c := range cmd.ChannelRules {
+		if _, err := storage.UpdateChannelRule(ctx, orgID, c); err != nil {
+			return fmt.Errorf("can't save channel rule %q: %w", c.Pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func TestExportImportOrg(t *testing.T) {
+	ctx := context.Background()
+	secretsService := fakes.NewFakeSecretsService()
+	storage := &FileStorage{DataPath: t.TempDir(), SecretsService: secretsService}
+
+	rule := ChannelRuleUpdateCmd{
+		Pattern: "stream/factory/telemetry",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+			FrameOutputters: []*FrameOutputterConfig{{
+				Type:                    FrameOutputTypeRemoteWrite,
+				RemoteWriteOutputConfig: &RemoteWriteOutputConfig{UID: "prom"},
+			}},
+		},
+	}
+	writeConfig := WriteConfigUpdateCmd{
+		UID:            "prom",
+		Settings:       WriteSettings{Endpoint: "http://prometheus:9090/api/v1/write"},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	}
+
+	err := ImportOrg(ctx, storage, secretsService, 1, ImportCmd{
+		ChannelRules: []ChannelRuleUpdateCmd{rule},
+		WriteConfigs: []WriteConfigUpdateCmd{writeConfig},
+	})
+	require.NoError(t, err)
+
+	export, err := ExportOrg(ctx, storage, 1)
+	require.NoError(t, err)
+	require.Len(t, export.ChannelRules, 1)
+	require.Equal(t, rule.Pattern, export.ChannelRules[0].Pattern)
+	require.Equal(t, []WriteConfigDto{{
+		UID:          "prom",
+		Settings:     writeConfig.Settings,
+		SecureFields: map[string]bool{"basicAuthPassword": true},
+	}}, export.WriteConfigs)
+
+	t.Run("should keep secure settings which are not imported", func(t *testing.T) {
+		writeConfig.Settings.Endpoint = "http://mimir:9009/api/v1/push"
+		writeConfig.SecureSettings = nil
+		err := ImportOrg(ctx, storage, secretsService, 1, ImportCmd{WriteConfigs: []WriteConfigUpdateCmd{writeConfig}})
+		require.NoError(t, err)
+
+		c, ok, err := storage.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: "prom"})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "http://mimir:9009/api/v1/push", c.Settings.Endpoint)
+		secureSettings, err := secretsService.DecryptJsonData(ctx, c.SecureSettings)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, secureSettings)
+	})
+
+	t.Run("should not save anything if something is invalid", func(t *testing.T) {
+		err := ImportOrg(ctx, storage, secretsService, 1, ImportCmd{
+			WriteConfigs: []WriteConfigUpdateCmd{{UID: "loki", Settings: WriteSettings{Endpoint: "http://loki:3100"}}},
+			ChannelRules: []ChannelRuleUpdateCmd{{Pattern: "stream/factory/other", Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: "unknown"}}}},
+		})
+		require.ErrorIs(t, err, ErrInvalidImport)
+
+		_, ok, err := storage.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: "loki"})
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
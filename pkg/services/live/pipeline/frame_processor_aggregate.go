@@ -0,0 +1,222 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AggregateFrameProcessor aggregates field values of a channel over tumbling time windows.
+// Windows are based on the time field of frames, or on the current time for frames without
+// one. A frame with the aggregated values of a window is passed on as soon as a value of
+// a later window arrives, or when the state of the channel is evicted because the stream
+// stopped. All other frames are dropped.
+type AggregateFrameProcessor struct {
+	config  AggregateFrameProcessorConfig
+	window  time.Duration
+	storage *ProcessorStateStorage
+	stateID string
+	now     func() time.Time
+}
+
+type aggregateState struct {
+	name   string
+	window time.Duration
+	start  time.Time
+	fields map[string]*aggregateValues
+}
+
+// minIdleTime keeps the state of an open window until the window is over.
+func (s *aggregateState) minIdleTime() time.Duration {
+	return s.window
+}
+
+type aggregateValues struct {
+	labels data.Labels
+	config *data.FieldConfig
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	last   float64
+}
+
+func NewAggregateFrameProcessor(storage *ProcessorStateStorage, stateID string, config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	window, err := time.ParseDuration(config.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window: %w", err)
+	}
+	if window <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+	for _, field := range config.Fields {
+		for _, fn := range field.Functions {
+			switch fn {
+			case AggregateFunctionMean, AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionSum, AggregateFunctionCount, AggregateFunctionLast:
+			default:
+				return nil, fmt.Errorf("unknown aggregate function: %s", fn)
+			}
+		}
+	}
+	if storage == nil {
+		storage = NewProcessorStateStorage()
+	}
+	return &AggregateFrameProcessor{
+		config:  config,
+		window:  window,
+		storage: storage,
+		stateID: stateID,
+		now:     time.Now,
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+func (p *AggregateFrameProcessor) StateID() string {
+	return p.stateID
+}
+
+// FlushState returns the frame with the aggregated values of the open window of the state.
+func (p *AggregateFrameProcessor) FlushState(s any) *data.Frame {
+	state, _ := s.(*aggregateState)
+	if state == nil || state.fields == nil {
+		return nil
+	}
+	return p.appendWindow(nil, state.name, state)
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	var timeField *data.Field
+	if indices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime); len(indices) > 0 {
+		timeField = frame.Fields[indices[0]]
+	}
+	var result *data.Frame
+	p.storage.Update(p.stateID, vars.OrgID, vars.Channel, func(s any) any {
+		state, _ := s.(*aggregateState)
+		if state == nil {
+			state = &aggregateState{}
+		}
+		state.name = frame.Name
+		state.window = p.window
+		for i := 0; i < frame.Rows(); i++ {
+			start := rowTime(timeField, i, p.now).Truncate(p.window)
+			if state.fields != nil && start.After(state.start) {
+				result = p.appendWindow(result, frame.Name, state)
+				state.fields = nil
+			}
+			if state.fields == nil {
+				state.start = start
+				state.fields = map[string]*aggregateValues{}
+			} else if start.Before(state.start) {
+				// The window of the row was already passed on.
+				continue
+			}
+			p.addRow(state, frame, i)
+		}
+		return state
+	})
+	return result, nil
+}
+
+func (p *AggregateFrameProcessor) addRow(state *aggregateState, frame *data.Frame, row int) {
+	for _, f := range p.config.Fields {
+		field, _ := frame.FieldByName(f.Name)
+		if field == nil {
+			continue
+		}
+		v, err := field.NullableFloatAt(row)
+		if err != nil || v == nil {
+			continue
+		}
+		values, ok := state.fields[f.Name]
+		if !ok {
+			values = &aggregateValues{min: *v, max: *v}
+			state.fields[f.Name] = values
+		}
+		values.labels = field.Labels
+		values.config = field.Config
+		values.count++
+		values.sum += *v
+		values.min = math.Min(values.min, *v)
+		values.max = math.Max(values.max, *v)
+		values.last = *v
+	}
+}
+
+func (p *AggregateFrameProcessor) appendWindow(result *data.Frame, name string, state *aggregateState) *data.Frame {
+	if result == nil {
+		fields := []*data.Field{data.NewField("time", nil, []time.Time{})}
+		for _, f := range p.config.Fields {
+			values := state.fields[f.Name]
+			for _, fn := range f.Functions {
+				field := data.NewField(fmt.Sprintf("%s_%s", f.Name, fn), nil, []*float64{})
+				if values != nil {
+					field.Labels = values.labels
+					if fn != AggregateFunctionCount {
+						field.Config = values.config
+					}
+				}
+				fields = append(fields, field)
+			}
+		}
+		result = data.NewFrame(name, fields...)
+	}
+	row := []any{state.start}
+	for _, f := range p.config.Fields {
+		values := state.fields[f.Name]
+		for _, fn := range f.Functions {
+			row = append(row, values.aggregate(fn))
+		}
+	}
+	result.AppendRow(row...)
+	return result
+}
+
+func (v *aggregateValues) aggregate(fn AggregateFunction) *float64 {
+	if v == nil {
+		if fn == AggregateFunctionCount {
+			count := float64(0)
+			return &count
+		}
+		return nil
+	}
+	var result float64
+	switch fn {
+	case AggregateFunctionMean:
+		result = v.sum / float64(v.count)
+	case AggregateFunctionMin:
+		result = v.min
+	case AggregateFunctionMax:
+		result = v.max
+	case AggregateFunctionSum:
+		result = v.sum
+	case AggregateFunctionCount:
+		result = float64(v.count)
+	case AggregateFunctionLast:
+		result = v.last
+	}
+	return &result
+}
+
+// rowTime returns the time of the row, or the current time if the frame has no time field.
+func rowTime(timeField *data.Field, row int, now func() time.Time) time.Time {
+	if timeField != nil {
+		switch t := timeField.At(row).(type) {
+		case time.Time:
+			return t
+		case *time.Time:
+			if t != nil {
+				return *t
+			}
+		}
+	}
+	return now()
+}
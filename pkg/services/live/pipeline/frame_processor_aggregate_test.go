@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor(t *testing.T) {
+	storage := NewProcessorStateStorage()
+	newProcessor := func(t *testing.T) *AggregateFrameProcessor {
+		p, err := NewAggregateFrameProcessor(storage, "stream/sensor/:id", AggregateFrameProcessorConfig{
+			Window: "1s",
+			Fields: []AggregateField{{Name: "value", Functions: []AggregateFunction{AggregateFunctionMean, AggregateFunctionMax, AggregateFunctionCount}}},
+		})
+		require.NoError(t, err)
+		return p
+	}
+	start := time.Unix(1700000000, 0)
+	newFrame := func(offsets []time.Duration, values []float64) *data.Frame {
+		times := make([]time.Time, 0, len(offsets))
+		for _, o := range offsets {
+			times = append(times, start.Add(o))
+		}
+		return data.NewFrame("sensor",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"sensor": "a"}, values),
+			data.NewField("other", nil, values),
+		)
+	}
+	vars := Vars{OrgID: 1, Channel: "stream/sensor/a"}
+
+	p := newProcessor(t)
+	frame, err := p.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{0, 100 * time.Millisecond}, []float64{1, 5}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// The state is kept when rules are rebuilt.
+	p = newProcessor(t)
+	frame, err = p.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{500 * time.Millisecond}, []float64{3}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	t.Run("should not mix windows of different channels", func(t *testing.T) {
+		frame, err := p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/sensor/b"}, newFrame([]time.Duration{2 * time.Second}, []float64{10}))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+	})
+
+	frame, err = p.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{time.Second, 3 * time.Second}, []float64{7, 8}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, []string{"time", "value_mean", "value_max", "value_count"}, []string{frame.Fields[0].Name, frame.Fields[1].Name, frame.Fields[2].Name, frame.Fields[3].Name})
+	require.Equal(t, data.Labels{"sensor": "a"}, frame.Fields[1].Labels)
+	require.Equal(t, 2, frame.Rows())
+
+	require.Equal(t, start, frame.Fields[0].At(0))
+	require.Equal(t, 3.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, 5.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, 3.0, *frame.Fields[3].At(0).(*float64))
+
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(1))
+	require.Equal(t, 7.0, *frame.Fields[1].At(1).(*float64))
+	require.Equal(t, 1.0, *frame.Fields[3].At(1).(*float64))
+
+	t.Run("should drop values of windows that were passed on", func(t *testing.T) {
+		frame, err := p.ProcessFrame(context.Background(), vars, newFrame([]time.Duration{time.Second, 4 * time.Second}, []float64{100, 9}))
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, start.Add(3*time.Second), frame.Fields[0].At(0))
+		require.Equal(t, 8.0, *frame.Fields[1].At(0).(*float64))
+	})
+}
+
+func TestNewAggregateFrameProcessor_Invalid(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(nil, "", AggregateFrameProcessorConfig{Window: "0s"})
+	require.ErrorContains(t, err, "window must be positive")
+
+	_, err = NewAggregateFrameProcessor(nil, "", AggregateFrameProcessorConfig{
+		Window: "1s",
+		Fields: []AggregateField{{Name: "value", Functions: []AggregateFunction{"median"}}},
+	})
+	require.ErrorContains(t, err, "unknown aggregate function: median")
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ComputeFrameProcessor adds fields calculated with math expressions from the values of other
+// fields in the same row. Expressions use the syntax of server-side math expressions, computed
+// fields can be referenced by the expressions of the following computed fields.
+type ComputeFrameProcessor struct {
+	fields []computedField
+}
+
+type computedField struct {
+	name string
+	expr *mathexp.Expr
+}
+
+func NewComputeFrameProcessor(config ComputeFrameProcessorConfig) (*ComputeFrameProcessor, error) {
+	fields := make([]computedField, 0, len(config.Fields))
+	for _, f := range config.Fields {
+		expr, err := mathexp.New(f.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression of field %s: %w", f.Name, err)
+		}
+		fields = append(fields, computedField{name: f.Name, expr: expr})
+	}
+	return &ComputeFrameProcessor{fields: fields}, nil
+}
+
+const FrameProcessorTypeCompute = "compute"
+
+func (p *ComputeFrameProcessor) Type() string {
+	return FrameProcessorTypeCompute
+}
+
+func (p *ComputeFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rows := frame.Rows()
+	for _, f := range p.fields {
+		values := make([]*float64, rows)
+		for i := 0; i < rows; i++ {
+			v, err := f.evaluate(frame, i)
+			if err != nil {
+				return nil, fmt.Errorf("error computing field %s: %w", f.name, err)
+			}
+			values[i] = v
+		}
+		frame.Fields = append(frame.Fields, data.NewField(f.name, nil, values))
+	}
+	return frame, nil
+}
+
+func (f computedField) evaluate(frame *data.Frame, row int) (*float64, error) {
+	vars := make(mathexp.Vars, len(f.expr.VarNames))
+	for _, name := range f.expr.VarNames {
+		var v *float64
+		if field, _ := frame.FieldByName(name); field != nil {
+			v, _ = field.NullableFloatAt(row)
+		}
+		vars[name] = mathexp.NewScalarResults(name, v)
+	}
+	// Expressions are evaluated on scalars, which are not traced.
+	res, err := f.expr.Execute(f.name, vars, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Values) != 1 {
+		return nil, nil
+	}
+	switch v := res.Values[0].(type) {
+	case mathexp.Scalar:
+		return v.GetFloat64Value(), nil
+	case mathexp.Number:
+		return v.GetFloat64Value(), nil
+	default:
+		return nil, fmt.Errorf("unexpected result type: %s", v.Type())
+	}
+}
@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFrameProcessor(t *testing.T) {
+	p, err := NewComputeFrameProcessor(ComputeFrameProcessorConfig{
+		Fields: []ComputedField{
+			{Name: "power", Expression: "$voltage * $current"},
+			{Name: "power_kw", Expression: "${power} / 1000"},
+			{Name: "missing", Expression: "$unknown + 1"},
+		},
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("voltage", nil, []float64{230, 240}),
+		data.NewField("current", nil, []*float64{float64Ptr(10), nil}),
+	)
+	frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 5)
+
+	power, _ := frame.FieldByName("power")
+	require.Equal(t, 2300.0, *power.At(0).(*float64))
+	require.Nil(t, power.At(1).(*float64))
+
+	powerKW, _ := frame.FieldByName("power_kw")
+	require.Equal(t, 2.3, *powerKW.At(0).(*float64))
+
+	missing, _ := frame.FieldByName("missing")
+	require.Nil(t, missing.At(0).(*float64))
+
+	_, err = NewComputeFrameProcessor(ComputeFrameProcessorConfig{Fields: []ComputedField{{Name: "invalid", Expression: "$a +"}}})
+	require.ErrorContains(t, err, "invalid expression of field invalid")
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
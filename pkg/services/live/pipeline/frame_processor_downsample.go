@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DownsampleFrameProcessor passes at most one frame per interval for each channel and drops
+// all other frames. This limits the rate of frames sent to subscribers of high frequency channels.
+type DownsampleFrameProcessor struct {
+	interval time.Duration
+	storage  *ProcessorStateStorage
+	stateID  string
+	now      func() time.Time
+}
+
+func NewDownsampleFrameProcessor(storage *ProcessorStateStorage, stateID string, config DownsampleFrameProcessorConfig) (*DownsampleFrameProcessor, error) {
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval: %w", err)
+	}
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if storage == nil {
+		storage = NewProcessorStateStorage()
+	}
+	return &DownsampleFrameProcessor{
+		interval: interval,
+		storage:  storage,
+		stateID:  stateID,
+		now:      time.Now,
+	}, nil
+}
+
+const FrameProcessorTypeDownsample = "downsample"
+
+func (p *DownsampleFrameProcessor) Type() string {
+	return FrameProcessorTypeDownsample
+}
+
+func (p *DownsampleFrameProcessor) StateID() string {
+	return p.stateID
+}
+
+// FlushState returns nil, frames which are not passed are dropped.
+func (p *DownsampleFrameProcessor) FlushState(_ any) *data.Frame {
+	return nil
+}
+
+func (p *DownsampleFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	now := p.now()
+	pass := false
+	p.storage.Update(p.stateID, vars.OrgID, vars.Channel, func(s any) any {
+		last, ok := s.(time.Time)
+		if ok && now.Sub(last) < p.interval {
+			return last
+		}
+		pass = true
+		return now
+	})
+	if !pass {
+		return nil, nil
+	}
+	return frame, nil
+}
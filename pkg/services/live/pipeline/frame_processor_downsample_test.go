@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsampleFrameProcessor(t *testing.T) {
+	p, err := NewDownsampleFrameProcessor(nil, "", DownsampleFrameProcessorConfig{Interval: "1s"})
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	vars := Vars{OrgID: 1, Channel: "stream/test/a"}
+
+	processed := func(vars Vars) bool {
+		f, err := p.ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		return f != nil
+	}
+
+	require.True(t, processed(vars))
+	now = now.Add(500 * time.Millisecond)
+	require.False(t, processed(vars))
+	require.True(t, processed(Vars{OrgID: 1, Channel: "stream/test/b"}))
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, processed(vars))
+}
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FieldConfigFrameProcessor renames fields and sets their unit and display name.
+type FieldConfigFrameProcessor struct {
+	config FieldConfigFrameProcessorConfig
+}
+
+func NewFieldConfigFrameProcessor(config FieldConfigFrameProcessorConfig) *FieldConfigFrameProcessor {
+	return &FieldConfigFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeFieldConfig = "fieldConfig"
+
+func (p *FieldConfigFrameProcessor) Type() string {
+	return FrameProcessorTypeFieldConfig
+}
+
+func (p *FieldConfigFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, override := range p.config.Fields {
+		field, _ := frame.FieldByName(override.FieldName)
+		if field == nil {
+			continue
+		}
+		if override.Rename != "" {
+			field.Name = override.Rename
+		}
+		if override.Unit == "" && override.DisplayName == "" {
+			continue
+		}
+		config := data.FieldConfig{}
+		if field.Config != nil {
+			config = *field.Config
+		}
+		if override.Unit != "" {
+			config.Unit = override.Unit
+		}
+		if override.DisplayName != "" {
+			config.DisplayName = override.DisplayName
+		}
+		field.Config = &config
+	}
+	return frame, nil
+}
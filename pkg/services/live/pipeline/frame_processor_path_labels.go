@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
+)
+
+// PathLabelsFrameProcessor adds the values of channel rule pattern params as labels to all
+// fields except time fields. For example, frames of the channel stream/factory/line1 get
+// the label line=line1 with the pattern stream/factory/:line.
+type PathLabelsFrameProcessor struct {
+	config PathLabelsFrameProcessorConfig
+	tree   *tree.Node
+}
+
+func NewPathLabelsFrameProcessor(pattern string, config PathLabelsFrameProcessorConfig) *PathLabelsFrameProcessor {
+	t := tree.New()
+	t.AddRoute("/"+pattern, struct{}{})
+	return &PathLabelsFrameProcessor{config: config, tree: t}
+}
+
+const FrameProcessorTypePathLabels = "pathLabels"
+
+func (p *PathLabelsFrameProcessor) Type() string {
+	return FrameProcessorTypePathLabels
+}
+
+func (p *PathLabelsFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	value := p.tree.GetValue("/"+vars.Channel, true)
+	if value.Params == nil {
+		return frame, nil
+	}
+	var params tree.Params
+	for _, param := range *value.Params {
+		if len(p.config.Params) == 0 || stringInSlice(param.Key, p.config.Params) {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return frame, nil
+	}
+	for _, field := range frame.Fields {
+		if field.Type().Time() {
+			continue
+		}
+		labels := make(data.Labels, len(field.Labels)+len(params))
+		for k, v := range field.Labels {
+			labels[k] = v
+		}
+		for _, param := range params {
+			// Values of catch-all params start with a slash.
+			labels[param.Key] = strings.TrimPrefix(param.Value, "/")
+		}
+		field.Labels = labels
+	}
+	return frame, nil
+}
@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathLabelsFrameProcessor(t *testing.T) {
+	newFrame := func() *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{time.Unix(1700000000, 0)}),
+			data.NewField("value", data.Labels{"unit": "a"}, []float64{1}),
+		)
+	}
+
+	t.Run("should add all params as labels", func(t *testing.T) {
+		p := NewPathLabelsFrameProcessor("stream/factory/:line/*sensor", PathLabelsFrameProcessorConfig{})
+		frame, err := p.ProcessFrame(context.Background(), Vars{Channel: "stream/factory/line1/temp/inner"}, newFrame())
+		require.NoError(t, err)
+		require.Nil(t, frame.Fields[0].Labels)
+		require.Equal(t, data.Labels{"unit": "a", "line": "line1", "sensor": "temp/inner"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("should add only the configured params", func(t *testing.T) {
+		p := NewPathLabelsFrameProcessor("stream/factory/:line/:sensor", PathLabelsFrameProcessorConfig{Params: []string{"line"}})
+		frame, err := p.ProcessFrame(context.Background(), Vars{Channel: "stream/factory/line1/temp"}, newFrame())
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"unit": "a", "line": "line1"}, frame.Fields[1].Labels)
+	})
+}
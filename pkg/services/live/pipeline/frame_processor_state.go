@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// ProcessorStateIdleTimeout is the default time after which the state of a channel which
+	// received no frames is evicted, for example because the stream of the channel stopped.
+	ProcessorStateIdleTimeout      = time.Minute
+	processorStateEvictionInterval = 10 * time.Second
+)
+
+// StatefulFrameProcessor is a FrameProcessor which keeps its state in a ProcessorStateStorage.
+type StatefulFrameProcessor interface {
+	FrameProcessor
+	// StateID returns the ID of the state of the processor in the storage.
+	StateID() string
+	// FlushState returns the frame held back in a state which is evicted from the storage, or nil.
+	FlushState(state any) *data.Frame
+}
+
+// ProcessorStateStorage keeps the state of stateful frame processors for each channel in memory,
+// so the state survives periodic rebuilding of channel rules. Not usable in HA setup.
+// States of channels which received no frames for IdleTimeout are evicted, as well as states of
+// processors which are no longer part of the channel rules.
+type ProcessorStateStorage struct {
+	IdleTimeout time.Duration
+
+	mu     sync.Mutex
+	states map[processorStateKey]*processorState
+	now    func() time.Time
+}
+
+type processorStateKey struct {
+	processor string
+	orgID     int64
+	channel   string
+}
+
+type processorState struct {
+	value   any
+	updated time.Time
+}
+
+// minIdleTimeState is implemented by states which must not be evicted before they were idle
+// for a minimum time, for example states of windows longer than the idle timeout.
+type minIdleTimeState interface {
+	minIdleTime() time.Duration
+}
+
+// evictedProcessorState is a state which was evicted from the storage.
+type evictedProcessorState struct {
+	processorStateKey
+	value any
+}
+
+func NewProcessorStateStorage() *ProcessorStateStorage {
+	return &ProcessorStateStorage{
+		IdleTimeout: ProcessorStateIdleTimeout,
+		states:      map[processorStateKey]*processorState{},
+		now:         time.Now,
+	}
+}
+
+// Update calls fn with the current state of the processor for the channel, nil if there is no
+// state yet, and stores the state returned by fn.
+func (s *ProcessorStateStorage) Update(processor string, orgID int64, channel string, fn func(state any) any) {
+	key := processorStateKey{processor: processor, orgID: orgID, channel: channel}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	if !ok {
+		state = &processorState{}
+		s.states[key] = state
+	}
+	state.value = fn(state.value)
+	state.updated = s.now()
+}
+
+// evictIdle removes and returns the states which were not updated for the idle timeout.
+func (s *ProcessorStateStorage) evictIdle() []evictedProcessorState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var evicted []evictedProcessorState
+	for key, state := range s.states {
+		timeout := s.IdleTimeout
+		if st, ok := state.value.(minIdleTimeState); ok && st.minIdleTime() > timeout {
+			timeout = st.minIdleTime()
+		}
+		if now.Sub(state.updated) < timeout {
+			continue
+		}
+		evicted = append(evicted, evictedProcessorState{processorStateKey: key, value: state.value})
+		delete(s.states, key)
+	}
+	return evicted
+}
+
+// retain removes the states of the organization which don't belong to the given processors,
+// the states of processors which were replaced or removed from channel rules.
+func (s *ProcessorStateStorage) retain(orgID int64, processors map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.states {
+		if key.orgID != orgID {
+			continue
+		}
+		if _, ok := processors[key.processor]; !ok {
+			delete(s.states, key)
+		}
+	}
+}
+
+// RunProcessorStateEviction evicts idle states of the storage until the context is done. Frames held back
+// by evicted states, like the last window of an aggregate processor of a stream that stopped, are passed
+// on to the rest of the channel rule.
+func (p *Pipeline) RunProcessorStateEviction(ctx context.Context, states *ProcessorStateStorage) error {
+	ticker := time.NewTicker(processorStateEvictionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			for _, state := range states.evictIdle() {
+				if err := p.flushProcessorState(ctx, state); err != nil {
+					logger.Error("Error flushing frame processor state", "error", err, "orgId", state.orgID, "channel", state.channel)
+				}
+			}
+		}
+	}
+}
+
+// flushProcessorState passes the frame held back by an evicted state to the processors which follow the
+// processor of the state and to the frame outputs of the channel rule.
+func (p *Pipeline) flushProcessorState(ctx context.Context, state evictedProcessorState) error {
+	rule, ok, err := p.ruleGetter.Get(state.orgID, state.channel)
+	if err != nil || !ok {
+		return err
+	}
+	processors := flattenFrameProcessors(rule.FrameProcessors)
+	for i, proc := range processors {
+		stateful, ok := proc.(StatefulFrameProcessor)
+		if !ok || stateful.StateID() != state.processor {
+			continue
+		}
+		frame := stateful.FlushState(state.value)
+		if frame == nil {
+			return nil
+		}
+		vars, err := channelVars(state.orgID, state.channel)
+		if err != nil {
+			return err
+		}
+		frames, err := p.processRuleFrame(ctx, rule, vars, frame, processors[i+1:])
+		if err != nil || len(frames) == 0 {
+			return err
+		}
+		return p.processChannelFrames(ctx, state.orgID, state.channel, frames, map[string]struct{}{state.channel: {}})
+	}
+	return nil
+}
+
+// flattenFrameProcessors returns the processors in the order they are executed, with the processors
+// of MultipleFrameProcessor in place of it.
+func flattenFrameProcessors(processors []FrameProcessor) []FrameProcessor {
+	result := make([]FrameProcessor, 0, len(processors))
+	for _, proc := range processors {
+		if multiple, ok := proc.(*MultipleFrameProcessor); ok {
+			result = append(result, flattenFrameProcessors(multiple.Processors)...)
+			continue
+		}
+		result = append(result, proc)
+	}
+	return result
+}
+
+// processorStateIDs returns the state IDs of the stateful processors of the rules.
+func processorStateIDs(rules []*LiveChannelRule) map[string]struct{} {
+	ids := map[string]struct{}{}
+	for _, rule := range rules {
+		for _, proc := range flattenFrameProcessors(rule.FrameProcessors) {
+			if stateful, ok := proc.(StatefulFrameProcessor); ok {
+				ids[stateful.StateID()] = struct{}{}
+			}
+		}
+	}
+	return ids
+}
+
+// processorStateID identifies the state of a processor, the state is reset when the
+// configuration of the processor changes.
+func processorStateID(pattern string, processorType string, config any) string {
+	b, _ := json.Marshal(config)
+	return pattern + "|" + processorType + "|" + string(b)
+}
@@ -0,0 +1,91 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessorStateStorage(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	storage := NewProcessorStateStorage()
+	storage.now = func() time.Time { return now }
+	set := func(processor string, orgID int64, channel string, value any) {
+		storage.Update(processor, orgID, channel, func(_ any) any { return value })
+	}
+
+	t.Run("should evict states which were idle for the idle timeout", func(t *testing.T) {
+		set("downsample", 1, "stream/sensor/a", "a")
+		now = now.Add(storage.IdleTimeout / 2)
+		set("downsample", 1, "stream/sensor/b", "b")
+		require.Empty(t, storage.evictIdle())
+
+		now = now.Add(storage.IdleTimeout / 2)
+		evicted := storage.evictIdle()
+		require.Len(t, evicted, 1)
+		require.Equal(t, "stream/sensor/a", evicted[0].channel)
+		require.Equal(t, "a", evicted[0].value)
+		require.Len(t, storage.states, 1)
+	})
+
+	t.Run("should keep open windows longer than the idle timeout", func(t *testing.T) {
+		storage.states = map[processorStateKey]*processorState{}
+		set("aggregate", 1, "stream/sensor/a", &aggregateState{window: 2 * storage.IdleTimeout})
+		now = now.Add(storage.IdleTimeout)
+		require.Empty(t, storage.evictIdle())
+		now = now.Add(storage.IdleTimeout)
+		require.Len(t, storage.evictIdle(), 1)
+	})
+
+	t.Run("should remove states of processors which are not part of the rules", func(t *testing.T) {
+		storage.states = map[processorStateKey]*processorState{}
+		set("old", 1, "stream/sensor/a", "a")
+		set("new", 1, "stream/sensor/a", "a")
+		set("old", 2, "stream/sensor/a", "a")
+		storage.retain(1, map[string]struct{}{"new": {}})
+		require.Len(t, storage.states, 2)
+		require.Contains(t, storage.states, processorStateKey{processor: "new", orgID: 1, channel: "stream/sensor/a"})
+		require.Contains(t, storage.states, processorStateKey{processor: "old", orgID: 2, channel: "stream/sensor/a"})
+	})
+}
+
+func TestPipeline_FlushProcessorState(t *testing.T) {
+	storage := NewProcessorStateStorage()
+	aggregate, err := NewAggregateFrameProcessor(storage, "stream/sensor/:id", AggregateFrameProcessorConfig{
+		Window: "1s",
+		Fields: []AggregateField{{Name: "value", Functions: []AggregateFunction{AggregateFunctionMax}}},
+	})
+	require.NoError(t, err)
+	outputter := &testOutputter{}
+	rule := &LiveChannelRule{
+		FrameProcessors: []FrameProcessor{NewMultipleFrameProcessor(aggregate, &testProcessor{})},
+		FrameOutputters: []FrameOutputter{outputter},
+	}
+	p, err := New(&testRuleGetter{rules: map[string]*LiveChannelRule{"stream/sensor/a": rule}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"stream/sensor/:id": {}}, processorStateIDs([]*LiveChannelRule{rule}))
+
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/sensor/a"}
+	frame, err := aggregate.ProcessFrame(context.Background(), vars, data.NewFrame("sensor",
+		data.NewField("time", nil, []time.Time{start, start.Add(100 * time.Millisecond)}),
+		data.NewField("value", nil, []float64{1, 5}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	storage.now = func() time.Time { return time.Now().Add(storage.IdleTimeout) }
+	evicted := storage.evictIdle()
+	require.Len(t, evicted, 1)
+
+	err = p.flushProcessorState(context.Background(), evicted[0])
+	require.NoError(t, err)
+	require.NotNil(t, outputter.frame)
+	require.Equal(t, "sensor", outputter.frame.Name)
+	require.Equal(t, 1, outputter.frame.Rows())
+	require.Equal(t, start, outputter.frame.Fields[0].At(0))
+	require.Equal(t, 5.0, *outputter.frame.Fields[1].At(0).(*float64))
+}
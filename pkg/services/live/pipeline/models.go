@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
@@ -38,6 +39,19 @@ func (r ChannelRule) Valid() (bool, string) {
 			}
 		}
 	}
+	if len(r.Settings.Sources) > 0 {
+		if strings.ContainsAny(r.Pattern, ":*") {
+			return false, "sources require a pattern without parameters"
+		}
+		if r.Settings.Converter == nil && len(r.Settings.DataOutputters) == 0 {
+			return false, "sources require a converter or data outputs"
+		}
+		for _, src := range r.Settings.Sources {
+			if !typeRegistered(src.Type, SourcesRegistry) {
+				return false, fmt.Sprintf("unknown source type: %s", src.Type)
+			}
+		}
+	}
 	return true, ""
 }
 
@@ -50,6 +64,31 @@ func typeRegistered(entityType string, registry []EntityInfo) bool {
 	return false
 }
 
+// ChannelRuleToDto returns the rule with the names of the secrets of its sources
+// instead of the encrypted secrets.
+func ChannelRuleToDto(r ChannelRule) ChannelRule {
+	if len(r.Settings.Sources) == 0 {
+		return r
+	}
+	sources := make([]*SourceConfig, 0, len(r.Settings.Sources))
+	for _, s := range r.Settings.Sources {
+		if s == nil {
+			sources = append(sources, nil)
+			continue
+		}
+		source := *s
+		source.SecureSettings = nil
+		source.EncryptedSecureSettings = nil
+		source.SecureFields = make(map[string]bool, len(s.EncryptedSecureSettings))
+		for k := range s.EncryptedSecureSettings {
+			source.SecureFields[k] = true
+		}
+		sources = append(sources, &source)
+	}
+	r.Settings.Sources = sources
+	return r
+}
+
 func WriteConfigToDto(b WriteConfig) WriteConfigDto {
 	secureFields := make(map[string]bool, len(b.SecureSettings))
 	for k := range b.SecureSettings {
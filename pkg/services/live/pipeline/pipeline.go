@@ -391,22 +391,35 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		return nil, err
 	}
 
-	ch, err := live.ParseChannel(channelID)
+	vars, err := channelVars(orgID, channelID)
 	if err != nil {
 		logger.Error("Error parsing channel", "error", err, "channel", channelID)
 		return nil, err
 	}
 
-	vars := Vars{
+	return p.processRuleFrame(ctx, rule, vars, frame, rule.FrameProcessors)
+}
+
+// channelVars returns the variables of a channel which are passed to processors and outputs.
+func channelVars(orgID int64, channelID string) (Vars, error) {
+	ch, err := live.ParseChannel(channelID)
+	if err != nil {
+		return Vars{}, err
+	}
+	return Vars{
 		OrgID:     orgID,
 		Channel:   channelID,
 		Scope:     ch.Scope,
 		Namespace: ch.Namespace,
 		Path:      ch.Path,
-	}
+	}, nil
+}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
+// processRuleFrame applies the processors to the frame and then the frame outputs of the rule.
+func (p *Pipeline) processRuleFrame(ctx context.Context, rule *LiveChannelRule, vars Vars, frame *data.Frame, processors []FrameProcessor) ([]*ChannelFrame, error) {
+	var err error
+	if len(processors) > 0 {
+		for _, proc := range processors {
 			frame, err = p.execProcessor(ctx, proc, vars, frame)
 			if err != nil {
 				logger.Error("Error processing frame", "error", err)
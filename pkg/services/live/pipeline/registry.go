@@ -79,6 +79,38 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate field values over tumbling time windows",
+		Example: AggregateFrameProcessorConfig{
+			Window: "1s",
+			Fields: []AggregateField{{Name: "value", Functions: []AggregateFunction{AggregateFunctionMean, AggregateFunctionMax}}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeDownsample,
+		Description: "pass at most one frame per interval for each channel",
+		Example:     DownsampleFrameProcessorConfig{Interval: "1s"},
+	},
+	{
+		Type:        FrameProcessorTypeCompute,
+		Description: "add fields calculated with math expressions",
+		Example: ComputeFrameProcessorConfig{
+			Fields: []ComputedField{{Name: "power", Expression: "$voltage * $current"}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeFieldConfig,
+		Description: "rename fields and set their unit or display name",
+		Example: FieldConfigFrameProcessorConfig{
+			Fields: []FieldConfigOverride{{FieldName: "temp", Rename: "temperature", Unit: "celsius"}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypePathLabels,
+		Description: "add channel rule pattern params as labels of fields",
+		Example:     PathLabelsFrameProcessorConfig{},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -95,3 +127,30 @@ var DataOutputsRegistry = []EntityInfo{
 		Description: "output data to Loki as logs",
 	},
 }
+
+var SourcesRegistry = []EntityInfo{
+	{
+		Type:        SourceTypeMQTT,
+		Description: "subscribe to MQTT topics",
+		Example: MQTTSourceConfig{
+			Broker: "tcp://localhost:1883",
+			Topics: []string{"factory/+/telemetry"},
+		},
+	},
+	{
+		Type:        SourceTypeNATS,
+		Description: "subscribe to NATS subjects",
+		Example: NATSSourceConfig{
+			URL:      "nats://localhost:4222",
+			Subjects: []string{"factory.*.telemetry"},
+		},
+	},
+	{
+		Type:        SourceTypeKafka,
+		Description: "consume Kafka topics",
+		Example: KafkaSourceConfig{
+			Brokers: []string{"localhost:9092"},
+			Topics:  []string{"telemetry"},
+		},
+	},
+}
@@ -17,6 +17,8 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// ProcessorStates keeps the state of stateful frame processors between rule rebuilds.
+	ProcessorStates *ProcessorStateStorage
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -74,7 +76,7 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 	}
 }
 
-func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig) (FrameProcessor, error) {
+func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig, pattern string) (FrameProcessor, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -97,13 +99,40 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 		var processors []FrameProcessor
 		for _, outConf := range config.MultipleProcessorConfig.Processors {
 			out := outConf
-			proc, err := f.extractFrameProcessor(&out)
+			proc, err := f.extractFrameProcessor(&out, pattern)
 			if err != nil {
 				return nil, err
 			}
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		stateID := processorStateID(pattern, config.Type, config.AggregateProcessorConfig)
+		return NewAggregateFrameProcessor(f.ProcessorStates, stateID, *config.AggregateProcessorConfig)
+	case FrameProcessorTypeDownsample:
+		if config.DownsampleProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		stateID := processorStateID(pattern, config.Type, config.DownsampleProcessorConfig)
+		return NewDownsampleFrameProcessor(f.ProcessorStates, stateID, *config.DownsampleProcessorConfig)
+	case FrameProcessorTypeCompute:
+		if config.ComputeProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewComputeFrameProcessor(*config.ComputeProcessorConfig)
+	case FrameProcessorTypeFieldConfig:
+		if config.FieldConfigProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewFieldConfigFrameProcessor(*config.FieldConfigProcessorConfig), nil
+	case FrameProcessorTypePathLabels:
+		if config.PathLabelsProcessorConfig == nil {
+			config.PathLabelsProcessorConfig = &PathLabelsFrameProcessorConfig{}
+		}
+		return NewPathLabelsFrameProcessor(pattern, *config.PathLabelsProcessorConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -335,7 +364,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 
 		var processors []FrameProcessor
 		for _, procConfig := range ruleConfig.Settings.FrameProcessors {
-			proc, err := f.extractFrameProcessor(procConfig)
+			proc, err := f.extractFrameProcessor(procConfig, rule.Pattern)
 			if err != nil {
 				return nil, fmt.Errorf("error building processor for %s: %w", rule.Pattern, err)
 			}
@@ -376,5 +405,9 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		rules = append(rules, rule)
 	}
 
+	if f.ProcessorStates != nil {
+		f.ProcessorStates.retain(orgID, processorStateIDs(rules))
+	}
+
 	return rules, nil
 }
@@ -0,0 +1,225 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// Source consumes messages from an external system, for example an MQTT broker.
+type Source interface {
+	Type() string
+	// Run consumes messages and passes them to the handler until the context is done or
+	// the connection to the external system fails.
+	Run(ctx context.Context, handle MessageHandler) error
+}
+
+// MessageHandler handles a message consumed by a Source.
+type MessageHandler func(ctx context.Context, body []byte)
+
+// InputProcessor processes input data of a channel, this is implemented by Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// OrgIDsGetter returns the IDs of all organizations.
+type OrgIDsGetter func(ctx context.Context) ([]int64, error)
+
+const (
+	sourceSyncInterval  = 30 * time.Second
+	sourceRetryInterval = 5 * time.Second
+)
+
+// SourceBasicAuthPassword is the secure setting of the basic authentication password of a source.
+const SourceBasicAuthPassword = "basicAuthPassword"
+
+// SourceRunner runs the sources of channel rules and processes the consumed messages as input
+// of the rule channel, just like data pushed over HTTP or WebSocket. Channel rules are reloaded
+// periodically, sources are started, restarted or stopped when their configuration changes.
+type SourceRunner struct {
+	storage        Storage
+	processor      InputProcessor
+	orgIDs         OrgIDsGetter
+	secretsService secrets.Service
+	newSource      func(config SourceConfig, secureSettings map[string]string) (Source, error)
+	retryInterval  time.Duration
+
+	running map[sourceKey]*runningSource
+}
+
+type sourceKey struct {
+	orgID   int64
+	channel string
+	index   int
+}
+
+type runningSource struct {
+	config []byte
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSourceRunner(storage Storage, processor InputProcessor, orgIDs OrgIDsGetter, secretsService secrets.Service) *SourceRunner {
+	return &SourceRunner{
+		storage:        storage,
+		processor:      processor,
+		orgIDs:         orgIDs,
+		secretsService: secretsService,
+		newSource:      newSource,
+		retryInterval:  sourceRetryInterval,
+		running:        map[sourceKey]*runningSource{},
+	}
+}
+
+// Run runs the sources until the context is done.
+func (r *SourceRunner) Run(ctx context.Context) error {
+	defer r.stopAll()
+	ticker := time.NewTicker(sourceSyncInterval)
+	defer ticker.Stop()
+	for {
+		r.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *SourceRunner) sync(ctx context.Context) {
+	orgIDs, err := r.orgIDs(ctx)
+	if err != nil {
+		logger.Error("Error getting organizations of pipeline sources", "error", err)
+		return
+	}
+
+	configs := map[sourceKey][]byte{}
+	failedOrgs := map[int64]struct{}{}
+	for _, orgID := range orgIDs {
+		rules, err := r.storage.ListChannelRules(ctx, orgID)
+		if err != nil {
+			// Keep the sources of the organization running, they are synced on the next attempt.
+			logger.Error("Error listing channel rules of pipeline sources", "error", err, "orgId", orgID)
+			failedOrgs[orgID] = struct{}{}
+			continue
+		}
+		for _, rule := range rules {
+			for i, config := range rule.Settings.Sources {
+				if config == nil {
+					continue
+				}
+				b, err := json.Marshal(config)
+				if err != nil {
+					logger.Error("Error encoding pipeline source", "error", err, "orgId", orgID, "channel", rule.Pattern)
+					continue
+				}
+				configs[sourceKey{orgID: orgID, channel: rule.Pattern, index: i}] = b
+			}
+		}
+	}
+
+	for key, s := range r.running {
+		if _, ok := failedOrgs[key.orgID]; ok {
+			continue
+		}
+		if config, ok := configs[key]; ok && string(config) == string(s.config) {
+			continue
+		}
+		s.stop()
+		delete(r.running, key)
+	}
+
+	for key, b := range configs {
+		if _, ok := r.running[key]; ok {
+			continue
+		}
+		var config SourceConfig
+		if err := json.Unmarshal(b, &config); err != nil {
+			logger.Error("Error decoding pipeline source", "error", err, "orgId", key.orgID, "channel", key.channel)
+			continue
+		}
+		secureSettings, err := r.secretsService.DecryptJsonData(ctx, config.EncryptedSecureSettings)
+		if err != nil {
+			logger.Error("Error decrypting pipeline source secure settings", "error", err, "orgId", key.orgID, "channel", key.channel, "type", config.Type)
+			continue
+		}
+		source, err := r.newSource(config, secureSettings)
+		if err != nil {
+			logger.Error("Error creating pipeline source", "error", err, "orgId", key.orgID, "channel", key.channel, "type", config.Type)
+			continue
+		}
+		r.running[key] = r.start(ctx, key, source, b)
+	}
+}
+
+func (r *SourceRunner) start(ctx context.Context, key sourceKey, source Source, config []byte) *runningSource {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &runningSource{config: config, cancel: cancel, done: make(chan struct{})}
+	handle := func(ctx context.Context, body []byte) {
+		_, err := r.processor.ProcessInput(ctx, key.orgID, key.channel, body)
+		if err != nil {
+			logger.Error("Error processing pipeline source message", "error", err, "orgId", key.orgID, "channel", key.channel, "type", source.Type())
+		}
+	}
+	go func() {
+		defer close(s.done)
+		logger.Info("Starting pipeline source", "orgId", key.orgID, "channel", key.channel, "type", source.Type())
+		for {
+			err := source.Run(ctx, handle)
+			if ctx.Err() != nil {
+				logger.Info("Pipeline source stopped", "orgId", key.orgID, "channel", key.channel, "type", source.Type())
+				return
+			}
+			logger.Error("Pipeline source failed, restarting", "error", err, "orgId", key.orgID, "channel", key.channel, "type", source.Type())
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.retryInterval):
+			}
+		}
+	}()
+	return s
+}
+
+func (r *SourceRunner) stopAll() {
+	for key, s := range r.running {
+		s.stop()
+		delete(r.running, key)
+	}
+}
+
+func (s *runningSource) stop() {
+	s.cancel()
+	<-s.done
+}
+
+func newSource(config SourceConfig, secureSettings map[string]string) (Source, error) {
+	missingConfiguration := fmt.Errorf("missing configuration for %s", config.Type)
+	password := secureSettings[SourceBasicAuthPassword]
+	switch config.Type {
+	case SourceTypeMQTT:
+		if config.MQTTSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		if strings.ContainsAny(config.MQTTSourceConfig.SharedGroup, "/+#") {
+			return nil, fmt.Errorf("invalid shared group: %s", config.MQTTSourceConfig.SharedGroup)
+		}
+		return NewMQTTSource(*config.MQTTSourceConfig, password), nil
+	case SourceTypeNATS:
+		if config.NATSSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewNATSSource(*config.NATSSourceConfig, password), nil
+	case SourceTypeKafka:
+		if config.KafkaSourceConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewKafkaSource(*config.KafkaSourceConfig, password), nil
+	default:
+		return nil, fmt.Errorf("unknown source type: %s", config.Type)
+	}
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+)
+
+// KafkaSource consumes Kafka topics.
+type KafkaSource struct {
+	config   KafkaSourceConfig
+	password string
+}
+
+func NewKafkaSource(config KafkaSourceConfig, password string) *KafkaSource {
+	return &KafkaSource{config: config, password: password}
+}
+
+const SourceTypeKafka = "kafka"
+
+func (s *KafkaSource) Type() string {
+	return SourceTypeKafka
+}
+
+func (s *KafkaSource) Run(ctx context.Context, handle MessageHandler) error {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(s.config.Brokers...),
+		kgo.ConsumeTopics(s.config.Topics...),
+		// Live data is only useful while it's fresh, so start from the end of the partitions
+		// unless the consumer group has committed offsets.
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtEnd()),
+	}
+	if s.config.ConsumerGroup != "" {
+		opts = append(opts, kgo.ConsumerGroup(s.config.ConsumerGroup))
+	}
+	if s.config.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if s.config.BasicAuth != nil {
+		opts = append(opts, kgo.SASL(plain.Auth{
+			User: s.config.BasicAuth.User,
+			Pass: s.password,
+		}.AsMechanism()))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return fmt.Errorf("error creating Kafka client: %w", err)
+	}
+	defer client.Close()
+
+	for {
+		fetches := client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fetches.IsClientClosed() {
+			return errors.New("kafka client closed")
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			logger.Warn("Error fetching Kafka records", "error", err, "topic", topic, "partition", partition)
+		})
+		fetches.EachRecord(func(record *kgo.Record) {
+			handle(ctx, record.Value)
+		})
+	}
+}
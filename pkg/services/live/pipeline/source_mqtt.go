@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MQTTSource subscribes to MQTT topics.
+type MQTTSource struct {
+	config   MQTTSourceConfig
+	password string
+}
+
+func NewMQTTSource(config MQTTSourceConfig, password string) *MQTTSource {
+	return &MQTTSource{config: config, password: password}
+}
+
+const SourceTypeMQTT = "mqtt"
+
+func (s *MQTTSource) Type() string {
+	return SourceTypeMQTT
+}
+
+func (s *MQTTSource) Run(ctx context.Context, handle MessageHandler) error {
+	clientID := s.config.ClientID
+	if clientID == "" {
+		clientID = "grafana-live-" + util.GenerateShortUID()
+	}
+	lost := make(chan error, 1)
+	// Reconnects are handled by the SourceRunner, which also subscribes again.
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.config.Broker).
+		SetClientID(clientID).
+		SetCleanSession(true).
+		SetOrderMatters(false).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			select {
+			case lost <- err:
+			default:
+			}
+		})
+	if s.config.BasicAuth != nil {
+		opts.SetUsername(s.config.BasicAuth.User)
+		opts.SetPassword(s.password)
+	}
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("error connecting to MQTT broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	filters := make(map[string]byte, len(s.config.Topics))
+	for _, topic := range s.config.Topics {
+		filters[mqttTopicFilter(topic, s.config.SharedGroup)] = s.config.QoS
+	}
+	err := waitMQTTToken(ctx, client.SubscribeMultiple(filters, func(_ mqtt.Client, msg mqtt.Message) {
+		handle(ctx, msg.Payload())
+	}))
+	if err != nil {
+		return fmt.Errorf("error subscribing to MQTT topics: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-lost:
+		return fmt.Errorf("connection to MQTT broker lost: %w", err)
+	}
+}
+
+// mqttTopicFilter returns the subscription filter of a topic, the subscription is shared
+// between the clients of the group if a group is set.
+func mqttTopicFilter(topic, sharedGroup string) string {
+	if sharedGroup == "" {
+		return topic
+	}
+	return "$share/" + sharedGroup + "/" + topic
+}
+
+func waitMQTTToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-token.Done():
+		return token.Error()
+	}
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSource subscribes to NATS subjects.
+type NATSSource struct {
+	config   NATSSourceConfig
+	password string
+}
+
+func NewNATSSource(config NATSSourceConfig, password string) *NATSSource {
+	return &NATSSource{config: config, password: password}
+}
+
+const SourceTypeNATS = "nats"
+
+func (s *NATSSource) Type() string {
+	return SourceTypeNATS
+}
+
+func (s *NATSSource) Run(ctx context.Context, handle MessageHandler) error {
+	closed := make(chan struct{})
+	opts := []nats.Option{
+		nats.Name("grafana-live"),
+		nats.MaxReconnects(-1),
+		nats.ClosedHandler(func(_ *nats.Conn) {
+			close(closed)
+		}),
+	}
+	if s.config.BasicAuth != nil {
+		opts = append(opts, nats.UserInfo(s.config.BasicAuth.User, s.password))
+	}
+
+	nc, err := nats.Connect(s.config.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("error connecting to NATS: %w", err)
+	}
+	defer nc.Close()
+
+	cb := func(msg *nats.Msg) {
+		handle(ctx, msg.Data)
+	}
+	for _, subject := range s.config.Subjects {
+		if s.config.Queue != "" {
+			_, err = nc.QueueSubscribe(subject, s.config.Queue, cb)
+		} else {
+			_, err = nc.Subscribe(subject, cb)
+		}
+		if err != nil {
+			return fmt.Errorf("error subscribing to NATS subject %s: %w", subject, err)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		return fmt.Errorf("connection to NATS closed: %v", nc.LastError())
+	}
+}
@@ -0,0 +1,269 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+type testSourceStorage struct {
+	Storage
+	rules []ChannelRule
+	err   error
+}
+
+func (s *testSourceStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return s.rules, s.err
+}
+
+type testInput struct {
+	orgID   int64
+	channel string
+	body    string
+}
+
+type testInputProcessor struct {
+	inputs chan testInput
+}
+
+func (p *testInputProcessor) ProcessInput(_ context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	p.inputs <- testInput{orgID: orgID, channel: channelID, body: string(body)}
+	return true, nil
+}
+
+// testSource sends the topics of its config as messages and records when it's stopped.
+type testSource struct {
+	config  MQTTSourceConfig
+	stopped chan string
+}
+
+func (s *testSource) Type() string {
+	return SourceTypeMQTT
+}
+
+func (s *testSource) Run(ctx context.Context, handle MessageHandler) error {
+	for _, topic := range s.config.Topics {
+		handle(ctx, []byte(topic))
+	}
+	<-ctx.Done()
+	s.stopped <- s.config.Broker
+	return nil
+}
+
+func TestSourceRunner(t *testing.T) {
+	storage := &testSourceStorage{}
+	processor := &testInputProcessor{inputs: make(chan testInput, 10)}
+	stopped := make(chan string, 10)
+	passwords := make(chan string, 10)
+	runner := NewSourceRunner(storage, processor, func(_ context.Context) ([]int64, error) {
+		return []int64{1}, nil
+	}, fakes.NewFakeSecretsService())
+	runner.newSource = func(config SourceConfig, secureSettings map[string]string) (Source, error) {
+		passwords <- secureSettings[SourceBasicAuthPassword]
+		return &testSource{config: *config.MQTTSourceConfig, stopped: stopped}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mqttRule := func(broker string) ChannelRule {
+		return ChannelRule{
+			Pattern: "stream/factory/telemetry",
+			Settings: ChannelRuleSettings{
+				Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+				Sources: []*SourceConfig{{
+					Type:                    SourceTypeMQTT,
+					MQTTSourceConfig:        &MQTTSourceConfig{Broker: broker, Topics: []string{"message"}},
+					EncryptedSecureSettings: map[string][]byte{SourceBasicAuthPassword: []byte("secret")},
+				}},
+			},
+		}
+	}
+
+	t.Run("should start sources and process their messages as channel input", func(t *testing.T) {
+		storage.rules = []ChannelRule{mqttRule("tcp://first:1883")}
+		runner.sync(ctx)
+		require.Len(t, runner.running, 1)
+		require.Equal(t, testInput{orgID: 1, channel: "stream/factory/telemetry", body: "message"}, receive(t, processor.inputs))
+		require.Equal(t, "secret", receive(t, passwords))
+	})
+
+	t.Run("should keep sources running if the rules can't be listed", func(t *testing.T) {
+		storage.err = errors.New("failed")
+		runner.sync(ctx)
+		storage.err = nil
+		require.Len(t, runner.running, 1)
+		require.Empty(t, stopped)
+	})
+
+	t.Run("should not restart sources if their config didn't change", func(t *testing.T) {
+		runner.sync(ctx)
+		require.Len(t, runner.running, 1)
+		require.Empty(t, stopped)
+		require.Empty(t, processor.inputs)
+	})
+
+	t.Run("should restart sources if their config changed", func(t *testing.T) {
+		storage.rules = []ChannelRule{mqttRule("tcp://second:1883")}
+		runner.sync(ctx)
+		require.Len(t, runner.running, 1)
+		require.Equal(t, "tcp://first:1883", receive(t, stopped))
+		require.Equal(t, "message", receive(t, processor.inputs).body)
+	})
+
+	t.Run("should stop sources of removed rules", func(t *testing.T) {
+		storage.rules = nil
+		runner.sync(ctx)
+		require.Empty(t, runner.running)
+		require.Equal(t, "tcp://second:1883", receive(t, stopped))
+	})
+}
+
+func TestFileStorageSourceSecureSettings(t *testing.T) {
+	ctx := context.Background()
+	storage := &FileStorage{DataPath: t.TempDir(), SecretsService: fakes.NewFakeSecretsService()}
+	settings := func(source *SourceConfig) ChannelRuleSettings {
+		return ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+			Sources:   []*SourceConfig{source},
+		}
+	}
+	source := func(t *testing.T) *SourceConfig {
+		t.Helper()
+		rules, err := storage.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Len(t, rules[0].Settings.Sources, 1)
+		return rules[0].Settings.Sources[0]
+	}
+	mqttConfig := &MQTTSourceConfig{
+		Broker:    "tcp://localhost:1883",
+		Topics:    []string{"telemetry"},
+		BasicAuth: &SourceBasicAuth{User: "grafana"},
+	}
+
+	t.Run("should encrypt secure settings of sources", func(t *testing.T) {
+		rule, err := storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern: "stream/factory/telemetry",
+			Settings: settings(&SourceConfig{
+				Type:             SourceTypeMQTT,
+				MQTTSourceConfig: mqttConfig,
+				SecureSettings:   map[string]string{SourceBasicAuthPassword: "secret"},
+			}),
+		})
+		require.NoError(t, err)
+
+		s := source(t)
+		require.Nil(t, s.SecureSettings)
+		require.Equal(t, map[string][]byte{SourceBasicAuthPassword: []byte("secret")}, s.EncryptedSecureSettings)
+
+		dto := ChannelRuleToDto(rule).Settings.Sources[0]
+		require.Nil(t, dto.SecureSettings)
+		require.Nil(t, dto.EncryptedSecureSettings)
+		require.Equal(t, map[string]bool{SourceBasicAuthPassword: true}, dto.SecureFields)
+	})
+
+	t.Run("should keep secure settings marked in secure fields", func(t *testing.T) {
+		_, err := storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern: "stream/factory/telemetry",
+			Settings: settings(&SourceConfig{
+				Type:             SourceTypeMQTT,
+				MQTTSourceConfig: mqttConfig,
+				SecureFields:     map[string]bool{SourceBasicAuthPassword: true},
+			}),
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{SourceBasicAuthPassword: []byte("secret")}, source(t).EncryptedSecureSettings)
+	})
+
+	t.Run("should remove secure settings not marked in secure fields", func(t *testing.T) {
+		_, err := storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern: "stream/factory/telemetry",
+			Settings: settings(&SourceConfig{
+				Type:             SourceTypeMQTT,
+				MQTTSourceConfig: mqttConfig,
+			}),
+		})
+		require.NoError(t, err)
+		require.Nil(t, source(t).EncryptedSecureSettings)
+	})
+}
+
+func TestNewSource(t *testing.T) {
+	t.Run("should subscribe to shared MQTT topics if a shared group is set", func(t *testing.T) {
+		require.Equal(t, "factory/+/telemetry", mqttTopicFilter("factory/+/telemetry", ""))
+		require.Equal(t, "$share/grafana/factory/+/telemetry", mqttTopicFilter("factory/+/telemetry", "grafana"))
+	})
+
+	t.Run("should reject invalid MQTT shared groups", func(t *testing.T) {
+		_, err := newSource(SourceConfig{
+			Type:             SourceTypeMQTT,
+			MQTTSourceConfig: &MQTTSourceConfig{Broker: "tcp://localhost:1883", SharedGroup: "grafana/live"},
+		}, nil)
+		require.ErrorContains(t, err, "invalid shared group")
+	})
+
+	t.Run("should pass the basic authentication password to the source", func(t *testing.T) {
+		s, err := newSource(SourceConfig{
+			Type:             SourceTypeNATS,
+			NATSSourceConfig: &NATSSourceConfig{URL: "nats://localhost:4222", BasicAuth: &SourceBasicAuth{User: "grafana"}},
+		}, map[string]string{SourceBasicAuthPassword: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, "secret", s.(*NATSSource).password)
+	})
+}
+
+func TestChannelRuleValidSources(t *testing.T) {
+	source := &SourceConfig{Type: SourceTypeNATS, NATSSourceConfig: &NATSSourceConfig{URL: "nats://localhost:4222"}}
+	converter := &ConverterConfig{Type: ConverterTypeJsonAuto}
+
+	testCases := []struct {
+		name   string
+		rule   ChannelRule
+		reason string
+	}{
+		{
+			name: "valid",
+			rule: ChannelRule{Pattern: "stream/factory/telemetry", Settings: ChannelRuleSettings{Converter: converter, Sources: []*SourceConfig{source}}},
+		},
+		{
+			name:   "pattern with parameters",
+			rule:   ChannelRule{Pattern: "stream/factory/:metric", Settings: ChannelRuleSettings{Converter: converter, Sources: []*SourceConfig{source}}},
+			reason: "sources require a pattern without parameters",
+		},
+		{
+			name:   "without converter",
+			rule:   ChannelRule{Pattern: "stream/factory/telemetry", Settings: ChannelRuleSettings{Sources: []*SourceConfig{source}}},
+			reason: "sources require a converter or data outputs",
+		},
+		{
+			name:   "unknown type",
+			rule:   ChannelRule{Pattern: "stream/factory/telemetry", Settings: ChannelRuleSettings{Converter: converter, Sources: []*SourceConfig{{Type: "amqp"}}}},
+			reason: "unknown source type: amqp",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, reason := tc.rule.Valid()
+			require.Equal(t, tc.reason == "", ok)
+			require.Equal(t, tc.reason, reason)
+		})
+	}
+}
+
+func receive[T any](t *testing.T, ch chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for value")
+	}
+	var v T
+	return v
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -158,12 +159,17 @@ func (f *FileStorage) ListChannelRules(_ context.Context, orgID int64) ([]Channe
 	return rules, nil
 }
 
-func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+func (f *FileStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
 	channelRules, err := f.readRules()
 	if err != nil {
 		return ChannelRule{}, fmt.Errorf("can't read channel rules: %w", err)
 	}
 
+	cmd.Settings.Sources, err = f.encryptSources(ctx, cmd.Settings.Sources, nil)
+	if err != nil {
+		return ChannelRule{}, err
+	}
+
 	rule := ChannelRule{
 		OrgId:    orgID,
 		Pattern:  cmd.Pattern,
@@ -218,6 +224,10 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 		}
 	}
 	if index > -1 {
+		rule.Settings.Sources, err = f.encryptSources(ctx, rule.Settings.Sources, channelRules.Rules[index].Settings.Sources)
+		if err != nil {
+			return ChannelRule{}, err
+		}
 		channelRules.Rules[index] = rule
 	} else {
 		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd(cmd))
@@ -227,6 +237,42 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 	return rule, err
 }
 
+// encryptSources returns copies of the sources with encrypted secure settings. Secrets of the
+// existing source at the same position which are marked in the secure fields of the source are
+// kept, so that a rule returned by the API can be saved again without its secrets.
+func (f *FileStorage) encryptSources(ctx context.Context, sources []*SourceConfig, existing []*SourceConfig) ([]*SourceConfig, error) {
+	if len(sources) == 0 {
+		return sources, nil
+	}
+	result := make([]*SourceConfig, 0, len(sources))
+	for i, s := range sources {
+		if s == nil {
+			result = append(result, nil)
+			continue
+		}
+		encrypted, err := f.SecretsService.EncryptJsonData(ctx, s.SecureSettings, secrets.WithoutScope())
+		if err != nil {
+			return nil, fmt.Errorf("error encrypting data: %w", err)
+		}
+		if i < len(existing) && existing[i] != nil && existing[i].Type == s.Type {
+			for k, v := range existing[i].EncryptedSecureSettings {
+				if _, ok := encrypted[k]; !ok && s.SecureFields[k] {
+					encrypted[k] = v
+				}
+			}
+		}
+		source := *s
+		source.SecureSettings = nil
+		source.SecureFields = nil
+		source.EncryptedSecureSettings = nil
+		if len(encrypted) > 0 {
+			source.EncryptedSecureSettings = encrypted
+		}
+		result = append(result, &source)
+	}
+	return result, nil
+}
+
 func removeChannelRuleByIndex(s []ChannelRule, index int) []ChannelRule {
 	return append(s[:index], s[index+1:]...)
 }
@@ -240,6 +286,9 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	ruleBytes, err := os.ReadFile(ruleFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ChannelRules{}, nil
+	}
 	if err != nil {
 		return ChannelRules{}, fmt.Errorf("can't read pipeline rules: %s: %w", f.ruleFilePath(), err)
 	}
@@ -257,6 +306,9 @@ func (f *FileStorage) saveChannelRules(orgID int64, rules ChannelRules) error {
 		return errors.New(reason)
 	}
 	ruleFile := f.ruleFilePath()
+	if err := os.MkdirAll(filepath.Dir(ruleFile), 0750); err != nil {
+		return fmt.Errorf("can't create pipeline directory: %w", err)
+	}
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	file, err := os.OpenFile(ruleFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
@@ -309,6 +361,9 @@ func (f *FileStorage) readWriteConfigs() (WriteConfigs, error) {
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	bytes, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return WriteConfigs{}, nil
+	}
 	if err != nil {
 		return WriteConfigs{}, fmt.Errorf("can't read %s file: %w", filePath, err)
 	}
@@ -322,6 +377,9 @@ func (f *FileStorage) readWriteConfigs() (WriteConfigs, error) {
 
 func (f *FileStorage) saveWriteConfigs(_ int64, writeConfigs WriteConfigs) error {
 	filePath := f.writeConfigsFilePath()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+		return fmt.Errorf("can't create pipeline directory: %w", err)
+	}
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
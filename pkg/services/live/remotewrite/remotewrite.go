@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,6 +27,22 @@ func SerializeLabelsColumn(frames ...*data.Frame) ([]byte, error) {
 
 // TimeSeriesFromFrames converts frames to slice of Prometheus TimeSeries.
 func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(makeMetricName, frames...)
+}
+
+// TimeSeriesFromFramesWithName converts frames to slice of Prometheus TimeSeries
+// using the provided metric name for all numeric fields. Labels of each series are sorted by name.
+func TimeSeriesFromFramesWithName(name string, frames ...*data.Frame) []prompb.TimeSeries {
+	ts := timeSeriesFromFrames(func(*data.Frame, *data.Field) string { return name }, frames...)
+	for _, s := range ts {
+		sort.Slice(s.Labels, func(i, j int) bool {
+			return s.Labels[i].Name < s.Labels[j].Name
+		})
+	}
+	return ts
+}
+
+func timeSeriesFromFrames(metricNameFunc func(*data.Frame, *data.Field) string, frames ...*data.Frame) []prompb.TimeSeries {
 	var entries = make(map[metricKey]prompb.TimeSeries)
 	var keys []metricKey // sorted keys.
 
@@ -39,7 +56,7 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 			if !field.Type().Numeric() {
 				continue
 			}
-			metricName := makeMetricName(frame, field)
+			metricName := metricNameFunc(frame, field)
 			metricName, ok := sanitizeMetricName(metricName)
 			if !ok {
 				continue
@@ -78,8 +95,10 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 				Value: metricName,
 			})
 			promTimeSeries := prompb.TimeSeries{Labels: labelsCopy, Samples: samples}
+			if _, ok := entries[key]; !ok {
+				keys = append(keys, key)
+			}
 			entries[key] = promTimeSeries
-			keys = append(keys, key)
 		}
 	}
 
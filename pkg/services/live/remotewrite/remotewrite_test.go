@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,6 +28,24 @@ func TestTsFromFrames(t *testing.T) {
 	require.Equal(t, "test_value", ts[0].Labels[1].Value)
 }
 
+func TestTsFromFramesWithName(t *testing.T) {
+	t1 := time.Now()
+	frame1 := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"test": "yes", "A": "a"}, []float64{1.0}),
+	)
+	frame2 := data.NewFrame("other",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("other", map[string]string{"test": "no"}, []float64{2.0}),
+	)
+	ts := TimeSeriesFromFramesWithName("recorded", frame1, frame2)
+	require.Len(t, ts, 2)
+	require.Equal(t, []prompb.Label{{Name: "A", Value: "a"}, {Name: "__name__", Value: "recorded"}, {Name: "test", Value: "yes"}}, ts[0].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(t1), Value: 1.0}}, ts[0].Samples)
+	require.Equal(t, []prompb.Label{{Name: "__name__", Value: "recorded"}, {Name: "test", Value: "no"}}, ts[1].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(t1), Value: 2.0}}, ts[1].Samples)
+}
+
 func TestTsFromFramesMultipleSeries(t *testing.T) {
 	t1 := time.Now()
 	t2 := time.Now().Add(time.Second)
@@ -47,6 +47,11 @@ type RuleAccessControlService interface {
 	AuthorizeDatasourceAccessForRule(ctx context.Context, user identity.Requester, rule *models.AlertRule) error
 }
 
+// EvaluationStatsReader returns the statistics of the recent evaluations of alert rules.
+type EvaluationStatsReader interface {
+	GetEvaluationStats(key models.AlertRuleKey) (models.RuleEvaluationStats, bool)
+}
+
 // API handlers.
 type API struct {
 	Cfg                  *setting.Cfg
@@ -73,6 +78,7 @@ type API struct {
 	EvaluatorFactory     eval.EvaluatorFactory
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
+	EvaluationStats      EvaluationStatsReader
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 	UpgradeService       migration.UpgradeService
@@ -94,13 +100,21 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager},
+		&AlertmanagerSrv{
+			crypto:    api.MultiOrgAlertmanager.Crypto,
+			log:       logger,
+			ac:        api.AccessControl,
+			mam:       api.MultiOrgAlertmanager,
+			ruleStore: api.RuleStore,
+			authz:     ruleAuthzService,
+			cfg:       &api.Cfg.UnifiedAlerting,
+		},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkingProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService},
+		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService, evaluationStats: api.EvaluationStats},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkingRuler(
@@ -118,6 +132,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amConfigStore:      api.AlertingStore,
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
+			datasourceCache:    api.DatasourceCache,
+			evaluationStats:    api.EvaluationStats,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -128,16 +144,23 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			authz:           ruleAuthzService,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer),
+			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer, api.FeatureManager),
+			backtestJobs:    backtesting.NewJobs(),
 			featureManager:  api.FeatureManager,
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
 			folderService:   api.RuleStore,
+			ruleStore:       api.RuleStore,
+			amConfigStore:   api.AlertingStore,
+			alertmanagers:   api.MultiOrgAlertmanager,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
 			datasourceService:    api.DatasourceService,
 			store:                api.AdminConfigStore,
+			ruleStore:            api.RuleStore,
+			instanceArchiver:     api.StateManager,
+			amStateArchiver:      api.MultiOrgAlertmanager,
 			log:                  logger,
 			alertmanagerProvider: api.AlertsRouter,
 		},
@@ -150,6 +173,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		featureManager:      api.FeatureManager,
 	}), m)
 
 	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-openapi/strfmt"
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -18,9 +19,12 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -30,10 +34,13 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	log    log.Logger
-	ac     accesscontrol.AccessControl
-	mam    *notifier.MultiOrgAlertmanager
-	crypto notifier.Crypto
+	log       log.Logger
+	ac        accesscontrol.AccessControl
+	mam       *notifier.MultiOrgAlertmanager
+	crypto    notifier.Crypto
+	ruleStore RuleStore
+	authz     RuleAccessControlService
+	cfg       *setting.UnifiedAlertingSettings
 }
 
 type UnknownReceiverError struct {
@@ -361,6 +368,89 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestRoutes(c *contextmodel.ReqContext, body apimodels.TestRoutesConfigBodyParams) response.Response {
+	if len(body.Labels) == 0 && body.RuleUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("either labels or rule_uid must be specified"), "")
+	}
+
+	lset := make(model.LabelSet, len(body.Labels))
+	if body.RuleUID != "" {
+		ruleLabels, errResp := srv.getRuleLabels(c, body.RuleUID)
+		if errResp != nil {
+			return errResp
+		}
+		for k, v := range ruleLabels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+	}
+	// Labels of the request take precedence over the labels of the rule.
+	for k, v := range body.Labels {
+		lset[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if err := lset.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid labels")
+	}
+
+	now := body.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	res, err := srv.mam.SimulateRouting(c.Req.Context(), c.SignedInUser.GetOrgID(), lset, now)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to test routes")
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
+// getRuleLabels returns the labels of the alert rule together with the labels that Grafana adds to its alerts.
+// Templates in the labels are not expanded.
+func (srv AlertmanagerSrv) getRuleLabels(c *contextmodel.ReqContext, ruleUID string) (map[string]string, response.Response) {
+	ctx := c.Req.Context()
+	rules, err := srv.ruleStore.GetAlertRulesGroupByRuleUID(ctx, &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range rules {
+		if r.UID == ruleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(ctx, c.SignedInUser, rules); err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule group", err)
+	}
+
+	folder, err := srv.ruleStore.GetNamespaceByUID(ctx, rule.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return nil, toNamespaceErrorResponse(err)
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+	result := make(map[string]string, len(rule.Labels)+4)
+	for k, v := range rule.Labels {
+		result[k] = v
+	}
+	for k, v := range state.GetRuleExtraLabels(srv.log, rule, folder.Title, includeFolder) {
+		result[k] = v
+	}
+	return result, nil
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
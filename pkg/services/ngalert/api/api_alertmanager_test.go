@@ -577,6 +577,86 @@ func TestRoutePostTestTemplates(t *testing.T) {
 	})
 }
 
+func TestRoutePostTestRoutes(t *testing.T) {
+	sut := createSut(t)
+
+	t.Run("assert 400 when neither labels nor rule is specified", func(tt *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{})
+		require.Equal(tt, 400, response.Status())
+	})
+
+	t.Run("assert 400 when labels are invalid", func(tt *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"invalid-name": "value"},
+		})
+		require.Equal(tt, 400, response.Status())
+	})
+
+	t.Run("assert 404 when no alertmanager found", func(tt *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(10), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"alertname": "test"},
+		})
+		require.Equal(tt, 404, response.Status())
+	})
+
+	t.Run("assert 409 when alertmanager not ready", func(tt *testing.T) {
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(3), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"alertname": "test"},
+		})
+		require.Equal(tt, 409, response.Status())
+	})
+
+	t.Run("assert 200 and matched route for labels", func(tt *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+			Labels: map[string]string{"alertname": "test"},
+			Time:   now,
+		})
+		require.Equal(tt, 200, response.Status())
+
+		var result apimodels.TestRoutesResult
+		require.NoError(tt, json.Unmarshal(response.Body(), &result))
+		require.Equal(tt, map[string]string{"alertname": "test"}, result.Labels)
+		require.True(tt, now.Equal(result.Time))
+		require.Len(tt, result.Routes, 1)
+		require.Equal(tt, "grafana-default-email", result.Routes[0].Receiver)
+	})
+
+	t.Run("rule UID", func(tt *testing.T) {
+		ruleStore := ngfakes.NewRuleStore(tt)
+		rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule.Labels = map[string]string{"team": "a"}
+		ruleStore.PutRule(context.Background(), rule)
+
+		sut := sut
+		sut.ruleStore = ruleStore
+		sut.authz = fakeRuleAccessControlService{}
+		sut.cfg = &setting.UnifiedAlertingSettings{}
+
+		tt.Run("assert 404 when rule does not exist", func(tt *testing.T) {
+			response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+				RuleUID: "unknown",
+			})
+			require.Equal(tt, 404, response.Status())
+		})
+
+		tt.Run("assert labels of the rule are routed", func(tt *testing.T) {
+			response := sut.RoutePostTestRoutes(createRequestCtxInOrg(1), apimodels.TestRoutesConfigBodyParams{
+				RuleUID: rule.UID,
+				Labels:  map[string]string{"severity": "critical"},
+			})
+			require.Equal(tt, 200, response.Status())
+
+			var result apimodels.TestRoutesResult
+			require.NoError(tt, json.Unmarshal(response.Body(), &result))
+			require.Equal(tt, "a", result.Labels["team"])
+			require.Equal(tt, "critical", result.Labels["severity"])
+			require.Equal(tt, rule.Title, result.Labels["alertname"])
+			require.Contains(tt, result.Labels, ngmodels.FolderTitleLabel)
+		})
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
@@ -23,6 +23,9 @@ type ConfigSrv struct {
 	datasourceService    datasources.DataSourceService
 	alertmanagerProvider ExternalAlertmanagerProvider
 	store                store.AdminConfigurationStore
+	ruleStore            RuleStore
+	instanceArchiver     AlertInstanceArchiver
+	amStateArchiver      AlertmanagerStateArchiver
 	log                  log.Logger
 }
 
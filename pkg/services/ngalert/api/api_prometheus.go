@@ -29,9 +29,14 @@ type PrometheusSrv struct {
 	manager state.AlertInstanceManager
 	store   RuleStore
 	authz   RuleAccessControlService
+
+	evaluationStats EvaluationStatsReader
 }
 
-const queryIncludeInternalLabels = "includeInternalLabels"
+const (
+	queryIncludeInternalLabels  = "includeInternalLabels"
+	queryIncludeEvaluationStats = "includeEvaluationStats"
+)
 
 func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) response.Response {
 	alertResponse := apimodels.AlertResponse{
@@ -56,7 +61,7 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 			valString = formatValues(alertState)
 		}
 
-		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, &apimodels.Alert{
+		alert := &apimodels.Alert{
 			Labels:      alertState.GetLabels(labelOptions...),
 			Annotations: alertState.Annotations,
 
@@ -65,7 +70,12 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
 			ActiveAt: &startsAt,
 			Value:    valString,
-		})
+		}
+		if !alertState.KeepFiringSince.IsZero() {
+			keepFiringSince := alertState.KeepFiringSince
+			alert.KeepFiringSince = &keepFiringSince
+		}
+		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, alert)
 	}
 
 	return response.JSON(http.StatusOK, alertResponse)
@@ -183,6 +193,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 	if !c.QueryBoolWithDefault(queryIncludeInternalLabels, false) {
 		labelOptions = append(labelOptions, ngmodels.WithoutInternalLabels())
 	}
+	withEvaluationStats := c.QueryBoolWithDefault(queryIncludeEvaluationStats, false)
 
 	namespaceMap, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.SignedInUser.GetOrgID(), c.SignedInUser)
 	if err != nil {
@@ -242,7 +253,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		if !ok {
 			continue
 		}
-		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions, withEvaluationStats)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -300,7 +311,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption, withEvaluationStats bool) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -312,11 +323,12 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(srv.log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: rule.Annotations,
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(srv.log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   rule.Annotations,
 		}
 
 		newRule := apimodels.Rule{
@@ -326,6 +338,12 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.IsRecordingRule() {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+		if withEvaluationStats {
+			newRule.EvaluationStats = getEvaluationStats(srv.evaluationStats, rule.GetKey())
+		}
 
 		states := srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -352,6 +370,10 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 				ActiveAt: &activeAt,
 				Value:    valString,
 			}
+			if !alertState.KeepFiringSince.IsZero() {
+				keepFiringSince := alertState.KeepFiringSince
+				alert.KeepFiringSince = &keepFiringSince
+			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
 				newRule.LastEvaluation = alertState.LastEvaluationTime
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -491,6 +492,46 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 `, folder.Title), string(r.Body()))
 	})
 
+	t.Run("with the inclusion of evaluation stats", func(t *testing.T) {
+		fakeStore, fakeAIM, api := setupAPI(t)
+		generateRuleAndInstanceWithQuery(t, orgID, fakeAIM, fakeStore, withClassicConditionSingleQuery())
+		evaluatedAt := time.Date(2022, 3, 10, 14, 1, 0, 0, time.UTC)
+		api.evaluationStats = fakeEvaluationStatsReader{
+			ngmodels.AlertRuleKey{OrgID: orgID, UID: "RuleUID"}: {
+				Evaluations: []ngmodels.EvaluationRecord{
+					{EvaluatedAt: evaluatedAt, Duration: 2 * time.Second, DatasourceDuration: time.Second, ExpressionDuration: 500 * time.Millisecond, Series: 3},
+				},
+				Total:  10,
+				Errors: 1,
+			},
+		}
+
+		getRule := func(t *testing.T, url string) apimodels.AlertingRule {
+			req, err := http.NewRequest("GET", url, nil)
+			require.NoError(t, err)
+			c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID, Permissions: queryPermissions}}
+
+			r := api.RouteGetRuleStatuses(c)
+			require.Equal(t, http.StatusOK, r.Status())
+			result := &apimodels.RuleResponse{}
+			require.NoError(t, json.Unmarshal(r.Body(), result))
+			require.Len(t, result.Data.RuleGroups, 1)
+			require.Len(t, result.Data.RuleGroups[0].Rules, 1)
+			return result.Data.RuleGroups[0].Rules[0]
+		}
+
+		require.Nil(t, getRule(t, "/api/v1/rules").EvaluationStats)
+		require.Equal(t, &apimodels.RuleEvaluationStats{
+			TotalEvaluations: 10,
+			TotalErrors:      1,
+			DurationP50:      2,
+			DurationP95:      2,
+			Evaluations: []apimodels.RuleEvaluation{
+				{Timestamp: evaluatedAt, Duration: 2, DatasourceDuration: 1, ExpressionDuration: 0.5, Series: 3},
+			},
+		}, getRule(t, "/api/v1/rules?includeEvaluationStats=true").EvaluationStats)
+	})
+
 	t.Run("with many rules in a group", func(t *testing.T) {
 		t.Run("should return sorted", func(t *testing.T) {
 			ruleStore := fakes.NewRuleStore(t)
@@ -534,6 +575,28 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 		})
 	})
 
+	t.Run("with a recording rule", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		fakeAIM := NewFakeAlertInstanceManager(t)
+		rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(orgID), ngmodels.WithRecord("test_metric"))()
+		ruleStore.PutRule(context.Background(), rule)
+
+		api := PrometheusSrv{
+			log:     log.NewNopLogger(),
+			manager: fakeAIM,
+			store:   ruleStore,
+			authz:   &fakeRuleAccessControlService{},
+		}
+
+		response := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, response.Status())
+		result := &apimodels.RuleResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), result))
+		require.Len(t, result.Data.RuleGroups, 1)
+		require.Len(t, result.Data.RuleGroups[0].Rules, 1)
+		require.Equal(t, apiv1.RuleTypeRecording, result.Data.RuleGroups[0].Rules[0].Type)
+	})
+
 	t.Run("when fine-grained access is enabled", func(t *testing.T) {
 		t.Run("should return only rules if the user can query all data sources", func(t *testing.T) {
 			ruleStore := fakes.NewRuleStore(t)
@@ -1368,3 +1431,10 @@ func withExpressionsMultiQuery() func(r *ngmodels.AlertRule) {
 		r.Data = queries
 	}
 }
+
+type fakeEvaluationStatsReader map[ngmodels.AlertRuleKey]ngmodels.RuleEvaluationStats
+
+func (f fakeEvaluationStatsReader) GetEvaluationStats(key ngmodels.AlertRuleKey) (ngmodels.RuleEvaluationStats, bool) {
+	stats, ok := f[key]
+	return stats, ok
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -28,6 +29,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	featureManager      featuremgmt.FeatureToggles
 }
 
 type ContactPointService interface {
@@ -327,11 +329,11 @@ func (srv *ProvisioningSrv) RouteRouteGetAlertRule(c *contextmodel.ReqContext, U
 }
 
 func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar definitions.ProvisionedAlertRule) response.Response {
-	upstreamModel, err := AlertRuleFromProvisionedAlertRule(ar)
-	upstreamModel.OrgID = c.SignedInUser.GetOrgID()
+	upstreamModel, err := AlertRuleFromProvisionedAlertRule(ar, srv.featureManager)
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
+	upstreamModel.OrgID = c.SignedInUser.GetOrgID()
 	provenance := determineProvenance(c)
 	userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
 	createdAlertRule, err := srv.alertRules.CreateAlertRule(c.Req.Context(), upstreamModel, alerting_models.Provenance(provenance), userID)
@@ -356,9 +358,9 @@ func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar de
 }
 
 func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar definitions.ProvisionedAlertRule, UID string) response.Response {
-	updated, err := AlertRuleFromProvisionedAlertRule(ar)
+	updated, err := AlertRuleFromProvisionedAlertRule(ar, srv.featureManager)
 	if err != nil {
-		ErrResp(http.StatusBadRequest, err, "")
+		return ErrResp(http.StatusBadRequest, err, "")
 	}
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
@@ -482,9 +484,9 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleExport(c *contextmodel.ReqContext,
 func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, ag definitions.AlertRuleGroup, folderUID string, group string) response.Response {
 	ag.FolderUID = folderUID
 	ag.Title = group
-	groupModel, err := AlertRuleGroupFromApiAlertRuleGroup(ag)
+	groupModel, err := AlertRuleGroupFromApiAlertRuleGroup(ag, srv.featureManager)
 	if err != nil {
-		ErrResp(http.StatusBadRequest, err, "")
+		return ErrResp(http.StatusBadRequest, err, "")
 	}
 	provenance := determineProvenance(c)
 
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func (srv AlertmanagerSrv) RouteGetSilencePresets(c *contextmodel.ReqContext) response.Response {
+	presets, err := srv.mam.GetSilencePresets(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, presets)
+}
+
+func (srv AlertmanagerSrv) RoutePostSilencePreset(c *contextmodel.ReqContext, preset apimodels.SilencePreset) response.Response {
+	created, err := srv.mam.CreateSilencePreset(c.Req.Context(), c.SignedInUser.GetOrgID(), preset)
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv AlertmanagerSrv) RoutePutSilencePreset(c *contextmodel.ReqContext, preset apimodels.SilencePreset, uid string) response.Response {
+	preset.UID = uid
+	updated, err := srv.mam.UpdateSilencePreset(c.Req.Context(), c.SignedInUser.GetOrgID(), preset)
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (srv AlertmanagerSrv) RouteDeleteSilencePreset(c *contextmodel.ReqContext, uid string) response.Response {
+	if err := srv.mam.DeleteSilencePreset(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
+func (srv AlertmanagerSrv) RouteGetRecurringSilences(c *contextmodel.ReqContext) response.Response {
+	silences, err := srv.mam.GetRecurringSilences(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, silences)
+}
+
+func (srv AlertmanagerSrv) RoutePostRecurringSilence(c *contextmodel.ReqContext, silence apimodels.RecurringSilence) response.Response {
+	if silence.CreatedBy == "" {
+		silence.CreatedBy = c.SignedInUser.GetLogin()
+	}
+	created, err := srv.mam.CreateRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), silence)
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (srv AlertmanagerSrv) RoutePutRecurringSilence(c *contextmodel.ReqContext, silence apimodels.RecurringSilence, uid string) response.Response {
+	silence.UID = uid
+	if silence.CreatedBy == "" {
+		silence.CreatedBy = c.SignedInUser.GetLogin()
+	}
+	updated, err := srv.mam.UpdateRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), silence)
+	if err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (srv AlertmanagerSrv) RouteDeleteRecurringSilence(c *contextmodel.ReqContext, uid string) response.Response {
+	if err := srv.mam.DeleteRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return silenceScheduleErrorResponse(err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
+func silenceScheduleErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, notifier.ErrSilencePresetNotFound), errors.Is(err, notifier.ErrRecurringSilenceNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, notifier.ErrInvalidSilencePreset), errors.Is(err, notifier.ErrInvalidRecurringSilence):
+		return ErrResp(http.StatusBadRequest, err, "")
+	case errors.Is(err, notifier.ErrSilencePresetInUse):
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
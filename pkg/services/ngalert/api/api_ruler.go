@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -54,11 +55,15 @@ type RulerSrv struct {
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
 
-	amConfigStore  AMConfigStore
-	amRefresher    AMRefresher
-	featureManager featuremgmt.FeatureToggles
+	amConfigStore   AMConfigStore
+	amRefresher     AMRefresher
+	featureManager  featuremgmt.FeatureToggles
+	datasourceCache datasources.CacheService
+	evaluationStats EvaluationStatsReader
 }
 
+const queryIncludeRuleEvaluationStats = "include_evaluation_stats"
+
 var (
 	errProvisionedResource = errors.New("request affects resources created via provisioning API")
 )
@@ -182,6 +187,11 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *contextmodel.ReqContext, nam
 	for groupKey, rules := range ruleGroups {
 		result[namespace.Fullpath] = append(result[namespace.Fullpath], toGettableRuleGroupConfig(groupKey.RuleGroup, rules, provenanceRecords))
 	}
+	if c.QueryBoolWithDefault(queryIncludeRuleEvaluationStats, false) {
+		for _, groups := range result {
+			setEvaluationStats(srv.evaluationStats, groups)
+		}
+	}
 
 	return response.JSON(http.StatusAccepted, result)
 }
@@ -212,6 +222,10 @@ func (srv RulerSrv) RouteGetRulesGroupConfig(c *contextmodel.ReqContext, namespa
 		// nolint:staticcheck
 		GettableRuleGroupConfig: toGettableRuleGroupConfig(ruleGroup, rules, provenanceRecords),
 	}
+	if c.QueryBoolWithDefault(queryIncludeRuleEvaluationStats, false) {
+		// nolint:staticcheck
+		setEvaluationStats(srv.evaluationStats, []apimodels.GettableRuleGroupConfig{result.GettableRuleGroupConfig})
+	}
 	return response.JSON(http.StatusAccepted, result)
 }
 
@@ -260,6 +274,11 @@ func (srv RulerSrv) RouteGetRulesConfig(c *contextmodel.ReqContext) response.Res
 		}
 		result[folder.Fullpath] = append(result[folder.Fullpath], toGettableRuleGroupConfig(groupKey.RuleGroup, rules, provenanceRecords))
 	}
+	if c.QueryBoolWithDefault(queryIncludeRuleEvaluationStats, false) {
+		for _, groups := range result {
+			setEvaluationStats(srv.evaluationStats, groups)
+		}
+	}
 	return response.JSON(http.StatusOK, result)
 }
 
@@ -278,6 +297,14 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagGrafanaManagedRecordingRules) {
+		for _, r := range rules {
+			if r.IsRecordingRule() {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: recording rules are not enabled", ngmodels.ErrAlertRuleFailedValidation), "")
+			}
+		}
+	}
+
 	groupKey := ngmodels.AlertRuleGroupKey{
 		OrgID:        c.SignedInUser.GetOrgID(),
 		NamespaceUID: namespace.UID,
@@ -301,146 +328,169 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
-//
-//nolint:gocyclo
 func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
-		userNamespace, id := c.SignedInUser.GetNamespacedID()
-		logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
-			groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
-		groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
-		if err != nil {
-			return err
-		}
+		var err error
+		finalChanges, dbConfig, err = srv.updateRuleGroup(tranCtx, c, groupKey, rules, false)
+		return err
+	})
 
-		if groupChanges.IsEmpty() {
-			finalChanges = groupChanges
-			logger.Info("No changes detected in the request. Do nothing")
-			return nil
-		}
+	if err != nil {
+		return ruleGroupUpdateErrorToResponse(err)
+	}
+
+	srv.refreshAlertmanagerConfig(c, groupKey.OrgID, dbConfig)
+
+	return changesToResponse(finalChanges)
+}
+
+// updateRuleGroup calculates changes (rules to add,update,delete) and verifies that the user is authorized to do the calculated changes.
+// If dryRun is false, it updates the database. It must be called within a transaction.
+// Returns the calculated changes and the latest Alertmanager configuration if the changes affect notification settings.
+//
+//nolint:gocyclo
+func (srv RulerSrv) updateRuleGroup(tranCtx context.Context, c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals, dryRun bool) (*store.GroupDelta, *ngmodels.AlertConfiguration, error) {
+	var dbConfig *ngmodels.AlertConfiguration
+	userNamespace, id := c.SignedInUser.GetNamespacedID()
+	logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
+		groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
+	groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if groupChanges.IsEmpty() {
+		logger.Info("No changes detected in the request. Do nothing")
+		return groupChanges, nil, nil
+	}
+
+	err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+		return nil, nil, err
+	}
 
-		err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
+	if len(newOrUpdatedNotificationSettings) > 0 {
+		dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
 		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("failed to get latest configuration: %w", err)
 		}
-
-		if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
-			return err
+		cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse configuration: %w", err)
 		}
-
-		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
-		if len(newOrUpdatedNotificationSettings) > 0 {
-			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
-			if err != nil {
-				return fmt.Errorf("failed to get latest configuration: %w", err)
-			}
-			cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
-			if err != nil {
-				return fmt.Errorf("failed to parse configuration: %w", err)
+		validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
+		for _, s := range newOrUpdatedNotificationSettings {
+			if err := validator.Validate(s); err != nil {
+				return nil, nil, errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
 			}
-			validator := notifier.NewNotificationSettingsValidator(&cfg.AlertmanagerConfig)
-			for _, s := range newOrUpdatedNotificationSettings {
-				if err := validator.Validate(s); err != nil {
-					return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
-				}
-			}
-		}
-
-		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
-			return err
 		}
+	}
 
-		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
-		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+	if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
+		return nil, nil, err
+	}
 
-		// Delete first as this could prevent future unique constraint violations.
-		if len(finalChanges.Delete) > 0 {
-			UIDs := make([]string, 0, len(finalChanges.Delete))
-			for _, rule := range finalChanges.Delete {
-				UIDs = append(UIDs, rule.UID)
-			}
+	finalChanges := store.UpdateCalculatedRuleFields(groupChanges)
+	if dryRun {
+		logger.Debug("Skipping update of the database in dry-run mode", "add", len(finalChanges.New), "update", len(finalChanges.Update), "delete", len(finalChanges.Delete))
+		return finalChanges, nil, nil
+	}
+	logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
-			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
-				return fmt.Errorf("failed to delete rules: %w", err)
-			}
+	// Delete first as this could prevent future unique constraint violations.
+	if len(finalChanges.Delete) > 0 {
+		UIDs := make([]string, 0, len(finalChanges.Delete))
+		for _, rule := range finalChanges.Delete {
+			UIDs = append(UIDs, rule.UID)
 		}
 
-		if len(finalChanges.Update) > 0 {
-			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
-			for _, update := range finalChanges.Update {
-				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
-				updates = append(updates, ngmodels.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			err = srv.store.UpdateAlertRules(tranCtx, updates)
-			if err != nil {
-				return fmt.Errorf("failed to update rules: %w", err)
-			}
+		if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
+			return nil, nil, fmt.Errorf("failed to delete rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
-			for _, rule := range finalChanges.New {
-				inserts = append(inserts, *rule)
-			}
-			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
-			if err != nil {
-				return fmt.Errorf("failed to add rules: %w", err)
-			}
-			if len(added) != len(finalChanges.New) {
-				logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
-			} else {
-				for i, newRule := range finalChanges.New {
-					newRule.ID = added[i].ID
-					newRule.UID = added[i].UID
-				}
-			}
+	if len(finalChanges.Update) > 0 {
+		updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
+		for _, update := range finalChanges.Update {
+			logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+			updates = append(updates, ngmodels.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
+			})
+		}
+		err = srv.store.UpdateAlertRules(tranCtx, updates)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
-			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
-			}) // alert rule is table name
-			if err != nil {
-				return fmt.Errorf("failed to get alert rules quota: %w", err)
-			}
-			if limitReached {
-				return ngmodels.ErrQuotaReached
+	if len(finalChanges.New) > 0 {
+		inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
+		for _, rule := range finalChanges.New {
+			inserts = append(inserts, *rule)
+		}
+		added, err := srv.store.InsertAlertRules(tranCtx, inserts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add rules: %w", err)
+		}
+		if len(added) != len(finalChanges.New) {
+			logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
+		} else {
+			for i, newRule := range finalChanges.New {
+				newRule.ID = added[i].ID
+				newRule.UID = added[i].UID
 			}
 		}
-		return nil
-	})
+	}
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
+	if len(finalChanges.New) > 0 {
+		userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+		limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:  c.SignedInUser.GetOrgID(),
+			UserID: userID,
+		}) // alert rule is table name
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return nil, nil, ngmodels.ErrQuotaReached
 		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	return finalChanges, dbConfig, nil
+}
+
+// ruleGroupUpdateErrorToResponse converts the errors returned by updateRuleGroup to the API response.
+func ruleGroupUpdateErrorToResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+}
 
+// refreshAlertmanagerConfig applies the configuration returned by updateRuleGroup to the Alertmanager of the organization.
+func (srv RulerSrv) refreshAlertmanagerConfig(c *contextmodel.ReqContext, orgID int64, dbConfig *ngmodels.AlertConfiguration) {
 	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
 		// This isn't strictly necessary since the alertmanager config is periodically synced.
-		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+		err := srv.amRefresher.ApplyConfig(c.Req.Context(), orgID, dbConfig)
 		if err != nil {
-			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
+			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", orgID, "error", err)
 		}
 	}
-
-	return changesToResponse(finalChanges)
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -507,6 +557,7 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			Provenance:           apimodels.Provenance(provenance),
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
+			Record:               ApiRecordFromRecord(r.Record),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -515,6 +566,10 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
@@ -610,6 +665,34 @@ func (srv RulerSrv) getAuthorizedRuleByUid(ctx context.Context, c *contextmodel.
 	return ngmodels.AlertRule{}, ngmodels.ErrAlertRuleNotFound
 }
 
+// setEvaluationStats sets the statistics of the recent evaluations to the Grafana managed rules of the groups.
+func setEvaluationStats(reader EvaluationStatsReader, groups []apimodels.GettableRuleGroupConfig) {
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.GrafanaManagedAlert == nil {
+				continue
+			}
+			rule.GrafanaManagedAlert.EvaluationStats = getEvaluationStats(reader, ngmodels.AlertRuleKey{
+				OrgID: rule.GrafanaManagedAlert.OrgID,
+				UID:   rule.GrafanaManagedAlert.UID,
+			})
+		}
+	}
+}
+
+// getEvaluationStats returns the statistics of the recent evaluations of the rule, or nil if the rule has not been
+// evaluated yet.
+func getEvaluationStats(reader EvaluationStatsReader, key ngmodels.AlertRuleKey) *apimodels.RuleEvaluationStats {
+	if reader == nil {
+		return nil
+	}
+	stats, ok := reader.GetEvaluationStats(key)
+	if !ok {
+		return nil
+	}
+	return ApiRuleEvaluationStatsFromRuleEvaluationStats(stats)
+}
+
 // getAuthorizedRuleGroup fetches rules that belong to the specified models.AlertRuleGroupKey and validate user's authorization.
 // A user is authorized to access a group of rules only when it has permission to query all data sources used by all rules in this group.
 // Returns models.RuleGroup if authorization passed or ErrAuthorization if user is not authorized to access the rule.
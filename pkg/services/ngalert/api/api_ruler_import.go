@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ImportFromPrometheus converts the rule groups of the Prometheus rule file to Grafana-managed rules that query the data source
+// specified by the query parameter "datasourceUid", and saves them to the folder `namespaceUID`.
+// Every group of the file replaces the group with the same name in the folder. The converted rules are matched with the
+// existing rules of the group by title, so re-importing a file updates the rules instead of re-creating them.
+// If the query parameter "dryRun" is true, the changes are calculated and returned but not saved.
+func (srv RulerSrv) ImportFromPrometheus(c *contextmodel.ReqContext, file apimodels.PrometheusRuleFile, namespaceUID string) response.Response {
+	datasourceUID := c.Query("datasourceUid")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("query parameter datasourceUid must be specified"), "")
+	}
+	dryRun := c.QueryBool("dryRun")
+
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	ds, err := srv.datasourceCache.GetDatasourceByUID(c.Req.Context(), datasourceUID, c.SignedInUser, c.SkipDSCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, datasources.DS_PROMETHEUS))
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   ds.UID,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		RecordingRules:  srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagGrafanaManagedRecordingRules),
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create converter")
+	}
+	groups, err := converter.ConvertRuleFile(c.SignedInUser.GetOrgID(), namespace.UID, file)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if err := rule.ValidateAlertRule(*srv.cfg); err != nil {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid rule %q of group %q: %w", rule.Title, group.Title, err), "")
+			}
+		}
+	}
+
+	body := apimodels.ImportRulesResponse{
+		Message: "rule groups imported successfully",
+		DryRun:  dryRun,
+		Groups:  make([]apimodels.ImportedRuleGroup, 0, len(groups)),
+	}
+	var dbConfig *ngmodels.AlertConfiguration
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for _, group := range groups {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			rules, err := srv.validateImportedGroup(tranCtx, namespace, groupKey, group)
+			if err != nil {
+				return err
+			}
+			delta, cfg, err := srv.updateRuleGroup(tranCtx, c, groupKey, rules, dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to import rule group %q: %w", group.Title, err)
+			}
+			if cfg != nil {
+				dbConfig = cfg
+			}
+			body.Groups = append(body.Groups, toImportedRuleGroup(group.Title, delta))
+		}
+		return nil
+	})
+	if err != nil {
+		return ruleGroupUpdateErrorToResponse(err)
+	}
+
+	srv.refreshAlertmanagerConfig(c, c.SignedInUser.GetOrgID(), dbConfig)
+
+	if dryRun {
+		body.Message = "rule groups can be imported"
+	}
+	return response.JSON(http.StatusAccepted, body)
+}
+
+// validateImportedGroup matches the imported rules with the existing rules of the group, and validates the group like
+// the groups that are saved by the ruler API.
+func (srv RulerSrv) validateImportedGroup(ctx context.Context, namespace *folder.Folder, groupKey ngmodels.AlertRuleGroupKey, group ngmodels.AlertRuleGroup) ([]*ngmodels.AlertRuleWithOptionals, error) {
+	if err := srv.matchImportedRules(ctx, groupKey, group.Rules); err != nil {
+		return nil, err
+	}
+	ruleGroupConfig := postableRuleGroupFromAlertRuleGroup(group)
+	if err := srv.checkGroupLimits(ruleGroupConfig); err != nil {
+		return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+	}
+	rules, err := validateRuleGroup(&ruleGroupConfig, groupKey.OrgID, namespace, srv.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid rule group %q: %s", ngmodels.ErrAlertRuleFailedValidation, group.Title, err.Error())
+	}
+	return rules, nil
+}
+
+// matchImportedRules sets the UIDs of the existing rules of the group to the imported rules with the same title.
+func (srv RulerSrv) matchImportedRules(ctx context.Context, groupKey ngmodels.AlertRuleGroupKey, rules []ngmodels.AlertRule) error {
+	existing, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:         groupKey.OrgID,
+		NamespaceUIDs: []string{groupKey.NamespaceUID},
+		RuleGroup:     groupKey.RuleGroup,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query database for rules in the group %s: %w", groupKey, err)
+	}
+	uids := make(map[string]string, len(existing))
+	for _, r := range existing {
+		uids[r.Title] = r.UID
+	}
+	for i := range rules {
+		rules[i].UID = uids[rules[i].Title]
+	}
+	return nil
+}
+
+// postableRuleGroupFromAlertRuleGroup converts the group to the model of the ruler API.
+func postableRuleGroupFromAlertRuleGroup(group ngmodels.AlertRuleGroup) apimodels.PostableRuleGroupConfig {
+	result := apimodels.PostableRuleGroupConfig{
+		Name:     group.Title,
+		Interval: model.Duration(time.Duration(group.Interval) * time.Second),
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+	}
+	for _, r := range group.Rules {
+		forDuration := model.Duration(r.For)
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		node := apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:           &forDuration,
+				KeepFiringFor: &keepFiringFor,
+				Labels:        r.Labels,
+				Annotations:   r.Annotations,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:                r.Title,
+				Condition:            r.Condition,
+				Data:                 ApiAlertQueriesFromAlertQueries(r.Data),
+				UID:                  r.UID,
+				NoDataState:          apimodels.NoDataState(r.NoDataState),
+				ExecErrState:         apimodels.ExecutionErrorState(r.ExecErrState),
+				NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
+				Record:               ApiRecordFromRecord(r.Record),
+			},
+		}
+		result.Rules = append(result.Rules, node)
+	}
+	return result
+}
+
+func toImportedRuleGroup(name string, delta *store.GroupDelta) apimodels.ImportedRuleGroup {
+	result := apimodels.ImportedRuleGroup{Name: name}
+	for _, r := range delta.New {
+		result.Created = append(result.Created, apimodels.ImportedRule{UID: r.UID, Title: r.Title})
+	}
+	for _, r := range delta.Update {
+		result.Updated = append(result.Updated, apimodels.ImportedRule{UID: r.Existing.UID, Title: r.New.Title, Diff: r.Diff.Paths()})
+	}
+	for _, r := range delta.Delete {
+		result.Deleted = append(result.Deleted, apimodels.ImportedRule{UID: r.UID, Title: r.Title})
+	}
+	return result
+}
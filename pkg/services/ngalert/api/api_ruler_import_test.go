@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestImportFromPrometheus(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "api"}
+
+	file := apimodels.PrometheusRuleFile{
+		Groups: []apimodels.PrometheusRuleGroup{
+			{
+				Name: groupKey.RuleGroup,
+				Rules: []apimodels.ApiRuleNode{
+					{Alert: "HighLatency", Expr: "latency_seconds > 1", Labels: map[string]string{"severity": "warning"}},
+					{Alert: "HighErrorRate", Expr: "rate(errors_total[5m]) > 0.1"},
+				},
+			},
+		},
+	}
+
+	setup := func(t *testing.T) (*RulerSrv, *fakes.RuleStore, *models.AlertRule, *models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		existing := models.AlertRuleGen(withGroupKey(groupKey), models.WithTitle("HighLatency"))()
+		obsolete := models.AlertRuleGen(withGroupKey(groupKey), models.WithTitle("Obsolete"))()
+		ruleStore.PutRule(context.Background(), existing, obsolete)
+
+		svc := createService(ruleStore)
+		svc.cfg.DefaultRuleEvaluationInterval = time.Minute
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.datasourceCache = &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{
+			{UID: "prom", Type: datasources.DS_PROMETHEUS},
+			{UID: "loki", Type: datasources.DS_LOKI},
+		}}
+		return svc, ruleStore, existing, obsolete
+	}
+
+	newRequest := func(query map[string]string) *contextmodel.ReqContext {
+		request := createRequestContext(orgID, map[string]string{":Namespace": folder.UID})
+		for k, v := range query {
+			request.Req.Form.Set(k, v)
+		}
+		return request
+	}
+
+	t.Run("should return changes without saving them in dry-run mode", func(t *testing.T) {
+		svc, ruleStore, existing, obsolete := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "prom", "dryRun": "true"})
+
+		response := svc.ImportFromPrometheus(request, file, folder.UID)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		var result apimodels.ImportRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		require.Equal(t, groupKey.RuleGroup, group.Name)
+		require.Equal(t, []apimodels.ImportedRule{{Title: "HighErrorRate"}}, group.Created)
+		require.Len(t, group.Updated, 1)
+		require.Equal(t, existing.UID, group.Updated[0].UID)
+		require.Equal(t, "HighLatency", group.Updated[0].Title)
+		require.NotEmpty(t, group.Updated[0].Diff)
+		require.Equal(t, []apimodels.ImportedRule{{UID: obsolete.UID, Title: obsolete.Title}}, group.Deleted)
+
+		changes := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			switch c := cmd.(type) {
+			case []models.AlertRule, []models.UpdateRule:
+				return c, true
+			case fakes.GenericRecordedQuery:
+				return c, c.Name == "DeleteAlertRulesByUID"
+			}
+			return nil, false
+		})
+		require.Empty(t, changes)
+	})
+
+	t.Run("should save converted rules", func(t *testing.T) {
+		svc, ruleStore, existing, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "prom"})
+
+		response := svc.ImportFromPrometheus(request, file, folder.UID)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		inserts := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		})
+		require.Len(t, inserts, 1)
+		inserted := inserts[0].([]models.AlertRule)
+		require.Len(t, inserted, 1)
+		require.Equal(t, "HighErrorRate", inserted[0].Title)
+		require.Equal(t, "prom", inserted[0].Data[0].DatasourceUID)
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		updated := updates[0].([]models.UpdateRule)
+		require.Len(t, updated, 1)
+		require.Equal(t, existing.UID, updated[0].New.UID)
+		require.Equal(t, map[string]string{"severity": "warning"}, updated[0].New.Labels)
+	})
+
+	t.Run("should fail if data source is not specified", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		response := svc.ImportFromPrometheus(newRequest(nil), file, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should fail if data source does not exist", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "unknown"})
+		response := svc.ImportFromPrometheus(request, file, folder.UID)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should fail if data source is not Prometheus", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "loki"})
+		response := svc.ImportFromPrometheus(request, file, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should fail if expression is invalid", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "prom"})
+		invalid := apimodels.PrometheusRuleFile{Groups: []apimodels.PrometheusRuleGroup{
+			{Name: "test", Rules: []apimodels.ApiRuleNode{{Alert: "test", Expr: "up =="}}},
+		}}
+		response := svc.ImportFromPrometheus(request, invalid, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		require.Contains(t, string(response.Body()), "invalid expression")
+	})
+
+	t.Run("should fail if group is not valid", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "prom"})
+		invalid := apimodels.PrometheusRuleFile{Groups: []apimodels.PrometheusRuleGroup{
+			{Name: "test", Interval: model.Duration(15 * time.Second), Rules: []apimodels.ApiRuleNode{{Alert: "test", Expr: "up == 0"}}},
+		}}
+		response := svc.ImportFromPrometheus(request, invalid, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		require.Contains(t, string(response.Body()), "base interval")
+	})
+
+	t.Run("should fail if recording rules are not enabled", func(t *testing.T) {
+		svc, _, _, _ := setup(t)
+		request := newRequest(map[string]string{"datasourceUid": "prom"})
+		recording := apimodels.PrometheusRuleFile{Groups: []apimodels.PrometheusRuleGroup{
+			{Name: "test", Rules: []apimodels.ApiRuleNode{{Record: "job:up:sum", Expr: "sum by (job) (up)"}}},
+		}}
+		response := svc.ImportFromPrometheus(request, recording, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -462,6 +463,48 @@ func TestRouteGetRulesGroupConfig(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("should include evaluation stats if requested", func(t *testing.T) {
+		orgID := rand.Int63()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		groupKey := models.GenerateGroupKey(orgID)
+		groupKey.NamespaceUID = folder.UID
+
+		rules := models.GenerateAlertRules(2, models.AlertRuleGen(withGroupKey(groupKey), models.WithUniqueGroupIndex()))
+		ruleStore.PutRule(context.Background(), rules...)
+		models.RulesGroup(rules).SortByGroupIndex()
+
+		svc := createService(ruleStore)
+		svc.evaluationStats = fakeEvaluationStatsReader{
+			rules[0].GetKey(): {
+				Evaluations: []models.EvaluationRecord{{Duration: time.Second, Series: 2}},
+				Total:       1,
+			},
+		}
+
+		req := createRequestContext(orgID, nil)
+		response := svc.RouteGetRulesGroupConfig(req, folder.UID, groupKey.RuleGroup)
+		require.Equal(t, http.StatusAccepted, response.Status())
+		result := &apimodels.RuleGroupConfigResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), result))
+		require.Nil(t, result.Rules[0].GrafanaManagedAlert.EvaluationStats)
+
+		req = createRequestContext(orgID, nil)
+		req.Req.Form.Set("include_evaluation_stats", "true")
+		response = svc.RouteGetRulesGroupConfig(req, folder.UID, groupKey.RuleGroup)
+		require.Equal(t, http.StatusAccepted, response.Status())
+		result = &apimodels.RuleGroupConfigResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), result))
+		stats := result.Rules[0].GrafanaManagedAlert.EvaluationStats
+		require.NotNil(t, stats)
+		require.EqualValues(t, 1, stats.TotalEvaluations)
+		require.Equal(t, 1.0, stats.DurationP95)
+		require.Len(t, stats.Evaluations, 1)
+		require.Equal(t, 2, stats.Evaluations[0].Series)
+		require.Nil(t, result.Rules[1].GrafanaManagedAlert.EvaluationStats, "rule that was not evaluated should not have stats")
+	})
 }
 
 func TestVerifyProvisionedRulesNotAffected(t *testing.T) {
@@ -608,6 +651,29 @@ func TestValidateQueries(t *testing.T) {
 	})
 }
 
+func TestRoutePostNameRulesConfigRecordingRules(t *testing.T) {
+	t.Run("should reject recording rules if feature flag is disabled", func(t *testing.T) {
+		orgID := rand.Int63()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+
+		svc := createService(ruleStore)
+		rule := validRule()
+		rule.GrafanaManagedAlert.UID = ""
+		rule.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+		group := validGroup(svc.cfg, rule)
+		group.Interval = model.Duration(svc.cfg.BaseInterval)
+
+		request := createRequestContext(orgID, map[string]string{
+			":Namespace": folder.UID,
+		})
+		response := svc.RoutePostNameRulesConfig(request, group, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+		require.Contains(t, string(response.Body()), "recording rules are not enabled")
+	})
+}
+
 func createServiceWithProvenanceStore(store *fakes.RuleStore, provenanceStore provisioning.ProvisioningStore) *RulerSrv {
 	svc := createService(store)
 	svc.provenanceStore = provenanceStore
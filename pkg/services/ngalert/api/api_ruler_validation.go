@@ -76,7 +76,12 @@ func validateRuleNode(
 			return nil, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 		}
 	} else {
-		err = validateCondition(ruleNode.GrafanaManagedAlert.Condition, ruleNode.GrafanaManagedAlert.Data)
+		condition := ruleNode.GrafanaManagedAlert.Condition
+		// recording rules do not have a condition, and write the results of the query instead
+		if ruleNode.GrafanaManagedAlert.Record != nil {
+			condition = ruleNode.GrafanaManagedAlert.Record.From
+		}
+		err = validateCondition(condition, ruleNode.GrafanaManagedAlert.Data)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
 		}
@@ -104,11 +109,26 @@ func validateRuleNode(
 		}
 	}
 
+	if ruleNode.GrafanaManagedAlert.Record != nil {
+		newAlertRule.Record, err = validateRecord(ruleNode.GrafanaManagedAlert.Record, ruleNode.GrafanaManagedAlert.Data)
+		if err != nil {
+			return nil, err
+		}
+		if newAlertRule.NotificationSettings != nil {
+			return nil, fmt.Errorf("%w: recording rules cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
 
+	newAlertRule.KeepFiringFor, err = validateKeepFiringForInterval(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		err = validateLabels(ruleNode.Labels)
@@ -193,6 +213,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringForInterval validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -253,9 +288,34 @@ func validateRuleGroup(
 
 		result = append(result, &ruleWithOptionals)
 	}
+	if err := validateRuleDependencies(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// validateRuleDependencies checks that alert state expressions of the rules read the state of rules of the same group,
+// and that the rules do not read the state of each other in a cycle.
+func validateRuleDependencies(rules []*ngmodels.AlertRuleWithOptionals) error {
+	group := make(ngmodels.RulesGroup, 0, len(rules))
+	for _, rule := range rules {
+		group = append(group, &rule.AlertRule)
+	}
+	return group.ValidateDependencies()
+}
+
+func validateRecord(r *apimodels.Record, queries []apimodels.AlertQuery) (ngmodels.Record, error) {
+	record := RecordFromApiRecord(r)
+	// the queries can be omitted when an existing rule is patched. In this case, the record is validated with the queries of the existing rule.
+	if len(queries) == 0 {
+		return record, nil
+	}
+	if err := record.Validate(AlertQueriesFromApiAlertQueries(queries)); err != nil {
+		return ngmodels.Record{}, fmt.Errorf("%w: invalid recording rule: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+	}
+	return record, nil
+}
+
 func validateNotificationSettings(n *apimodels.AlertRuleNotificationSettings) ([]ngmodels.NotificationSettings, error) {
 	s := ngmodels.NotificationSettings{
 		Receiver:          n.Receiver,
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"path"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/rand"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -76,6 +78,16 @@ func validRule() apimodels.PostableExtendedRuleNode {
 	}
 }
 
+// withAlertStateQuery adds an alert state expression that reads the state of the rule with the specified UID.
+func withAlertStateQuery(rule apimodels.PostableExtendedRuleNode, ruleUID string) apimodels.PostableExtendedRuleNode {
+	rule.GrafanaManagedAlert.Data = append(rule.GrafanaManagedAlert.Data, apimodels.AlertQuery{
+		RefID:         "STATE",
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"type":"alert_state","ruleUid":%q}`, ruleUID)),
+	})
+	return rule
+}
+
 func validGroup(cfg *setting.UnifiedAlertingSettings, rules ...apimodels.PostableExtendedRuleNode) apimodels.PostableRuleGroupConfig {
 	return apimodels.PostableRuleGroupConfig{
 		Name:     "TEST-ALERTS-" + util.GenerateShortUID(),
@@ -213,6 +225,17 @@ func TestValidateRuleGroup(t *testing.T) {
 		}
 	})
 
+	t.Run("should allow rules to read the state of rules of the same group", func(t *testing.T) {
+		upstream := validRule()
+		service := withAlertStateQuery(validRule(), upstream.GrafanaManagedAlert.UID)
+		newRule := withAlertStateQuery(validRule(), service.GrafanaManagedAlert.UID)
+		newRule.GrafanaManagedAlert.UID = ""
+		g := validGroup(cfg, newRule, service, upstream)
+		alerts, err := validateRuleGroup(&g, orgId, folder, cfg)
+		require.NoError(t, err)
+		require.Len(t, alerts, 3)
+	})
+
 	t.Run("should show the payload has isPaused field", func(t *testing.T) {
 		for _, rule := range rules {
 			isPaused := true
@@ -317,6 +340,42 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
 			},
 		},
+		{
+			name: "fail if rule reads the state of a rule of another group",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := withAlertStateQuery(validRule(), util.GenerateShortUID())
+				g := validGroup(cfg, r1)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
+		{
+			name: "fail if rule reads its own state",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r1 = withAlertStateQuery(r1, r1.GrafanaManagedAlert.UID)
+				g := validGroup(cfg, r1)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
+		{
+			name: "fail if rules read the state of each other in a cycle",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r2 := withAlertStateQuery(validRule(), r1.GrafanaManagedAlert.UID)
+				r1 = withAlertStateQuery(r1, r2.GrafanaManagedAlert.UID)
+				g := validGroup(cfg, r1, r2)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -937,3 +996,102 @@ func TestValidateRuleNodeReservedLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNodeRecord(t *testing.T) {
+	cfg := config(t)
+
+	testCases := []struct {
+		name             string
+		condition        string
+		record           *apimodels.Record
+		notifications    *apimodels.AlertRuleNotificationSettings
+		expErrorContains string
+	}{
+		{
+			name:   "valid record without condition",
+			record: &apimodels.Record{Metric: "test_metric", From: "A"},
+		},
+		{
+			name:      "valid record with condition",
+			condition: "A",
+			record:    &apimodels.Record{Metric: "test:metric", From: "A"},
+		},
+		{
+			name:             "invalid metric name",
+			record:           &apimodels.Record{Metric: "test-metric", From: "A"},
+			expErrorContains: "invalid metric name",
+		},
+		{
+			name:             "empty metric name",
+			record:           &apimodels.Record{Metric: "", From: "A"},
+			expErrorContains: "invalid metric name",
+		},
+		{
+			name:             "unknown query",
+			record:           &apimodels.Record{Metric: "test_metric", From: "B"},
+			expErrorContains: "B does not exist",
+		},
+		{
+			name:             "notification settings",
+			record:           &apimodels.Record{Metric: "test_metric", From: "A"},
+			notifications:    &apimodels.AlertRuleNotificationSettings{Receiver: "test"},
+			expErrorContains: "recording rules cannot have notification settings",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.Condition = tt.condition
+			r.GrafanaManagedAlert.Record = tt.record
+			r.GrafanaManagedAlert.NotificationSettings = tt.notifications
+			rule, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval*time.Duration(rand.Int63n(10)+1), rand.Int63(), randFolder(), cfg)
+
+			if tt.expErrorContains != "" {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, tt.expErrorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, rule.IsRecordingRule())
+			require.Equal(t, models.Record{Metric: tt.record.Metric, From: tt.record.From}, rule.Record)
+			require.Equal(t, "A", rule.GetEvalCondition().Condition)
+		})
+	}
+}
+
+func TestValidateRuleNodeKeepFiringFor(t *testing.T) {
+	cfg := config(t)
+
+	t.Run("converts keep_firing_for", func(t *testing.T) {
+		r := validRule()
+		keepFiringFor := model.Duration(5 * time.Minute)
+		r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+
+		rule, err := validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, rule.KeepFiringFor)
+	})
+
+	t.Run("defaults to 0 for new rules and -1 for existing rules", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.UID = ""
+		rule, err := validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), rule.KeepFiringFor)
+
+		r.GrafanaManagedAlert.UID = util.GenerateShortUID()
+		rule, err = validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(-1), rule.KeepFiringFor)
+	})
+
+	t.Run("fails if keep_firing_for is negative", func(t *testing.T) {
+		r := validRule()
+		keepFiringFor := model.Duration(-time.Minute)
+		r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+
+		_, err := validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.ErrorContains(t, err, "keep_firing_for")
+	})
+}
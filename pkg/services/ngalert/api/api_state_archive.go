@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// AlertInstanceArchiver exports and imports the state of alert instances.
+type AlertInstanceArchiver interface {
+	ExportInstances(ctx context.Context, orgID int64) ([]ngmodels.AlertInstance, error)
+	ImportInstances(ctx context.Context, instances []ngmodels.AlertInstance, rules map[string]*ngmodels.AlertRule) (int, error)
+}
+
+// AlertmanagerStateArchiver exports and imports the silences and notification log of the Grafana Alertmanager.
+type AlertmanagerStateArchiver interface {
+	ExportState(ctx context.Context, orgID int64) (notifier.AlertmanagerState, error)
+	ImportState(ctx context.Context, orgID int64, st notifier.AlertmanagerState) error
+}
+
+func (srv ConfigSrv) RouteGetAlertStateArchive(c *contextmodel.ReqContext) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+
+	amState, err := srv.amStateArchiver.ExportState(c.Req.Context(), orgID)
+	if err != nil {
+		return alertmanagerStateErrorResponse(err)
+	}
+
+	rules, folders, errResp := srv.getRulesAndFolders(c)
+	if errResp != nil {
+		return errResp
+	}
+	rulesByUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByUID[rule.UID] = rule
+	}
+
+	archive := apimodels.AlertStateArchive{
+		Version:         apimodels.AlertStateArchiveVersion,
+		OrgID:           orgID,
+		CreatedAt:       time.Now().UTC(),
+		Rules:           []apimodels.AlertStateArchiveRule{},
+		Instances:       []apimodels.AlertStateArchiveInstance{},
+		Silences:        amState.Silences,
+		NotificationLog: amState.NotificationLog,
+	}
+	instances, err := srv.instanceArchiver.ExportInstances(c.Req.Context(), orgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export alert instances")
+	}
+	archived := map[string]struct{}{}
+	for _, instance := range instances {
+		rule, ok := rulesByUID[instance.RuleUID]
+		if !ok {
+			continue
+		}
+		if _, ok := archived[rule.UID]; !ok {
+			archived[rule.UID] = struct{}{}
+			archive.Rules = append(archive.Rules, apimodels.AlertStateArchiveRule{
+				UID:         rule.UID,
+				Title:       rule.Title,
+				RuleGroup:   rule.RuleGroup,
+				FolderTitle: folderTitle(folders, rule.NamespaceUID),
+			})
+		}
+		entry := apimodels.AlertStateArchiveInstance{
+			RuleUID:           instance.RuleUID,
+			Labels:            instance.Labels,
+			State:             string(instance.CurrentState),
+			Reason:            instance.CurrentReason,
+			StartsAt:          instance.CurrentStateSince,
+			EndsAt:            instance.CurrentStateEnd,
+			LastEvaluatedAt:   instance.LastEvalTime,
+			ResultFingerprint: instance.ResultFingerprint,
+		}
+		if !instance.KeepFiringSince.IsZero() {
+			keepFiringSince := instance.KeepFiringSince
+			entry.KeepFiringSince = &keepFiringSince
+		}
+		archive.Instances = append(archive.Instances, entry)
+	}
+	sort.Slice(archive.Rules, func(i, j int) bool {
+		return archive.Rules[i].UID < archive.Rules[j].UID
+	})
+
+	return response.JSON(http.StatusOK, archive)
+}
+
+func (srv ConfigSrv) RoutePostAlertStateArchive(c *contextmodel.ReqContext, archive apimodels.AlertStateArchive) response.Response {
+	if archive.Version != apimodels.AlertStateArchiveVersion {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unsupported archive version %d, expected %d", archive.Version, apimodels.AlertStateArchiveVersion), "")
+	}
+
+	rules, folders, errResp := srv.getRulesAndFolders(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	instances, unmatched, err := archiveToInstances(archive, rules, folders)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid archive")
+	}
+
+	result := apimodels.AlertStateImportResult{
+		UnmatchedRules: unmatched,
+	}
+	// The Alertmanager state is imported first because it is validated before it replaces the current one.
+	if len(archive.Silences) > 0 || len(archive.NotificationLog) > 0 {
+		err := srv.amStateArchiver.ImportState(c.Req.Context(), c.SignedInUser.GetOrgID(), notifier.AlertmanagerState{
+			Silences:        archive.Silences,
+			NotificationLog: archive.NotificationLog,
+		})
+		if err != nil {
+			return alertmanagerStateErrorResponse(err)
+		}
+		result.AlertmanagerState = true
+	}
+
+	rulesByUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByUID[rule.UID] = rule
+	}
+	result.Instances, err = srv.instanceArchiver.ImportInstances(c.Req.Context(), instances, rulesByUID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to import alert instances")
+	}
+
+	srv.log.Info("Imported alert state archive", "sourceOrg", archive.OrgID, "instances", result.Instances, "unmatchedRules", len(unmatched), "alertmanagerState", result.AlertmanagerState)
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv ConfigSrv) getRulesAndFolders(c *contextmodel.ReqContext) (ngmodels.RulesGroup, map[string]*folder.Folder, response.Response) {
+	rules, err := srv.ruleStore.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to list alert rules")
+	}
+	folders, err := srv.ruleStore.GetUserVisibleNamespaces(c.Req.Context(), c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to get folders")
+	}
+	return rules, folders, nil
+}
+
+// archiveToInstances converts the instances of the archive to alert instances of the given rules. An instance is assigned
+// to the rule with the same UID, or to the rule with the same title and group in a folder with the same title. It returns
+// the UIDs of the archived rules without a matching rule, whose instances are skipped.
+func archiveToInstances(archive apimodels.AlertStateArchive, rules ngmodels.RulesGroup, folders map[string]*folder.Folder) ([]ngmodels.AlertInstance, []string, error) {
+	type ruleKey struct {
+		folderTitle, group, title string
+	}
+	byUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	byKey := make(map[ruleKey]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		byUID[rule.UID] = rule
+		byKey[ruleKey{folderTitle(folders, rule.NamespaceUID), rule.RuleGroup, rule.Title}] = rule
+	}
+
+	targets := make(map[string]*ngmodels.AlertRule, len(archive.Rules))
+	var unmatched []string
+	for _, r := range archive.Rules {
+		if rule, ok := byUID[r.UID]; ok {
+			targets[r.UID] = rule
+		} else if rule, ok := byKey[ruleKey{r.FolderTitle, r.RuleGroup, r.Title}]; ok {
+			targets[r.UID] = rule
+		} else {
+			unmatched = append(unmatched, r.UID)
+		}
+	}
+
+	instances := make([]ngmodels.AlertInstance, 0, len(archive.Instances))
+	for _, i := range archive.Instances {
+		stateType := ngmodels.InstanceStateType(i.State)
+		if !stateType.IsValid() {
+			return nil, nil, fmt.Errorf("invalid state %q of alert instance of rule %s", i.State, i.RuleUID)
+		}
+		rule, ok := targets[i.RuleUID]
+		if !ok {
+			rule, ok = byUID[i.RuleUID]
+		}
+		if !ok {
+			continue
+		}
+		entry := ngmodels.AlertInstance{
+			Labels:            ngmodels.InstanceLabels(i.Labels),
+			CurrentState:      stateType,
+			CurrentReason:     i.Reason,
+			CurrentStateSince: i.StartsAt,
+			CurrentStateEnd:   i.EndsAt,
+			LastEvalTime:      i.LastEvaluatedAt,
+			ResultFingerprint: i.ResultFingerprint,
+		}
+		if i.KeepFiringSince != nil {
+			entry.KeepFiringSince = *i.KeepFiringSince
+		}
+		instance, err := state.RelabelInstance(entry, rule, folderTitle(folders, rule.NamespaceUID))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid labels of alert instance of rule %s: %w", i.RuleUID, err)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, unmatched, nil
+}
+
+func folderTitle(folders map[string]*folder.Folder, uid string) string {
+	if f, ok := folders[uid]; ok {
+		return f.Title
+	}
+	return ""
+}
+
+func alertmanagerStateErrorResponse(err error) response.Response {
+	if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if errors.Is(err, notifier.ErrInvalidAlertmanagerState) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to export or import the Alertmanager state")
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestArchiveToInstances(t *testing.T) {
+	folders := map[string]*folder.Folder{
+		"folder-uid": {UID: "folder-uid", Title: "Folder"},
+	}
+	sameUID := &ngmodels.AlertRule{OrgID: 2, UID: "same-uid", Title: "Same UID", RuleGroup: "group", NamespaceUID: "folder-uid"}
+	sameTitle := &ngmodels.AlertRule{OrgID: 2, UID: "other-uid", Title: "Same title", RuleGroup: "group", NamespaceUID: "folder-uid"}
+	rules := ngmodels.RulesGroup{sameUID, sameTitle}
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	archive := apimodels.AlertStateArchive{
+		Version: apimodels.AlertStateArchiveVersion,
+		OrgID:   1,
+		Rules: []apimodels.AlertStateArchiveRule{
+			{UID: "same-uid", Title: "Renamed", RuleGroup: "group", FolderTitle: "Other folder"},
+			{UID: "source-uid", Title: "Same title", RuleGroup: "group", FolderTitle: "Folder"},
+			{UID: "unknown-uid", Title: "Unknown", RuleGroup: "group", FolderTitle: "Folder"},
+		},
+		Instances: []apimodels.AlertStateArchiveInstance{
+			{
+				RuleUID:         "same-uid",
+				Labels:          map[string]string{"alertname": "Same UID", "instance": "a"},
+				State:           string(ngmodels.InstanceStateFiring),
+				StartsAt:        now,
+				KeepFiringSince: &now,
+			},
+			{
+				RuleUID: "source-uid",
+				Labels: map[string]string{
+					"alertname":                      "Same title",
+					alertingModels.RuleUIDLabel:      "source-uid",
+					alertingModels.NamespaceUIDLabel: "source-folder-uid",
+					ngmodels.FolderTitleLabel:        "Source folder",
+				},
+				State:  string(ngmodels.InstanceStatePending),
+				Reason: "reason",
+			},
+			{
+				RuleUID: "unknown-uid",
+				Labels:  map[string]string{"alertname": "Unknown"},
+				State:   string(ngmodels.InstanceStateNormal),
+			},
+		},
+	}
+
+	instances, unmatched, err := archiveToInstances(archive, rules, folders)
+	require.NoError(t, err)
+	require.Equal(t, []string{"unknown-uid"}, unmatched)
+	require.Len(t, instances, 2)
+
+	require.Equal(t, int64(2), instances[0].RuleOrgID)
+	require.Equal(t, "same-uid", instances[0].RuleUID)
+	require.Equal(t, ngmodels.InstanceStateFiring, instances[0].CurrentState)
+	require.Equal(t, now, instances[0].CurrentStateSince)
+	require.Equal(t, now, instances[0].KeepFiringSince)
+	require.NotEmpty(t, instances[0].LabelsHash)
+
+	require.Equal(t, "other-uid", instances[1].RuleUID)
+	require.Equal(t, ngmodels.InstanceLabels{
+		"alertname":                      "Same title",
+		alertingModels.RuleUIDLabel:      "other-uid",
+		alertingModels.NamespaceUIDLabel: "folder-uid",
+		ngmodels.FolderTitleLabel:        "Folder",
+	}, instances[1].Labels)
+	require.Equal(t, "reason", instances[1].CurrentReason)
+	require.True(t, instances[1].KeepFiringSince.IsZero())
+
+	t.Run("should fail for invalid state", func(t *testing.T) {
+		archive := apimodels.AlertStateArchive{
+			Instances: []apimodels.AlertStateArchiveInstance{{RuleUID: "same-uid", State: "Firing"}},
+		}
+		_, _, err := archiveToInstances(archive, rules, folders)
+		require.ErrorContains(t, err, "invalid state")
+	})
+}
+
+func TestRouteAlertStateArchive(t *testing.T) {
+	ruleStore := fakes.NewRuleStore(t)
+	rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+	ruleStore.PutRule(context.Background(), rule)
+
+	instance := ngmodels.AlertInstance{
+		AlertInstanceKey: ngmodels.AlertInstanceKey{RuleOrgID: 1, RuleUID: rule.UID, LabelsHash: "hash"},
+		Labels:           ngmodels.InstanceLabels{"alertname": rule.Title},
+		CurrentState:     ngmodels.InstanceStateFiring,
+		KeepFiringSince:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	orphan := ngmodels.AlertInstance{
+		AlertInstanceKey: ngmodels.AlertInstanceKey{RuleOrgID: 1, RuleUID: "deleted", LabelsHash: "hash"},
+		CurrentState:     ngmodels.InstanceStateFiring,
+	}
+
+	createSut := func() (ConfigSrv, *fakeAlertInstanceArchiver, *fakeAlertmanagerStateArchiver) {
+		instances := &fakeAlertInstanceArchiver{instances: []ngmodels.AlertInstance{instance, orphan}}
+		am := &fakeAlertmanagerStateArchiver{state: notifier.AlertmanagerState{Silences: []byte("silences")}}
+		return ConfigSrv{
+			ruleStore:        ruleStore,
+			instanceArchiver: instances,
+			amStateArchiver:  am,
+			log:              log.NewNopLogger(),
+		}, instances, am
+	}
+
+	t.Run("export should include instances of existing rules and Alertmanager state", func(t *testing.T) {
+		sut, _, _ := createSut()
+		resp := sut.RouteGetAlertStateArchive(createRequestCtxInOrg(1))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var archive apimodels.AlertStateArchive
+		require.NoError(t, json.Unmarshal(resp.Body(), &archive))
+		require.Equal(t, apimodels.AlertStateArchiveVersion, archive.Version)
+		require.Equal(t, int64(1), archive.OrgID)
+		require.Len(t, archive.Rules, 1)
+		require.Equal(t, rule.UID, archive.Rules[0].UID)
+		require.Len(t, archive.Instances, 1)
+		require.Equal(t, string(ngmodels.InstanceStateFiring), archive.Instances[0].State)
+		require.NotNil(t, archive.Instances[0].KeepFiringSince)
+		require.True(t, instance.KeepFiringSince.Equal(*archive.Instances[0].KeepFiringSince))
+		require.Equal(t, []byte("silences"), archive.Silences)
+	})
+
+	t.Run("export should fail if alert instances cannot be read", func(t *testing.T) {
+		sut, instances, _ := createSut()
+		instances.err = errors.New("failed")
+		resp := sut.RouteGetAlertStateArchive(createRequestCtxInOrg(1))
+		require.Equal(t, http.StatusInternalServerError, resp.Status())
+	})
+
+	t.Run("export should return 404 if there is no Alertmanager", func(t *testing.T) {
+		sut, _, am := createSut()
+		am.err = notifier.ErrNoAlertmanagerForOrg
+		resp := sut.RouteGetAlertStateArchive(createRequestCtxInOrg(1))
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("import should fail for unsupported version", func(t *testing.T) {
+		sut, _, _ := createSut()
+		resp := sut.RoutePostAlertStateArchive(createRequestCtxInOrg(1), apimodels.AlertStateArchive{Version: 2})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("import should restore instances and Alertmanager state", func(t *testing.T) {
+		sut, instances, am := createSut()
+		resp := sut.RoutePostAlertStateArchive(createRequestCtxInOrg(1), apimodels.AlertStateArchive{
+			Version: apimodels.AlertStateArchiveVersion,
+			Instances: []apimodels.AlertStateArchiveInstance{
+				{RuleUID: rule.UID, Labels: map[string]string{"alertname": rule.Title}, State: string(ngmodels.InstanceStateFiring)},
+			},
+			NotificationLog: []byte("nflog"),
+		})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.AlertStateImportResult
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Equal(t, 1, result.Instances)
+		require.True(t, result.AlertmanagerState)
+		require.Len(t, instances.imported, 1)
+		require.Equal(t, rule.UID, instances.imported[0].RuleUID)
+		require.Equal(t, []byte("nflog"), am.imported.NotificationLog)
+	})
+
+	t.Run("import should return 400 for invalid Alertmanager state", func(t *testing.T) {
+		sut, instances, am := createSut()
+		am.err = notifier.ErrInvalidAlertmanagerState
+		resp := sut.RoutePostAlertStateArchive(createRequestCtxInOrg(1), apimodels.AlertStateArchive{
+			Version:  apimodels.AlertStateArchiveVersion,
+			Silences: []byte("invalid"),
+		})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		require.Empty(t, instances.imported)
+	})
+}
+
+type fakeAlertInstanceArchiver struct {
+	instances []ngmodels.AlertInstance
+	imported  []ngmodels.AlertInstance
+	err       error
+}
+
+func (f *fakeAlertInstanceArchiver) ExportInstances(_ context.Context, orgID int64) ([]ngmodels.AlertInstance, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var result []ngmodels.AlertInstance
+	for _, i := range f.instances {
+		if i.RuleOrgID == orgID {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeAlertInstanceArchiver) ImportInstances(_ context.Context, instances []ngmodels.AlertInstance, rules map[string]*ngmodels.AlertRule) (int, error) {
+	for _, i := range instances {
+		if _, ok := rules[i.RuleUID]; ok {
+			f.imported = append(f.imported, i)
+		}
+	}
+	return len(f.imported), nil
+}
+
+type fakeAlertmanagerStateArchiver struct {
+	state    notifier.AlertmanagerState
+	imported notifier.AlertmanagerState
+	err      error
+}
+
+func (f *fakeAlertmanagerStateArchiver) ExportState(context.Context, int64) (notifier.AlertmanagerState, error) {
+	return f.state, f.err
+}
+
+func (f *fakeAlertmanagerStateArchiver) ImportState(_ context.Context, _ int64, st notifier.AlertmanagerState) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.imported = st
+	return nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/benbjohnson/clock"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -29,6 +31,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -39,6 +42,14 @@ type folderService interface {
 	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user identity.Requester) (*folder.Folder, error)
 }
 
+type ruleGroupReader interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+}
+
+type alertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (notifier.Alertmanager, error)
+}
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	DatasourceCache datasources.CacheService
@@ -47,10 +58,14 @@ type TestingApiSrv struct {
 	evaluator       eval.EvaluatorFactory
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
+	backtestJobs    *backtesting.Jobs
 	featureManager  featuremgmt.FeatureToggles
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	ruleStore       ruleGroupReader
+	amConfigStore   AlertingStore
+	alertmanagers   alertmanagerProvider
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -242,6 +257,12 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	if err != nil {
 		return ErrResp(400, err, "")
 	}
+	if err := validateBacktestParallelism(cmd.Parallelism); err != nil {
+		return ErrResp(400, err, "")
+	}
+	if cmd.Async && cmd.SimulateNotifications {
+		return ErrResp(400, nil, "Simulation of notifications is not supported by asynchronous backtesting")
+	}
 
 	queries := AlertQueriesFromApiAlertQueries(cmd.Data)
 	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, ngmodels.RulesGroup{&ngmodels.AlertRule{Data: queries}}); err != nil {
@@ -271,7 +292,32 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
-	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	if cmd.NotificationSettings != nil {
+		rule.NotificationSettings, err = validateNotificationSettings(cmd.NotificationSettings)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	var folderTitle string
+	if cmd.NamespaceUID != "" {
+		folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		rule.NamespaceUID = folder.UID
+		folderTitle = folder.Fullpath
+	}
+
+	if cmd.SimulateNotifications {
+		return srv.backtestNotifications(c, rule, folderTitle, cmd.From, cmd.To)
+	}
+	if cmd.Async {
+		return srv.startBacktestJob(c, ngmodels.RulesGroup{rule}, cmd.From, cmd.To, cmd.Parallelism)
+	}
+
+	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To, backtesting.TestOptions{
+		Parallelism: cmd.Parallelism,
+	})
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
 			return ErrResp(400, err, "Failed to evaluate")
@@ -285,3 +331,229 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestRuleGroup starts a job that tests all rules of the rule group over the same interval.
+func (srv TestingApiSrv) BacktestRuleGroup(c *contextmodel.ReqContext, cmd apimodels.BacktestGroupConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+	}
+
+	if cmd.From.After(cmd.To) {
+		return ErrResp(400, nil, "From cannot be greater than To")
+	}
+	if err := validateBacktestParallelism(cmd.Parallelism); err != nil {
+		return ErrResp(400, err, "")
+	}
+
+	if _, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	rules, err := srv.ruleStore.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: []string{cmd.NamespaceUID},
+		RuleGroup:     cmd.RuleGroup,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get rule group")
+	}
+	if len(rules) == 0 {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleGroupNotFound, "")
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules); err != nil {
+		return errorToResponse(err)
+	}
+
+	return srv.startBacktestJob(c, rules, cmd.From, cmd.To, cmd.Parallelism)
+}
+
+// RouteGetBacktestJob returns the progress of the backtesting job, and its results if it is completed.
+func (srv TestingApiSrv) RouteGetBacktestJob(c *contextmodel.ReqContext, jobID string) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+	}
+	status, err := srv.backtestJobs.Get(c.SignedInUser, jobID)
+	if err != nil {
+		return backtestJobErrorResponse(err)
+	}
+	return toBacktestJobResponse(http.StatusOK, status)
+}
+
+// RouteDeleteBacktestJob cancels the backtesting job.
+func (srv TestingApiSrv) RouteDeleteBacktestJob(c *contextmodel.ReqContext, jobID string) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+	}
+	status, err := srv.backtestJobs.Cancel(c.SignedInUser, jobID)
+	if err != nil {
+		return backtestJobErrorResponse(err)
+	}
+	return toBacktestJobResponse(http.StatusOK, status)
+}
+
+func (srv TestingApiSrv) startBacktestJob(c *contextmodel.ReqContext, rules ngmodels.RulesGroup, from, to time.Time, parallelism int) response.Response {
+	total := 0
+	for _, rule := range rules {
+		total += backtesting.Evaluations(rule, from, to)
+	}
+	user := c.SignedInUser
+	status, err := srv.backtestJobs.Start(c.Req.Context(), user, total, func(ctx context.Context, onProgress func()) ([]backtesting.RuleResult, error) {
+		return srv.backtesting.TestGroup(ctx, user, rules, from, to, backtesting.TestOptions{
+			Parallelism: parallelism,
+			OnProgress:  onProgress,
+		})
+	})
+	if err != nil {
+		return backtestJobErrorResponse(err)
+	}
+	return toBacktestJobResponse(http.StatusAccepted, status)
+}
+
+func validateBacktestParallelism(parallelism int) error {
+	if parallelism < 0 || parallelism > backtesting.MaxParallelism {
+		return fmt.Errorf("parallelism must be between 0 and %d", backtesting.MaxParallelism)
+	}
+	return nil
+}
+
+func backtestJobErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, backtesting.ErrJobNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, backtesting.ErrTooManyJobs):
+		return ErrResp(http.StatusTooManyRequests, err, "")
+	default:
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+}
+
+func toBacktestJobResponse(status int, job backtesting.JobStatus) response.Response {
+	result := apimodels.BacktestJob{
+		ID:        job.ID,
+		State:     apimodels.BacktestJobState(job.State),
+		Total:     job.Total,
+		Done:      job.Done,
+		StartedAt: job.StartedAt,
+	}
+	if !job.FinishedAt.IsZero() {
+		result.FinishedAt = &job.FinishedAt
+	}
+	if job.Err != nil {
+		result.Error = job.Err.Error()
+	}
+	for _, r := range job.Results {
+		states, err := data.FrameToJSON(r.States, data.IncludeAll)
+		if err != nil {
+			return ErrResp(500, err, "Failed to convert frame to JSON")
+		}
+		result.Results = append(result.Results, apimodels.BacktestRuleResult{
+			UID:    r.Rule.UID,
+			Title:  r.Rule.Title,
+			States: states,
+		})
+	}
+	return response.JSON(status, result)
+}
+
+// backtestNotifications tests the rule and simulates the notifications it would have sent with the current
+// notification policies, mute timings and templates of the organization.
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, folderTitle string, from, to time.Time) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+	amConfig, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), orgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager configuration")
+	}
+	cfg, err := notifier.Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to parse the Alertmanager configuration")
+	}
+	// the stored configuration does not have the routes of simplified routing, so they are generated as the
+	// Alertmanager does it, but only for the notification settings of the tested rule
+	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) {
+		err := notifier.AddAutogenConfig(c.Req.Context(), srv.log, ruleNotificationSettingsStore{rule: rule}, orgID, &cfg.AlertmanagerConfig, false)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "Failed to route the notifications of the rule")
+		}
+	}
+	am, err := srv.alertmanagers.AlertmanagerFor(orgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager of the organization")
+	}
+
+	result, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, from, to, backtesting.NotificationConfig{
+		Config:        &cfg.AlertmanagerConfig,
+		Templates:     am,
+		FolderTitle:   folderTitle,
+		IncludeFolder: !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
+	})
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	states, err := data.FrameToJSON(result, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, apimodels.BacktestNotificationsResult{
+		States:        states,
+		Notifications: toBacktestNotifications(notifications),
+	})
+}
+
+func toBacktestNotifications(notifications []backtesting.Notification) []apimodels.BacktestNotification {
+	labels := func(ls model.LabelSet) map[string]string {
+		result := make(map[string]string, len(ls))
+		for k, v := range ls {
+			result[string(k)] = string(v)
+		}
+		return result
+	}
+	result := make([]apimodels.BacktestNotification, 0, len(notifications))
+	for _, n := range notifications {
+		alerts := make([]apimodels.BacktestNotificationAlert, 0, len(n.Alerts))
+		for _, a := range n.Alerts {
+			alerts = append(alerts, apimodels.BacktestNotificationAlert{
+				Status:      string(a.Status),
+				Labels:      labels(a.Labels),
+				Annotations: labels(a.Annotations),
+				StartsAt:    a.StartsAt,
+				EndsAt:      a.EndsAt,
+			})
+		}
+		var integrations []apimodels.BacktestNotificationIntegration
+		for _, i := range n.Integrations {
+			integrations = append(integrations, apimodels.BacktestNotificationIntegration{
+				Name:   i.Name,
+				Type:   i.Type,
+				Fields: i.Fields,
+				Errors: i.Errors,
+			})
+		}
+		result = append(result, apimodels.BacktestNotification{
+			Time:         n.Time,
+			Receiver:     n.Receiver,
+			GroupKey:     n.GroupKey,
+			GroupLabels:  labels(n.GroupLabels),
+			Status:       string(n.Status),
+			Alerts:       alerts,
+			Integrations: integrations,
+		})
+	}
+	return result
+}
+
+// ruleNotificationSettingsStore provides the notification settings of a single rule to the autogenerated configuration.
+type ruleNotificationSettingsStore struct {
+	rule *ngmodels.AlertRule
+}
+
+func (s ruleNotificationSettingsStore) ListNotificationSettings(_ context.Context, _ ngmodels.ListNotificationSettingsQuery) (map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings, error) {
+	if len(s.rule.NotificationSettings) == 0 {
+		return nil, nil
+	}
+	return map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings{
+		s.rule.GetKey(): s.rule.NotificationSettings,
+	}, nil
+}
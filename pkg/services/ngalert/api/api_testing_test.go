@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -23,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -387,6 +389,132 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestRuleNotificationSettingsStore(t *testing.T) {
+	t.Run("should return notification settings of the rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithNotificationSettingsGen(models.NotificationSettingsGen()))()
+		settings, err := ruleNotificationSettingsStore{rule: rule}.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: rule.OrgID})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey][]models.NotificationSettings{rule.GetKey(): rule.NotificationSettings}, settings)
+	})
+
+	t.Run("should return nothing if the rule has no notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithNoNotificationSettings())()
+		settings, err := ruleNotificationSettingsStore{rule: rule}.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: rule.OrgID})
+		require.NoError(t, err)
+		require.Empty(t, settings)
+	})
+}
+
+func TestBacktestRuleGroup(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID:  1,
+			UserID: 1,
+		},
+	}
+	features := featuremgmt.WithManager(featuremgmt.FlagAlertingBacktesting)
+	from := time.Unix(0, 0)
+
+	f := randFolder()
+	query := models.GenerateAlertQuery()
+	groupKey := models.AlertRuleGroupKey{OrgID: rc.OrgID, NamespaceUID: f.UID, RuleGroup: "test-group"}
+	rules := models.GenerateAlertRules(2, models.AlertRuleGen(
+		models.WithGroupKey(groupKey),
+		models.WithQuery(query),
+		models.WithInterval(time.Minute),
+		func(rule *models.AlertRule) {
+			rule.Condition = query.RefID
+		},
+	))
+	newRuleStore := func() *fakes2.RuleStore {
+		ruleStore := fakes2.NewRuleStore(t)
+		ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+		ruleStore.PutRule(context.Background(), rules...)
+		return ruleStore
+	}
+
+	t.Run("should return NotFound if rule group does not exist", func(t *testing.T) {
+		ac := acMock.New().WithPermissions([]ac.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceAllScope()},
+		})
+		srv := createTestingApiSrv(t, nil, ac, eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), features, newRuleStore())
+
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestGroupConfig{
+			NamespaceUID: f.UID,
+			RuleGroup:    "unknown",
+			From:         from,
+			To:           from.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return Forbidden if user cannot query a data source", func(t *testing.T) {
+		srv := createTestingApiSrv(t, nil, acMock.New(), eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), features, newRuleStore())
+
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestGroupConfig{
+			NamespaceUID: f.UID,
+			RuleGroup:    groupKey.RuleGroup,
+			From:         from,
+			To:           from.Add(time.Hour),
+		})
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should return BadRequest if parallelism is invalid", func(t *testing.T) {
+		srv := createTestingApiSrv(t, nil, acMock.New(), eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{}), features, newRuleStore())
+
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestGroupConfig{
+			NamespaceUID: f.UID,
+			RuleGroup:    groupKey.RuleGroup,
+			From:         from,
+			To:           from.Add(time.Hour),
+			Parallelism:  backtesting.MaxParallelism + 1,
+		})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should start job and return its results", func(t *testing.T) {
+		ac := acMock.New().WithPermissions([]ac.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)},
+		})
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(eval.Results{}, nil)
+		srv := createTestingApiSrv(t, nil, ac, eval_mocks.NewEvaluatorFactory(evaluator), features, newRuleStore())
+
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestGroupConfig{
+			NamespaceUID: f.UID,
+			RuleGroup:    groupKey.RuleGroup,
+			From:         from,
+			To:           from.Add(10 * time.Minute),
+			Parallelism:  2,
+		})
+		require.Equal(t, http.StatusAccepted, response.Status())
+		var job definitions.BacktestJob
+		require.NoError(t, json.Unmarshal(response.Body(), &job))
+		require.Equal(t, 20, job.Total)
+
+		require.Eventually(t, func() bool {
+			response = srv.RouteGetBacktestJob(rc, job.ID)
+			require.Equal(t, http.StatusOK, response.Status())
+			require.NoError(t, json.Unmarshal(response.Body(), &job))
+			return job.State == definitions.BacktestJobCompleted
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, 20, job.Done)
+		require.Len(t, job.Results, 2)
+		require.NotNil(t, job.FinishedAt)
+
+		other := &contextmodel.ReqContext{
+			Context:      rc.Context,
+			SignedInUser: &user.SignedInUser{OrgID: 1, UserID: 2},
+		}
+		require.Equal(t, http.StatusNotFound, srv.RouteGetBacktestJob(other, job.ID).Status())
+		require.Equal(t, http.StatusNotFound, srv.RouteDeleteBacktestJob(other, job.ID).Status())
+	})
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory, featureManager *featuremgmt.FeatureManager, ruleStore RuleStore) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -400,5 +528,8 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 		tracer:          tracing.InitializeTracerForTest(),
 		featureManager:  featureManager,
 		folderService:   ruleStore,
+		ruleStore:       ruleStore,
+		backtesting:     backtesting.NewEngine(nil, evaluator, tracing.InitializeTracerForTest(), featureManager),
+		backtestJobs:    backtesting.NewJobs(),
 	}
 }
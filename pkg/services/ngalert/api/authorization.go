@@ -35,7 +35,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead, scope)
-	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}":
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/import":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
 		eval = ac.EvalAny(
@@ -94,6 +95,13 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodPost + "/api/v1/rule/backtest":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/v1/rule/backtest/group":
+		// additional authorization is done in the request handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/v1/rule/backtest/jobs/{JobID}",
+		http.MethodDelete + "/api/v1/rule/backtest/jobs/{JobID}":
+		// jobs are visible only to the user who started them
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 	case http.MethodPost + "/api/v1/eval":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
@@ -133,6 +141,19 @@ func (api *API) authorize(method, path string) web.Handler {
 		// additional authorization is done in the request handler
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingInstanceCreate), ac.EvalPermission(ac.ActionAlertingInstanceUpdate))
 
+	// Silence presets and recurring silences. Grafana Paths
+	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/silence-presets",
+		http.MethodGet + "/api/alertmanager/grafana/config/api/v1/recurring-silences":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/silence-presets",
+		http.MethodPost + "/api/alertmanager/grafana/config/api/v1/recurring-silences":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceCreate)
+	case http.MethodPut + "/api/alertmanager/grafana/config/api/v1/silence-presets/{UID}",
+		http.MethodDelete + "/api/alertmanager/grafana/config/api/v1/silence-presets/{UID}",
+		http.MethodPut + "/api/alertmanager/grafana/config/api/v1/recurring-silences/{UID}",
+		http.MethodDelete + "/api/alertmanager/grafana/config/api/v1/recurring-silences/{UID}":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate)
+
 	// Alert Instances. Grafana Paths
 	case http.MethodGet + "/api/alertmanager/grafana/api/v2/alerts/groups":
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
@@ -185,6 +206,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/receivers/test":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/routes/test":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/templates/test":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
 
@@ -212,7 +235,9 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodDelete + "/api/v1/ngalert/admin_config",
 		http.MethodGet + "/api/v1/ngalert/admin_config",
 		http.MethodPost + "/api/v1/ngalert/admin_config",
-		http.MethodGet + "/api/v1/ngalert/alertmanagers":
+		http.MethodGet + "/api/v1/ngalert/alertmanagers",
+		http.MethodGet + "/api/v1/ngalert/state/export",
+		http.MethodPost + "/api/v1/ngalert/state/import":
 		return middleware.ReqOrgAdmin
 
 	// Grafana-only Provisioning Read Paths
@@ -2,18 +2,24 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
-// AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
-func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
+// AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule.
+// It returns an error if the rule is a recording rule and recording rules are not enabled.
+func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule, features featuremgmt.FeatureToggles) (models.AlertRule, error) {
+	if a.Record != nil && !features.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) {
+		return models.AlertRule{}, fmt.Errorf("%w: recording rules are not enabled", models.ErrAlertRuleFailedValidation)
+	}
 	return models.AlertRule{
 		ID:                   a.ID,
 		UID:                  a.UID,
@@ -27,10 +33,12 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
+		Record:               RecordFromApiRecord(a.Record),
 	}, nil
 }
 
@@ -44,6 +52,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -54,6 +63,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
+		Record:               ApiRecordFromRecord(rule.Record),
 	}
 }
 
@@ -102,14 +112,14 @@ func ApiAlertQueriesFromAlertQueries(queries []models.AlertQuery) []definitions.
 	return result
 }
 
-func AlertRuleGroupFromApiAlertRuleGroup(a definitions.AlertRuleGroup) (models.AlertRuleGroup, error) {
+func AlertRuleGroupFromApiAlertRuleGroup(a definitions.AlertRuleGroup, features featuremgmt.FeatureToggles) (models.AlertRuleGroup, error) {
 	ruleGroup := models.AlertRuleGroup{
 		Title:     a.Title,
 		FolderUID: a.FolderUID,
 		Interval:  a.Interval,
 	}
 	for i := range a.Rules {
-		converted, err := AlertRuleFromProvisionedAlertRule(a.Rules[i])
+		converted, err := AlertRuleFromProvisionedAlertRule(a.Rules[i], features)
 		if err != nil {
 			return models.AlertRuleGroup{}, err
 		}
@@ -180,6 +190,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -188,10 +199,14 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState),
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsExportFromNotificationSettings(rule.NotificationSettings),
+		Record:               ApiRecordFromRecord(rule.Record),
 	}
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
@@ -434,3 +449,47 @@ func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.Alert
 		},
 	}
 }
+
+// ApiRecordFromRecord converts models.Record to definitions.Record. Returns nil if the rule is not a recording rule.
+func ApiRecordFromRecord(r models.Record) *definitions.Record {
+	if r.IsEmpty() {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// RecordFromApiRecord converts definitions.Record to models.Record
+func RecordFromApiRecord(r *definitions.Record) models.Record {
+	if r == nil {
+		return models.Record{}
+	}
+	return models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ApiRuleEvaluationStatsFromRuleEvaluationStats converts models.RuleEvaluationStats to definitions.RuleEvaluationStats
+func ApiRuleEvaluationStatsFromRuleEvaluationStats(s models.RuleEvaluationStats) *definitions.RuleEvaluationStats {
+	result := &definitions.RuleEvaluationStats{
+		TotalEvaluations: s.Total,
+		TotalErrors:      s.Errors,
+		DurationP50:      s.DurationPercentile(0.5).Seconds(),
+		DurationP95:      s.DurationPercentile(0.95).Seconds(),
+		Evaluations:      make([]definitions.RuleEvaluation, 0, len(s.Evaluations)),
+	}
+	for _, e := range s.Evaluations {
+		result.Evaluations = append(result.Evaluations, definitions.RuleEvaluation{
+			Timestamp:          e.EvaluatedAt,
+			Duration:           e.Duration.Seconds(),
+			DatasourceDuration: e.DatasourceDuration.Seconds(),
+			ExpressionDuration: e.ExpressionDuration.Seconds(),
+			Series:             e.Series,
+			Error:              e.Error,
+		})
+	}
+	return result
+}
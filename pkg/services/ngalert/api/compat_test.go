@@ -5,7 +5,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -15,7 +17,7 @@ func TestToModel(t *testing.T) {
 			FolderUID: "123",
 			Interval:  10,
 		}
-		tm, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup)
+		tm, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup, featuremgmt.WithFeatures())
 		require.NoError(t, err)
 		require.Nil(t, tm.Rules)
 	})
@@ -30,8 +32,27 @@ func TestToModel(t *testing.T) {
 				},
 			},
 		}
-		tm, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup)
+		tm, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup, featuremgmt.WithFeatures())
 		require.NoError(t, err)
 		require.Len(t, tm.Rules, 1)
 	})
+	t.Run("recording rules should be rejected if they are not enabled", func(t *testing.T) {
+		ruleGroup := definitions.AlertRuleGroup{
+			Title:     "123",
+			FolderUID: "123",
+			Interval:  10,
+			Rules: []definitions.ProvisionedAlertRule{
+				{
+					UID:    "1",
+					Record: &definitions.Record{Metric: "metric", From: "A"},
+				},
+			},
+		}
+		_, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup, featuremgmt.WithFeatures())
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		tm, err := AlertRuleGroupFromApiAlertRuleGroup(ruleGroup, featuremgmt.WithFeatures(featuremgmt.FlagGrafanaManagedRecordingRules))
+		require.NoError(t, err)
+		require.Equal(t, "metric", tm.Rules[0].Record.Metric)
+	})
 }
@@ -36,3 +36,11 @@ func (f *ConfigurationApiHandler) handleRouteDeleteNGalertConfig(c *contextmodel
 func (f *ConfigurationApiHandler) handleRouteGetStatus(c *contextmodel.ReqContext) response.Response {
 	return f.grafana.RouteGetAlertingStatus(c)
 }
+
+func (f *ConfigurationApiHandler) handleRouteGetAlertStateArchive(c *contextmodel.ReqContext) response.Response {
+	return f.grafana.RouteGetAlertStateArchive(c)
+}
+
+func (f *ConfigurationApiHandler) handleRoutePostAlertStateArchive(c *contextmodel.ReqContext, body apimodels.AlertStateArchive) response.Response {
+	return f.grafana.RoutePostAlertStateArchive(c, body)
+}
@@ -190,6 +190,42 @@ func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contex
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilencePresets(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSilencePresets(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencePreset(ctx *contextmodel.ReqContext, body apimodels.SilencePreset) response.Response {
+	return f.GrafanaSvc.RoutePostSilencePreset(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePutGrafanaSilencePreset(ctx *contextmodel.ReqContext, body apimodels.SilencePreset, uid string) response.Response {
+	return f.GrafanaSvc.RoutePutSilencePreset(ctx, body, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaSilencePreset(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteDeleteSilencePreset(ctx, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaRecurringSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetRecurringSilences(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaRecurringSilence(ctx *contextmodel.ReqContext, body apimodels.RecurringSilence) response.Response {
+	return f.GrafanaSvc.RoutePostRecurringSilence(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePutGrafanaRecurringSilence(ctx *contextmodel.ReqContext, body apimodels.RecurringSilence, uid string) response.Response {
+	return f.GrafanaSvc.RoutePutRecurringSilence(ctx, body, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaRecurringSilence(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteDeleteRecurringSilence(ctx, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaRoutes(ctx *contextmodel.ReqContext, conf apimodels.TestRoutesConfigBodyParams) response.Response {
+	return f.GrafanaSvc.RoutePostTestRoutes(ctx, conf)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaTemplates(ctx *contextmodel.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
 }
@@ -109,6 +109,10 @@ func (f *RulerApiHandler) handleRoutePostRulesGroupForExport(ctx *contextmodel.R
 	return f.GrafanaRuler.ExportFromPayload(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRoutePostRulesImport(ctx *contextmodel.ReqContext, conf apimodels.PrometheusRuleFile, namespace string) response.Response {
+	return f.GrafanaRuler.ImportFromPrometheus(ctx, conf, namespace)
+}
+
 func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.ExportRules(ctx)
 }
@@ -21,7 +21,10 @@ import (
 
 type TestingApi interface {
 	BacktestConfig(*contextmodel.ReqContext) response.Response
+	BacktestGroupConfig(*contextmodel.ReqContext) response.Response
+	RouteDeleteBacktestJob(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
+	RouteGetBacktestJob(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
 }
@@ -34,6 +37,19 @@ func (f *TestingApiHandler) BacktestConfig(ctx *contextmodel.ReqContext) respons
 	}
 	return f.handleBacktestConfig(ctx, conf)
 }
+func (f *TestingApiHandler) BacktestGroupConfig(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.BacktestGroupConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleBacktestGroupConfig(ctx, conf)
+}
+func (f *TestingApiHandler) RouteDeleteBacktestJob(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	jobIDParam := web.Params(ctx.Req)[":JobID"]
+	return f.handleRouteDeleteBacktestJob(ctx, jobIDParam)
+}
 func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EvalQueriesPayload{}
@@ -42,6 +58,11 @@ func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) respo
 	}
 	return f.handleRouteEvalQueries(ctx, conf)
 }
+func (f *TestingApiHandler) RouteGetBacktestJob(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	jobIDParam := web.Params(ctx.Req)[":JobID"]
+	return f.handleRouteGetBacktestJob(ctx, jobIDParam)
+}
 func (f *TestingApiHandler) RouteTestRuleConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -75,6 +96,30 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest/group"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/backtest/group"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest/group",
+				api.Hooks.Wrap(srv.BacktestGroupConfig),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/rule/backtest/jobs/{JobID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/v1/rule/backtest/jobs/{JobID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/rule/backtest/jobs/{JobID}",
+				api.Hooks.Wrap(srv.RouteDeleteBacktestJob),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -87,6 +132,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/rule/backtest/jobs/{JobID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/rule/backtest/jobs/{JobID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rule/backtest/jobs/{JobID}",
+				api.Hooks.Wrap(srv.RouteGetBacktestJob),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{DatasourceUID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -32,3 +32,15 @@ func (f *TestingApiHandler) handleRouteEvalQueries(c *contextmodel.ReqContext, b
 func (f *TestingApiHandler) handleBacktestConfig(ctx *contextmodel.ReqContext, conf apimodels.BacktestConfig) response.Response {
 	return f.svc.BacktestAlertRule(ctx, conf)
 }
+
+func (f *TestingApiHandler) handleBacktestGroupConfig(ctx *contextmodel.ReqContext, conf apimodels.BacktestGroupConfig) response.Response {
+	return f.svc.BacktestRuleGroup(ctx, conf)
+}
+
+func (f *TestingApiHandler) handleRouteGetBacktestJob(ctx *contextmodel.ReqContext, jobID string) response.Response {
+	return f.svc.RouteGetBacktestJob(ctx, jobID)
+}
+
+func (f *TestingApiHandler) handleRouteDeleteBacktestJob(ctx *contextmodel.ReqContext, jobID string) response.Response {
+	return f.svc.RouteDeleteBacktestJob(ctx, jobID)
+}
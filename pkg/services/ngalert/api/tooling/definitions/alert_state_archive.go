@@ -0,0 +1,86 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /v1/ngalert/state/export configuration RouteGetAlertStateArchive
+//
+// Export the state of the alert instances, and the silences and notification log of the Grafana Alertmanager of the user's organization.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertStateArchive
+//       404: NotFound
+
+// swagger:route POST /v1/ngalert/state/import configuration RoutePostAlertStateArchive
+//
+// Import an archive of alert state into the user's organization. Alert instances are assigned to the rules with the same UID, or with the same title, group and folder title.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertStateImportResult
+//       400: ValidationError
+//       404: NotFound
+
+// AlertStateArchiveVersion is the version of the format of AlertStateArchive.
+const AlertStateArchiveVersion = 1
+
+// swagger:parameters RoutePostAlertStateArchive
+type AlertStateArchiveParams struct {
+	// in:body
+	Body AlertStateArchive
+}
+
+// swagger:model
+type AlertStateArchive struct {
+	Version   int       `json:"version"`
+	OrgID     int64     `json:"orgId"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Rules of the alert instances, to assign the instances to rules when the rule UIDs are different.
+	Rules     []AlertStateArchiveRule     `json:"rules"`
+	Instances []AlertStateArchiveInstance `json:"instances"`
+
+	// Silences of the Alertmanager, in the snapshot format of the Alertmanager.
+	Silences []byte `json:"silences,omitempty"`
+	// Notification log of the Alertmanager, in the snapshot format of the Alertmanager.
+	NotificationLog []byte `json:"notificationLog,omitempty"`
+}
+
+type AlertStateArchiveRule struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	RuleGroup   string `json:"ruleGroup"`
+	FolderTitle string `json:"folderTitle"`
+}
+
+type AlertStateArchiveInstance struct {
+	RuleUID           string            `json:"ruleUid"`
+	Labels            map[string]string `json:"labels"`
+	State             string            `json:"state"`
+	Reason            string            `json:"reason,omitempty"`
+	StartsAt          time.Time         `json:"startsAt"`
+	EndsAt            time.Time         `json:"endsAt"`
+	LastEvaluatedAt   time.Time         `json:"lastEvaluatedAt"`
+	ResultFingerprint string            `json:"resultFingerprint,omitempty"`
+	// KeepFiringSince is the time since which the instance keeps firing although its condition is not met anymore.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
+}
+
+// swagger:model
+type AlertStateImportResult struct {
+	// Number of imported alert instances.
+	Instances int `json:"instances"`
+	// UIDs of the rules of the archive that do not match any rule of the organization. Their instances are not imported.
+	UnmatchedRules []string `json:"unmatchedRules,omitempty"`
+	// AlertmanagerState is true if silences or a notification log were imported.
+	AlertmanagerState bool `json:"alertmanagerState"`
+}
@@ -178,6 +178,20 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/routes/test alertmanager RoutePostTestGrafanaRoutes
+//
+// Test how the Grafana Alertmanager handles an alert with the given labels or the alerts of the given rule.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: TestRoutesResult
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -349,6 +363,94 @@ const (
 	ExecutionError  TemplateErrorKind = "execution_error"
 )
 
+// swagger:parameters RoutePostTestGrafanaRoutes
+type TestRoutesConfigParams struct {
+	// in:body
+	Body TestRoutesConfigBodyParams
+}
+
+type TestRoutesConfigBodyParams struct {
+	// Labels of the alert to route. Either labels or rule_uid must be set.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// UID of the alert rule whose labels, including the labels added by Grafana, are routed.
+	// Labels that contain templates are used as is.
+	RuleUID string `json:"rule_uid,omitempty"`
+
+	// Time at which mute timings and silences are evaluated. Defaults to the current time.
+	Time time.Time `json:"time,omitempty"`
+}
+
+// swagger:model
+type TestRoutesResult struct {
+	// Labels that were routed.
+	Labels map[string]string `json:"labels"`
+
+	// Time at which mute timings and silences were evaluated.
+	Time time.Time `json:"time"`
+
+	// Notification policies that handle the alert.
+	Routes []TestRoutesRoute `json:"routes"`
+
+	// Inhibition rules whose target matchers match the labels.
+	InhibitRules []TestRoutesInhibitRule `json:"inhibit_rules,omitempty"`
+
+	// Active silences that match the labels.
+	Silences []TestRoutesSilence `json:"silences,omitempty"`
+
+	// Silenced is true if at least one active silence matches the labels.
+	Silenced bool `json:"silenced"`
+
+	// Inhibited is true if at least one firing alert inhibits the labels.
+	Inhibited bool `json:"inhibited"`
+}
+
+type TestRoutesRoute struct {
+	// Path from the root of the policy tree to the matched policy.
+	Path []TestRoutesPolicy `json:"path"`
+
+	// Effective options of the matched policy, including the ones inherited from its parents.
+	Receiver          string            `json:"receiver"`
+	GroupBy           []string          `json:"group_by,omitempty"`
+	GroupLabels       map[string]string `json:"group_labels"`
+	GroupWait         model.Duration    `json:"group_wait"`
+	GroupInterval     model.Duration    `json:"group_interval"`
+	RepeatInterval    model.Duration    `json:"repeat_interval"`
+	MuteTimeIntervals []string          `json:"mute_time_intervals,omitempty"`
+
+	// Mute timings of the policy that are active at the given time.
+	ActiveMuteTimeIntervals []string `json:"active_mute_time_intervals,omitempty"`
+
+	// Muted is true if notifications of the policy are muted at the given time.
+	Muted bool `json:"muted"`
+}
+
+type TestRoutesPolicy struct {
+	// Index of the policy among the routes of its parent. The root policy has index 0.
+	Index    int      `json:"index"`
+	Receiver string   `json:"receiver,omitempty"`
+	Matchers []string `json:"matchers,omitempty"`
+	Continue bool     `json:"continue"`
+}
+
+type TestRoutesInhibitRule struct {
+	SourceMatchers []string `json:"source_matchers,omitempty"`
+	TargetMatchers []string `json:"target_matchers,omitempty"`
+	Equal          []string `json:"equal,omitempty"`
+
+	// Labels of the firing alerts that inhibit the given labels.
+	InhibitingAlerts []map[string]string `json:"inhibiting_alerts,omitempty"`
+}
+
+type TestRoutesSilence struct {
+	ID        string    `json:"id"`
+	Matchers  []string  `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -71,6 +71,19 @@ import (
 //       403: ForbiddenError
 //       404: description: Not found.
 
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostRulesImport
+//
+// Converts the rule groups of a Prometheus rule file to Grafana-managed rules and saves them to the folder
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: ImportRulesResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
 // swagger:route POST /ruler/{DatasourceUID}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
 // Creates or updates a rule group
@@ -173,6 +186,23 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostRulesImport
+type ImportRulesParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// The UID of the Prometheus data source that the queries of the rules are bound to
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUid"`
+	// Calculate the changes without saving them
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+	// in:body
+	Body PrometheusRuleFile
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// The UID of the rule folder
@@ -460,6 +490,7 @@ type PostableGrafanaRule struct {
 	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
+	Record               *Record                        `json:"record" yaml:"record"`
 }
 
 // swagger:model
@@ -480,6 +511,23 @@ type GettableGrafanaRule struct {
 	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	// Statistics of the recent evaluations of the rule. Only set if requested with the query parameter include_evaluation_stats
+	// and the rule has been evaluated by the Grafana instance that serves the request.
+	EvaluationStats *RuleEvaluationStats `json:"evaluation_stats,omitempty" yaml:"-"`
+}
+
+// Record defines how the results of a recording rule are written.
+// swagger:model
+type Record struct {
+	// Name of the metric that the results are written to.
+	// required: true
+	// example: grafana_alerts_ratio
+	Metric string `json:"metric" yaml:"metric" hcl:"metric"`
+	// RefID of the query or expression whose results are written.
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from" hcl:"from"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -556,3 +604,41 @@ type UpdateRuleGroupResponse struct {
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
 }
+
+// PrometheusRuleFile is the content of a Prometheus rule file.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRuleGroup is a rule group of a Prometheus rule file.
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type ImportRulesResponse struct {
+	Message string `json:"message"`
+	// DryRun is true if the changes were calculated but not saved.
+	DryRun bool                `json:"dryRun"`
+	Groups []ImportedRuleGroup `json:"groups"`
+}
+
+// ImportedRuleGroup describes the changes that the import makes to a rule group.
+type ImportedRuleGroup struct {
+	Name    string         `json:"name"`
+	Created []ImportedRule `json:"created,omitempty"`
+	Updated []ImportedRule `json:"updated,omitempty"`
+	Deleted []ImportedRule `json:"deleted,omitempty"`
+}
+
+// ImportedRule identifies a rule that is created, updated or deleted by the import.
+type ImportedRule struct {
+	// UID is empty for rules that are created in dry-run mode.
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// Diff contains the paths of the fields that are changed by the update.
+	Diff []string `json:"diff,omitempty"`
+}
@@ -0,0 +1,45 @@
+package definitions
+
+import "time"
+
+// swagger:parameters RouteGetGrafanaRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteGetGrafanaRuleGroupConfig
+type GetGrafanaRulesConfigParams struct {
+	// Include the statistics of the recent evaluations of the rules.
+	// in: query
+	// required: false
+	// default: false
+	IncludeEvaluationStats bool `json:"include_evaluation_stats"`
+}
+
+// RuleEvaluationStats are the statistics of the recent evaluations of a rule by the Grafana instance that serves the
+// request. They are reset when the rule is deleted or the instance restarts, and are not shared between the instances
+// of a high availability setup.
+// swagger:model
+type RuleEvaluationStats struct {
+	// Number of evaluations since the rule was scheduled, including retries.
+	TotalEvaluations int64 `json:"totalEvaluations"`
+	// Number of failed evaluations since the rule was scheduled.
+	TotalErrors int64 `json:"totalErrors"`
+	// 50th percentile of the duration of the recent evaluations, in seconds.
+	DurationP50 float64 `json:"durationP50"`
+	// 95th percentile of the duration of the recent evaluations, in seconds.
+	DurationP95 float64 `json:"durationP95"`
+	// The most recent evaluations, the oldest first.
+	Evaluations []RuleEvaluation `json:"evaluations"`
+}
+
+// RuleEvaluation describes a single evaluation of a rule.
+// swagger:model
+type RuleEvaluation struct {
+	// The time the evaluation was scheduled at.
+	Timestamp time.Time `json:"timestamp"`
+	// Duration of the evaluation, in seconds.
+	Duration float64 `json:"duration"`
+	// Time spent in the queries to datasources, in seconds.
+	DatasourceDuration float64 `json:"datasourceDuration"`
+	// Time spent in the expressions, in seconds.
+	ExpressionDuration float64 `json:"expressionDuration"`
+	// Number of series returned by the evaluation.
+	Series int    `json:"series"`
+	Error  string `json:"error,omitempty"`
+}
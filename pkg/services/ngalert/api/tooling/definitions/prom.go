@@ -131,6 +131,8 @@ type AlertingRule struct {
 	// required: true
 	Query    string  `json:"query,omitempty"`
 	Duration float64 `json:"duration,omitempty"`
+	// KeepFiringFor is the number of seconds an alert keeps firing after the condition is not met anymore.
+	KeepFiringFor float64 `json:"keepFiringFor,omitempty"`
 	// required: true
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
@@ -156,6 +158,9 @@ type Rule struct {
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
 	EvaluationTime float64     `json:"evaluationTime"`
+	// Statistics of the recent evaluations of the rule. Only set if requested with the query parameter includeEvaluationStats
+	// and the rule has been evaluated by the Grafana instance that serves the request.
+	EvaluationStats *RuleEvaluationStats `json:"evaluationStats,omitempty"`
 }
 
 // Alert has info for an alert.
@@ -168,6 +173,8 @@ type Alert struct {
 	// required: true
 	State    string     `json:"state"`
 	ActiveAt *time.Time `json:"activeAt"`
+	// KeepFiringSince is set if the alert keeps firing even though the condition is not met anymore.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
 	// required: true
 	Value string `json:"value"`
 }
@@ -303,4 +310,10 @@ type GetGrafanaRuleStatusesParams struct {
 	// in: query
 	// required: false
 	PanelID int64
+
+	// Include the statistics of the recent evaluations of the rules.
+	// in: query
+	// required: false
+	// default: false
+	IncludeEvaluationStats bool `json:"includeEvaluationStats"`
 }
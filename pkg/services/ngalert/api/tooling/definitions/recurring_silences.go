@@ -0,0 +1,129 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+)
+
+// swagger:route GET /alertmanager/grafana/config/api/v1/silence-presets alertmanager RouteGetGrafanaSilencePresets
+//
+// Get the silence presets of the Grafana Alertmanager.
+//
+//     Responses:
+//       200: SilencePresets
+
+// swagger:route POST /alertmanager/grafana/config/api/v1/silence-presets alertmanager RoutePostGrafanaSilencePreset
+//
+// Create a silence preset.
+//
+//     Responses:
+//       201: SilencePreset
+//       400: ValidationError
+
+// swagger:route PUT /alertmanager/grafana/config/api/v1/silence-presets/{UID} alertmanager RoutePutGrafanaSilencePreset
+//
+// Update a silence preset. The silences of the recurring silences that use the preset are expired and created again with the new matchers.
+//
+//     Responses:
+//       200: SilencePreset
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /alertmanager/grafana/config/api/v1/silence-presets/{UID} alertmanager RouteDeleteGrafanaSilencePreset
+//
+// Delete a silence preset. A preset that is used by a recurring silence cannot be deleted.
+//
+//     Responses:
+//       204: description: The silence preset was deleted.
+//       404: NotFound
+//       409: GenericPublicError
+
+// swagger:route GET /alertmanager/grafana/config/api/v1/recurring-silences alertmanager RouteGetGrafanaRecurringSilences
+//
+// Get the recurring silences of the Grafana Alertmanager.
+//
+//     Responses:
+//       200: RecurringSilences
+
+// swagger:route POST /alertmanager/grafana/config/api/v1/recurring-silences alertmanager RoutePostGrafanaRecurringSilence
+//
+// Create a recurring silence. The Grafana Alertmanager creates a silence for each occurrence that starts within the next 24 hours.
+//
+//     Responses:
+//       201: RecurringSilence
+//       400: ValidationError
+
+// swagger:route PUT /alertmanager/grafana/config/api/v1/recurring-silences/{UID} alertmanager RoutePutGrafanaRecurringSilence
+//
+// Update a recurring silence. The silences of the recurring silence that have not ended yet are expired and created again.
+//
+//     Responses:
+//       200: RecurringSilence
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /alertmanager/grafana/config/api/v1/recurring-silences/{UID} alertmanager RouteDeleteGrafanaRecurringSilence
+//
+// Delete a recurring silence and expire its silences that have not ended yet.
+//
+//     Responses:
+//       204: description: The recurring silence was deleted.
+//       404: NotFound
+
+// swagger:parameters RoutePutGrafanaSilencePreset RouteDeleteGrafanaSilencePreset RoutePutGrafanaRecurringSilence RouteDeleteGrafanaRecurringSilence
+type SilenceScheduleUIDParam struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostGrafanaSilencePreset RoutePutGrafanaSilencePreset
+type SilencePresetParams struct {
+	// in:body
+	Body SilencePreset
+}
+
+// swagger:parameters RoutePostGrafanaRecurringSilence RoutePutGrafanaRecurringSilence
+type RecurringSilenceParams struct {
+	// in:body
+	Body RecurringSilence
+}
+
+// swagger:model
+type SilencePresets []SilencePreset
+
+// SilencePreset is a reusable set of silence matchers.
+// swagger:model
+type SilencePreset struct {
+	UID      string        `json:"uid"`
+	Name     string        `json:"name"`
+	Comment  string        `json:"comment,omitempty"`
+	Matchers amv2.Matchers `json:"matchers"`
+}
+
+// swagger:model
+type RecurringSilences []RecurringSilence
+
+// RecurringSilence is a silence that repeats during the given time intervals.
+// swagger:model
+type RecurringSilence struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	Comment   string `json:"comment,omitempty"`
+	CreatedBy string `json:"createdBy"`
+	// Matchers of the silences. Either matchers or presetUid must be set.
+	Matchers amv2.Matchers `json:"matchers,omitempty"`
+	// UID of the silence preset whose matchers are used.
+	PresetUID string `json:"presetUid,omitempty"`
+	// Time intervals during which the silences are active, in the same format as the time intervals of mute timings.
+	TimeIntervals []timeinterval.TimeInterval `json:"time_intervals"`
+	// Silences that were created for the current and upcoming occurrences. Read-only.
+	Silences []RecurringSilenceOccurrence `json:"silences,omitempty"`
+}
+
+type RecurringSilenceOccurrence struct {
+	SilenceID string    `json:"silenceId"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+}
@@ -67,6 +67,43 @@ import (
 //
 //     Responses:
 //       200: BacktestResult
+//       202: BacktestJob
+
+// swagger:route Post /v1/rule/backtest/group testing BacktestGroupConfig
+//
+// Test all rules of a rule group in the background
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: BacktestJob
+//       404: NotFound
+
+// swagger:route Get /v1/rule/backtest/jobs/{JobID} testing RouteGetBacktestJob
+//
+// Get the progress and results of a backtesting job
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestJob
+//       404: NotFound
+
+// swagger:route Delete /v1/rule/backtest/jobs/{JobID} testing RouteDeleteBacktestJob
+//
+// Cancel a backtesting job
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestJob
+//       404: NotFound
 
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
@@ -230,7 +267,115 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// NamespaceUID is the UID of the folder of the rule. The folder title is added to the alerts of the rule in the
+	// simulation of notifications.
+	NamespaceUID string `json:"namespace_uid,omitempty"`
+	// NotificationSettings are the notification settings of the rule that are used to route its alerts in the simulation
+	// of notifications if simplified routing is enabled.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
+
+	// SimulateNotifications enables the simulation of the notification pipeline. If it is true, the response
+	// is BacktestNotificationsResult that has the notifications the rule would have sent in addition to the states.
+	SimulateNotifications bool `json:"simulate_notifications,omitempty"`
+
+	// Parallelism is the maximum number of evaluations that run concurrently. Default is 1.
+	Parallelism int `json:"parallelism,omitempty"`
+	// Async runs the backtesting in the background. If it is true, the response is BacktestJob that can be used to
+	// follow the progress of the backtesting and get its results.
+	Async bool `json:"async,omitempty"`
+}
+
+// swagger:parameters BacktestGroupConfig
+type BacktestGroupConfigRequest struct {
+	// in:body
+	Body BacktestGroupConfig
+}
+
+// swagger:model
+type BacktestGroupConfig struct {
+	// example: okrd3I0Vz
+	NamespaceUID string `json:"folderUid"`
+	// example: project_x
+	RuleGroup string    `json:"ruleGroup"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+
+	// Parallelism is the maximum number of evaluations of a rule that run concurrently. Default is 1.
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// swagger:parameters RouteGetBacktestJob RouteDeleteBacktestJob
+type BacktestJobParams struct {
+	// in:path
+	JobID string
+}
+
+// swagger:enum BacktestJobState
+type BacktestJobState string
+
+const (
+	BacktestJobRunning   BacktestJobState = "running"
+	BacktestJobCompleted BacktestJobState = "completed"
+	BacktestJobFailed    BacktestJobState = "failed"
+	BacktestJobCancelled BacktestJobState = "cancelled"
+)
+
+// swagger:model
+type BacktestJob struct {
+	ID    string           `json:"id"`
+	State BacktestJobState `json:"state"`
+	// Total is the total number of evaluations of the job.
+	Total int `json:"total"`
+	// Done is the number of evaluations that are done.
+	Done       int        `json:"done"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// Results are set when the job is completed.
+	Results []BacktestRuleResult `json:"results,omitempty"`
+}
+
+type BacktestRuleResult struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// States is the data frame of states of the alerts of the rule, the same as BacktestResult.
+	States json.RawMessage `json:"states"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the data frame of states of the alerts, the same as BacktestResult.
+	States json.RawMessage `json:"states"`
+	// Notifications are the notifications that would have been sent, in the order they would have been sent.
+	Notifications []BacktestNotification `json:"notifications"`
+}
+
+type BacktestNotification struct {
+	Time        time.Time                   `json:"time"`
+	Receiver    string                      `json:"receiver"`
+	GroupKey    string                      `json:"groupKey"`
+	GroupLabels map[string]string           `json:"groupLabels"`
+	Status      string                      `json:"status"`
+	Alerts      []BacktestNotificationAlert `json:"alerts"`
+	// Integrations are the integrations of the receiver with their templated settings rendered for the notification.
+	Integrations []BacktestNotificationIntegration `json:"integrations,omitempty"`
+}
+
+type BacktestNotificationIntegration struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Fields map[string]string `json:"fields"`
+	Errors []string          `json:"errors,omitempty"`
+}
+
+type BacktestNotificationAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
@@ -6,15 +6,18 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/benbjohnson/clock"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
@@ -42,11 +45,15 @@ type stateManager interface {
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	appUrl             *url.URL
+	features           featuremgmt.FeatureToggles
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer, features featuremgmt.FeatureToggles) *Engine {
 	return &Engine{
 		evalFactory: evalFactory,
+		appUrl:      appUrl,
+		features:    features,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -63,7 +70,75 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 	}
 }
 
-func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+// MaxParallelism is the maximum number of concurrent evaluations of a rule that can be requested.
+const MaxParallelism = 16
+
+// TestOptions are the options of backtesting.
+type TestOptions struct {
+	// Parallelism is the maximum number of evaluations of a rule that run concurrently. Values less than 2 mean that
+	// evaluations run sequentially. It is ignored for rules that use the results of previous evaluations, e.g. hysteresis,
+	// and by TestGroup for groups of more than one rule, which rules are evaluated one after another.
+	Parallelism int
+	// OnProgress, if set, is called after each evaluation of a rule.
+	OnProgress func()
+}
+
+// RuleResult is the result of backtesting of a single rule of a group.
+type RuleResult struct {
+	Rule   *models.AlertRule
+	States *data.Frame
+}
+
+func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, opts TestOptions) (*data.Frame, error) {
+	return e.test(ctx, user, rule, from, to, testOptions{TestOptions: opts})
+}
+
+// Evaluations returns the number of evaluations of the rule in the interval [from, to).
+func Evaluations(rule *models.AlertRule, from, to time.Time) int {
+	if rule.IntervalSeconds <= 0 || !from.Before(to) {
+		return 0
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
+}
+
+// TestNotifications tests the rule like Test, and also simulates the notification pipeline for the alerts of the rule.
+// The alerts are routed by the notification policy tree of the configuration, and the notifications that would have
+// been sent to receivers are returned in the order they would have been sent. The alerts have the labels that
+// Grafana adds to all alerts of a rule, such as alertname, the folder title and the labels of the notification settings
+// of the rule, so they are routed like the alerts of a saved rule.
+func (e *Engine) TestNotifications(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, cfg NotificationConfig) (*data.Frame, []Notification, error) {
+	simulator, err := newNotificationSimulator(cfg.Config, e.appUrl)
+	if err != nil {
+		return nil, nil, errors.Join(ErrInvalidInputData, err)
+	}
+
+	result, err := e.test(ctx, user, rule, from, to, testOptions{
+		extraLabels:   state.GetRuleExtraLabels(logger, rule, cfg.FolderTitle, cfg.IncludeFolder),
+		onTransitions: simulator.Put,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	simulator.FlushUntil(to)
+
+	notifications := simulator.Notifications()
+	if cfg.Templates != nil {
+		for i := range notifications {
+			if err := renderNotification(ctx, cfg.Templates, simulator.Receiver(notifications[i].Receiver), &notifications[i]); err != nil {
+				return nil, nil, fmt.Errorf("failed to render notification: %w", err)
+			}
+		}
+	}
+	return result, notifications, nil
+}
+
+type testOptions struct {
+	TestOptions
+	extraLabels   data.Labels
+	onTransitions func(now time.Time, transitions []state.StateTransition)
+}
+
+func (e *Engine) test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, opts testOptions) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -73,64 +148,113 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
 		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
+	length := Evaluations(rule, from, to)
 
 	stateManager := e.createStateManager()
 
 	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition(), &schedule.AlertingResultsFromRuleState{
 		Manager: stateManager,
 		Rule:    rule,
-	})
+	}, opts.Parallelism, e.features)
 	if err != nil {
 		return nil, errors.Join(ErrInvalidInputData, err)
 	}
 
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length, "parallelism", opts.Parallelism)
 
 	start := time.Now()
 
-	tsField := data.NewField("Time", nil, make([]time.Time, length))
-	valueFields := make(map[string]*data.Field)
+	frame := newStatesFrame(length)
 
 	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
 		if idx >= length {
 			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
 			return nil
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
-		tsField.Set(idx, currentTime)
-		for _, s := range states {
-			field, ok := valueFields[s.CacheID]
-			if !ok {
-				field = data.NewField("", s.Labels, make([]*string, length))
-				valueFields[s.CacheID] = field
-			}
-			if s.State.State != eval.NoData { // set nil if NoData
-				value := s.State.State.String()
-				if s.StateReason != "" {
-					value += " (" + s.StateReason + ")"
-				}
-				field.Set(idx, &value)
-				continue
-			}
+		if err := ruleCtx.Err(); err != nil {
+			return err
+		}
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, opts.extraLabels)
+		if opts.onTransitions != nil {
+			opts.onTransitions(currentTime, states)
+		}
+		frame.setStates(idx, currentTime, states)
+		if opts.OnProgress != nil {
+			opts.OnProgress()
 		}
 		return nil
 	})
-	fields := make([]*data.Field, 0, len(valueFields)+1)
-	fields = append(fields, tsField)
-	for _, f := range valueFields {
-		fields = append(fields, f)
-	}
-	result := data.NewFrame("Testing results", fields...)
-
 	if err != nil {
 		return nil, err
 	}
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
-	return result, nil
+	return frame.Frame(), nil
+}
+
+// statesFrame collects the state of the alert instances of a rule at each evaluation into a data frame with a time
+// field and a field per alert instance.
+type statesFrame struct {
+	length int
+	time   *data.Field
+	values map[string]*data.Field
 }
 
-func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
+func newStatesFrame(length int) *statesFrame {
+	return &statesFrame{
+		length: length,
+		time:   data.NewField("Time", nil, make([]time.Time, length)),
+		values: make(map[string]*data.Field),
+	}
+}
+
+// setStates sets the states of the alert instances at the evaluation idx. Instances that have no data get no value.
+func (f *statesFrame) setStates(idx int, now time.Time, states []state.StateTransition) {
+	f.time.Set(idx, now)
+	for _, s := range states {
+		field := f.field(s.CacheID, s.Labels)
+		if s.State.State != eval.NoData { // set nil if NoData
+			value := s.State.State.String()
+			if s.StateReason != "" {
+				value += " (" + s.StateReason + ")"
+			}
+			field.Set(idx, &value)
+		}
+	}
+}
+
+// setRecorded sets the values that a recording rule recorded at the evaluation idx. The labels of the rule are added
+// to the labels of the series, as they are when the values are written.
+func (f *statesFrame) setRecorded(idx int, now time.Time, instances []expr.AlertStateInstance, ruleLabels map[string]string) {
+	f.time.Set(idx, now)
+	for _, instance := range instances {
+		labels := instance.Labels.Copy()
+		for k, v := range ruleLabels {
+			labels[k] = v
+		}
+		value := strconv.FormatFloat(*instance.Value, 'f', -1, 64)
+		f.field(labels.String(), labels).Set(idx, &value)
+	}
+}
+
+func (f *statesFrame) field(id string, labels data.Labels) *data.Field {
+	field, ok := f.values[id]
+	if !ok {
+		field = data.NewField("", labels, make([]*string, f.length))
+		f.values[id] = field
+	}
+	return field
+}
+
+func (f *statesFrame) Frame() *data.Frame {
+	fields := make([]*data.Field, 0, len(f.values)+1)
+	fields = append(fields, f.time)
+	for _, field := range f.values {
+		fields = append(fields, field)
+	}
+	return data.NewFrame("Testing results", fields...)
+}
+
+func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader, parallelism int, features featuremgmt.FeatureToggles) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
 			if len(condition.Data) != 1 {
@@ -162,11 +286,27 @@ func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFact
 		return nil, err
 	}
 
+	// queries that depend on the results of the previous evaluation cannot be evaluated concurrently
+	if parallelism > 1 && usesPreviousResults(condition, features) {
+		parallelism = 1
+	}
+
 	return &queryEvaluator{
-		eval: evaluator,
+		eval:        evaluator,
+		parallelism: parallelism,
 	}, nil
 }
 
+func usesPreviousResults(condition models.Condition, features featuremgmt.FeatureToggles) bool {
+	for _, q := range condition.Data {
+		// consider the query as hysteresis if it cannot be parsed. The evaluator will fail anyway.
+		if ok, err := q.IsHysteresisExpression(features); ok || err != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // NoopImageService is a no-op image service.
 type NoopImageService struct{}
 
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -145,7 +146,7 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
-				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition, nil)
+				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition, nil, 1, featuremgmt.WithFeatures())
 				if testCase.error {
 					require.Error(t, err)
 					return
@@ -155,6 +156,59 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("creates query evaluator", func(t *testing.T) {
+		evalFactory := eval_mocks.NewEvaluatorFactory(&eval_mocks.ConditionEvaluatorMock{})
+		query := models.AlertQuery{
+			RefID:         "A",
+			DatasourceUID: util.GenerateShortUID(),
+			Model:         json.RawMessage(`{ "expr": "up" }`),
+		}
+		hysteresis := models.AlertQuery{
+			RefID:         "B",
+			DatasourceUID: "__expr__",
+			Model:         json.RawMessage(`{ "type": "math", "expression": "$A > 10", "unloadExpression": "$A < 5" }`),
+		}
+
+		testCases := []struct {
+			name                string
+			condition           models.Condition
+			parallelism         int
+			features            featuremgmt.FeatureToggles
+			expectedParallelism int
+		}{
+			{
+				name:                "with requested parallelism",
+				condition:           models.Condition{Condition: "A", Data: []models.AlertQuery{query}},
+				parallelism:         4,
+				features:            featuremgmt.WithFeatures(),
+				expectedParallelism: 4,
+			},
+			{
+				name:                "without parallelism if condition uses results of previous evaluation",
+				condition:           models.Condition{Condition: "B", Data: []models.AlertQuery{query, hysteresis}},
+				parallelism:         4,
+				features:            featuremgmt.WithFeatures(featuremgmt.FlagRecoveryConditions),
+				expectedParallelism: 1,
+			},
+			{
+				name:                "with requested parallelism if recovery conditions are disabled",
+				condition:           models.Condition{Condition: "B", Data: []models.AlertQuery{query, hysteresis}},
+				parallelism:         4,
+				features:            featuremgmt.WithFeatures(),
+				expectedParallelism: 4,
+			},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition, nil, testCase.parallelism, testCase.features)
+				require.NoError(t, err)
+				require.IsType(t, &queryEvaluator{}, e)
+				require.Equal(t, testCase.expectedParallelism, e.(*queryEvaluator).parallelism)
+			})
+		}
+	})
 }
 
 func TestEvaluatorTest(t *testing.T) {
@@ -175,7 +229,7 @@ func TestEvaluatorTest(t *testing.T) {
 	}
 	manager := &fakeStateManager{}
 
-	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader, _ int, _ featuremgmt.FeatureToggles) (backtestingEvaluator, error) {
 		return evaluator, nil
 	}
 
@@ -214,7 +268,7 @@ func TestEvaluatorTest(t *testing.T) {
 			return states
 		}
 
-		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		frame, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 
 		require.NoError(t, err)
 		require.Len(t, frame.Fields, len(states)+1) // +1 - timestamp
@@ -283,12 +337,12 @@ func TestEvaluatorTest(t *testing.T) {
 			return states
 		}
 
-		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		frame, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 		require.NoError(t, err)
 		expectedLen := frame.Rows()
 		for i := 0; i < 100; i++ {
 			jitter := time.Duration(rand.Int63n(ruleInterval.Milliseconds())) * time.Millisecond
-			frame, err = engine.Test(context.Background(), nil, rule, from, to.Add(jitter))
+			frame, err = engine.Test(context.Background(), nil, rule, from, to.Add(jitter), TestOptions{})
 			require.NoError(t, err)
 			require.Equalf(t, expectedLen, frame.Rows(), "jitter %v caused result to be different that base-line", jitter)
 		}
@@ -319,7 +373,7 @@ func TestEvaluatorTest(t *testing.T) {
 			return stateByTime[now]
 		}
 
-		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		frame, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 		require.NoError(t, err)
 
 		var field3 *data.Field
@@ -341,6 +395,18 @@ func TestEvaluatorTest(t *testing.T) {
 		}
 	})
 
+	t.Run("should stop if context is cancelled", func(t *testing.T) {
+		evaluator.evalCallback = randomResultCallback
+		manager.stateCallback = func(now time.Time) []state.StateTransition {
+			return nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		from := time.Unix(0, 0)
+		_, err := engine.Test(ctx, nil, rule, from, from.Add(5*ruleInterval), TestOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
 	t.Run("should fail", func(t *testing.T) {
 		manager.stateCallback = func(now time.Time) []state.StateTransition {
 			return nil
@@ -350,17 +416,17 @@ func TestEvaluatorTest(t *testing.T) {
 			from := time.Now()
 			t.Run("when from=to", func(t *testing.T) {
 				to := from
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 			t.Run("when from > to", func(t *testing.T) {
 				to := from.Add(-ruleInterval)
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 			t.Run("when to-from < interval", func(t *testing.T) {
 				to := from.Add(ruleInterval).Add(-time.Millisecond)
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 		})
@@ -372,7 +438,7 @@ func TestEvaluatorTest(t *testing.T) {
 			}
 			from := time.Now()
 			to := from.Add(ruleInterval)
-			_, err := engine.Test(context.Background(), nil, rule, from, to)
+			_, err := engine.Test(context.Background(), nil, rule, from, to, TestOptions{})
 			require.ErrorIs(t, err, expectedError)
 		})
 	})
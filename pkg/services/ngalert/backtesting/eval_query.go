@@ -4,24 +4,58 @@ import (
 	"context"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 )
 
 // QueryEvaluator is evaluator of regular alert rule queries
 type queryEvaluator struct {
 	eval eval.ConditionEvaluator
+	// parallelism is the maximum number of evaluations that run concurrently. Values less than 2 mean sequential evaluation.
+	parallelism int
 }
 
 func (d *queryEvaluator) Eval(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error {
-	for idx, now := 0, from; idx < evaluations; idx, now = idx+1, now.Add(interval) {
-		results, err := d.eval.Evaluate(ctx, now)
-		if err != nil {
-			return err
+	if d.parallelism <= 1 {
+		for idx, now := 0, from; idx < evaluations; idx, now = idx+1, now.Add(interval) {
+			results, err := d.eval.Evaluate(ctx, now)
+			if err != nil {
+				return err
+			}
+			err = callback(idx, now, results)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Evaluations are independent of each other and therefore can run concurrently.
+	// However, the results must be processed in order because the state of alerts depends on the previous state.
+	// Therefore, evaluate a batch of consecutive timestamps concurrently and then pass the results to the callback in order.
+	results := make([]eval.Results, d.parallelism)
+	for batchStart := 0; batchStart < evaluations; batchStart += d.parallelism {
+		batchSize := min(d.parallelism, evaluations-batchStart)
+		g, gCtx := errgroup.WithContext(ctx)
+		for i := 0; i < batchSize; i++ {
+			i := i
+			now := from.Add(time.Duration(batchStart+i) * interval)
+			g.Go(func() error {
+				r, err := d.eval.Evaluate(gCtx, now)
+				results[i] = r
+				return err
+			})
 		}
-		err = callback(idx, now, results)
-		if err != nil {
+		if err := g.Wait(); err != nil {
 			return err
 		}
+		for i := 0; i < batchSize; i++ {
+			idx := batchStart + i
+			if err := callback(idx, from.Add(time.Duration(idx)*interval), results[i]); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -49,6 +49,34 @@ func TestQueryEvaluator_Eval(t *testing.T) {
 		}
 	})
 
+	t.Run("should evaluate query concurrently and call callback in order", func(t *testing.T) {
+		m := &eval_mocks.ConditionEvaluatorMock{}
+		m.On("Evaluate", mock.Anything, mock.Anything).Return(func(_ context.Context, now time.Time) eval.Results {
+			return eval.Results{{EvaluatedAt: now}}
+		}, nil)
+		evaluator := queryEvaluator{
+			eval:        m,
+			parallelism: 4,
+		}
+
+		intervals := make([]time.Time, 0, times)
+		err := evaluator.Eval(ctx, from, interval, times, func(idx int, now time.Time, results eval.Results) error {
+			require.Len(t, intervals, idx)
+			require.Equal(t, now, results[0].EvaluatedAt)
+			intervals = append(intervals, now)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, intervals, times)
+
+		expected := from
+		for idx, actual := range intervals {
+			assert.Equalf(t, expected, actual, "item at index %d is not times of interval %v", idx, interval)
+			expected = expected.Add(interval)
+		}
+		m.AssertNumberOfCalls(t, "Evaluate", times)
+	})
+
 	t.Run("should stop evaluation if error", func(t *testing.T) {
 		t.Run("when evaluation fails", func(t *testing.T) {
 			m := &eval_mocks.ConditionEvaluatorMock{}
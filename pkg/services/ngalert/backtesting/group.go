@@ -0,0 +1,206 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+// TestGroup tests all rules of the group over the same interval. At each evaluation time, the rules are evaluated one
+// after another as the scheduler does it: in the order of the group, and after the rules which state they read. Rules
+// that read the state of other rules of the group get the state of the same evaluation time. The results of recording
+// rules are not written but kept in memory, and the rules of the group read them like the state of alert rules.
+// A group of a single alert rule is tested like Test. Testing stops at the first evaluation that fails.
+func (e *Engine) TestGroup(ctx context.Context, user identity.Requester, rules models.RulesGroup, from, to time.Time, opts TestOptions) ([]RuleResult, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%w: rule group is empty", ErrInvalidInputData)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if len(rules) == 1 && !rules[0].IsRecordingRule() && len(rules[0].GetRuleDependencies()) == 0 {
+		frame, err := e.Test(ctx, user, rules[0], from, to, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []RuleResult{{Rule: rules[0], States: frame}}, nil
+	}
+	sorted := make(models.RulesGroup, len(rules))
+	copy(sorted, rules)
+	sorted.SortByGroupIndex()
+	sorted, err := sorted.SortByDependencies()
+	if err != nil {
+		return nil, errors.Join(ErrInvalidInputData, err)
+	}
+
+	g := &groupTest{
+		evalFactory:  e.evalFactory,
+		user:         user,
+		stateManager: e.createStateManager(),
+		rules:        make(map[string]*models.AlertRule, len(sorted)),
+		recorded:     make(map[string][]expr.AlertStateInstance),
+	}
+	tests := make([]*ruleTest, 0, len(sorted))
+	for _, rule := range sorted {
+		length := Evaluations(rule, from, to)
+		if length == 0 {
+			return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds] of rule %s", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds, rule.UID)
+		}
+		g.rules[rule.UID] = rule
+		tests = append(tests, &ruleTest{
+			rule:     rule,
+			length:   length,
+			interval: time.Duration(rule.IntervalSeconds) * time.Second,
+			frame:    newStatesFrame(length),
+		})
+	}
+
+	logger := logger.FromContext(ctx)
+	logger.Info("Start testing rule group", "from", from, "to", to, "rules", len(tests))
+	start := time.Now()
+
+	for {
+		// the next evaluation time is the earliest time at which any of the rules is due
+		var now time.Time
+		due := false
+		for _, t := range tests {
+			if t.done() {
+				continue
+			}
+			if next := t.next(from); !due || next.Before(now) {
+				now, due = next, true
+			}
+		}
+		if !due {
+			break
+		}
+		for _, t := range tests {
+			if t.done() || !t.next(from).Equal(now) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := g.evaluate(ctx, t, now); err != nil {
+				return nil, fmt.Errorf("failed to test rule %s: %w", t.rule.UID, err)
+			}
+			t.idx++
+			if opts.OnProgress != nil {
+				opts.OnProgress()
+			}
+		}
+	}
+
+	results := make([]RuleResult, 0, len(tests))
+	for _, t := range tests {
+		results = append(results, RuleResult{Rule: t.rule, States: t.frame.Frame()})
+	}
+	logger.Info("Rule group testing finished successfully", "duration", time.Since(start))
+	return results, nil
+}
+
+// ruleTest is the progress of the test of a rule of a group.
+type ruleTest struct {
+	rule     *models.AlertRule
+	length   int
+	interval time.Duration
+	idx      int
+	frame    *statesFrame
+}
+
+func (t *ruleTest) done() bool {
+	return t.idx >= t.length
+}
+
+func (t *ruleTest) next(from time.Time) time.Time {
+	return from.Add(time.Duration(t.idx) * t.interval)
+}
+
+// groupTest evaluates the rules of a group against a shared state, so that rules can read the state of other rules.
+type groupTest struct {
+	evalFactory  eval.EvaluatorFactory
+	user         identity.Requester
+	stateManager stateManager
+	rules        map[string]*models.AlertRule
+	// recorded are the results of the last evaluation of the recording rules by rule UID.
+	recorded map[string][]expr.AlertStateInstance
+}
+
+func (g *groupTest) evaluate(ctx context.Context, t *ruleTest, now time.Time) error {
+	ruleCtx := models.WithRuleKey(ctx, t.rule.GetKey())
+	evalCtx := eval.NewContextWithPreviousResults(ruleCtx, g.user, &schedule.AlertingResultsFromRuleState{
+		Manager: g.stateManager,
+		Rule:    t.rule,
+	})
+	evalCtx.RuleStatesReader = schedule.RuleStatesFromStateManager{
+		Manager:     g.stateManager,
+		GetRule:     g.getRule,
+		GetRecorded: g.getRecorded,
+		Rule:        t.rule,
+	}
+	// the evaluator is created for every evaluation because the state of the rules is set to the queries when it is created
+	evaluator, err := g.evalFactory.Create(evalCtx, t.rule.GetEvalCondition())
+	if err != nil {
+		return errors.Join(ErrInvalidInputData, err)
+	}
+
+	if t.rule.IsRecordingRule() {
+		resp, err := evaluator.EvaluateRaw(ruleCtx, now)
+		if err != nil {
+			return err
+		}
+		instances, err := recordedInstances(resp, t.rule.Record.From)
+		if err != nil {
+			return err
+		}
+		g.recorded[t.rule.UID] = instances
+		t.frame.setRecorded(t.idx, now, instances, t.rule.Labels)
+		return nil
+	}
+
+	results, err := evaluator.Evaluate(ruleCtx, now)
+	if err != nil {
+		return err
+	}
+	states := g.stateManager.ProcessEvalResults(ruleCtx, now, t.rule, results, nil)
+	t.frame.setStates(t.idx, now, states)
+	return nil
+}
+
+// getRecorded returns the values that the recording rule recorded at its last evaluation.
+func (g *groupTest) getRecorded(key models.AlertRuleKey) []expr.AlertStateInstance {
+	return g.recorded[key.UID]
+}
+
+func (g *groupTest) getRule(key models.AlertRuleKey) *models.AlertRule {
+	rule, ok := g.rules[key.UID]
+	if !ok || rule.OrgID != key.OrgID {
+		return nil
+	}
+	return rule
+}
+
+// recordedInstances returns the last value of each numeric series of the query refID, the same values that the
+// scheduler writes for a recording rule.
+func recordedInstances(resp *backend.QueryDataResponse, refID string) ([]expr.AlertStateInstance, error) {
+	if resp == nil {
+		return nil, errors.New("no response from the query pipeline")
+	}
+	result, ok := resp.Responses[refID]
+	if !ok {
+		return nil, fmt.Errorf("no results of the query %s", refID)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("query %s failed: %w", refID, result.Error)
+	}
+	return schedule.RecordedInstances(result.Frames), nil
+}
@@ -0,0 +1,169 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestEngineTestGroup(t *testing.T) {
+	groupKey := models.GenerateGroupKey(1)
+	from := time.Unix(0, 0)
+	to := from.Add(3 * time.Second)
+
+	newEngine := func(create func(ctx eval.EvaluationContext, key models.AlertRuleKey) (eval.ConditionEvaluator, error)) *Engine {
+		manager := &fakeStateManager{stateCallback: func(now time.Time) []state.StateTransition {
+			return nil
+		}}
+		return &Engine{
+			evalFactory: &fakeGroupEvaluatorFactory{create: create},
+			createStateManager: func() stateManager {
+				return manager
+			},
+		}
+	}
+	alertStateQuery := func(ruleUID string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         "STATE",
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(fmt.Sprintf(`{"type":"alert_state","ruleUid":%q}`, ruleUID)),
+		}
+	}
+
+	t.Run("should evaluate rules in order of dependencies at each evaluation time", func(t *testing.T) {
+		recording := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithGroupIndex(1), models.WithRecord("recorded"))()
+		alerting := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithGroupIndex(0), models.WithQuery(alertStateQuery(recording.UID)))()
+
+		var evaluations []string
+		engine := newEngine(func(ctx eval.EvaluationContext, key models.AlertRuleKey) (eval.ConditionEvaluator, error) {
+			return &fakeConditionEvaluator{
+				evaluateRaw: func(now time.Time) (*backend.QueryDataResponse, error) {
+					evaluations = append(evaluations, fmt.Sprintf("%s@%d", key.UID, now.Unix()))
+					return &backend.QueryDataResponse{Responses: backend.Responses{
+						recording.Record.From: backend.DataResponse{Frames: data.Frames{
+							data.NewFrame("", data.NewField("value", data.Labels{"service": "db"}, []float64{float64(now.Unix())})),
+						}},
+					}}, nil
+				},
+				evaluate: func(now time.Time) (eval.Results, error) {
+					evaluations = append(evaluations, fmt.Sprintf("%s@%d", key.UID, now.Unix()))
+					// the recorded values of the same evaluation time are read
+					instances, err := ctx.RuleStatesReader.Read(recording.UID)
+					require.NoError(t, err)
+					require.Len(t, instances, 1)
+					require.Equal(t, data.Labels{"service": "db"}, instances[0].Labels)
+					require.Equal(t, float64(now.Unix()), *instances[0].Value)
+					return eval.Results{{State: eval.Normal, EvaluatedAt: now}}, nil
+				},
+			}, nil
+		})
+
+		progress := 0
+		results, err := engine.TestGroup(context.Background(), nil, models.RulesGroup{alerting, recording}, from, to, TestOptions{
+			OnProgress: func() { progress++ },
+		})
+		require.NoError(t, err)
+		require.Equal(t, 6, progress)
+		require.Equal(t, []string{
+			recording.UID + "@0", alerting.UID + "@0",
+			recording.UID + "@1", alerting.UID + "@1",
+			recording.UID + "@2", alerting.UID + "@2",
+		}, evaluations)
+
+		require.Len(t, results, 2)
+		require.Equal(t, recording, results[0].Rule)
+		require.Equal(t, alerting, results[1].Rule)
+		recorded := results[0].States
+		require.Len(t, recorded.Fields, 2)
+		expectedLabels := data.Labels{"service": "db"}
+		for k, v := range recording.Labels {
+			expectedLabels[k] = v
+		}
+		require.Equal(t, expectedLabels, recorded.Fields[1].Labels)
+		for i, expected := range []string{"0", "1", "2"} {
+			require.Equal(t, expected, *recorded.Fields[1].At(i).(*string))
+		}
+	})
+
+	t.Run("should evaluate every rule at its own interval", func(t *testing.T) {
+		rule1 := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithGroupIndex(1))()
+		rule2 := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(2*time.Second), models.WithGroupIndex(0))()
+		engine := newEngine(func(ctx eval.EvaluationContext, key models.AlertRuleKey) (eval.ConditionEvaluator, error) {
+			return &fakeConditionEvaluator{evaluate: func(now time.Time) (eval.Results, error) {
+				return eval.Results{{State: eval.Normal, EvaluatedAt: now}}, nil
+			}}, nil
+		})
+
+		progress := 0
+		to := from.Add(10 * time.Second)
+		results, err := engine.TestGroup(context.Background(), nil, models.RulesGroup{rule1, rule2}, from, to, TestOptions{
+			OnProgress: func() { progress++ },
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, rule2, results[0].Rule)
+		require.Equal(t, rule1, results[1].Rule)
+		require.Equal(t, Evaluations(rule1, from, to)+Evaluations(rule2, from, to), progress)
+		require.Equal(t, 15, progress)
+	})
+
+	t.Run("should fail if rule reads state of rule outside of the group", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithQuery(alertStateQuery("other")))()
+		engine := newEngine(nil)
+		_, err := engine.TestGroup(context.Background(), nil, models.RulesGroup{rule}, from, to, TestOptions{})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should stop at first failed evaluation", func(t *testing.T) {
+		expectedError := errors.New("test-error")
+		rules := models.GenerateAlertRules(2, models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second)))
+		evaluations := 0
+		engine := newEngine(func(ctx eval.EvaluationContext, key models.AlertRuleKey) (eval.ConditionEvaluator, error) {
+			return &fakeConditionEvaluator{evaluate: func(now time.Time) (eval.Results, error) {
+				evaluations++
+				return nil, expectedError
+			}}, nil
+		})
+		_, err := engine.TestGroup(context.Background(), nil, rules, from, to, TestOptions{})
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, 1, evaluations)
+	})
+}
+
+type fakeGroupEvaluatorFactory struct {
+	create func(ctx eval.EvaluationContext, key models.AlertRuleKey) (eval.ConditionEvaluator, error)
+}
+
+func (f *fakeGroupEvaluatorFactory) Validate(_ eval.EvaluationContext, _ models.Condition) error {
+	return nil
+}
+
+func (f *fakeGroupEvaluatorFactory) Create(ctx eval.EvaluationContext, _ models.Condition) (eval.ConditionEvaluator, error) {
+	key, _ := models.RuleKeyFromContext(ctx.Ctx)
+	return f.create(ctx, key)
+}
+
+type fakeConditionEvaluator struct {
+	evaluate    func(now time.Time) (eval.Results, error)
+	evaluateRaw func(now time.Time) (*backend.QueryDataResponse, error)
+}
+
+func (f *fakeConditionEvaluator) EvaluateRaw(_ context.Context, now time.Time) (*backend.QueryDataResponse, error) {
+	return f.evaluateRaw(now)
+}
+
+func (f *fakeConditionEvaluator) Evaluate(_ context.Context, now time.Time) (eval.Results, error) {
+	return f.evaluate(now)
+}
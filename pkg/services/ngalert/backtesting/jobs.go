@@ -0,0 +1,213 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultMaxRunningJobs  = 5
+	defaultMaxFinishedJobs = 20
+	defaultJobRetention    = time.Hour
+)
+
+var (
+	ErrJobNotFound = errors.New("backtesting job not found")
+	ErrTooManyJobs = errors.New("too many backtesting jobs are running")
+)
+
+type JobState string
+
+const (
+	JobStateRunning   JobState = "running"
+	JobStateCompleted JobState = "completed"
+	JobStateFailed    JobState = "failed"
+	JobStateCancelled JobState = "cancelled"
+)
+
+// JobStatus is a snapshot of the status of a backtesting job.
+type JobStatus struct {
+	ID    string
+	State JobState
+	// Total is the total number of evaluations the job has to do and Done is the number of evaluations it has done.
+	Total      int
+	Done       int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Err is set if the job has failed.
+	Err error
+	// Results are set if the job has completed.
+	Results []RuleResult
+}
+
+// JobFunc is the function that a job runs. It must call onProgress after each evaluation and stop when ctx is cancelled.
+type JobFunc func(ctx context.Context, onProgress func()) ([]RuleResult, error)
+
+type job struct {
+	orgID  int64
+	owner  string
+	cancel context.CancelFunc
+
+	mtx    sync.Mutex
+	status JobStatus
+}
+
+func (j *job) snapshot() JobStatus {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	return j.status
+}
+
+// Jobs runs backtesting in the background so that long backtests do not depend on the lifetime of the HTTP request.
+// Jobs are visible only to the user who started them. Finished jobs are kept for some time so that their results can be
+// fetched, and then forgotten. Only a limited number of finished jobs is kept, the oldest ones are forgotten first.
+type Jobs struct {
+	clock       clock.Clock
+	maxRunning  int
+	maxFinished int
+	retention   time.Duration
+
+	mtx  sync.Mutex
+	jobs map[string]*job
+}
+
+func NewJobs() *Jobs {
+	return &Jobs{
+		clock:       clock.New(),
+		maxRunning:  defaultMaxRunningJobs,
+		maxFinished: defaultMaxFinishedJobs,
+		retention:   defaultJobRetention,
+		jobs:        make(map[string]*job),
+	}
+}
+
+// Start starts a job that does the total number of evaluations. The job does not depend on the cancellation of ctx,
+// and can be cancelled only via Cancel.
+func (j *Jobs) Start(ctx context.Context, user identity.Requester, total int, fn JobFunc) (JobStatus, error) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	j.cleanup()
+
+	running := 0
+	for _, jb := range j.jobs {
+		if jb.snapshot().State == JobStateRunning {
+			running++
+		}
+	}
+	if running >= j.maxRunning {
+		return JobStatus{}, ErrTooManyJobs
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	jb := &job{
+		orgID:  user.GetOrgID(),
+		owner:  jobOwner(user),
+		cancel: cancel,
+		status: JobStatus{
+			ID:        util.GenerateShortUID(),
+			State:     JobStateRunning,
+			Total:     total,
+			StartedAt: j.clock.Now(),
+		},
+	}
+	j.jobs[jb.status.ID] = jb
+
+	go func() {
+		defer cancel()
+		results, err := fn(jobCtx, func() {
+			jb.mtx.Lock()
+			defer jb.mtx.Unlock()
+			jb.status.Done++
+		})
+
+		jb.mtx.Lock()
+		defer jb.mtx.Unlock()
+		jb.status.FinishedAt = j.clock.Now()
+		switch {
+		case jb.status.State == JobStateCancelled:
+		case err != nil:
+			jb.status.State = JobStateFailed
+			jb.status.Err = err
+		default:
+			jb.status.State = JobStateCompleted
+			jb.status.Results = results
+		}
+	}()
+
+	return jb.snapshot(), nil
+}
+
+// Get returns the status of the job started by the user.
+func (j *Jobs) Get(user identity.Requester, id string) (JobStatus, error) {
+	jb, err := j.get(user, id)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	return jb.snapshot(), nil
+}
+
+// Cancel cancels the job started by the user. It does nothing if the job has already finished.
+func (j *Jobs) Cancel(user identity.Requester, id string) (JobStatus, error) {
+	jb, err := j.get(user, id)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	jb.mtx.Lock()
+	if jb.status.State == JobStateRunning {
+		jb.status.State = JobStateCancelled
+		jb.cancel()
+	}
+	jb.mtx.Unlock()
+	return jb.snapshot(), nil
+}
+
+func (j *Jobs) get(user identity.Requester, id string) (*job, error) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+	j.cleanup()
+	jb, ok := j.jobs[id]
+	if !ok || jb.orgID != user.GetOrgID() || jb.owner != jobOwner(user) {
+		return nil, ErrJobNotFound
+	}
+	return jb, nil
+}
+
+// cleanup forgets jobs that finished earlier than the retention period, and the oldest finished jobs if there are more
+// than maxFinished of them. Must be called under the lock.
+func (j *Jobs) cleanup() {
+	now := j.clock.Now()
+	finished := make([]JobStatus, 0, len(j.jobs))
+	for id, jb := range j.jobs {
+		s := jb.snapshot()
+		if s.State == JobStateRunning || s.FinishedAt.IsZero() {
+			continue
+		}
+		if now.Sub(s.FinishedAt) > j.retention {
+			delete(j.jobs, id)
+			continue
+		}
+		finished = append(finished, s)
+	}
+	if len(finished) <= j.maxFinished {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool {
+		return finished[a].FinishedAt.Before(finished[b].FinishedAt)
+	})
+	for _, s := range finished[:len(finished)-j.maxFinished] {
+		delete(j.jobs, s.ID)
+	}
+}
+
+func jobOwner(user identity.Requester) string {
+	namespace, id := user.GetNamespacedID()
+	return namespace + ":" + id
+}
@@ -0,0 +1,181 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestJobs(t *testing.T) {
+	owner := &user.SignedInUser{OrgID: 1, UserID: 1}
+
+	newJobs := func() (*Jobs, *clock.Mock) {
+		clk := clock.NewMock()
+		jobs := NewJobs()
+		jobs.clock = clk
+		return jobs, clk
+	}
+	waitForState := func(t *testing.T, jobs *Jobs, id string, state JobState) JobStatus {
+		t.Helper()
+		var status JobStatus
+		require.Eventually(t, func() bool {
+			var err error
+			status, err = jobs.Get(owner, id)
+			require.NoError(t, err)
+			return status.State == state
+		}, time.Second, 10*time.Millisecond)
+		return status
+	}
+
+	t.Run("should report progress and results", func(t *testing.T) {
+		jobs, _ := newJobs()
+		proceed := make(chan struct{})
+		expected := []RuleResult{{Rule: &models.AlertRule{UID: "test"}}}
+
+		status, err := jobs.Start(context.Background(), owner, 2, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			onProgress()
+			<-proceed
+			onProgress()
+			return expected, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, JobStateRunning, status.State)
+		require.Equal(t, 2, status.Total)
+
+		require.Eventually(t, func() bool {
+			s, err := jobs.Get(owner, status.ID)
+			require.NoError(t, err)
+			return s.Done == 1
+		}, time.Second, 10*time.Millisecond)
+
+		close(proceed)
+		status = waitForState(t, jobs, status.ID, JobStateCompleted)
+		require.Equal(t, 2, status.Done)
+		require.Equal(t, expected, status.Results)
+	})
+
+	t.Run("should report error", func(t *testing.T) {
+		jobs, _ := newJobs()
+		expectedErr := errors.New("test")
+		status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			return nil, expectedErr
+		})
+		require.NoError(t, err)
+
+		status = waitForState(t, jobs, status.ID, JobStateFailed)
+		require.ErrorIs(t, status.Err, expectedErr)
+	})
+
+	t.Run("should not be cancelled with the context that started it", func(t *testing.T) {
+		jobs, _ := newJobs()
+		ctx, cancel := context.WithCancel(context.Background())
+		proceed := make(chan struct{})
+		status, err := jobs.Start(ctx, owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			<-proceed
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+		cancel()
+		close(proceed)
+
+		waitForState(t, jobs, status.ID, JobStateCompleted)
+	})
+
+	t.Run("should cancel job", func(t *testing.T) {
+		jobs, _ := newJobs()
+		status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+
+		status, err = jobs.Cancel(owner, status.ID)
+		require.NoError(t, err)
+		require.Equal(t, JobStateCancelled, status.State)
+
+		require.Eventually(t, func() bool {
+			s, err := jobs.Get(owner, status.ID)
+			require.NoError(t, err)
+			return !s.FinishedAt.IsZero()
+		}, time.Second, 10*time.Millisecond)
+		status, err = jobs.Get(owner, status.ID)
+		require.NoError(t, err)
+		require.Equal(t, JobStateCancelled, status.State)
+		require.NoError(t, status.Err)
+	})
+
+	t.Run("should not let other users access the job", func(t *testing.T) {
+		jobs, _ := newJobs()
+		status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		for _, other := range []*user.SignedInUser{{OrgID: 1, UserID: 2}, {OrgID: 2, UserID: 1}} {
+			_, err = jobs.Get(other, status.ID)
+			require.ErrorIs(t, err, ErrJobNotFound)
+			_, err = jobs.Cancel(other, status.ID)
+			require.ErrorIs(t, err, ErrJobNotFound)
+		}
+	})
+
+	t.Run("should limit number of running jobs", func(t *testing.T) {
+		jobs, _ := newJobs()
+		jobs.maxRunning = 1
+		proceed := make(chan struct{})
+		status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			<-proceed
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		_, err = jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			return nil, nil
+		})
+		require.ErrorIs(t, err, ErrTooManyJobs)
+
+		close(proceed)
+		waitForState(t, jobs, status.ID, JobStateCompleted)
+	})
+
+	t.Run("should forget finished jobs after retention period", func(t *testing.T) {
+		jobs, clk := newJobs()
+		status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		waitForState(t, jobs, status.ID, JobStateCompleted)
+
+		clk.Add(jobs.retention + time.Second)
+		_, err = jobs.Get(owner, status.ID)
+		require.ErrorIs(t, err, ErrJobNotFound)
+	})
+	t.Run("should forget oldest finished jobs if there are too many", func(t *testing.T) {
+		jobs, clk := newJobs()
+		jobs.maxFinished = 2
+		ids := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			status, err := jobs.Start(context.Background(), owner, 1, func(ctx context.Context, onProgress func()) ([]RuleResult, error) {
+				return nil, nil
+			})
+			require.NoError(t, err)
+			waitForState(t, jobs, status.ID, JobStateCompleted)
+			ids = append(ids, status.ID)
+			clk.Add(time.Second)
+		}
+
+		_, err := jobs.Get(owner, ids[0])
+		require.ErrorIs(t, err, ErrJobNotFound)
+		for _, id := range ids[1:] {
+			_, err := jobs.Get(owner, id)
+			require.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,485 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	backtestingTemplateName = "__backtesting"
+	// defaultTitle and defaultMessage are the title and the message of most integrations if they are not configured.
+	defaultTitle   = `{{ template "default.title" . }}`
+	defaultMessage = `{{ template "default.message" . }}`
+)
+
+// TemplateTester renders templates with the templates of an organization. It is implemented by notifier.Alertmanager.
+type TemplateTester interface {
+	TestTemplate(ctx context.Context, c definitions.TestTemplatesConfigBodyParams) (*alertingNotify.TestTemplatesResults, error)
+}
+
+// NotificationConfig configures the simulation of the notification pipeline.
+type NotificationConfig struct {
+	// Config is the Alertmanager configuration that provides the notification policy tree, receivers and mute timings.
+	Config *definitions.PostableApiAlertingConfig
+	// Templates renders the templated settings of the integrations of the notifications. If it is nil, notifications
+	// are not rendered.
+	Templates TemplateTester
+	// FolderTitle is the title of the folder of the rule that is added to the alerts if IncludeFolder is true.
+	FolderTitle   string
+	IncludeFolder bool
+}
+
+// Notification is a notification that the Alertmanager would have sent to a receiver.
+type Notification struct {
+	Time        time.Time
+	Receiver    string
+	GroupKey    string
+	GroupLabels model.LabelSet
+	Status      model.AlertStatus
+	Alerts      []NotificationAlert
+	// Integrations are the integrations of the receiver with their templated settings rendered for the notification.
+	Integrations []RenderedIntegration
+}
+
+// RenderedIntegration is an integration of a receiver with its templated settings rendered for a notification.
+type RenderedIntegration struct {
+	Name string
+	Type string
+	// Fields are the rendered settings of the integration, such as title and message.
+	Fields map[string]string
+	// Errors contains the errors of rendering the settings.
+	Errors []string
+}
+
+// NotificationAlert is an alert in a Notification.
+type NotificationAlert struct {
+	Status      model.AlertStatus
+	Labels      model.LabelSet
+	Annotations model.LabelSet
+	StartsAt    time.Time
+	EndsAt      time.Time
+}
+
+type simulatedAlert struct {
+	fingerprint model.Fingerprint
+	labels      model.LabelSet
+	annotations model.LabelSet
+	startsAt    time.Time
+	endsAt      time.Time
+}
+
+func (a *simulatedAlert) resolvedAt(t time.Time) bool {
+	return !a.endsAt.IsZero() && !a.endsAt.After(t)
+}
+
+// aggregationGroup is a group of alerts that are notified together, as in the Alertmanager dispatcher.
+type aggregationGroup struct {
+	key    string
+	route  *dispatch.Route
+	labels model.LabelSet
+	alerts map[model.Fingerprint]*simulatedAlert
+	next   time.Time
+}
+
+// notificationLogEntry is the last notification of an aggregation group, as in the Alertmanager notification log.
+type notificationLogEntry struct {
+	firing    map[model.Fingerprint]struct{}
+	resolved  map[model.Fingerprint]struct{}
+	timestamp time.Time
+}
+
+// notificationSimulator simulates the Alertmanager dispatcher and the notification pipeline for the alerts of a single rule.
+// It groups the alerts by the routes of the notification policy tree, flushes the groups according to group_wait and
+// group_interval, drops notifications during mute timings and deduplicates notifications the way the Alertmanager
+// notification log does, which includes repeat_interval. Silences and inhibition rules are not simulated.
+type notificationSimulator struct {
+	appURL        *url.URL
+	route         *dispatch.Route
+	muteTimings   map[string][]timeinterval.TimeInterval
+	receivers     map[string]*definitions.PostableApiReceiver
+	sendResolved  map[string]bool
+	groups        map[string]*aggregationGroup
+	log           map[string]*notificationLogEntry
+	notifications []Notification
+}
+
+func newNotificationSimulator(cfg *definitions.PostableApiAlertingConfig, appURL *url.URL) (*notificationSimulator, error) {
+	if cfg == nil || cfg.Route == nil {
+		return nil, errors.New("notification policy tree is not configured")
+	}
+
+	muteTimings := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		muteTimings[mt.Name] = mt.TimeIntervals
+	}
+
+	// A receiver sends resolved notifications if any of its integrations does.
+	receivers := make(map[string]*definitions.PostableApiReceiver, len(cfg.Receivers))
+	sendResolved := make(map[string]bool, len(cfg.Receivers))
+	for _, r := range cfg.Receivers {
+		receivers[r.Name] = r
+		for _, integration := range r.GrafanaManagedReceivers {
+			if !integration.DisableResolveMessage {
+				sendResolved[r.Name] = true
+			}
+		}
+	}
+
+	return &notificationSimulator{
+		appURL:       appURL,
+		route:        dispatch.NewRoute(cfg.Route.AsAMRoute(), nil),
+		muteTimings:  muteTimings,
+		receivers:    receivers,
+		sendResolved: sendResolved,
+		groups:       make(map[string]*aggregationGroup),
+		log:          make(map[string]*notificationLogEntry),
+	}, nil
+}
+
+// Put sends the alerts of the state transitions that would be sent to the Alertmanager at the given time.
+func (s *notificationSimulator) Put(now time.Time, transitions []state.StateTransition) {
+	s.FlushUntil(now)
+	for _, t := range transitions {
+		if !t.NeedsSending(state.ResendDelay) {
+			continue
+		}
+		s.put(now, state.StateToPostableAlert(t, s.appURL))
+	}
+	// flush groups without group_wait right away
+	s.FlushUntil(now)
+}
+
+func (s *notificationSimulator) put(now time.Time, postable *amv2.PostableAlert) {
+	alert := &simulatedAlert{
+		labels:      make(model.LabelSet, len(postable.Labels)),
+		annotations: make(model.LabelSet, len(postable.Annotations)),
+		startsAt:    time.Time(postable.StartsAt),
+		endsAt:      time.Time(postable.EndsAt),
+	}
+	for k, v := range postable.Labels {
+		alert.labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	for k, v := range postable.Annotations {
+		alert.annotations[model.LabelName(k)] = model.LabelValue(v)
+	}
+	alert.fingerprint = alert.labels.Fingerprint()
+
+	for _, route := range s.route.Match(alert.labels) {
+		groupLabels := getGroupLabels(alert.labels, route)
+		key := fmt.Sprintf("%s:%s", route.Key(), groupLabels)
+		g, ok := s.groups[key]
+		if !ok {
+			g = &aggregationGroup{
+				key:    key,
+				route:  route,
+				labels: groupLabels,
+				alerts: make(map[model.Fingerprint]*simulatedAlert),
+				next:   now.Add(route.RouteOpts.GroupWait),
+			}
+			s.groups[key] = g
+		}
+		g.alerts[alert.fingerprint] = alert
+	}
+}
+
+// FlushUntil flushes all aggregation groups that are due at or before the given time, in the order they are due.
+func (s *notificationSimulator) FlushUntil(t time.Time) {
+	for {
+		var g *aggregationGroup
+		for _, candidate := range s.groups {
+			if candidate.next.After(t) {
+				continue
+			}
+			if g == nil || candidate.next.Before(g.next) || candidate.next.Equal(g.next) && candidate.key < g.key {
+				g = candidate
+			}
+		}
+		if g == nil {
+			return
+		}
+		s.flush(g, g.next)
+		interval := g.route.RouteOpts.GroupInterval
+		if interval <= 0 {
+			interval = dispatch.DefaultRouteOpts.GroupInterval
+		}
+		g.next = g.next.Add(interval)
+		if len(g.alerts) == 0 {
+			delete(s.groups, g.key)
+		}
+	}
+}
+
+// Receiver returns the receiver of the configuration with the given name, or nil if there is no such receiver.
+func (s *notificationSimulator) Receiver(name string) *definitions.PostableApiReceiver {
+	return s.receivers[name]
+}
+
+// Notifications returns the notifications sent so far in the order they were sent.
+func (s *notificationSimulator) Notifications() []Notification {
+	return s.notifications
+}
+
+func (s *notificationSimulator) flush(g *aggregationGroup, now time.Time) {
+	alerts := make([]*simulatedAlert, 0, len(g.alerts))
+	for _, a := range g.alerts {
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].labels.Before(alerts[j].labels)
+	})
+
+	var firing, resolved []*simulatedAlert
+	for _, a := range alerts {
+		if a.resolvedAt(now) {
+			resolved = append(resolved, a)
+		} else {
+			firing = append(firing, a)
+		}
+	}
+
+	if !s.isMuted(g.route, now) {
+		s.notify(g, now, firing, resolved)
+	}
+
+	// resolved alerts are removed from the group once they are flushed
+	for _, a := range resolved {
+		delete(g.alerts, a.fingerprint)
+	}
+}
+
+func (s *notificationSimulator) isMuted(route *dispatch.Route, now time.Time) bool {
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		for _, ti := range s.muteTimings[name] {
+			if ti.ContainsTime(now.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// notify sends the notification of the group if the notification log requires it, see DedupStage of the Alertmanager.
+func (s *notificationSimulator) notify(g *aggregationGroup, now time.Time, firing, resolved []*simulatedAlert) {
+	receiver := g.route.RouteOpts.Receiver
+	sendResolved := s.sendResolved[receiver]
+	firingSet, resolvedSet := fingerprintSet(firing), fingerprintSet(resolved)
+
+	if !needsUpdate(s.log[g.key], firingSet, resolvedSet, sendResolved, g.route.RouteOpts.RepeatInterval, now) {
+		return
+	}
+	s.log[g.key] = &notificationLogEntry{
+		firing:    firingSet,
+		resolved:  resolvedSet,
+		timestamp: now,
+	}
+
+	toSend := firing
+	if sendResolved {
+		toSend = append(toSend, resolved...)
+	}
+	if len(toSend) == 0 {
+		return
+	}
+
+	n := Notification{
+		Time:        now,
+		Receiver:    receiver,
+		GroupKey:    g.key,
+		GroupLabels: g.labels.Clone(),
+		Status:      model.AlertResolved,
+		Alerts:      make([]NotificationAlert, 0, len(toSend)),
+	}
+	if len(firing) > 0 {
+		n.Status = model.AlertFiring
+	}
+	for _, a := range toSend {
+		status := model.AlertFiring
+		if a.resolvedAt(now) {
+			status = model.AlertResolved
+		}
+		n.Alerts = append(n.Alerts, NotificationAlert{
+			Status:      status,
+			Labels:      a.labels.Clone(),
+			Annotations: a.annotations.Clone(),
+			StartsAt:    a.startsAt,
+			EndsAt:      a.endsAt,
+		})
+	}
+	s.notifications = append(s.notifications, n)
+}
+
+func needsUpdate(entry *notificationLogEntry, firing, resolved map[model.Fingerprint]struct{}, sendResolved bool, repeat time.Duration, now time.Time) bool {
+	// notify about a new group right away unless it only has resolved alerts
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	// notify about all alerts being resolved only if the last notification had firing alerts
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if sendResolved && !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	// nothing changed, notify only if the repeat interval has passed
+	return entry.timestamp.Before(now.Add(-repeat))
+}
+
+// renderNotification renders the templated settings of each integration of the receiver for the notification. Settings
+// that are not configured are not rendered, except for title and message that fall back to the default templates as
+// they do in most integrations.
+func renderNotification(ctx context.Context, templates TemplateTester, receiver *definitions.PostableApiReceiver, n *Notification) error {
+	if receiver == nil || len(receiver.GrafanaManagedReceivers) == 0 {
+		return nil
+	}
+
+	type field struct {
+		integration int
+		name        string
+	}
+	var tmpl strings.Builder
+	fields := make(map[string]field)
+	n.Integrations = make([]RenderedIntegration, 0, len(receiver.GrafanaManagedReceivers))
+	for i, integration := range receiver.GrafanaManagedReceivers {
+		rendered := RenderedIntegration{
+			Name:   integration.Name,
+			Type:   integration.Type,
+			Fields: make(map[string]string),
+		}
+		settings, err := templatedSettings(integration.Settings)
+		if err != nil {
+			rendered.Errors = append(rendered.Errors, err.Error())
+		}
+		names := make([]string, 0, len(settings))
+		for name := range settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			templateName := fmt.Sprintf("%s_%d_%s", backtestingTemplateName, i, name)
+			fields[templateName] = field{integration: i, name: name}
+			tmpl.WriteString(`{{ define "` + templateName + `" }}` + settings[name] + `{{ end }}`)
+		}
+		n.Integrations = append(n.Integrations, rendered)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	alerts := make([]*amv2.PostableAlert, 0, len(n.Alerts))
+	for _, a := range n.Alerts {
+		alert := &amv2.PostableAlert{
+			Alert: amv2.Alert{
+				Labels: make(amv2.LabelSet, len(a.Labels)),
+			},
+			Annotations: make(amv2.LabelSet, len(a.Annotations)),
+			StartsAt:    strfmt.DateTime(a.StartsAt),
+		}
+		for k, v := range a.Labels {
+			alert.Labels[string(k)] = string(v)
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[string(k)] = string(v)
+		}
+		// the template data decides the status of an alert by the current time, so only resolved alerts get EndsAt
+		if a.Status == model.AlertResolved {
+			alert.EndsAt = strfmt.DateTime(a.EndsAt)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	res, err := templates.TestTemplate(ctx, definitions.TestTemplatesConfigBodyParams{
+		Alerts:   alerts,
+		Template: tmpl.String(),
+		Name:     backtestingTemplateName,
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range res.Results {
+		if f, ok := fields[r.Name]; ok {
+			n.Integrations[f.integration].Fields[f.name] = r.Text
+		}
+	}
+	for _, e := range res.Errors {
+		// errors that are not specific to a setting, such as parse errors, fail the rendering of all integrations
+		if f, ok := fields[e.Name]; ok {
+			n.Integrations[f.integration].Errors = append(n.Integrations[f.integration].Errors, e.Error.Error())
+			continue
+		}
+		for i := range n.Integrations {
+			n.Integrations[i].Errors = append(n.Integrations[i].Errors, e.Error.Error())
+		}
+	}
+	return nil
+}
+
+// templatedSettings returns the settings of an integration that are templates, that is the string settings that have
+// an action, and the title and the message with the default templates if they are not configured.
+func templatedSettings(raw definitions.RawMessage) (map[string]string, error) {
+	settings := map[string]any{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	}
+	result := make(map[string]string, len(settings))
+	for name, value := range settings {
+		if s, ok := value.(string); ok && strings.Contains(s, "{{") {
+			result[name] = s
+		}
+	}
+	if _, ok := settings["title"]; !ok {
+		result["title"] = defaultTitle
+	}
+	if _, ok := settings["message"]; !ok {
+		result["message"] = defaultMessage
+	}
+	return result, nil
+}
+
+func getGroupLabels(labels model.LabelSet, route *dispatch.Route) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for ln, lv := range labels {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	return groupLabels
+}
+
+func fingerprintSet(alerts []*simulatedAlert) map[model.Fingerprint]struct{} {
+	result := make(map[model.Fingerprint]struct{}, len(alerts))
+	for _, a := range alerts {
+		result[a.fingerprint] = struct{}{}
+	}
+	return result
+}
+
+// isSubset returns true if all elements of subset are in set.
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,295 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := time.Minute
+
+	firing := func(l data.Labels, now, startsAt time.Time) state.StateTransition {
+		return state.StateTransition{
+			State: &state.State{
+				State:              eval.Alerting,
+				Labels:             l,
+				StartsAt:           startsAt,
+				EndsAt:             now.Add(4 * interval),
+				LastEvaluationTime: now,
+			},
+			PreviousState: eval.Alerting,
+		}
+	}
+	resolved := func(l data.Labels, now, startsAt time.Time) state.StateTransition {
+		return state.StateTransition{
+			State: &state.State{
+				State:              eval.Normal,
+				Labels:             l,
+				StartsAt:           startsAt,
+				EndsAt:             now,
+				LastEvaluationTime: now,
+				Resolved:           true,
+			},
+			PreviousState: eval.Alerting,
+		}
+	}
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	receiver := func(name string, disableResolveMessage bool) *definitions.PostableApiReceiver {
+		return &definitions.PostableApiReceiver{
+			Receiver: config.Receiver{Name: name},
+			PostableGrafanaReceivers: definitions.PostableGrafanaReceivers{
+				GrafanaManagedReceivers: []*definitions.PostableGrafanaReceiver{
+					{Name: name, Type: "webhook", DisableResolveMessage: disableResolveMessage},
+				},
+			},
+		}
+	}
+	newConfig := func(routes ...*definitions.Route) *definitions.PostableApiAlertingConfig {
+		return &definitions.PostableApiAlertingConfig{
+			Config: definitions.Config{
+				Route: &definitions.Route{
+					Receiver:       "default",
+					GroupByStr:     []string{"alertname"},
+					GroupBy:        []model.LabelName{"alertname"},
+					GroupWait:      duration(30 * time.Second),
+					GroupInterval:  duration(5 * time.Minute),
+					RepeatInterval: duration(time.Hour),
+					Routes:         routes,
+				},
+				MuteTimeIntervals: []config.MuteTimeInterval{
+					{Name: "always", TimeIntervals: []timeinterval.TimeInterval{{}}},
+				},
+			},
+			Receivers: []*definitions.PostableApiReceiver{
+				receiver("default", false),
+				receiver("team-a", true),
+			},
+		}
+	}
+	teamA := func(muteTimings ...string) *definitions.Route {
+		m, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+		require.NoError(t, err)
+		return &definitions.Route{
+			Receiver:          "team-a",
+			Matchers:          config.Matchers{m},
+			MuteTimeIntervals: muteTimings,
+		}
+	}
+
+	type sent struct {
+		time     time.Time
+		receiver string
+		status   model.AlertStatus
+		alerts   int
+	}
+	summary := func(notifications []Notification) []sent {
+		result := make([]sent, 0, len(notifications))
+		for _, n := range notifications {
+			result = append(result, sent{time: n.Time, receiver: n.Receiver, status: n.Status, alerts: len(n.Alerts)})
+		}
+		return result
+	}
+
+	t.Run("should apply group_wait, repeat_interval and send resolved notification", func(t *testing.T) {
+		s, err := newNotificationSimulator(newConfig(), nil)
+		require.NoError(t, err)
+
+		alert := data.Labels{"alertname": "test", "host": "a"}
+		for i := 0; i < 70; i++ {
+			now := start.Add(time.Duration(i) * interval)
+			s.Put(now, []state.StateTransition{firing(alert, now, start)})
+		}
+		end := start.Add(70 * interval)
+		s.Put(end, []state.StateTransition{resolved(alert, end, start)})
+		s.FlushUntil(start.Add(80 * time.Minute))
+
+		require.Equal(t, []sent{
+			{time: start.Add(30 * time.Second), receiver: "default", status: model.AlertFiring, alerts: 1},
+			{time: start.Add(65*time.Minute + 30*time.Second), receiver: "default", status: model.AlertFiring, alerts: 1},
+			{time: start.Add(70*time.Minute + 30*time.Second), receiver: "default", status: model.AlertResolved, alerts: 1},
+		}, summary(s.Notifications()))
+		require.Equal(t, model.LabelSet{"alertname": "test"}, s.Notifications()[0].GroupLabels)
+		require.Empty(t, s.groups)
+	})
+
+	t.Run("should notify about new alerts in the group at group_interval", func(t *testing.T) {
+		s, err := newNotificationSimulator(newConfig(), nil)
+		require.NoError(t, err)
+
+		a := data.Labels{"alertname": "test", "host": "a"}
+		b := data.Labels{"alertname": "test", "host": "b"}
+		for i := 0; i < 10; i++ {
+			now := start.Add(time.Duration(i) * interval)
+			transitions := []state.StateTransition{firing(a, now, start)}
+			if i >= 2 {
+				transitions = append(transitions, firing(b, now, start.Add(2*interval)))
+			}
+			s.Put(now, transitions)
+		}
+
+		require.Equal(t, []sent{
+			{time: start.Add(30 * time.Second), receiver: "default", status: model.AlertFiring, alerts: 1},
+			{time: start.Add(5*time.Minute + 30*time.Second), receiver: "default", status: model.AlertFiring, alerts: 2},
+		}, summary(s.Notifications()))
+	})
+
+	t.Run("should not notify during mute timings", func(t *testing.T) {
+		s, err := newNotificationSimulator(newConfig(teamA("always")), nil)
+		require.NoError(t, err)
+
+		now := start
+		s.Put(now, []state.StateTransition{
+			firing(data.Labels{"alertname": "test", "team": "a"}, now, start),
+			firing(data.Labels{"alertname": "test", "team": "b"}, now, start),
+		})
+		s.FlushUntil(start.Add(time.Minute))
+
+		require.Equal(t, []sent{
+			{time: start.Add(30 * time.Second), receiver: "default", status: model.AlertFiring, alerts: 1},
+		}, summary(s.Notifications()))
+		require.Equal(t, model.LabelValue("b"), s.Notifications()[0].Alerts[0].Labels["team"])
+	})
+
+	t.Run("should not send resolved notifications if receiver disables them", func(t *testing.T) {
+		s, err := newNotificationSimulator(newConfig(teamA()), nil)
+		require.NoError(t, err)
+
+		alert := data.Labels{"alertname": "test", "team": "a"}
+		s.Put(start, []state.StateTransition{firing(alert, start, start)})
+		end := start.Add(interval)
+		s.Put(end, []state.StateTransition{resolved(alert, end, start)})
+		s.FlushUntil(start.Add(time.Hour))
+
+		require.Equal(t, []sent{
+			{time: start.Add(30 * time.Second), receiver: "team-a", status: model.AlertFiring, alerts: 1},
+		}, summary(s.Notifications()))
+	})
+
+	t.Run("should skip pending states", func(t *testing.T) {
+		s, err := newNotificationSimulator(newConfig(), nil)
+		require.NoError(t, err)
+
+		pending := firing(data.Labels{"alertname": "test"}, start, start)
+		pending.State.State = eval.Pending
+		s.Put(start, []state.StateTransition{pending})
+		s.FlushUntil(start.Add(time.Hour))
+
+		require.Empty(t, s.Notifications())
+	})
+
+	t.Run("should fail without notification policy tree", func(t *testing.T) {
+		_, err := newNotificationSimulator(&definitions.PostableApiAlertingConfig{}, nil)
+		require.Error(t, err)
+	})
+}
+
+type fakeTemplateTester struct {
+	params definitions.TestTemplatesConfigBodyParams
+	result *alertingNotify.TestTemplatesResults
+	err    error
+}
+
+func (f *fakeTemplateTester) TestTemplate(_ context.Context, c definitions.TestTemplatesConfigBodyParams) (*alertingNotify.TestTemplatesResults, error) {
+	f.params = c
+	return f.result, f.err
+}
+
+func TestRenderNotification(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := Notification{
+		Alerts: []NotificationAlert{
+			{Status: model.AlertFiring, Labels: model.LabelSet{"alertname": "a"}, StartsAt: now, EndsAt: now.Add(time.Minute)},
+			{Status: model.AlertResolved, Labels: model.LabelSet{"alertname": "b"}, StartsAt: now, EndsAt: now.Add(time.Minute)},
+		},
+	}
+
+	receiver := &definitions.PostableApiReceiver{
+		PostableGrafanaReceivers: definitions.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*definitions.PostableGrafanaReceiver{
+				{Name: "slack", Type: "slack", Settings: definitions.RawMessage(`{"title": "{{ .CommonLabels.alertname }}", "text": "{{ template \"custom\" . }}", "recipient": "#alerts"}`)},
+				{Name: "email", Type: "email", Settings: definitions.RawMessage(`{"message": "static"}`)},
+			},
+		},
+	}
+
+	t.Run("should render templated settings of each integration", func(t *testing.T) {
+		tester := &fakeTemplateTester{result: &alertingNotify.TestTemplatesResults{
+			Results: []alertingNotify.TestTemplatesResult{
+				{Name: "__backtesting_0_title", Text: "title"},
+				{Name: "__backtesting_0_message", Text: "message"},
+				{Name: "__backtesting_1_title", Text: "default title"},
+			},
+			Errors: []alertingNotify.TestTemplatesErrorResult{
+				{Name: "__backtesting_0_text", Error: errors.New("failed")},
+			},
+		}}
+		notification := n
+		require.NoError(t, renderNotification(context.Background(), tester, receiver, &notification))
+		require.Equal(t, []RenderedIntegration{
+			{
+				Name:   "slack",
+				Type:   "slack",
+				Fields: map[string]string{"title": "title", "message": "message"},
+				Errors: []string{"failed"},
+			},
+			{
+				Name:   "email",
+				Type:   "email",
+				Fields: map[string]string{"title": "default title"},
+			},
+		}, notification.Integrations)
+
+		require.Equal(t, `{{ define "__backtesting_0_message" }}`+defaultMessage+`{{ end }}`+
+			`{{ define "__backtesting_0_text" }}{{ template "custom" . }}{{ end }}`+
+			`{{ define "__backtesting_0_title" }}{{ .CommonLabels.alertname }}{{ end }}`+
+			`{{ define "__backtesting_1_title" }}`+defaultTitle+`{{ end }}`, tester.params.Template)
+		require.Len(t, tester.params.Alerts, 2)
+		require.True(t, time.Time(tester.params.Alerts[0].EndsAt).IsZero(), "firing alerts should not have EndsAt")
+		require.Equal(t, now.Add(time.Minute), time.Time(tester.params.Alerts[1].EndsAt))
+	})
+
+	t.Run("should add errors that are not specific to a setting to all integrations", func(t *testing.T) {
+		tester := &fakeTemplateTester{result: &alertingNotify.TestTemplatesResults{
+			Errors: []alertingNotify.TestTemplatesErrorResult{
+				{Error: errors.New("failed")},
+			},
+		}}
+		notification := n
+		require.NoError(t, renderNotification(context.Background(), tester, receiver, &notification))
+		require.Len(t, notification.Integrations, 2)
+		for _, integration := range notification.Integrations {
+			require.Equal(t, []string{"failed"}, integration.Errors)
+		}
+	})
+
+	t.Run("should not render notifications of receivers without integrations", func(t *testing.T) {
+		tester := &fakeTemplateTester{err: errors.New("should not be called")}
+		notification := n
+		require.NoError(t, renderNotification(context.Background(), tester, nil, &notification))
+		require.NoError(t, renderNotification(context.Background(), tester, &definitions.PostableApiReceiver{}, &notification))
+		require.Empty(t, notification.Integrations)
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		notification := n
+		require.Error(t, renderNotification(context.Background(), &fakeTemplateTester{err: errors.New("failed")}, receiver, &notification))
+	})
+}
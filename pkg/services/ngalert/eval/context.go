@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
 
@@ -14,11 +15,18 @@ type AlertingResultsReader interface {
 	Read() map[data.Fingerprint]struct{}
 }
 
+// RuleStatesReader provides the state of the alert instances of other rules.
+// It is used to populate alert state expressions during the evaluation of queries.
+type RuleStatesReader interface {
+	Read(ruleUID string) ([]expr.AlertStateInstance, error)
+}
+
 // EvaluationContext represents the context in which a condition is evaluated.
 type EvaluationContext struct {
 	Ctx                   context.Context
 	User                  identity.Requester
 	AlertingResultsReader AlertingResultsReader
+	RuleStatesReader      RuleStatesReader
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
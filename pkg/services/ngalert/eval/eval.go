@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -315,7 +316,7 @@ func buildDatasourceHeaders(ctx context.Context) map[string]string {
 }
 
 // getExprRequest validates the condition, gets the datasource information and creates an expr.Request from it.
-func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheService datasources.CacheService, reader AlertingResultsReader) (*expr.Request, error) {
+func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheService datasources.CacheService, reader AlertingResultsReader, features featuremgmt.FeatureToggles) (*expr.Request, error) {
 	req := &expr.Request{
 		OrgId:   ctx.User.GetOrgID(),
 		Headers: buildDatasourceHeaders(ctx.Ctx),
@@ -344,7 +345,7 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 		// if the query is command expression and it's a hysteresis, patch it with the current state
 		// it's important to do this before GetModel
 		if ds.Type == expr.DatasourceType {
-			isHysteresis, err := q.IsHysteresisExpression()
+			isHysteresis, err := q.IsHysteresisExpression(features)
 			if err != nil {
 				return nil, fmt.Errorf("failed to build query '%s': %w", q.RefID, err)
 			}
@@ -356,7 +357,7 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 				}
 				if reader != nil {
 					logger.FromContext(ctx.Ctx).Debug("Detected hysteresis threshold command. Populating with the results")
-					err = q.PatchHysteresisExpression(reader.Read())
+					err = q.PatchHysteresisExpression(reader.Read(), features)
 					if err != nil {
 						return nil, fmt.Errorf("failed to amend hysteresis command '%s': %w", q.RefID, err)
 					}
@@ -364,6 +365,24 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 			}
 		}
 
+		// if the query is an alert state expression, patch it with the current state of the rule it reads
+		if ds.Type == expr.DatasourceType && ctx.RuleStatesReader != nil {
+			ruleUID, err := q.AlertStateRuleUID()
+			if err != nil {
+				return nil, fmt.Errorf("failed to build query '%s': %w", q.RefID, err)
+			}
+			if ruleUID != "" {
+				instances, err := ctx.RuleStatesReader.Read(ruleUID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read the state of rule '%s' for query '%s': %w", ruleUID, q.RefID, err)
+				}
+				err = q.PatchAlertStateExpression(instances)
+				if err != nil {
+					return nil, fmt.Errorf("failed to amend alert state command '%s': %w", q.RefID, err)
+				}
+			}
+		}
+
 		model, err := q.GetModel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
@@ -766,7 +785,7 @@ func (evalResults Results) AsDataFrame() data.Frame {
 }
 
 func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Condition) error {
-	req, err := getExprRequest(ctx, condition, e.dataSourceCache, ctx.AlertingResultsReader)
+	req, err := getExprRequest(ctx, condition, e.dataSourceCache, ctx.AlertingResultsReader, e.expressionService.Features())
 	if err != nil {
 		return err
 	}
@@ -802,7 +821,7 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if len(condition.Condition) == 0 {
 		return nil, errors.New("condition must not be empty")
 	}
-	req, err := getExprRequest(ctx, condition, e.dataSourceCache, ctx.AlertingResultsReader)
+	req, err := getExprRequest(ctx, condition, e.dataSourceCache, ctx.AlertingResultsReader, e.expressionService.Features())
 	if err != nil {
 		return nil, err
 	}
@@ -730,6 +730,49 @@ func TestCreate_HysteresisCommand(t *testing.T) {
 	}
 }
 
+func TestCreate_AlertStateCommand(t *testing.T) {
+	instances := []expr.AlertStateInstance{
+		{Labels: data.Labels{"service": "db"}, Firing: true},
+		{Labels: data.Labels{"service": "cache"}},
+	}
+	reader := FakeRuleStatesReader{instances: map[string][]expr.AlertStateInstance{"upstream": instances}}
+	condition := func() models.Condition {
+		return models.Condition{
+			Condition: "A",
+			Data:      []models.AlertQuery{models.CreateAlertStateExpression("A", "upstream")},
+		}
+	}
+	evaluator := NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, &fakes.FakeCacheService{}, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, featuremgmt.WithFeatures(featuremgmt.FlagAlertRuleDependencies), nil, tracing.InitializeTracerForTest()), &pluginstore.FakePluginStore{})
+
+	t.Run("populate with the instances of the rule", func(t *testing.T) {
+		evalCtx := NewContext(context.Background(), &user.SignedInUser{})
+		evalCtx.RuleStatesReader = reader
+
+		eval, err := evaluator.Create(evalCtx, condition())
+		require.NoError(t, err)
+		require.IsType(t, &conditionEvaluator{}, eval)
+		cmds := expr.GetCommandsFromPipeline[*expr.AlertStateCommand](eval.(*conditionEvaluator).pipeline)
+		require.Len(t, cmds, 1)
+		require.Equal(t, instances, cmds[0].Instances)
+	})
+
+	t.Run("fail if the state of the rule cannot be read", func(t *testing.T) {
+		evalCtx := NewContext(context.Background(), &user.SignedInUser{})
+		evalCtx.RuleStatesReader = FakeRuleStatesReader{}
+
+		_, err := evaluator.Create(evalCtx, condition())
+		require.Error(t, err)
+	})
+
+	t.Run("do nothing if reader is not specified", func(t *testing.T) {
+		eval, err := evaluator.Create(NewContext(context.Background(), &user.SignedInUser{}), condition())
+		require.NoError(t, err)
+		cmds := expr.GetCommandsFromPipeline[*expr.AlertStateCommand](eval.(*conditionEvaluator).pipeline)
+		require.Len(t, cmds, 1)
+		require.Empty(t, cmds[0].Instances)
+	})
+}
+
 func TestEvaluate(t *testing.T) {
 	cases := []struct {
 		name     string
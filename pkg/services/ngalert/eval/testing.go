@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -87,3 +88,15 @@ type FakeLoadedMetricsReader struct {
 func (f FakeLoadedMetricsReader) Read() map[data.Fingerprint]struct{} {
 	return f.fingerprints
 }
+
+type FakeRuleStatesReader struct {
+	instances map[string][]expr.AlertStateInstance
+}
+
+func (f FakeRuleStatesReader) Read(ruleUID string) ([]expr.AlertStateInstance, error) {
+	instances, ok := f.instances[ruleUID]
+	if !ok {
+		return nil, fmt.Errorf("rule %s not found", ruleUID)
+	}
+	return instances, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 const defaultMaxDataPoints float64 = 43200 // 12 hours at 1sec interval
@@ -119,25 +120,51 @@ func (aq *AlertQuery) IsExpression() (bool, error) {
 }
 
 // IsHysteresisExpression returns true if the model describes a hysteresis command expression. Returns error if the Model is not a valid JSON
-func (aq *AlertQuery) IsHysteresisExpression() (bool, error) {
+func (aq *AlertQuery) IsHysteresisExpression(features featuremgmt.FeatureToggles) (bool, error) {
 	if aq.modelProps == nil {
 		err := aq.setModelProps()
 		if err != nil {
 			return false, err
 		}
 	}
-	return expr.IsHysteresisExpression(aq.modelProps), nil
+	return expr.IsHysteresisExpression(aq.modelProps, features), nil
 }
 
 // PatchHysteresisExpression updates the AlertQuery to include loaded metrics into hysteresis
-func (aq *AlertQuery) PatchHysteresisExpression(loadedMetrics map[data.Fingerprint]struct{}) error {
+func (aq *AlertQuery) PatchHysteresisExpression(loadedMetrics map[data.Fingerprint]struct{}, features featuremgmt.FeatureToggles) error {
 	if aq.modelProps == nil {
 		err := aq.setModelProps()
 		if err != nil {
 			return err
 		}
 	}
-	return expr.SetLoadedDimensionsToHysteresisCommand(aq.modelProps, loadedMetrics)
+	return expr.SetLoadedDimensionsToHysteresisCommand(aq.modelProps, loadedMetrics, features)
+}
+
+// AlertStateRuleUID returns the UID of the rule which state is read by the query if the model describes an alert state
+// command expression, or an empty string otherwise. Returns error if the Model is not a valid JSON
+func (aq *AlertQuery) AlertStateRuleUID() (string, error) {
+	if expr.NodeTypeFromDatasourceUID(aq.DatasourceUID) != expr.TypeCMDNode {
+		return "", nil
+	}
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return "", err
+		}
+	}
+	return expr.AlertStateRuleUID(aq.modelProps), nil
+}
+
+// PatchAlertStateExpression updates the AlertQuery to include the instances of the rule read by the alert state command
+func (aq *AlertQuery) PatchAlertStateExpression(instances []expr.AlertStateInstance) error {
+	if aq.modelProps == nil {
+		err := aq.setModelProps()
+		if err != nil {
+			return err
+		}
+	}
+	return expr.SetInstancesToAlertStateCommand(aq.modelProps, instances)
 }
 
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func TestAlertQuery(t *testing.T) {
@@ -205,7 +206,7 @@ func TestAlertQuery(t *testing.T) {
 			})
 
 			t.Run("can recognize if it's a hysteresis expression", func(t *testing.T) {
-				isExpression, err := tc.alertQuery.IsHysteresisExpression()
+				isExpression, err := tc.alertQuery.IsHysteresisExpression(featuremgmt.WithFeatures())
 				require.NoError(t, err)
 				assert.Equal(t, tc.expectedIsHysteresis, isExpression)
 			})
@@ -256,7 +257,7 @@ func TestAlertQuery(t *testing.T) {
 
 			if tc.expectedIsHysteresis {
 				t.Run("can patch the command with loaded metrics", func(t *testing.T) {
-					require.NoError(t, tc.alertQuery.PatchHysteresisExpression(map[data.Fingerprint]struct{}{1: {}, 2: {}, 3: {}}))
+					require.NoError(t, tc.alertQuery.PatchHysteresisExpression(map[data.Fingerprint]struct{}{1: {}, 2: {}, 3: {}}, featuremgmt.WithFeatures()))
 					data, ok := tc.alertQuery.modelProps["conditions"].([]any)[0].(map[string]any)["loadedDimensions"]
 					require.True(t, ok)
 					require.NotNil(t, data)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	prommodel "github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -145,6 +147,8 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	// StateReasonRecovering is the reason of alerts that keep firing even though the condition is not met anymore.
+	StateReasonRecovering = "Recovering"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -245,10 +249,58 @@ type AlertRule struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	Record               Record                 `xorm:"record"`
+}
+
+// Record is the configuration of a recording rule. The rule is a recording rule if Metric is not empty.
+type Record struct {
+	// Metric is the name of the metric that the results of the query are written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose results are written.
+	From string `json:"from"`
+}
+
+// IsEmpty returns true if the record is not configured.
+func (r Record) IsEmpty() bool {
+	return r.Metric == "" && r.From == ""
+}
+
+// Validate checks that the record has a valid metric name and that it refers to one of the queries.
+func (r Record) Validate(queries []AlertQuery) error {
+	if !prommodel.IsValidMetricName(prommodel.LabelValue(r.Metric)) {
+		return fmt.Errorf("invalid metric name %q", r.Metric)
+	}
+	if r.From == "" {
+		return errors.New("the query to record must be specified")
+	}
+	for _, q := range queries {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("the query to record %s does not exist", r.From)
+}
+
+// FromDB loads the record from its JSON representation stored in the database.
+// The struct is stored as JSON because xorm does not serialize nested structs.
+func (r *Record) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, r)
+}
+
+// ToDB serializes the record to JSON. Empty record is stored as an empty string.
+func (r *Record) ToDB() ([]byte, error) {
+	if r.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(r)
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -332,13 +384,37 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// IsRecordingRule returns true if the rule writes the results of the query instead of evaluating alerts.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return !alertRule.Record.IsEmpty()
+}
+
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.IsRecordingRule() {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
 	}
 }
 
+// GetRuleDependencies returns the UIDs of the rules which state is read by the alert state expressions of the rule.
+func (alertRule *AlertRule) GetRuleDependencies() []string {
+	var result []string
+	for i := range alertRule.Data {
+		uid, err := alertRule.Data[i].AlertStateRuleUID()
+		if err != nil || uid == "" || slices.Contains(result, uid) {
+			continue
+		}
+		result = append(result, uid)
+	}
+	return result
+}
+
 // Diff calculates diff between two alert rules. Returns nil if two rules are equal. Otherwise, returns cmputil.DiffReport
 func (alertRule *AlertRule) Diff(rule *AlertRule, ignore ...string) cmputil.DiffReport {
 	var reporter cmputil.DiffReporter
@@ -506,6 +582,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -522,6 +602,15 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid notification settings: %w", err))
 		}
 	}
+
+	if alertRule.IsRecordingRule() {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid recording rule: %w", err))
+		}
+		if len(alertRule.NotificationSettings) > 0 {
+			return fmt.Errorf("%w: recording rules cannot have notification settings", ErrAlertRuleFailedValidation)
+		}
+	}
 	return nil
 }
 
@@ -566,10 +655,12 @@ type AlertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	Record               Record                 `xorm:"record"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -663,7 +754,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.Title == "" {
 		ruleToPatch.Title = existingRule.Title
 	}
-	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
+	if (ruleToPatch.Condition == "" && !ruleToPatch.IsRecordingRule()) || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
 	}
@@ -685,6 +776,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -709,6 +803,70 @@ func (g RulesGroup) SortByGroupIndex() {
 	})
 }
 
+// SortByDependencies returns the rules of the group ordered so that every rule comes after the rules which state it
+// reads. Rules that do not depend on each other keep their order. Returns error if a rule reads the state of a rule
+// that does not belong to the group, or if rules read the state of each other in a cycle.
+func (g RulesGroup) SortByDependencies() (RulesGroup, error) {
+	dependencies := make(map[string][]string, len(g))
+	for _, rule := range g {
+		dependencies[rule.UID] = rule.GetRuleDependencies()
+	}
+	for _, rule := range g {
+		for _, uid := range dependencies[rule.UID] {
+			if uid == rule.UID {
+				return nil, fmt.Errorf("%w: rule '%s' cannot read its own state", ErrAlertRuleFailedValidation, rule.Title)
+			}
+			if _, ok := dependencies[uid]; !ok {
+				return nil, fmt.Errorf("%w: rule '%s' reads the state of rule '%s' that does not belong to the group", ErrAlertRuleFailedValidation, rule.Title, uid)
+			}
+		}
+	}
+
+	result := make(RulesGroup, 0, len(g))
+	placed := make(map[string]struct{}, len(g))
+	for len(result) < len(g) {
+		progress := false
+		for _, rule := range g {
+			if _, ok := placed[rule.UID]; ok {
+				continue
+			}
+			ready := true
+			for _, uid := range dependencies[rule.UID] {
+				if _, ok := placed[uid]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				result = append(result, rule)
+				placed[rule.UID] = struct{}{}
+				progress = true
+				break
+			}
+		}
+		if !progress {
+			return nil, fmt.Errorf("%w: rules of the group read the state of each other in a cycle", ErrAlertRuleFailedValidation)
+		}
+	}
+	return result, nil
+}
+
+// ValidateDependencies checks that the rules of the group read the state of rules of the same group only, and that
+// they do not read the state of each other in a cycle. Rules without UID are new rules that cannot be read by others.
+func (g RulesGroup) ValidateDependencies() error {
+	group := make(RulesGroup, 0, len(g))
+	for idx, rule := range g {
+		if rule.UID == "" {
+			r := *rule
+			r.UID = fmt.Sprintf("new-rule-%d", idx)
+			rule = &r
+		}
+		group = append(group, rule)
+	}
+	_, err := group.SortByDependencies()
+	return err
+}
+
 func SortAlertRulesByGroupIndex(rules []AlertRule) {
 	sort.Slice(rules, func(i, j int) bool {
 		if rules[i].RuleGroupIndex == rules[j].RuleGroupIndex {
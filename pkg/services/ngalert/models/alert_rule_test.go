@@ -253,6 +253,12 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.For = -1
 				},
 			},
+			{
+				name: "KeepFiringFor is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.KeepFiringFor = -1
+				},
+			},
 			{
 				name: "IsPaused did not come in request",
 				mutator: func(r *AlertRuleWithOptionals) {
@@ -815,6 +821,61 @@ func TestDiff(t *testing.T) {
 	})
 }
 
+func TestRecord(t *testing.T) {
+	queries := []AlertQuery{{RefID: "A"}, {RefID: "B"}}
+
+	t.Run("Validate", func(t *testing.T) {
+		testCases := []struct {
+			name        string
+			record      Record
+			expectedErr string
+		}{
+			{name: "valid", record: Record{Metric: "test_metric:sum", From: "B"}},
+			{name: "invalid metric name", record: Record{Metric: "test-metric", From: "A"}, expectedErr: "invalid metric name"},
+			{name: "empty from", record: Record{Metric: "test_metric"}, expectedErr: "must be specified"},
+			{name: "unknown from", record: Record{Metric: "test_metric", From: "C"}, expectedErr: "C does not exist"},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.record.Validate(queries)
+				if tc.expectedErr == "" {
+					require.NoError(t, err)
+					return
+				}
+				require.ErrorContains(t, err, tc.expectedErr)
+			})
+		}
+	})
+
+	t.Run("should be stored as JSON", func(t *testing.T) {
+		r := Record{Metric: "test_metric", From: "A"}
+		b, err := r.ToDB()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"metric":"test_metric","from":"A"}`, string(b))
+
+		var actual Record
+		require.NoError(t, actual.FromDB(b))
+		require.Equal(t, r, actual)
+	})
+
+	t.Run("should store empty record as empty value", func(t *testing.T) {
+		b, err := (&Record{}).ToDB()
+		require.NoError(t, err)
+		require.Empty(t, b)
+
+		var actual Record
+		require.NoError(t, actual.FromDB(b))
+		require.True(t, actual.IsEmpty())
+	})
+
+	t.Run("recording rule should be evaluated by the query of the record", func(t *testing.T) {
+		rule := AlertRuleGen(WithRecord("test_metric"))()
+		require.True(t, rule.IsRecordingRule())
+		require.Equal(t, rule.Data[0].RefID, rule.GetEvalCondition().Condition)
+		require.Equal(t, rule.Data, rule.GetEvalCondition().Data)
+	})
+}
+
 func TestSortByGroupIndex(t *testing.T) {
 	ensureNotSorted := func(t *testing.T, rules []*AlertRule, less func(i, j int) bool) {
 		for i := 0; i < 5; i++ {
@@ -851,6 +912,88 @@ func TestSortByGroupIndex(t *testing.T) {
 	})
 }
 
+func TestSortByDependencies(t *testing.T) {
+	readState := func(rule *AlertRule, deps ...*AlertRule) {
+		for i, dep := range deps {
+			rule.Data = append(rule.Data, CreateAlertStateExpression(fmt.Sprintf("STATE%d", i), dep.UID))
+		}
+	}
+	uids := func(rules RulesGroup) []string {
+		result := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			result = append(result, rule.UID)
+		}
+		return result
+	}
+
+	t.Run("should order rules after the rules which state they read", func(t *testing.T) {
+		rules := GenerateAlertRules(4, AlertRuleGen())
+		readState(rules[0], rules[2])
+		readState(rules[2], rules[3])
+		readState(rules[1], rules[3], rules[2])
+
+		require.Equal(t, []string{rules[2].UID, rules[3].UID}, rules[1].GetRuleDependencies())
+
+		sorted, err := RulesGroup(rules).SortByDependencies()
+		require.NoError(t, err)
+		require.Equal(t, []string{rules[3].UID, rules[2].UID, rules[0].UID, rules[1].UID}, uids(sorted))
+	})
+
+	t.Run("should keep the order of independent rules", func(t *testing.T) {
+		rules := GenerateAlertRules(5, AlertRuleGen())
+		sorted, err := RulesGroup(rules).SortByDependencies()
+		require.NoError(t, err)
+		require.Equal(t, uids(rules), uids(sorted))
+	})
+
+	t.Run("should fail if rule reads the state of rule that is not in the group", func(t *testing.T) {
+		rules := GenerateAlertRules(2, AlertRuleGen())
+		readState(rules[0], AlertRuleGen()())
+		_, err := RulesGroup(rules).SortByDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail if rule reads its own state", func(t *testing.T) {
+		rules := GenerateAlertRules(2, AlertRuleGen())
+		readState(rules[1], rules[1])
+		_, err := RulesGroup(rules).SortByDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail if rules read the state of each other in a cycle", func(t *testing.T) {
+		rules := GenerateAlertRules(3, AlertRuleGen())
+		readState(rules[0], rules[1])
+		readState(rules[1], rules[2])
+		readState(rules[2], rules[0])
+		_, err := RulesGroup(rules).SortByDependencies()
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestValidateDependencies(t *testing.T) {
+	t.Run("should accept new rules without UID", func(t *testing.T) {
+		rules := GenerateAlertRules(3, AlertRuleGen())
+		rules[0].UID = ""
+		rules[1].UID = ""
+		rules[1].Data = append(rules[1].Data, CreateAlertStateExpression("STATE", rules[2].UID))
+		require.NoError(t, RulesGroup(rules).ValidateDependencies())
+	})
+
+	t.Run("should fail if rule reads the state of rule that is not in the group", func(t *testing.T) {
+		rules := GenerateAlertRules(2, AlertRuleGen())
+		rules[0].UID = ""
+		rules[0].Data = append(rules[0].Data, CreateAlertStateExpression("STATE", util.GenerateShortUID()))
+		require.ErrorIs(t, RulesGroup(rules).ValidateDependencies(), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail if rules read the state of each other in a cycle", func(t *testing.T) {
+		rules := GenerateAlertRules(2, AlertRuleGen())
+		rules[0].Data = append(rules[0].Data, CreateAlertStateExpression("STATE", rules[1].UID))
+		rules[1].Data = append(rules[1].Data, CreateAlertStateExpression("STATE", rules[0].UID))
+		require.ErrorIs(t, RulesGroup(rules).ValidateDependencies(), ErrAlertRuleFailedValidation)
+	})
+}
+
 func TestTimeRangeYAML(t *testing.T) {
 	yamlRaw := "from: 600\nto: 0\n"
 	var rtr RelativeTimeRange
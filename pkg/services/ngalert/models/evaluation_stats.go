@@ -0,0 +1,46 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// EvaluationRecord describes a single evaluation of an alert rule.
+type EvaluationRecord struct {
+	EvaluatedAt time.Time
+	// Duration is the time it took to evaluate the rule, from building the evaluator to getting the results.
+	Duration time.Duration
+	// DatasourceDuration is the part of Duration that was spent in the queries to datasources.
+	DatasourceDuration time.Duration
+	// ExpressionDuration is the part of Duration that was spent in the expressions.
+	ExpressionDuration time.Duration
+	// Series is the number of results of the evaluation, or the number of recorded frames for recording rules.
+	Series int
+	Error  string
+}
+
+// RuleEvaluationStats are the statistics of the evaluations of an alert rule since it was scheduled by this instance.
+type RuleEvaluationStats struct {
+	// Evaluations are the most recent evaluations, the oldest first.
+	Evaluations []EvaluationRecord
+	// Total is the number of evaluations since the rule was scheduled, including the ones that are not in Evaluations.
+	Total int64
+	// Errors is the number of evaluations since the rule was scheduled that failed.
+	Errors int64
+}
+
+// DurationPercentile returns the q-th percentile (0 < q <= 1) of the duration of the recent evaluations, using the
+// nearest-rank method. It returns 0 if there are no evaluations.
+func (s RuleEvaluationStats) DurationPercentile(q float64) time.Duration {
+	if len(s.Evaluations) == 0 {
+		return 0
+	}
+	durations := make([]time.Duration, 0, len(s.Evaluations))
+	for _, e := range s.Evaluations {
+		durations = append(durations, e.Duration)
+	}
+	slices.Sort(durations)
+	rank := int(math.Ceil(q*float64(len(durations)))) - 1
+	return durations[max(0, min(rank, len(durations)-1))]
+}
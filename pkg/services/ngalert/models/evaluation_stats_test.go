@@ -0,0 +1,21 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleEvaluationStats_DurationPercentile(t *testing.T) {
+	require.Zero(t, RuleEvaluationStats{}.DurationPercentile(0.5))
+
+	stats := RuleEvaluationStats{}
+	for i := 20; i > 0; i-- {
+		stats.Evaluations = append(stats.Evaluations, EvaluationRecord{Duration: time.Duration(i) * time.Second})
+	}
+	require.Equal(t, 10*time.Second, stats.DurationPercentile(0.5))
+	require.Equal(t, 19*time.Second, stats.DurationPercentile(0.95))
+	require.Equal(t, 20*time.Second, stats.DurationPercentile(1))
+	require.Equal(t, time.Second, stats.DurationPercentile(0))
+}
@@ -15,6 +15,7 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	KeepFiringSince   time.Time
 }
 
 type AlertInstanceKey struct {
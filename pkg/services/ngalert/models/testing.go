@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -211,6 +212,12 @@ func WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
 func WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.For = time.Duration(rule.IntervalSeconds*timesOfInterval) * time.Second
@@ -316,6 +323,17 @@ func WithNoNotificationSettings() AlertRuleMutator {
 	}
 }
 
+// WithRecord makes the rule a recording rule that writes the results of its first query to the metric.
+func WithRecord(metric string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = Record{
+			Metric: metric,
+			From:   rule.Data[0].RefID,
+		}
+		rule.NotificationSettings = nil
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -408,6 +426,8 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		KeepFiringFor:   r.KeepFiringFor,
+		Record:          r.Record,
 	}
 
 	if r.DashboardUID != nil {
@@ -590,12 +610,22 @@ func CreateHysteresisExpression(t *testing.T, refID string, inputRefID string, t
             ]
 		}`, refID, inputRefID, threshold, recoveryThreshold, expr.DatasourceUID, expr.DatasourceType)),
 	}
-	h, err := q.IsHysteresisExpression()
+	h, err := q.IsHysteresisExpression(featuremgmt.WithFeatures())
 	require.NoError(t, err)
 	require.Truef(t, h, "test model is expected to be a hysteresis expression")
 	return q
 }
 
+// CreateAlertStateExpression creates an alert state expression that reads the state of the rule with the specified UID.
+func CreateAlertStateExpression(refID string, ruleUID string) AlertQuery {
+	return AlertQuery{
+		RefID:         refID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"refId": %q, "type": "alert_state", "ruleUid": %q}`, refID, ruleUID)),
+	}
+}
+
 type AlertInstanceMutator func(*AlertInstance)
 
 // AlertInstanceGen provides a factory function that generates a random AlertInstance.
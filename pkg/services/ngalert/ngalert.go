@@ -3,6 +3,7 @@ package ngalert
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -41,6 +42,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -268,6 +270,10 @@ func (ng *AlertNG) init() error {
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
+	recordingWriter, err := configureRecordingWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.FeatureToggles, ng.Log)
+	if err != nil {
+		return err
+	}
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -280,14 +286,19 @@ func (ng *AlertNG) init() error {
 		RuleStore:            ng.store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
+		RecordingWriter:      recordingWriter,
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 	}
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+		schedCfg.ClusterMembership = ng.MultiOrgAlertmanager
+		schedCfg.EvaluationStatsStore = ng.KVStore
+	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.SQLStore, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
 	if err != nil {
 		return err
 	}
@@ -308,8 +319,14 @@ func (ng *AlertNG) init() error {
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
 	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
-		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
-		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
+		// The periodic persister replaces all states in the database with the states of this instance, which would
+		// delete the states of the rules that are evaluated by the other instances.
+		if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+			logger.Warn("Periodic saving of alert states is not supported when the evaluation is sharded across the high availability cluster, states are saved after every evaluation")
+		} else {
+			ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
+			statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
+		}
 	}
 	stateManager := state.NewManager(cfg, statePersister)
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
@@ -360,6 +377,7 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		EvaluationStats:      scheduler,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 		UpgradeService:       ng.upgradeService,
@@ -459,12 +477,25 @@ func (ng *AlertNG) GetHooks() *api.Hooks {
 	return ng.api.Hooks
 }
 
+// configureRecordingWriter returns the writer of the results of recording rules, or nil if recording rules are disabled.
+func configureRecordingWriter(cfg setting.RecordingRuleSettings, features featuremgmt.FeatureToggles, l log.Logger) (schedule.RecordingWriter, error) {
+	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) || !cfg.Enabled {
+		return nil, nil
+	}
+	w, err := writer.NewPrometheusWriter(cfg, &http.Client{}, l.New("component", "recording-writer"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording rules configuration: %w", err)
+	}
+	l.Info("Recording rules are enabled", "url", cfg.URL)
+	return w, nil
+}
+
 type Historian interface {
 	api.Historian
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, sqlStore db.DB, rs historian.RuleStore, met *metrics.Historian, l log.Logger) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -479,7 +510,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, sqlStore, rs, met, l)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -488,7 +519,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, sqlStore, rs, met, l)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -502,6 +533,9 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		store := historian.NewAnnotationStore(ar, ds, met)
 		return historian.NewAnnotationBackend(store, rs, met), nil
 	}
+	if backend == historian.BackendTypeSQL {
+		return historian.NewSQLBackend(sqlStore, cfg.SQLRetention, met), nil
+	}
 	if backend == historian.BackendTypeLoki {
 		lcfg, err := historian.NewLokiConfig(cfg)
 		if err != nil {
@@ -58,6 +58,9 @@ type alertmanager struct {
 
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
+	// templatePaths are the paths of the templates of the applied configuration. They are used to test templated
+	// HTTP contact points, which are not tested by the alerting package.
+	templatePaths []string
 
 	withAutogen bool
 }
@@ -359,6 +362,10 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	if err != nil {
 		return false, err
 	}
+	am.templatePaths = make([]string, 0, len(paths))
+	for _, p := range paths {
+		am.templatePaths = append(am.templatePaths, filepath.Join(am.Base.WorkingDirectory(), p))
+	}
 
 	am.updateConfigMetrics(cfg)
 	return true, nil
@@ -388,6 +395,8 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
+	// Templated HTTP contact points are not known to the alerting package, so they are built separately.
+	receiver, httpIntegrations := splitTemplatedHTTPIntegrations(receiver)
 	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
 	if err != nil {
 		return nil, err
@@ -411,6 +420,13 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+	for i, cfg := range httpIntegrations {
+		integration, err := newTemplatedHTTPIntegration(context.Background(), cfg, len(receiver.Integrations)+i, tmpl, am.decryptFn, am.orgID)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, integration)
+	}
 	return integrations, nil
 }
 
@@ -955,6 +955,124 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "http",
+			Name:        "HTTP",
+			Description: "Sends an HTTP request with a templated URL, headers and body",
+			Heading:     "HTTP settings",
+			Info:        "The HTTP method, URL, header values and body are templates that are rendered with the notification data.",
+			Options: []NotifierOption{
+				{
+					Label:        "URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "HTTP Method",
+					Description:  "Templated HTTP method of the request. Default is POST.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "httpMethod",
+					Placeholder:  "POST",
+				},
+				{
+					Label:        "Headers",
+					Description:  "Headers of the request. The values are templates. Content-Type is application/json unless it is set here.",
+					Element:      ElementTypeKeyValueMap,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "Body",
+					Description:  "Templated body of the request.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "body",
+					Placeholder:  `{"title": "{{ template "default.title" . }}"}`,
+				},
+				{
+					Label:        "HTTP Basic Authentication - Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "HTTP Basic Authentication - Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "OAuth2 - Client ID",
+					Description:  "Client ID for the OAuth2 client credentials flow. Only one of HTTP Basic Authentication or OAuth2 can be set.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2_client_id",
+				},
+				{
+					Label:        "OAuth2 - Client Secret",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "oauth2_client_secret",
+					Secure:       true,
+				},
+				{
+					Label:        "OAuth2 - Token URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2_token_url",
+				},
+				{
+					Label:        "OAuth2 - Scopes",
+					Description:  "Comma-separated list of scopes.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2_scopes",
+				},
+				{
+					Label:        "TLS - CA Certificate",
+					Description:  "PEM-encoded certificate of the CA that signed the certificate of the server.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tls_ca_cert",
+				},
+				{
+					Label:        "TLS - Client Certificate",
+					Description:  "PEM-encoded client certificate for mutual TLS.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tls_client_cert",
+				},
+				{
+					Label:        "TLS - Client Key",
+					Description:  "PEM-encoded private key of the client certificate.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tls_client_key",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS - Skip Verify",
+					Description:  "Skip the verification of the server certificate.",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "tls_insecure_skip_verify",
+				},
+				{
+					Label:        "HMAC Signature - Secret",
+					Description:  "Signs the request with HMAC-SHA256 of the timestamp and the body, separated by a dot. The timestamp is sent in the X-Grafana-Alerting-Signature-Timestamp header.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "hmac_secret",
+					Secure:       true,
+				},
+				{
+					Label:        "HMAC Signature - Header",
+					Description:  "Header of the hex-encoded signature.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "hmac_header",
+					Placeholder:  "X-Grafana-Alerting-Signature",
+				},
+			},
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -68,6 +69,9 @@ type MultiOrgAlertmanager struct {
 	alertmanagersMtx sync.RWMutex
 	alertmanagers    map[int64]Alertmanager
 
+	// silenceSchedulesMtx serializes changes to the silence presets and recurring silences.
+	silenceSchedulesMtx sync.Mutex
+
 	settings       *setting.Cfg
 	featureManager featuremgmt.FeatureToggles
 	logger         log.Logger
@@ -221,6 +225,7 @@ func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 			if err := moa.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
 				moa.logger.Error("Error while synchronizing Alertmanager orgs", "error", err)
 			}
+			moa.SyncRecurringSilences(ctx, time.Now())
 		}
 	}
 }
@@ -370,7 +375,7 @@ func (moa *MultiOrgAlertmanager) cleanupOrphanLocalOrgState(ctx context.Context,
 	// Remove all orphaned items from kvstore by listing all existing items
 	// in our used namespace and comparing them to the currently active
 	// organizations.
-	storedFiles := []string{NotificationLogFilename, SilencesFilename}
+	storedFiles := []string{NotificationLogFilename, SilencesFilename, SilenceSchedulesKey}
 	for _, fileName := range storedFiles {
 		keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, fileName)
 		if err != nil {
@@ -412,6 +417,26 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembers returns the name of this instance and the sorted names of the live members of the high availability
+// cluster, including this instance. It returns false if Grafana does not run in high availability mode.
+func (moa *MultiOrgAlertmanager) ClusterMembers() (string, []string, bool) {
+	switch p := moa.peer.(type) {
+	case *redisPeer:
+		members := append([]string(nil), p.Members()...)
+		sort.Strings(members)
+		return p.withPrefix(p.name), members, true
+	case *alertingCluster.Peer:
+		nodes := p.Peers()
+		members := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			members = append(members, n.Name)
+		}
+		sort.Strings(members)
+		return p.Name(), members, true
+	}
+	return "", nil, false
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
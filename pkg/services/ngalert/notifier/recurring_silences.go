@@ -0,0 +1,537 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// SilenceSchedulesKey is the key under which the silence presets and the recurring silences of an organization are stored.
+	SilenceSchedulesKey = "silence_schedules"
+
+	// recurringSilenceLookahead is how far ahead silences are created for the occurrences of recurring silences.
+	recurringSilenceLookahead = 24 * time.Hour
+	// maxRecurringSilenceOccurrence is the maximum duration of a single silence of a recurring silence.
+	// Longer occurrences, e.g. of time intervals that span weeks, are split into several silences.
+	maxRecurringSilenceOccurrence = 7 * 24 * time.Hour
+)
+
+var (
+	ErrSilencePresetNotFound    = errors.New("silence preset not found")
+	ErrSilencePresetInUse       = errors.New("silence preset is used by one or more recurring silences")
+	ErrInvalidSilencePreset     = errors.New("invalid silence preset")
+	ErrRecurringSilenceNotFound = errors.New("recurring silence not found")
+	ErrInvalidRecurringSilence  = errors.New("invalid recurring silence")
+)
+
+type silenceSchedules struct {
+	Presets           []apimodels.SilencePreset    `json:"presets,omitempty"`
+	RecurringSilences []apimodels.RecurringSilence `json:"recurringSilences,omitempty"`
+}
+
+func (s silenceSchedules) preset(uid string) (int, bool) {
+	for i, p := range s.Presets {
+		if p.UID == uid {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (s silenceSchedules) recurringSilence(uid string) (int, bool) {
+	for i, rs := range s.RecurringSilences {
+		if rs.UID == uid {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// matchers returns the matchers of the recurring silence, or the ones of its preset.
+func (s silenceSchedules) matchers(rs apimodels.RecurringSilence) (amv2.Matchers, error) {
+	if rs.PresetUID == "" {
+		return rs.Matchers, nil
+	}
+	idx, ok := s.preset(rs.PresetUID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSilencePresetNotFound, rs.PresetUID)
+	}
+	return s.Presets[idx].Matchers, nil
+}
+
+// GetSilencePresets returns the silence presets of the organization.
+func (moa *MultiOrgAlertmanager) GetSilencePresets(ctx context.Context, orgID int64) ([]apimodels.SilencePreset, error) {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if schedules.Presets == nil {
+		return []apimodels.SilencePreset{}, nil
+	}
+	return schedules.Presets, nil
+}
+
+// CreateSilencePreset creates a silence preset. A UID is generated if the preset does not have one.
+func (moa *MultiOrgAlertmanager) CreateSilencePreset(ctx context.Context, orgID int64, p apimodels.SilencePreset) (apimodels.SilencePreset, error) {
+	if err := validateSilencePreset(p); err != nil {
+		return apimodels.SilencePreset{}, err
+	}
+
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return apimodels.SilencePreset{}, err
+	}
+	if p.UID == "" {
+		p.UID = util.GenerateShortUID()
+	} else if _, ok := schedules.preset(p.UID); ok {
+		return apimodels.SilencePreset{}, fmt.Errorf("%w: silence preset with UID %s already exists", ErrInvalidSilencePreset, p.UID)
+	}
+	schedules.Presets = append(schedules.Presets, p)
+	return p, moa.saveSilenceSchedules(ctx, orgID, schedules)
+}
+
+// UpdateSilencePreset updates a silence preset. The silences of the recurring silences that use the preset are
+// expired and created again with the new matchers.
+func (moa *MultiOrgAlertmanager) UpdateSilencePreset(ctx context.Context, orgID int64, p apimodels.SilencePreset) (apimodels.SilencePreset, error) {
+	if err := validateSilencePreset(p); err != nil {
+		return apimodels.SilencePreset{}, err
+	}
+
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return apimodels.SilencePreset{}, err
+	}
+	idx, ok := schedules.preset(p.UID)
+	if !ok {
+		return apimodels.SilencePreset{}, ErrSilencePresetNotFound
+	}
+	schedules.Presets[idx] = p
+
+	now := time.Now()
+	for i := range schedules.RecurringSilences {
+		if rs := &schedules.RecurringSilences[i]; rs.PresetUID == p.UID {
+			moa.expireRecurringSilence(ctx, orgID, rs, now)
+		}
+	}
+	moa.materializeRecurringSilencesForOrg(ctx, orgID, &schedules, now)
+	return p, moa.saveSilenceSchedules(ctx, orgID, schedules)
+}
+
+// DeleteSilencePreset deletes a silence preset. It fails if a recurring silence uses the preset.
+func (moa *MultiOrgAlertmanager) DeleteSilencePreset(ctx context.Context, orgID int64, uid string) error {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	idx, ok := schedules.preset(uid)
+	if !ok {
+		return ErrSilencePresetNotFound
+	}
+	for _, rs := range schedules.RecurringSilences {
+		if rs.PresetUID == uid {
+			return fmt.Errorf("%w: %s", ErrSilencePresetInUse, rs.Name)
+		}
+	}
+	schedules.Presets = append(schedules.Presets[:idx], schedules.Presets[idx+1:]...)
+	return moa.saveSilenceSchedules(ctx, orgID, schedules)
+}
+
+// GetRecurringSilences returns the recurring silences of the organization.
+func (moa *MultiOrgAlertmanager) GetRecurringSilences(ctx context.Context, orgID int64) ([]apimodels.RecurringSilence, error) {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if schedules.RecurringSilences == nil {
+		return []apimodels.RecurringSilence{}, nil
+	}
+	return schedules.RecurringSilences, nil
+}
+
+// CreateRecurringSilence creates a recurring silence and the silences of its occurrences that start within the lookahead.
+// A UID is generated if the recurring silence does not have one.
+func (moa *MultiOrgAlertmanager) CreateRecurringSilence(ctx context.Context, orgID int64, rs apimodels.RecurringSilence) (apimodels.RecurringSilence, error) {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+	if err := validateRecurringSilence(rs, schedules); err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+	if rs.UID == "" {
+		rs.UID = util.GenerateShortUID()
+	} else if _, ok := schedules.recurringSilence(rs.UID); ok {
+		return apimodels.RecurringSilence{}, fmt.Errorf("%w: recurring silence with UID %s already exists", ErrInvalidRecurringSilence, rs.UID)
+	}
+	rs.Silences = nil
+	schedules.RecurringSilences = append(schedules.RecurringSilences, rs)
+
+	moa.materializeRecurringSilencesForOrg(ctx, orgID, &schedules, time.Now())
+	if err := moa.saveSilenceSchedules(ctx, orgID, schedules); err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+	return schedules.RecurringSilences[len(schedules.RecurringSilences)-1], nil
+}
+
+// UpdateRecurringSilence updates a recurring silence. Its silences that have not ended yet are expired and created again.
+func (moa *MultiOrgAlertmanager) UpdateRecurringSilence(ctx context.Context, orgID int64, rs apimodels.RecurringSilence) (apimodels.RecurringSilence, error) {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+	idx, ok := schedules.recurringSilence(rs.UID)
+	if !ok {
+		return apimodels.RecurringSilence{}, ErrRecurringSilenceNotFound
+	}
+	if err := validateRecurringSilence(rs, schedules); err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+
+	now := time.Now()
+	moa.expireRecurringSilence(ctx, orgID, &schedules.RecurringSilences[idx], now)
+	rs.Silences = nil
+	schedules.RecurringSilences[idx] = rs
+
+	moa.materializeRecurringSilencesForOrg(ctx, orgID, &schedules, now)
+	if err := moa.saveSilenceSchedules(ctx, orgID, schedules); err != nil {
+		return apimodels.RecurringSilence{}, err
+	}
+	return schedules.RecurringSilences[idx], nil
+}
+
+// DeleteRecurringSilence deletes a recurring silence and expires its silences that have not ended yet.
+func (moa *MultiOrgAlertmanager) DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error {
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	schedules, err := moa.getSilenceSchedules(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	idx, ok := schedules.recurringSilence(uid)
+	if !ok {
+		return ErrRecurringSilenceNotFound
+	}
+	moa.expireRecurringSilence(ctx, orgID, &schedules.RecurringSilences[idx], time.Now())
+	schedules.RecurringSilences = append(schedules.RecurringSilences[:idx], schedules.RecurringSilences[idx+1:]...)
+	return moa.saveSilenceSchedules(ctx, orgID, schedules)
+}
+
+// SyncRecurringSilences creates the silences of the occurrences of the recurring silences of all organizations that
+// start within the lookahead, and forgets the silences that have ended.
+func (moa *MultiOrgAlertmanager) SyncRecurringSilences(ctx context.Context, now time.Time) {
+	keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, SilenceSchedulesKey)
+	if err != nil {
+		moa.logger.Error("Failed to list recurring silences", "error", err)
+		return
+	}
+
+	moa.silenceSchedulesMtx.Lock()
+	defer moa.silenceSchedulesMtx.Unlock()
+
+	for _, key := range keys {
+		if key.Key != SilenceSchedulesKey {
+			continue
+		}
+		schedules, err := moa.getSilenceSchedules(ctx, key.OrgId)
+		if err != nil {
+			moa.logger.Error("Failed to get recurring silences", "org", key.OrgId, "error", err)
+			continue
+		}
+		if len(schedules.RecurringSilences) == 0 {
+			continue
+		}
+		if !moa.materializeRecurringSilencesForOrg(ctx, key.OrgId, &schedules, now) {
+			continue
+		}
+		if err := moa.saveSilenceSchedules(ctx, key.OrgId, schedules); err != nil {
+			moa.logger.Error("Failed to save recurring silences", "org", key.OrgId, "error", err)
+		}
+	}
+}
+
+func (moa *MultiOrgAlertmanager) getSilenceSchedules(ctx context.Context, orgID int64) (silenceSchedules, error) {
+	kv := kvstore.WithNamespace(moa.kvStore, orgID, KVNamespace)
+	content, exists, err := kv.Get(ctx, SilenceSchedulesKey)
+	if err != nil {
+		return silenceSchedules{}, fmt.Errorf("error reading %s from database: %w", SilenceSchedulesKey, err)
+	}
+	var schedules silenceSchedules
+	if !exists {
+		return schedules, nil
+	}
+	if err := json.Unmarshal([]byte(content), &schedules); err != nil {
+		return silenceSchedules{}, fmt.Errorf("error decoding %s: %w", SilenceSchedulesKey, err)
+	}
+	return schedules, nil
+}
+
+func (moa *MultiOrgAlertmanager) saveSilenceSchedules(ctx context.Context, orgID int64, schedules silenceSchedules) error {
+	kv := kvstore.WithNamespace(moa.kvStore, orgID, KVNamespace)
+	if len(schedules.Presets) == 0 && len(schedules.RecurringSilences) == 0 {
+		return kv.Del(ctx, SilenceSchedulesKey)
+	}
+	content, err := json.Marshal(schedules)
+	if err != nil {
+		return err
+	}
+	return kv.Set(ctx, SilenceSchedulesKey, string(content))
+}
+
+// materializeRecurringSilencesForOrg creates the silences of the recurring silences in the Alertmanager of the
+// organization. It returns true if the recurring silences were changed. If the Alertmanager is not ready, the silences
+// are created by the next sync.
+func (moa *MultiOrgAlertmanager) materializeRecurringSilencesForOrg(ctx context.Context, orgID int64, schedules *silenceSchedules, now time.Time) bool {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		moa.logger.Debug("Skipping creation of recurring silences", "org", orgID, "error", err)
+		return false
+	}
+	changed, err := materializeRecurringSilences(ctx, am, schedules, now)
+	if err != nil {
+		moa.logger.Error("Failed to create silences of recurring silences", "org", orgID, "error", err)
+	}
+	return changed
+}
+
+// expireRecurringSilence expires the silences of the recurring silence that have not ended yet.
+func (moa *MultiOrgAlertmanager) expireRecurringSilence(ctx context.Context, orgID int64, rs *apimodels.RecurringSilence, now time.Time) {
+	silences := rs.Silences
+	rs.Silences = nil
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		moa.logger.Warn("Unable to expire silences of recurring silence", "org", orgID, "uid", rs.UID, "error", err)
+		return
+	}
+	for _, o := range silences {
+		if !o.EndsAt.After(now) {
+			continue
+		}
+		if err := am.DeleteSilence(ctx, o.SilenceID); err != nil && !errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			moa.logger.Warn("Failed to expire silence of recurring silence", "org", orgID, "uid", rs.UID, "silence", o.SilenceID, "error", err)
+		}
+	}
+}
+
+// materializeRecurringSilences creates a silence for each occurrence of the recurring silences that starts within the
+// lookahead and does not overlap an occurrence that already has a silence, and forgets the silences that have ended.
+// A silence that already exists in the Alertmanager with the same matchers and end, e.g. because another replica
+// created it, is used instead of creating a new one. It returns true if the recurring silences were changed.
+func materializeRecurringSilences(ctx context.Context, am Alertmanager, schedules *silenceSchedules, now time.Time) (bool, error) {
+	existing, err := am.ListSilences(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to list silences: %w", err)
+	}
+
+	var errs []error
+	changed := false
+	for i := range schedules.RecurringSilences {
+		rs := &schedules.RecurringSilences[i]
+
+		current := rs.Silences[:0]
+		for _, o := range rs.Silences {
+			if o.EndsAt.After(now) {
+				current = append(current, o)
+			}
+		}
+		if len(current) != len(rs.Silences) {
+			changed = true
+		}
+		rs.Silences = current
+
+		matchers, err := schedules.matchers(*rs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurring silence %s: %w", rs.UID, err))
+			continue
+		}
+		for _, o := range occurrences(rs.TimeIntervals, now, now.Add(recurringSilenceLookahead)) {
+			if overlapsAny(rs.Silences, o) {
+				continue
+			}
+			o.SilenceID = findSilence(existing, matchers, o.EndsAt)
+			if o.SilenceID == "" {
+				id, err := am.CreateSilence(ctx, recurringSilenceToPostable(*rs, matchers, o))
+				if err != nil {
+					errs = append(errs, fmt.Errorf("recurring silence %s: %w", rs.UID, err))
+					continue
+				}
+				o.SilenceID = id
+			}
+			rs.Silences = append(rs.Silences, o)
+			changed = true
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+// occurrences returns the periods during which the time intervals are active and that start before the given time,
+// at minute resolution. An occurrence that is in progress at from starts at from, truncated to the minute.
+func occurrences(intervals []timeinterval.TimeInterval, from, to time.Time) []apimodels.RecurringSilenceOccurrence {
+	var result []apimodels.RecurringSilenceOccurrence
+	var start time.Time
+	active := false
+	for t := from.Truncate(time.Minute); ; t = t.Add(time.Minute) {
+		contains := containsTime(intervals, t)
+		if active && (!contains || t.Sub(start) >= maxRecurringSilenceOccurrence) {
+			result = append(result, apimodels.RecurringSilenceOccurrence{StartsAt: start, EndsAt: t})
+			active = false
+		}
+		if active {
+			continue
+		}
+		if !t.Before(to) {
+			break
+		}
+		if contains {
+			start, active = t, true
+		}
+	}
+	return result
+}
+
+func containsTime(intervals []timeinterval.TimeInterval, t time.Time) bool {
+	for _, ti := range intervals {
+		if ti.ContainsTime(t.UTC()) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsAny(occurrences []apimodels.RecurringSilenceOccurrence, o apimodels.RecurringSilenceOccurrence) bool {
+	for _, e := range occurrences {
+		if e.StartsAt.Before(o.EndsAt) && o.StartsAt.Before(e.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSilence returns the ID of a silence that is not expired and has the given matchers and end.
+func findSilence(silences apimodels.GettableSilences, matchers amv2.Matchers, endsAt time.Time) string {
+	key := matchersKey(matchers)
+	for _, s := range silences {
+		if s == nil || s.ID == nil || s.EndsAt == nil || s.Status == nil || s.Status.State == nil {
+			continue
+		}
+		if *s.Status.State == amv2.SilenceStatusStateExpired || !time.Time(*s.EndsAt).Equal(endsAt) {
+			continue
+		}
+		if matchersKey(s.Matchers) == key {
+			return *s.ID
+		}
+	}
+	return ""
+}
+
+func matchersKey(ms amv2.Matchers) string {
+	matchers, err := toLabelMatchers(ms)
+	if err != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		keys = append(keys, m.String())
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func recurringSilenceToPostable(rs apimodels.RecurringSilence, matchers amv2.Matchers, o apimodels.RecurringSilenceOccurrence) *apimodels.PostableSilence {
+	comment := rs.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Recurring silence %s", rs.Name)
+	}
+	createdBy := rs.CreatedBy
+	startsAt, endsAt := strfmt.DateTime(o.StartsAt), strfmt.DateTime(o.EndsAt)
+	return &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			Matchers:  matchers,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+		},
+	}
+}
+
+func validateSilencePreset(p apimodels.SilencePreset) error {
+	if p.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSilencePreset)
+	}
+	if err := validateSilenceMatchers(p.Matchers); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSilencePreset, err)
+	}
+	return nil
+}
+
+func validateRecurringSilence(rs apimodels.RecurringSilence, schedules silenceSchedules) error {
+	if rs.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRecurringSilence)
+	}
+	if rs.CreatedBy == "" {
+		return fmt.Errorf("%w: createdBy is required", ErrInvalidRecurringSilence)
+	}
+	if rs.PresetUID != "" {
+		if len(rs.Matchers) > 0 {
+			return fmt.Errorf("%w: either matchers or presetUid must be set, not both", ErrInvalidRecurringSilence)
+		}
+		if _, ok := schedules.preset(rs.PresetUID); !ok {
+			return fmt.Errorf("%w: silence preset %s does not exist", ErrInvalidRecurringSilence, rs.PresetUID)
+		}
+	} else if err := validateSilenceMatchers(rs.Matchers); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRecurringSilence, err)
+	}
+	if len(rs.TimeIntervals) == 0 {
+		return fmt.Errorf("%w: at least one time interval is required", ErrInvalidRecurringSilence)
+	}
+	return nil
+}
+
+func validateSilenceMatchers(ms amv2.Matchers) error {
+	if len(ms) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if err := ms.Validate(strfmt.Default); err != nil {
+		return err
+	}
+	if _, err := toLabelMatchers(ms); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestOccurrences(t *testing.T) {
+	sundayNight := parseTimeIntervals(t, `[{"weekdays": ["sunday"], "times": [{"start_time": "02:00", "end_time": "04:00"}]}]`)
+	always := parseTimeIntervals(t, `[{"weekdays": ["monday:sunday"]}]`)
+	saturday := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		intervals []timeinterval.TimeInterval
+		from      time.Time
+		to        time.Time
+		expected  []apimodels.RecurringSilenceOccurrence
+	}{
+		{
+			name:      "occurrence that starts within the range",
+			intervals: sundayNight,
+			from:      saturday,
+			to:        saturday.Add(24 * time.Hour),
+			expected: []apimodels.RecurringSilenceOccurrence{
+				{StartsAt: sunday.Add(2 * time.Hour), EndsAt: sunday.Add(4 * time.Hour)},
+			},
+		},
+		{
+			name:      "occurrence in progress starts at the beginning of the range",
+			intervals: sundayNight,
+			from:      sunday.Add(3*time.Hour + 30*time.Second),
+			to:        sunday.Add(27 * time.Hour),
+			expected: []apimodels.RecurringSilenceOccurrence{
+				{StartsAt: sunday.Add(3 * time.Hour), EndsAt: sunday.Add(4 * time.Hour)},
+			},
+		},
+		{
+			name:      "no occurrence within the range",
+			intervals: sundayNight,
+			from:      sunday.Add(5 * time.Hour),
+			to:        sunday.Add(29 * time.Hour),
+		},
+		{
+			name:      "occurrence longer than the maximum is split",
+			intervals: always,
+			from:      saturday,
+			to:        saturday.Add(24 * time.Hour),
+			expected: []apimodels.RecurringSilenceOccurrence{
+				{StartsAt: saturday, EndsAt: saturday.Add(maxRecurringSilenceOccurrence)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, occurrences(tc.intervals, tc.from, tc.to))
+		})
+	}
+}
+
+func TestMultiOrgAlertmanager_RecurringSilences(t *testing.T) {
+	ctx := context.Background()
+	mam := setupRecurringSilencesMam(t)
+	always := parseTimeIntervals(t, `[{"weekdays": ["monday:sunday"]}]`)
+
+	preset, err := mam.CreateSilencePreset(ctx, 1, apimodels.SilencePreset{
+		Name:     "maintenance",
+		Matchers: amv2.Matchers{equalMatcher("team", "a")},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, preset.UID)
+
+	t.Run("should fail if the recurring silence is invalid", func(t *testing.T) {
+		_, err := mam.CreateRecurringSilence(ctx, 1, apimodels.RecurringSilence{Name: "test", CreatedBy: "test", TimeIntervals: always})
+		require.ErrorIs(t, err, ErrInvalidRecurringSilence)
+
+		_, err = mam.CreateRecurringSilence(ctx, 1, apimodels.RecurringSilence{Name: "test", CreatedBy: "test", PresetUID: "unknown", TimeIntervals: always})
+		require.ErrorIs(t, err, ErrInvalidRecurringSilence)
+	})
+
+	rs, err := mam.CreateRecurringSilence(ctx, 1, apimodels.RecurringSilence{
+		Name:          "test",
+		CreatedBy:     "test",
+		PresetUID:     preset.UID,
+		TimeIntervals: always,
+	})
+	require.NoError(t, err)
+	require.Len(t, rs.Silences, 1)
+
+	am, err := mam.AlertmanagerFor(1)
+	require.NoError(t, err)
+	silence, err := am.GetSilence(ctx, rs.Silences[0].SilenceID)
+	require.NoError(t, err)
+	require.Equal(t, amv2.SilenceStatusStateActive, *silence.Status.State)
+	require.Equal(t, "Recurring silence test", *silence.Comment)
+
+	t.Run("sync should not create silences for occurrences that have silences", func(t *testing.T) {
+		mam.SyncRecurringSilences(ctx, time.Now().Add(time.Hour))
+		silences, err := mam.GetRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, rs.Silences, silences[0].Silences)
+	})
+
+	t.Run("should not delete a preset that is in use", func(t *testing.T) {
+		require.ErrorIs(t, mam.DeleteSilencePreset(ctx, 1, preset.UID), ErrSilencePresetInUse)
+	})
+
+	t.Run("updating the preset should create the silences again", func(t *testing.T) {
+		preset.Matchers = amv2.Matchers{equalMatcher("team", "b")}
+		_, err := mam.UpdateSilencePreset(ctx, 1, preset)
+		require.NoError(t, err)
+
+		old, err := am.GetSilence(ctx, rs.Silences[0].SilenceID)
+		require.NoError(t, err)
+		require.Equal(t, amv2.SilenceStatusStateExpired, *old.Status.State)
+
+		silences, err := mam.GetRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, silences[0].Silences, 1)
+		updated, err := am.GetSilence(ctx, silences[0].Silences[0].SilenceID)
+		require.NoError(t, err)
+		require.Equal(t, "b", *updated.Matchers[0].Value)
+		rs = silences[0]
+	})
+
+	t.Run("deleting the recurring silence should expire its silences", func(t *testing.T) {
+		require.NoError(t, mam.DeleteRecurringSilence(ctx, 1, rs.UID))
+		silence, err := am.GetSilence(ctx, rs.Silences[0].SilenceID)
+		require.NoError(t, err)
+		require.Equal(t, amv2.SilenceStatusStateExpired, *silence.Status.State)
+
+		require.ErrorIs(t, mam.DeleteRecurringSilence(ctx, 1, rs.UID), ErrRecurringSilenceNotFound)
+		require.NoError(t, mam.DeleteSilencePreset(ctx, 1, preset.UID))
+	})
+}
+
+func setupRecurringSilencesMam(t *testing.T) *MultiOrgAlertmanager {
+	t.Helper()
+	configStore := NewFakeConfigStore(t, map[int64]*models.AlertConfiguration{})
+	orgStore := &FakeOrgStore{
+		orgs: []int64{1},
+	}
+	cfg := &setting.Cfg{
+		DataPath:        t.TempDir(),
+		UnifiedAlerting: setting.UnifiedAlertingSettings{AlertmanagerConfigPollInterval: 3 * time.Minute, DefaultConfiguration: setting.GetAlertmanagerDefaultConfiguration()}, // do not poll in tests.
+	}
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	m := metrics.NewNGAlert(prometheus.NewPedanticRegistry())
+	mam, err := NewMultiOrgAlertmanager(cfg, configStore, orgStore, ngfakes.NewFakeKVStore(t), ngfakes.NewFakeProvisioningStore(), secretsService.GetDecryptedValue, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"), secretsService, &featuremgmt.FeatureManager{})
+	require.NoError(t, err)
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(context.Background()))
+	return mam
+}
+
+func parseTimeIntervals(t *testing.T, s string) []timeinterval.TimeInterval {
+	t.Helper()
+	var intervals []timeinterval.TimeInterval
+	require.NoError(t, json.Unmarshal([]byte(s), &intervals))
+	return intervals
+}
+
+func equalMatcher(name, value string) *amv2.Matcher {
+	return &amv2.Matcher{
+		Name:    util.Pointer(name),
+		Value:   util.Pointer(value),
+		IsRegex: util.Pointer(false),
+		IsEqual: util.Pointer(true),
+	}
+}
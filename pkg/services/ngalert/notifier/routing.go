@@ -0,0 +1,265 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// SimulateRouting returns how the Alertmanager of the organization handles an alert with the labels at the given time:
+// the policies of the notification policy tree that the alert matches, their effective grouping and timing options,
+// the mute timings that are active, and the inhibition rules and silences that match the alert.
+// It uses the current configuration, including the autogenerated policies, and the current silences and alerts.
+func (moa *MultiOrgAlertmanager) SimulateRouting(ctx context.Context, orgID int64, lset model.LabelSet, now time.Time) (apimodels.TestRoutesResult, error) {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return apimodels.TestRoutesResult{}, err
+	}
+
+	cfg, err := moa.GetAlertmanagerConfiguration(ctx, orgID, true)
+	if err != nil {
+		return apimodels.TestRoutesResult{}, err
+	}
+
+	silences, err := am.ListSilences(ctx, nil)
+	if err != nil {
+		return apimodels.TestRoutesResult{}, fmt.Errorf("failed to list silences: %w", err)
+	}
+
+	alerts, err := am.GetAlerts(ctx, true, true, true, nil, "")
+	if err != nil {
+		return apimodels.TestRoutesResult{}, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	return simulateRouting(cfg.AlertmanagerConfig.Config, silences, alerts, lset, now)
+}
+
+// simulateRouting routes the labels through the policy tree of the configuration the way the Alertmanager dispatcher does,
+// and checks the mute timings of the matched policies, the inhibition rules and the silences at the given time.
+func simulateRouting(cfg apimodels.Config, silences apimodels.GettableSilences, alerts apimodels.GettableAlerts, lset model.LabelSet, now time.Time) (apimodels.TestRoutesResult, error) {
+	if cfg.Route == nil {
+		return apimodels.TestRoutesResult{}, fmt.Errorf("no route present in current alertmanager config")
+	}
+
+	result := apimodels.TestRoutesResult{
+		Labels: make(map[string]string, len(lset)),
+		Time:   now,
+	}
+	for k, v := range lset {
+		result.Labels[string(k)] = string(v)
+	}
+
+	muteTimings := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		muteTimings[mt.Name] = mt.TimeIntervals
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	for _, m := range matchRoutes(root, lset, []apimodels.TestRoutesPolicy{toTestRoutesPolicy(root, 0)}) {
+		result.Routes = append(result.Routes, toTestRoutesRoute(m, lset, muteTimings, now))
+	}
+
+	for _, cr := range cfg.InhibitRules {
+		rule, ok := matchInhibitRule(cr, alerts, lset)
+		if !ok {
+			continue
+		}
+		if len(rule.InhibitingAlerts) > 0 {
+			result.Inhibited = true
+		}
+		result.InhibitRules = append(result.InhibitRules, rule)
+	}
+
+	for _, s := range silences {
+		silence, ok, err := matchSilence(s, lset, now)
+		if err != nil {
+			return apimodels.TestRoutesResult{}, err
+		}
+		if ok {
+			result.Silenced = true
+			result.Silences = append(result.Silences, silence)
+		}
+	}
+
+	return result, nil
+}
+
+type matchedRoute struct {
+	route *dispatch.Route
+	path  []apimodels.TestRoutesPolicy
+}
+
+// matchRoutes returns the routes that match the labels together with their paths from the root of the tree.
+// It follows the same rules as dispatch.Route.Match.
+func matchRoutes(r *dispatch.Route, lset model.LabelSet, path []apimodels.TestRoutesPolicy) []matchedRoute {
+	if !r.Matchers.Matches(lset) {
+		return nil
+	}
+
+	var all []matchedRoute
+	for i, cr := range r.Routes {
+		childPath := append(append(make([]apimodels.TestRoutesPolicy, 0, len(path)+1), path...), toTestRoutesPolicy(cr, i))
+		matches := matchRoutes(cr, lset, childPath)
+		all = append(all, matches...)
+		if matches != nil && !cr.Continue {
+			break
+		}
+	}
+
+	// If no child route matches, the route itself handles the alert.
+	if len(all) == 0 {
+		all = append(all, matchedRoute{route: r, path: path})
+	}
+	return all
+}
+
+func toTestRoutesPolicy(r *dispatch.Route, index int) apimodels.TestRoutesPolicy {
+	return apimodels.TestRoutesPolicy{
+		Index:    index,
+		Receiver: r.RouteOpts.Receiver,
+		Matchers: matchersToStrings(r.Matchers),
+		Continue: r.Continue,
+	}
+}
+
+func toTestRoutesRoute(m matchedRoute, lset model.LabelSet, muteTimings map[string][]timeinterval.TimeInterval, now time.Time) apimodels.TestRoutesRoute {
+	opts := m.route.RouteOpts
+	result := apimodels.TestRoutesRoute{
+		Path:              m.path,
+		Receiver:          opts.Receiver,
+		GroupLabels:       map[string]string{},
+		GroupWait:         model.Duration(opts.GroupWait),
+		GroupInterval:     model.Duration(opts.GroupInterval),
+		RepeatInterval:    model.Duration(opts.RepeatInterval),
+		MuteTimeIntervals: opts.MuteTimeIntervals,
+	}
+
+	if opts.GroupByAll {
+		result.GroupBy = []string{"..."}
+	} else {
+		for ln := range opts.GroupBy {
+			result.GroupBy = append(result.GroupBy, string(ln))
+		}
+		sort.Strings(result.GroupBy)
+	}
+	for ln, lv := range lset {
+		if _, ok := opts.GroupBy[ln]; ok || opts.GroupByAll {
+			result.GroupLabels[string(ln)] = string(lv)
+		}
+	}
+
+	for _, name := range opts.MuteTimeIntervals {
+		for _, ti := range muteTimings[name] {
+			if ti.ContainsTime(now.UTC()) {
+				result.ActiveMuteTimeIntervals = append(result.ActiveMuteTimeIntervals, name)
+				break
+			}
+		}
+	}
+	result.Muted = len(result.ActiveMuteTimeIntervals) > 0
+	return result
+}
+
+// matchInhibitRule returns the inhibition rule if the labels match its target matchers, along with the firing alerts
+// that inhibit the alert. As in the Alertmanager inhibitor, if the labels match the source matchers too, alerts that
+// match both sides do not inhibit it.
+func matchInhibitRule(cr apimodels.InhibitRule, alerts apimodels.GettableAlerts, lset model.LabelSet) (apimodels.TestRoutesInhibitRule, bool) {
+	rule := inhibit.NewInhibitRule(cr)
+	if !rule.TargetMatchers.Matches(lset) {
+		return apimodels.TestRoutesInhibitRule{}, false
+	}
+
+	result := apimodels.TestRoutesInhibitRule{
+		SourceMatchers: matchersToStrings(rule.SourceMatchers),
+		TargetMatchers: matchersToStrings(rule.TargetMatchers),
+	}
+	for ln := range rule.Equal {
+		result.Equal = append(result.Equal, string(ln))
+	}
+	sort.Strings(result.Equal)
+
+	excludeTwoSidedMatch := rule.SourceMatchers.Matches(lset)
+	fp := lset.Fingerprint()
+	for _, a := range alerts {
+		if a.Status != nil && a.Status.State != nil && *a.Status.State != amv2.AlertStatusStateActive {
+			continue
+		}
+		source := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			source[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if source.Fingerprint() == fp || !rule.SourceMatchers.Matches(source) {
+			continue
+		}
+		if excludeTwoSidedMatch && rule.TargetMatchers.Matches(source) {
+			continue
+		}
+		equal := true
+		for ln := range rule.Equal {
+			if source[ln] != lset[ln] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			result.InhibitingAlerts = append(result.InhibitingAlerts, a.Labels)
+		}
+	}
+	return result, true
+}
+
+// matchSilence returns the silence if it is active at the given time and its matchers match the labels.
+func matchSilence(s *apimodels.GettableSilence, lset model.LabelSet, now time.Time) (apimodels.TestRoutesSilence, bool, error) {
+	if s == nil || s.StartsAt == nil || s.EndsAt == nil {
+		return apimodels.TestRoutesSilence{}, false, nil
+	}
+	startsAt, endsAt := time.Time(*s.StartsAt), time.Time(*s.EndsAt)
+	if now.Before(startsAt) || !now.Before(endsAt) {
+		return apimodels.TestRoutesSilence{}, false, nil
+	}
+
+	matchers, err := toLabelMatchers(s.Matchers)
+	if err != nil {
+		return apimodels.TestRoutesSilence{}, false, fmt.Errorf("invalid matcher of silence: %w", err)
+	}
+	if len(matchers) == 0 || !matchers.Matches(lset) {
+		return apimodels.TestRoutesSilence{}, false, nil
+	}
+
+	result := apimodels.TestRoutesSilence{
+		Matchers: matchersToStrings(matchers),
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+	}
+	if s.ID != nil {
+		result.ID = *s.ID
+	}
+	if s.CreatedBy != nil {
+		result.CreatedBy = *s.CreatedBy
+	}
+	if s.Comment != nil {
+		result.Comment = *s.Comment
+	}
+	return result, true, nil
+}
+
+func matchersToStrings(matchers labels.Matchers) []string {
+	if len(matchers) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		result = append(result, m.String())
+	}
+	return result
+}
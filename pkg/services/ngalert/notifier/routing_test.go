@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const testRoutingConfig = `{
+	"route": {
+		"receiver": "default",
+		"group_by": ["alertname"],
+		"group_wait": "30s",
+		"routes": [
+			{
+				"receiver": "team-a",
+				"object_matchers": [["team", "=", "a"]],
+				"continue": true
+			},
+			{
+				"receiver": "team-a-critical",
+				"object_matchers": [["team", "=", "a"], ["severity", "=", "critical"]],
+				"group_by": ["..."],
+				"repeat_interval": "1h",
+				"mute_time_intervals": ["weekends"]
+			},
+			{
+				"receiver": "team-b",
+				"object_matchers": [["team", "=", "b"]]
+			}
+		]
+	},
+	"inhibit_rules": [
+		{
+			"source_matchers": ["severity=critical"],
+			"target_matchers": ["severity=warning"],
+			"equal": ["team"]
+		}
+	],
+	"mute_time_intervals": [
+		{
+			"name": "weekends",
+			"time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+		}
+	]
+}`
+
+func TestSimulateRouting(t *testing.T) {
+	var cfg apimodels.Config
+	require.NoError(t, json.Unmarshal([]byte(testRoutingConfig), &cfg))
+
+	// Saturday.
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should return all matched routes with effective options", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "HighCPU", "team": "a", "severity": "critical"}
+		result, err := simulateRouting(cfg, nil, nil, lset, now)
+		require.NoError(t, err)
+
+		require.Len(t, result.Routes, 2)
+
+		first := result.Routes[0]
+		require.Equal(t, "team-a", first.Receiver)
+		require.Len(t, first.Path, 2)
+		require.Equal(t, "default", first.Path[0].Receiver)
+		require.Equal(t, 0, first.Path[1].Index)
+		require.True(t, first.Path[1].Continue)
+		require.Equal(t, []string{"alertname"}, first.GroupBy)
+		require.Equal(t, map[string]string{"alertname": "HighCPU"}, first.GroupLabels)
+		require.Equal(t, model.Duration(30*time.Second), first.GroupWait)
+		require.False(t, first.Muted)
+
+		second := result.Routes[1]
+		require.Equal(t, "team-a-critical", second.Receiver)
+		require.Equal(t, 1, second.Path[1].Index)
+		require.Equal(t, []string{"..."}, second.GroupBy)
+		require.Equal(t, map[string]string{"alertname": "HighCPU", "team": "a", "severity": "critical"}, second.GroupLabels)
+		require.Equal(t, model.Duration(30*time.Second), second.GroupWait)
+		require.Equal(t, model.Duration(time.Hour), second.RepeatInterval)
+		require.Equal(t, []string{"weekends"}, second.MuteTimeIntervals)
+		require.Equal(t, []string{"weekends"}, second.ActiveMuteTimeIntervals)
+		require.True(t, second.Muted)
+	})
+
+	t.Run("should not report inactive mute timings", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "HighCPU", "team": "a", "severity": "critical"}
+		result, err := simulateRouting(cfg, nil, nil, lset, now.AddDate(0, 0, 2))
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 2)
+		require.Empty(t, result.Routes[1].ActiveMuteTimeIntervals)
+		require.False(t, result.Routes[1].Muted)
+	})
+
+	t.Run("should return root route if no child route matches", func(t *testing.T) {
+		result, err := simulateRouting(cfg, nil, nil, model.LabelSet{"alertname": "HighCPU", "team": "c"}, now)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		require.Equal(t, "default", result.Routes[0].Receiver)
+		require.Len(t, result.Routes[0].Path, 1)
+	})
+
+	t.Run("should return inhibition rules and inhibiting alerts", func(t *testing.T) {
+		alerts := apimodels.GettableAlerts{
+			gettableAlert(amv2.AlertStatusStateActive, amv2.LabelSet{"alertname": "Down", "severity": "critical", "team": "a"}),
+			gettableAlert(amv2.AlertStatusStateActive, amv2.LabelSet{"alertname": "Down", "severity": "critical", "team": "b"}),
+			gettableAlert(amv2.AlertStatusStateSuppressed, amv2.LabelSet{"alertname": "Lag", "severity": "critical", "team": "a"}),
+		}
+
+		result, err := simulateRouting(cfg, nil, alerts, model.LabelSet{"alertname": "HighCPU", "team": "a", "severity": "warning"}, now)
+		require.NoError(t, err)
+		require.True(t, result.Inhibited)
+		require.Len(t, result.InhibitRules, 1)
+		require.Equal(t, []string{"team"}, result.InhibitRules[0].Equal)
+		require.Equal(t, []map[string]string{{"alertname": "Down", "severity": "critical", "team": "a"}}, result.InhibitRules[0].InhibitingAlerts)
+
+		result, err = simulateRouting(cfg, nil, alerts, model.LabelSet{"alertname": "HighCPU", "team": "c", "severity": "warning"}, now)
+		require.NoError(t, err)
+		require.False(t, result.Inhibited)
+		require.Len(t, result.InhibitRules, 1)
+		require.Empty(t, result.InhibitRules[0].InhibitingAlerts)
+
+		result, err = simulateRouting(cfg, nil, alerts, model.LabelSet{"alertname": "HighCPU", "team": "a", "severity": "critical"}, now)
+		require.NoError(t, err)
+		require.False(t, result.Inhibited)
+		require.Empty(t, result.InhibitRules)
+	})
+
+	t.Run("should return active silences that match the labels", func(t *testing.T) {
+		silences := apimodels.GettableSilences{
+			gettableSilence("active", now.Add(-time.Hour), now.Add(time.Hour), "team", "a", false, true),
+			gettableSilence("expired", now.Add(-2*time.Hour), now.Add(-time.Hour), "team", "a", false, true),
+			gettableSilence("pending", now.Add(time.Hour), now.Add(2*time.Hour), "team", "a", false, true),
+			gettableSilence("regex", now.Add(-time.Hour), now.Add(time.Hour), "team", "b|c", true, true),
+			gettableSilence("not-equal", now.Add(-time.Hour), now.Add(time.Hour), "team", "b", false, false),
+		}
+
+		result, err := simulateRouting(cfg, silences, nil, model.LabelSet{"alertname": "HighCPU", "team": "a"}, now)
+		require.NoError(t, err)
+		require.True(t, result.Silenced)
+		require.Len(t, result.Silences, 2)
+		require.Equal(t, "active", result.Silences[0].ID)
+		require.Equal(t, []string{`team="a"`}, result.Silences[0].Matchers)
+		require.Equal(t, "not-equal", result.Silences[1].ID)
+		require.Equal(t, []string{`team!="b"`}, result.Silences[1].Matchers)
+
+		result, err = simulateRouting(cfg, silences, nil, model.LabelSet{"alertname": "HighCPU", "team": "b"}, now)
+		require.NoError(t, err)
+		require.True(t, result.Silenced)
+		require.Len(t, result.Silences, 1)
+		require.Equal(t, "regex", result.Silences[0].ID)
+		require.Equal(t, []string{`team=~"b|c"`}, result.Silences[0].Matchers)
+	})
+}
+
+func gettableAlert(state string, lset amv2.LabelSet) *amv2.GettableAlert {
+	return &amv2.GettableAlert{
+		Alert:  amv2.Alert{Labels: lset},
+		Status: &amv2.AlertStatus{State: util.Pointer(state)},
+	}
+}
+
+func gettableSilence(id string, startsAt, endsAt time.Time, name, value string, isRegex, isEqual bool) *apimodels.GettableSilence {
+	starts, ends := strfmt.DateTime(startsAt), strfmt.DateTime(endsAt)
+	return &apimodels.GettableSilence{
+		ID: util.Pointer(id),
+		Silence: amv2.Silence{
+			StartsAt: &starts,
+			EndsAt:   &ends,
+			Matchers: amv2.Matchers{{
+				Name:    util.Pointer(name),
+				Value:   util.Pointer(value),
+				IsRegex: util.Pointer(isRegex),
+				IsEqual: util.Pointer(isEqual),
+			}},
+		},
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
 )
 
 func (am *alertmanager) ListSilences(_ context.Context, filter []string) (alertingNotify.GettableSilences, error) {
@@ -21,3 +23,30 @@ func (am *alertmanager) CreateSilence(_ context.Context, ps *alertingNotify.Post
 func (am *alertmanager) DeleteSilence(_ context.Context, silenceID string) error {
 	return am.Base.DeleteSilence(silenceID)
 }
+
+// toLabelMatchers converts the matchers of a silence to label matchers. Incomplete matchers are skipped.
+func toLabelMatchers(ms amv2.Matchers) (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(ms))
+	for _, m := range ms {
+		if m == nil || m.Name == nil || m.Value == nil {
+			continue
+		}
+		matchType := labels.MatchEqual
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		switch {
+		case isRegex && isEqual:
+			matchType = labels.MatchRegexp
+		case isRegex && !isEqual:
+			matchType = labels.MatchNotRegexp
+		case !isRegex && !isEqual:
+			matchType = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(matchType, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
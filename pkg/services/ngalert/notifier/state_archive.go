@@ -0,0 +1,207 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ErrInvalidAlertmanagerState is returned when an imported Alertmanager state cannot be decoded.
+var ErrInvalidAlertmanagerState = errors.New("invalid Alertmanager state")
+
+// AlertmanagerState is the internal state of an Alertmanager in the binary format that the Alertmanager persists.
+type AlertmanagerState struct {
+	Silences        []byte
+	NotificationLog []byte
+}
+
+// ExportState returns the silences and the notification log of the Alertmanager of the organization.
+// They are read from the database, where the Alertmanager persists them periodically and when it stops.
+func (moa *MultiOrgAlertmanager) ExportState(ctx context.Context, orgID int64) (AlertmanagerState, error) {
+	if _, err := moa.AlertmanagerFor(orgID); err != nil && !errors.Is(err, ErrAlertmanagerNotReady) {
+		return AlertmanagerState{}, err
+	}
+
+	kv := kvstore.WithNamespace(moa.kvStore, orgID, KVNamespace)
+	silences, err := getStoredState(ctx, kv, SilencesFilename)
+	if err != nil {
+		return AlertmanagerState{}, err
+	}
+	nflog, err := getStoredState(ctx, kv, NotificationLogFilename)
+	if err != nil {
+		return AlertmanagerState{}, err
+	}
+	return AlertmanagerState{Silences: silences, NotificationLog: nflog}, nil
+}
+
+// ImportState merges the silences and the notification log with the ones of the Alertmanager of the organization.
+// A silence or a notification log entry replaces the existing one only if it was updated more recently.
+// The Alertmanager is stopped, which persists its current state, and started again with the merged state.
+func (moa *MultiOrgAlertmanager) ImportState(ctx context.Context, orgID int64, st AlertmanagerState) error {
+	// Validate the imported state before stopping the Alertmanager.
+	if _, err := mergeSilences(nil, st.Silences); err != nil {
+		return fmt.Errorf("%w: failed to decode silences: %s", ErrInvalidAlertmanagerState, err)
+	}
+	if _, err := mergeNotificationLog(nil, st.NotificationLog); err != nil {
+		return fmt.Errorf("%w: failed to decode notification log: %s", ErrInvalidAlertmanagerState, err)
+	}
+
+	moa.alertmanagersMtx.Lock()
+	defer moa.alertmanagersMtx.Unlock()
+
+	am, ok := moa.alertmanagers[orgID]
+	if !ok {
+		return ErrNoAlertmanagerForOrg
+	}
+
+	moa.logger.Info("Stopping Alertmanager to import its state", "org", orgID)
+	am.StopAndWait()
+	delete(moa.alertmanagers, orgID)
+	moa.metrics.RemoveOrgRegistry(orgID)
+
+	kv := kvstore.WithNamespace(moa.kvStore, orgID, KVNamespace)
+	mergeErr := errors.Join(
+		mergeStoredState(ctx, kv, SilencesFilename, st.Silences, mergeSilences),
+		mergeStoredState(ctx, kv, NotificationLogFilename, st.NotificationLog, mergeNotificationLog),
+	)
+	if mergeErr != nil {
+		moa.logger.Error("Failed to import Alertmanager state, starting the Alertmanager with its current state", "org", orgID, "error", mergeErr)
+	}
+
+	// The new Alertmanager loads the state from the database.
+	newAM, err := moa.factory(ctx, orgID)
+	if err != nil {
+		return errors.Join(mergeErr, fmt.Errorf("failed to start Alertmanager: %w", err))
+	}
+	dbConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	switch {
+	case errors.Is(err, store.ErrNoAlertmanagerConfiguration):
+		err = newAM.SaveAndApplyDefaultConfig(ctx)
+	case err == nil:
+		err = newAM.ApplyConfig(ctx, dbConfig)
+	}
+	moa.alertmanagers[orgID] = newAM
+	if err != nil {
+		return errors.Join(mergeErr, fmt.Errorf("failed to apply Alertmanager configuration: %w", err))
+	}
+	moa.logger.Info("Started Alertmanager with the imported state", "org", orgID)
+	return mergeErr
+}
+
+func getStoredState(ctx context.Context, kv *kvstore.NamespacedKVStore, key string) ([]byte, error) {
+	content, exists, err := kv.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s from database: %w", key, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	b, err := decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func mergeStoredState(ctx context.Context, kv *kvstore.NamespacedKVStore, key string, imported []byte, merge func(current, imported []byte) ([]byte, error)) error {
+	if len(imported) == 0 {
+		return nil
+	}
+	current, err := getStoredState(ctx, kv, key)
+	if err != nil {
+		return err
+	}
+	merged, err := merge(current, imported)
+	if err != nil {
+		return fmt.Errorf("failed to merge %s: %w", key, err)
+	}
+	return kv.Set(ctx, key, encode(merged))
+}
+
+// mergeSilences merges two snapshots of silences. A silence replaces the one with the same ID if it was updated more recently.
+func mergeSilences(current, imported []byte) ([]byte, error) {
+	silences := map[string]*silencepb.MeshSilence{}
+	for _, b := range [][]byte{current, imported} {
+		r := bytes.NewReader(b)
+		for {
+			var s silencepb.MeshSilence
+			if _, err := pbutil.ReadDelimited(r, &s); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			if s.Silence == nil {
+				continue
+			}
+			if prev, ok := silences[s.Silence.Id]; ok && !s.Silence.UpdatedAt.After(prev.Silence.UpdatedAt) {
+				continue
+			}
+			silences[s.Silence.Id] = &s
+		}
+	}
+
+	ids := make([]string, 0, len(silences))
+	for id := range silences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		if _, err := pbutil.WriteDelimited(&buf, silences[id]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNotificationLog merges two snapshots of notification logs. An entry replaces the one of the same group and receiver
+// if it is more recent.
+func mergeNotificationLog(current, imported []byte) ([]byte, error) {
+	entries := map[string]*nflogpb.MeshEntry{}
+	for _, b := range [][]byte{current, imported} {
+		r := bytes.NewReader(b)
+		for {
+			var e nflogpb.MeshEntry
+			if _, err := pbutil.ReadDelimited(r, &e); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			if e.Entry == nil || e.Entry.Receiver == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s:%s/%s/%d", e.Entry.GroupKey, e.Entry.Receiver.GroupName, e.Entry.Receiver.Integration, e.Entry.Receiver.Idx)
+			if prev, ok := entries[key]; ok && !e.Entry.Timestamp.After(prev.Entry.Timestamp) {
+				continue
+			}
+			entries[key] = &e
+		}
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		if _, err := pbutil.WriteDelimited(&buf, entries[k]); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto" // nolint:staticcheck
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSilences(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	silence := func(id, comment string, updatedAt time.Time) *silencepb.MeshSilence {
+		return &silencepb.MeshSilence{
+			Silence: &silencepb.Silence{
+				Id:        id,
+				Comment:   comment,
+				Matchers:  []*silencepb.Matcher{{Type: silencepb.Matcher_EQUAL, Name: "team", Pattern: "a"}},
+				StartsAt:  now,
+				EndsAt:    now.Add(time.Hour),
+				UpdatedAt: updatedAt,
+			},
+			ExpiresAt: now.Add(time.Hour),
+		}
+	}
+
+	current := writeDelimited(t, silence("a", "current", now), silence("b", "current", now))
+	imported := writeDelimited(t, silence("a", "imported", now.Add(time.Minute)), silence("b", "imported", now.Add(-time.Minute)), silence("c", "imported", now))
+
+	merged, err := mergeSilences(current, imported)
+	require.NoError(t, err)
+
+	comments := map[string]string{}
+	r := bytes.NewReader(merged)
+	for {
+		var s silencepb.MeshSilence
+		_, err := pbutil.ReadDelimited(r, &s)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		comments[s.Silence.Id] = s.Silence.Comment
+	}
+	require.Equal(t, map[string]string{"a": "imported", "b": "current", "c": "imported"}, comments)
+
+	t.Run("should fail for invalid snapshot", func(t *testing.T) {
+		_, err := mergeSilences(current, []byte("invalid"))
+		require.Error(t, err)
+	})
+
+	t.Run("should return current snapshot if nothing is imported", func(t *testing.T) {
+		merged, err := mergeSilences(current, nil)
+		require.NoError(t, err)
+		require.Equal(t, current, merged)
+	})
+}
+
+func TestMergeNotificationLog(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(groupKey, integration string, ts time.Time, firing ...uint64) *nflogpb.MeshEntry {
+		return &nflogpb.MeshEntry{
+			Entry: &nflogpb.Entry{
+				GroupKey:     []byte(groupKey),
+				Receiver:     &nflogpb.Receiver{GroupName: "team-a", Integration: integration},
+				Timestamp:    ts,
+				FiringAlerts: firing,
+			},
+			ExpiresAt: ts.Add(time.Hour),
+		}
+	}
+
+	current := writeDelimited(t, entry("{}:{alertname=\"a\"}", "email", now, 1), entry("{}:{alertname=\"b\"}", "email", now, 1))
+	imported := writeDelimited(t,
+		entry("{}:{alertname=\"a\"}", "email", now.Add(time.Minute), 1, 2),
+		entry("{}:{alertname=\"b\"}", "email", now.Add(-time.Minute), 1, 2),
+		entry("{}:{alertname=\"b\"}", "slack", now, 3),
+	)
+
+	merged, err := mergeNotificationLog(current, imported)
+	require.NoError(t, err)
+
+	firing := map[string][]uint64{}
+	r := bytes.NewReader(merged)
+	for {
+		var e nflogpb.MeshEntry
+		_, err := pbutil.ReadDelimited(r, &e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		firing[string(e.Entry.GroupKey)+"/"+e.Entry.Receiver.Integration] = e.Entry.FiringAlerts
+	}
+	require.Equal(t, map[string][]uint64{
+		"{}:{alertname=\"a\"}/email": {1, 2},
+		"{}:{alertname=\"b\"}/email": {1},
+		"{}:{alertname=\"b\"}/slack": {3},
+	}, firing)
+}
+
+func writeDelimited(t *testing.T, msgs ...proto.Message) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, m := range msgs {
+		_, err := pbutil.WriteDelimited(&buf, m)
+		require.NoError(t, err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,350 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	alertingLogging "github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// TemplatedHTTPType is the type of the contact point that sends an HTTP request which method, URL, headers and body are
+// templates.
+const TemplatedHTTPType = "http"
+
+const (
+	templatedHTTPDefaultMethod          = http.MethodPost
+	templatedHTTPDefaultSignatureHeader = "X-Grafana-Alerting-Signature"
+	templatedHTTPTimestampHeader        = "X-Grafana-Alerting-Signature-Timestamp"
+	templatedHTTPTimeout                = 30 * time.Second
+	// templatedHTTPMaxErrorBody is the maximum number of bytes of the response body that are included in the error.
+	templatedHTTPMaxErrorBody = 1024
+)
+
+var errInvalidTemplatedHTTPConfig = errors.New("invalid HTTP contact point settings")
+
+// templatedHTTPConfig are the settings of the templated HTTP contact point.
+type templatedHTTPConfig struct {
+	// URL, HTTPMethod, Headers and Body are templates that are rendered with the notification data.
+	URL        string            `json:"url"`
+	HTTPMethod string            `json:"httpMethod,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+
+	Username string `json:"username,omitempty"`
+	Password string `json:"-"`
+
+	OAuth2ClientID     string `json:"oauth2_client_id,omitempty"`
+	OAuth2ClientSecret string `json:"-"`
+	OAuth2TokenURL     string `json:"oauth2_token_url,omitempty"`
+	// OAuth2Scopes is a comma-separated list of scopes.
+	OAuth2Scopes string `json:"oauth2_scopes,omitempty"`
+
+	TLSCACert             string `json:"tls_ca_cert,omitempty"`
+	TLSClientCert         string `json:"tls_client_cert,omitempty"`
+	TLSClientKey          string `json:"-"`
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify,omitempty"`
+
+	HMACSecret string `json:"-"`
+	HMACHeader string `json:"hmac_header,omitempty"`
+}
+
+// parseTemplatedHTTPConfig parses and validates the settings of the templated HTTP contact point, and decrypts its
+// secure settings.
+func parseTemplatedHTTPConfig(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, decryptFn alertingNotify.GetDecryptedValueFn) (*templatedHTTPConfig, error) {
+	settings := &templatedHTTPConfig{}
+	if len(cfg.Settings) > 0 {
+		if err := json.Unmarshal(cfg.Settings, settings); err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidTemplatedHTTPConfig, err)
+		}
+	}
+
+	secureSettings := make(map[string][]byte, len(cfg.SecureSettings))
+	for key, value := range cfg.SecureSettings {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode secure setting %s: %s", errInvalidTemplatedHTTPConfig, key, err)
+		}
+		secureSettings[key] = decoded
+	}
+	// Secrets are read from the settings if they are not in the secure settings, for example when they are provisioned.
+	var plain map[string]any
+	if len(cfg.Settings) > 0 {
+		_ = json.Unmarshal(cfg.Settings, &plain)
+	}
+	secret := func(key string) string {
+		fallback, _ := plain[key].(string)
+		return decryptFn(ctx, secureSettings, key, fallback)
+	}
+	settings.Password = secret("password")
+	settings.OAuth2ClientSecret = secret("oauth2_client_secret")
+	settings.TLSClientKey = secret("tls_client_key")
+	settings.HMACSecret = secret("hmac_secret")
+
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (c *templatedHTTPConfig) validate() error {
+	if strings.TrimSpace(c.URL) == "" {
+		return fmt.Errorf("%w: URL is required", errInvalidTemplatedHTTPConfig)
+	}
+	if c.Username != "" && c.OAuth2ClientID != "" {
+		return fmt.Errorf("%w: only one of basic authentication and OAuth2 can be set", errInvalidTemplatedHTTPConfig)
+	}
+	if c.OAuth2ClientID != "" || c.OAuth2ClientSecret != "" || c.OAuth2TokenURL != "" {
+		if c.OAuth2ClientID == "" || c.OAuth2ClientSecret == "" || c.OAuth2TokenURL == "" {
+			return fmt.Errorf("%w: OAuth2 requires client ID, client secret and token URL", errInvalidTemplatedHTTPConfig)
+		}
+		if _, err := url.ParseRequestURI(c.OAuth2TokenURL); err != nil {
+			return fmt.Errorf("%w: invalid OAuth2 token URL: %s", errInvalidTemplatedHTTPConfig, err)
+		}
+	}
+	if _, err := c.tlsConfig(); err != nil {
+		return err
+	}
+	for name := range c.Headers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: header name must not be empty", errInvalidTemplatedHTTPConfig)
+		}
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS configuration of the client, or nil if the default configuration is used.
+func (c *templatedHTTPConfig) tlsConfig() (*tls.Config, error) {
+	if c.TLSCACert == "" && c.TLSClientCert == "" && c.TLSClientKey == "" && !c.TLSInsecureSkipVerify {
+		return nil, nil
+	}
+	// The user can choose to skip the verification of the server certificate.
+	// nolint:gosec
+	cfg := &tls.Config{InsecureSkipVerify: c.TLSInsecureSkipVerify}
+	if c.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.TLSCACert)) {
+			return nil, fmt.Errorf("%w: invalid CA certificate", errInvalidTemplatedHTTPConfig)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.TLSClientCert != "" || c.TLSClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(c.TLSClientCert), []byte(c.TLSClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid client certificate or key: %s", errInvalidTemplatedHTTPConfig, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// client returns the HTTP client that sends the requests of the contact point.
+func (c *templatedHTTPConfig) client() (*http.Client, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Use the same proxy and TLS settings as the webhooks of the other contact points.
+	client := &http.Client{Transport: notifications.NewWebhookTransport(tlsConfig), Timeout: templatedHTTPTimeout}
+	if c.OAuth2ClientID == "" {
+		return client, nil
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(c.OAuth2Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	oauth2Config := clientcredentials.Config{
+		ClientID:     c.OAuth2ClientID,
+		ClientSecret: c.OAuth2ClientSecret,
+		TokenURL:     c.OAuth2TokenURL,
+		Scopes:       scopes,
+	}
+	// The token is requested with the same client, so the token endpoint is called with the same TLS configuration.
+	oauth2Client := oauth2Config.Client(context.WithValue(context.Background(), oauth2.HTTPClient, client))
+	oauth2Client.Timeout = templatedHTTPTimeout
+	return oauth2Client, nil
+}
+
+// isTemplatedHTTPIntegration returns true if the integration is a templated HTTP contact point.
+func isTemplatedHTTPIntegration(cfg *alertingNotify.GrafanaIntegrationConfig) bool {
+	return cfg.Type == TemplatedHTTPType
+}
+
+// ValidateTemplatedHTTPIntegration validates the settings of a templated HTTP contact point.
+func ValidateTemplatedHTTPIntegration(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, decryptFn alertingNotify.GetDecryptedValueFn) error {
+	_, err := parseTemplatedHTTPConfig(ctx, cfg, decryptFn)
+	return err
+}
+
+// templatedHTTPNotifier sends notifications as HTTP requests which method, URL, headers and body are rendered from
+// templates.
+type templatedHTTPNotifier struct {
+	settings              *templatedHTTPConfig
+	disableResolveMessage bool
+	client                *http.Client
+	tmpl                  *alertingTemplates.Template
+	log                   alertingLogging.Logger
+	now                   func() time.Time
+}
+
+func newTemplatedHTTPNotifier(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, tmpl *alertingTemplates.Template, decryptFn alertingNotify.GetDecryptedValueFn, orgID int64) (*templatedHTTPNotifier, error) {
+	settings, err := parseTemplatedHTTPConfig(ctx, cfg, decryptFn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := settings.client()
+	if err != nil {
+		return nil, err
+	}
+	return &templatedHTTPNotifier{
+		settings:              settings,
+		disableResolveMessage: cfg.DisableResolveMessage,
+		client:                client,
+		tmpl:                  tmpl,
+		log:                   LoggerFactory("ngalert.notifier.http", "org", orgID, "receiver", cfg.Name, "uid", cfg.UID),
+		now:                   time.Now,
+	}, nil
+}
+
+// newTemplatedHTTPIntegration creates the integration of a templated HTTP contact point.
+func newTemplatedHTTPIntegration(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, idx int, tmpl *alertingTemplates.Template, decryptFn alertingNotify.GetDecryptedValueFn, orgID int64) (*alertingNotify.Integration, error) {
+	n, err := newTemplatedHTTPNotifier(ctx, cfg, tmpl, decryptFn, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build integration %q of type %s: %w", cfg.Name, cfg.Type, err)
+	}
+	return alertingNotify.NewIntegration(n, n, cfg.Type, idx, cfg.Name), nil
+}
+
+// Notify renders the request from the templates and sends it.
+func (n *templatedHTTPNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	req, err := n.buildRequest(ctx, alerts...)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send the request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return true, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, templatedHTTPMaxErrorBody))
+	err = fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	// Server errors and rate limiting are temporary, so the notification is retried.
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, err
+}
+
+func (n *templatedHTTPNotifier) SendResolved() bool {
+	return !n.disableResolveMessage
+}
+
+// buildRequest renders the method, URL, headers and body of the request from the templates and signs the request.
+func (n *templatedHTTPNotifier) buildRequest(ctx context.Context, alerts ...*types.Alert) (*http.Request, error) {
+	var tmplErr error
+	tmpl, _ := alertingTemplates.TmplText(ctx, n.tmpl, alerts, n.log, &tmplErr)
+
+	method := templatedHTTPDefaultMethod
+	if n.settings.HTTPMethod != "" {
+		method = strings.ToUpper(strings.TrimSpace(tmpl(n.settings.HTTPMethod)))
+	}
+	u := strings.TrimSpace(tmpl(n.settings.URL))
+	body := tmpl(n.settings.Body)
+	headers := make(map[string]string, len(n.settings.Headers))
+	for name, value := range n.settings.Headers {
+		headers[name] = tmpl(value)
+	}
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to render the request: %w", tmplErr)
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("rendered URL %q is not a valid HTTP URL", u)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Grafana/"+setting.BuildVersion)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	if n.settings.Username != "" {
+		req.SetBasicAuth(n.settings.Username, n.settings.Password)
+	}
+	if n.settings.HMACSecret != "" {
+		header := n.settings.HMACHeader
+		if header == "" {
+			header = templatedHTTPDefaultSignatureHeader
+		}
+		timestamp := strconv.FormatInt(n.now().Unix(), 10)
+		req.Header.Set(templatedHTTPTimestampHeader, timestamp)
+		req.Header.Set(header, signTemplatedHTTPRequest(n.settings.HMACSecret, timestamp, body))
+	}
+	return req, nil
+}
+
+// signTemplatedHTTPRequest returns the hex-encoded HMAC-SHA256 of the timestamp and the body, separated by a dot.
+func signTemplatedHTTPRequest(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// splitTemplatedHTTPIntegrations returns a copy of the receiver without the templated HTTP contact points, and the
+// templated HTTP contact points of the receiver.
+func splitTemplatedHTTPIntegrations(receiver *alertingNotify.APIReceiver) (*alertingNotify.APIReceiver, []*alertingNotify.GrafanaIntegrationConfig) {
+	var httpIntegrations []*alertingNotify.GrafanaIntegrationConfig
+	other := make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(receiver.Integrations))
+	for _, cfg := range receiver.Integrations {
+		if isTemplatedHTTPIntegration(cfg) {
+			httpIntegrations = append(httpIntegrations, cfg)
+			continue
+		}
+		other = append(other, cfg)
+	}
+	if len(httpIntegrations) == 0 {
+		return receiver, nil
+	}
+	result := *receiver
+	result.Integrations = other
+	return &result, httpIntegrations
+}
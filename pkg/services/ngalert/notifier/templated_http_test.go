@@ -0,0 +1,254 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatedHTTPNotifier(t *testing.T) {
+	tmpl := templateForTests(t)
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "HighCPU", "team": "infra"},
+				Annotations: model.LabelSet{"summary": "CPU is high"},
+			},
+		},
+	}
+
+	type request struct {
+		method  string
+		path    string
+		headers http.Header
+		body    string
+	}
+	newServer := func(t *testing.T, status int) (*httptest.Server, chan request) {
+		requests := make(chan request, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			requests <- request{method: r.Method, path: r.URL.RequestURI(), headers: r.Header, body: string(b)}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, requests
+	}
+
+	t.Run("should render the request from the templates", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusOK)
+		n := newTestTemplatedHTTPNotifier(t, tmpl, map[string]any{
+			"url":        srv.URL + `/tickets/{{ .CommonLabels.team }}`,
+			"httpMethod": `{{ if eq .Status "firing" }}put{{ else }}delete{{ end }}`,
+			"headers":    map[string]string{"X-Alert": "{{ .CommonLabels.alertname }}"},
+			"body":       `{"summary": "{{ .CommonAnnotations.summary }}", "count": {{ len .Alerts.Firing }}}`,
+			"username":   "user",
+		}, map[string]string{"password": "secret"})
+
+		ok, err := n.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		r := <-requests
+		require.Equal(t, http.MethodPut, r.method)
+		require.Equal(t, "/tickets/infra", r.path)
+		require.Equal(t, "HighCPU", r.headers.Get("X-Alert"))
+		require.Equal(t, "application/json", r.headers.Get("Content-Type"))
+		require.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")), r.headers.Get("Authorization"))
+		require.JSONEq(t, `{"summary": "CPU is high", "count": 1}`, r.body)
+	})
+
+	t.Run("should sign the request", func(t *testing.T) {
+		srv, requests := newServer(t, http.StatusOK)
+		n := newTestTemplatedHTTPNotifier(t, tmpl, map[string]any{
+			"url":         srv.URL,
+			"body":        `{{ .CommonLabels.alertname }}`,
+			"hmac_header": "X-Signature",
+		}, map[string]string{"hmac_secret": "key"})
+		n.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+		_, err := n.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+
+		r := <-requests
+		require.Equal(t, "1700000000", r.headers.Get(templatedHTTPTimestampHeader))
+		require.Equal(t, signTemplatedHTTPRequest("key", "1700000000", "HighCPU"), r.headers.Get("X-Signature"))
+	})
+
+	t.Run("should use the token of the OAuth2 client credentials flow", func(t *testing.T) {
+		tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			require.Equal(t, "tickets:write", r.PostForm.Get("scope"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		t.Cleanup(tokenSrv.Close)
+		srv, requests := newServer(t, http.StatusOK)
+		n := newTestTemplatedHTTPNotifier(t, tmpl, map[string]any{
+			"url":              srv.URL,
+			"oauth2_client_id": "grafana",
+			"oauth2_token_url": tokenSrv.URL,
+			"oauth2_scopes":    "tickets:write",
+		}, map[string]string{"oauth2_client_secret": "secret"})
+
+		_, err := n.Notify(context.Background(), alerts...)
+		require.NoError(t, err)
+		r := <-requests
+		require.Equal(t, "Bearer token", r.headers.Get("Authorization"))
+	})
+
+	t.Run("should retry only server errors", func(t *testing.T) {
+		for status, retry := range map[int]bool{
+			http.StatusInternalServerError: true,
+			http.StatusTooManyRequests:     true,
+			http.StatusBadRequest:          false,
+		} {
+			srv, requests := newServer(t, status)
+			n := newTestTemplatedHTTPNotifier(t, tmpl, map[string]any{"url": srv.URL}, nil)
+
+			ok, err := n.Notify(context.Background(), alerts...)
+			require.Error(t, err)
+			require.Equal(t, retry, ok)
+			<-requests
+		}
+	})
+
+	t.Run("should fail if the rendered URL is invalid", func(t *testing.T) {
+		n := newTestTemplatedHTTPNotifier(t, tmpl, map[string]any{"url": "{{ .CommonLabels.team }}"}, nil)
+
+		ok, err := n.Notify(context.Background(), alerts...)
+		require.ErrorContains(t, err, "not a valid HTTP URL")
+		require.False(t, ok)
+	})
+}
+
+func TestTemplatedHTTPConfigClient(t *testing.T) {
+	settings, err := parseTemplatedHTTPConfig(context.Background(), newTemplatedHTTPIntegrationConfig(t, map[string]any{
+		"url":                      "http://localhost",
+		"tls_insecure_skip_verify": true,
+	}, nil), fakeDecryptFn)
+	require.NoError(t, err)
+
+	client, err := settings.client()
+	require.NoError(t, err)
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.NotNil(t, transport.Proxy, "the client should use the proxy of the environment like webhooks")
+	require.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	require.Equal(t, tls.RenegotiateFreelyAsClient, transport.TLSClientConfig.Renegotiation)
+}
+
+func TestParseTemplatedHTTPConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings map[string]any
+		secure   map[string]string
+		expErr   string
+	}{
+		{
+			name:     "valid settings",
+			settings: map[string]any{"url": "http://localhost", "username": "user"},
+			secure:   map[string]string{"password": "secret"},
+		},
+		{
+			name:     "missing URL",
+			settings: map[string]any{"body": "{}"},
+			expErr:   "URL is required",
+		},
+		{
+			name:     "basic authentication and OAuth2",
+			settings: map[string]any{"url": "http://localhost", "username": "user", "oauth2_client_id": "id", "oauth2_token_url": "http://localhost/token"},
+			secure:   map[string]string{"oauth2_client_secret": "secret"},
+			expErr:   "only one of basic authentication and OAuth2",
+		},
+		{
+			name:     "incomplete OAuth2",
+			settings: map[string]any{"url": "http://localhost", "oauth2_client_id": "id"},
+			expErr:   "OAuth2 requires client ID, client secret and token URL",
+		},
+		{
+			name:     "invalid CA certificate",
+			settings: map[string]any{"url": "http://localhost", "tls_ca_cert": "invalid"},
+			expErr:   "invalid CA certificate",
+		},
+		{
+			name:     "client certificate without key",
+			settings: map[string]any{"url": "http://localhost", "tls_client_cert": "invalid"},
+			expErr:   "invalid client certificate or key",
+		},
+		{
+			name:     "secret in settings",
+			settings: map[string]any{"url": "http://localhost", "oauth2_client_id": "id", "oauth2_token_url": "http://localhost/token", "oauth2_client_secret": "secret"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTemplatedHTTPIntegration(context.Background(), newTemplatedHTTPIntegrationConfig(t, tc.settings, tc.secure), fakeDecryptFn)
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errInvalidTemplatedHTTPConfig)
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestSplitTemplatedHTTPIntegrations(t *testing.T) {
+	webhook := &alertingNotify.GrafanaIntegrationConfig{Type: "webhook"}
+	httpIntegration := &alertingNotify.GrafanaIntegrationConfig{Type: TemplatedHTTPType}
+	receiver := &alertingNotify.APIReceiver{
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{httpIntegration, webhook},
+		},
+	}
+
+	other, httpIntegrations := splitTemplatedHTTPIntegrations(receiver)
+	require.Equal(t, []*alertingNotify.GrafanaIntegrationConfig{webhook}, other.Integrations)
+	require.Equal(t, []*alertingNotify.GrafanaIntegrationConfig{httpIntegration}, httpIntegrations)
+	require.Len(t, receiver.Integrations, 2)
+}
+
+func fakeDecryptFn(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+	if v, ok := sjd[key]; ok {
+		return string(v)
+	}
+	return fallback
+}
+
+func newTemplatedHTTPIntegrationConfig(t *testing.T, settings map[string]any, secure map[string]string) *alertingNotify.GrafanaIntegrationConfig {
+	t.Helper()
+	raw, err := json.Marshal(settings)
+	require.NoError(t, err)
+	secureSettings := make(map[string]string, len(secure))
+	for k, v := range secure {
+		secureSettings[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return &alertingNotify.GrafanaIntegrationConfig{
+		UID:            "uid",
+		Name:           "tickets",
+		Type:           TemplatedHTTPType,
+		Settings:       raw,
+		SecureSettings: secureSettings,
+	}
+}
+
+func newTestTemplatedHTTPNotifier(t *testing.T, tmpl *template.Template, settings map[string]any, secure map[string]string) *templatedHTTPNotifier {
+	t.Helper()
+	n, err := newTemplatedHTTPNotifier(context.Background(), newTemplatedHTTPIntegrationConfig(t, settings, secure), tmpl, fakeDecryptFn, 1)
+	require.NoError(t, err)
+	return n
+}
@@ -3,12 +3,17 @@ package notifier
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	alertingTemplates "github.com/grafana/alerting/templates"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -34,6 +39,7 @@ type TestReceiverConfigResult struct {
 
 func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*TestReceiversResult, error) {
 	receivers := make([]*alertingNotify.APIReceiver, 0, len(c.Receivers))
+	httpIntegrations := make(map[string][]*alertingNotify.GrafanaIntegrationConfig)
 	for _, r := range c.Receivers {
 		integrations := make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(r.GrafanaManagedReceivers))
 		for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
@@ -46,12 +52,17 @@ func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestRecei
 				SecureSettings:        gr.SecureSettings,
 			})
 		}
-		receivers = append(receivers, &alertingNotify.APIReceiver{
+		// Templated HTTP contact points are not known to the alerting package, so they are tested separately.
+		receiver, httpCfgs := splitTemplatedHTTPIntegrations(&alertingNotify.APIReceiver{
 			ConfigReceiver: r.Receiver,
 			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
 				Integrations: integrations,
 			},
 		})
+		if len(httpCfgs) > 0 {
+			httpIntegrations[r.Receiver.Name] = httpCfgs
+		}
+		receivers = append(receivers, receiver)
 	}
 	var alert *alertingNotify.TestReceiversConfigAlertParams
 	if c.Alert != nil {
@@ -84,6 +95,32 @@ func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestRecei
 		})
 	}
 
+	if len(httpIntegrations) > 0 {
+		tmpl, err := am.templateForTestReceivers()
+		if err != nil {
+			return nil, err
+		}
+		for i, resultReceiver := range resultReceivers {
+			cfgs, ok := httpIntegrations[resultReceiver.Name]
+			if !ok {
+				continue
+			}
+			resultReceivers[i].Configs = append(resultReceivers[i].Configs, testTemplatedHTTPIntegrations(ctx, resultReceiver.Name, cfgs, &result.Alert, tmpl, am.decryptFn, am.orgID)...)
+			delete(httpIntegrations, resultReceiver.Name)
+		}
+		// Receivers that have only templated HTTP contact points might not be in the result of the alerting package.
+		for _, r := range c.Receivers {
+			cfgs, ok := httpIntegrations[r.Receiver.Name]
+			if !ok {
+				continue
+			}
+			resultReceivers = append(resultReceivers, TestReceiverResult{
+				Name:    r.Receiver.Name,
+				Configs: testTemplatedHTTPIntegrations(ctx, r.Receiver.Name, cfgs, &result.Alert, tmpl, am.decryptFn, am.orgID),
+			})
+		}
+	}
+
 	return &TestReceiversResult{
 		Alert:     result.Alert,
 		Receivers: resultReceivers,
@@ -91,6 +128,51 @@ func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestRecei
 	}, err
 }
 
+// templateForTestReceivers returns the templates of the applied configuration.
+func (am *alertmanager) templateForTestReceivers() (*alertingTemplates.Template, error) {
+	var paths []string
+	am.Base.WithLock(func() {
+		paths = am.templatePaths
+	})
+	tmpl, err := template.FromGlobs(paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	externalURL, err := url.Parse(am.Settings.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.ExternalURL = externalURL
+	return tmpl, nil
+}
+
+// testTemplatedHTTPIntegrations sends the test alert to the templated HTTP contact points of a receiver and returns
+// the result of each contact point.
+func testTemplatedHTTPIntegrations(ctx context.Context, receiverName string, cfgs []*alertingNotify.GrafanaIntegrationConfig, alert *types.Alert, tmpl *alertingTemplates.Template, decryptFn alertingNotify.GetDecryptedValueFn, orgID int64) []TestReceiverConfigResult {
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%s-%d", receiverName, alert.Labels.Fingerprint(), time.Now().Unix()))
+	ctx = notify.WithGroupLabels(ctx, alert.Labels)
+	ctx = notify.WithReceiverName(ctx, receiverName)
+
+	results := make([]TestReceiverConfigResult, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		result := TestReceiverConfigResult{
+			Name:   cfg.Name,
+			UID:    cfg.UID,
+			Status: "ok",
+		}
+		n, err := newTemplatedHTTPNotifier(ctx, cfg, tmpl, decryptFn, orgID)
+		if err == nil {
+			_, err = n.Notify(ctx, alert)
+		}
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 func (am *alertmanager) GetReceivers(_ context.Context) ([]apimodels.Receiver, error) {
 	apiReceivers := make([]apimodels.Receiver, 0, len(am.Base.GetReceivers()))
 	for _, rcv := range am.Base.GetReceivers() {
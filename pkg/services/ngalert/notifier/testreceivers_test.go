@@ -2,12 +2,20 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 )
 
 func TestInvalidReceiverError_Error(t *testing.T) {
@@ -80,3 +88,76 @@ func TestProcessNotifierError(t *testing.T) {
 		require.Equal(t, err, alertingNotify.ProcessIntegrationError(r, err))
 	})
 }
+
+func TestAlertmanager_TestReceiversTemplatedHTTP(t *testing.T) {
+	ctx := context.Background()
+	am := setupAMTest(t)
+	require.NoError(t, am.SaveAndApplyDefaultConfig(ctx))
+
+	bodies := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- string(b)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	newReceiver := func(t *testing.T, uid, url string) *apimodels.PostableGrafanaReceiver {
+		t.Helper()
+		settings, err := json.Marshal(map[string]any{
+			"url":  url,
+			"body": `{{ .Receiver }}: {{ .CommonLabels.alertname }}`,
+		})
+		require.NoError(t, err)
+		return &apimodels.PostableGrafanaReceiver{
+			UID:      uid,
+			Name:     "tickets",
+			Type:     TemplatedHTTPType,
+			Settings: apimodels.RawMessage(settings),
+		}
+	}
+
+	result, err := am.TestReceivers(ctx, apimodels.TestReceiversConfigBodyParams{
+		Alert: &apimodels.TestReceiversConfigAlertParams{
+			Labels: model.LabelSet{"alertname": "TestAlert"},
+		},
+		Receivers: []*apimodels.PostableApiReceiver{{
+			Receiver: config.Receiver{Name: "tickets"},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+				GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+					newReceiver(t, "ok", srv.URL+"/ok"),
+				},
+			},
+		}, {
+			Receiver: config.Receiver{Name: "broken"},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+				GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+					newReceiver(t, "fail", srv.URL+"/fail"),
+				},
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Receivers, 2)
+
+	configs := make(map[string]TestReceiverConfigResult)
+	for _, r := range result.Receivers {
+		require.Len(t, r.Configs, 1)
+		configs[r.Name] = r.Configs[0]
+	}
+
+	require.Equal(t, "ok", configs["tickets"].UID)
+	require.Equal(t, "ok", configs["tickets"].Status)
+	require.NoError(t, configs["tickets"].Error)
+
+	require.Equal(t, "fail", configs["broken"].UID)
+	require.Equal(t, "failed", configs["broken"].Status)
+	require.ErrorContains(t, configs["broken"].Error, "unexpected status code 400")
+
+	received := []string{<-bodies, <-bodies}
+	require.ElementsMatch(t, []string{"tickets: TestAlert", "broken: TestAlert"}, received)
+}
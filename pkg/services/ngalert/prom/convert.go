@@ -0,0 +1,238 @@
+// Package prom converts Prometheus rule files to Grafana-managed alert and recording rules.
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// queryRefID is the RefID of the query that runs the PromQL expression of the rule.
+	queryRefID = "A"
+	// conditionRefID is the RefID of the condition of alert rules.
+	conditionRefID = "B"
+	// conditionExpression fires for every series returned by the query, the same way Prometheus fires an alert for
+	// every element of the vector that is returned by the expression.
+	conditionExpression = "is_number($A) || is_nan($A) || is_inf($A)"
+	// queryTimeRange is the relative time range of the query. The query is instant, so it only defines the evaluation
+	// time and the look-back period of the range selectors is defined by the expression itself.
+	queryTimeRange = 10 * time.Minute
+)
+
+// valueVariable matches the $value variable of Prometheus templates, which is the value of the alert's expression.
+var valueVariable = regexp.MustCompile(`\$value\b`)
+
+// Config defines how Prometheus rules are converted.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus data source that runs the expressions of the rules.
+	DatasourceUID string
+	// DefaultInterval is the evaluation interval of the groups that do not specify one.
+	DefaultInterval time.Duration
+	// RecordingRules permits converting recording rules. If false, a rule group that contains recording rules cannot be converted.
+	RecordingRules bool
+}
+
+// Converter converts Prometheus rule groups to Grafana-managed rule groups.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID must be specified")
+	}
+	if cfg.DefaultInterval <= 0 {
+		return nil, errors.New("default evaluation interval must be positive")
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// ParseRuleFile parses a Prometheus rule file in YAML format.
+func ParseRuleFile(b []byte) (apimodels.PrometheusRuleFile, error) {
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return apimodels.PrometheusRuleFile{}, fmt.Errorf("failed to parse Prometheus rule file: %w", err)
+	}
+	return file, nil
+}
+
+// ConvertRuleFile converts all groups of the file to rule groups of the folder. The titles of the rules are unique
+// in the folder: if several rules have the same name, the titles of the second and following ones get a suffix.
+func (c *Converter) ConvertRuleFile(orgID int64, namespaceUID string, file apimodels.PrometheusRuleFile) ([]models.AlertRuleGroup, error) {
+	titles := make(map[string]int)
+	names := make(map[string]struct{}, len(file.Groups))
+	result := make([]models.AlertRuleGroup, 0, len(file.Groups))
+	for _, g := range file.Groups {
+		if _, ok := names[g.Name]; ok {
+			return nil, fmt.Errorf("%w: rule group %q is defined more than once", models.ErrAlertRuleFailedValidation, g.Name)
+		}
+		names[g.Name] = struct{}{}
+		group, err := c.convertRuleGroup(orgID, namespaceUID, g, titles)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, group)
+	}
+	return result, nil
+}
+
+// ConvertRuleGroup converts a Prometheus rule group to a rule group of the folder.
+func (c *Converter) ConvertRuleGroup(orgID int64, namespaceUID string, group apimodels.PrometheusRuleGroup) (models.AlertRuleGroup, error) {
+	return c.convertRuleGroup(orgID, namespaceUID, group, make(map[string]int))
+}
+
+func (c *Converter) convertRuleGroup(orgID int64, namespaceUID string, group apimodels.PrometheusRuleGroup, titles map[string]int) (models.AlertRuleGroup, error) {
+	if group.Name == "" {
+		return models.AlertRuleGroup{}, fmt.Errorf("%w: rule group name cannot be empty", models.ErrAlertRuleFailedValidation)
+	}
+	interval := time.Duration(group.Interval)
+	if interval == 0 {
+		interval = c.cfg.DefaultInterval
+	}
+	result := models.AlertRuleGroup{
+		Title:     group.Name,
+		FolderUID: namespaceUID,
+		Interval:  int64(interval.Seconds()),
+		Rules:     make([]models.AlertRule, 0, len(group.Rules)),
+	}
+	for idx, r := range group.Rules {
+		rule, err := c.convertRule(r)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("%w: invalid rule at index [%d] of group %q: %s", models.ErrAlertRuleFailedValidation, idx, group.Name, err.Error())
+		}
+		titles[rule.Title]++
+		if n := titles[rule.Title]; n > 1 {
+			rule.Title = fmt.Sprintf("%s (%d)", rule.Title, n)
+		}
+		rule.OrgID = orgID
+		rule.NamespaceUID = namespaceUID
+		rule.RuleGroup = group.Name
+		rule.RuleGroupIndex = idx + 1
+		rule.IntervalSeconds = result.Interval
+		result.Rules = append(result.Rules, rule)
+	}
+	return result, nil
+}
+
+func (c *Converter) convertRule(r apimodels.ApiRuleNode) (models.AlertRule, error) {
+	if (r.Alert == "") == (r.Record == "") {
+		return models.AlertRule{}, errors.New("exactly one of 'alert' and 'record' must be set")
+	}
+	if _, err := parser.ParseExpr(r.Expr); err != nil {
+		return models.AlertRule{}, fmt.Errorf("invalid expression: %w", err)
+	}
+	query, err := c.createQuery(r.Expr)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	if r.Record != "" {
+		if !c.cfg.RecordingRules {
+			return models.AlertRule{}, fmt.Errorf("cannot convert recording rule %s because recording rules are not enabled", r.Record)
+		}
+		if r.For != nil || r.KeepFiringFor != nil || len(r.Annotations) > 0 {
+			return models.AlertRule{}, fmt.Errorf("recording rule %s cannot have 'for', 'keep_firing_for' or annotations", r.Record)
+		}
+		rule := models.AlertRule{
+			Title:        r.Record,
+			Condition:    queryRefID,
+			Data:         []models.AlertQuery{query},
+			NoDataState:  models.OK,
+			ExecErrState: models.KeepLastErrState,
+			Labels:       r.Labels,
+			Record:       models.Record{Metric: r.Record, From: queryRefID},
+		}
+		if err := rule.Record.Validate(rule.Data); err != nil {
+			return models.AlertRule{}, err
+		}
+		return rule, nil
+	}
+
+	condition, err := createCondition()
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule := models.AlertRule{
+		Title:     r.Alert,
+		Condition: conditionRefID,
+		Data:      []models.AlertQuery{query, condition},
+		// Prometheus resolves the alerts when the expression returns nothing, and keeps them when the evaluation fails.
+		NoDataState:  models.OK,
+		ExecErrState: models.KeepLastErrState,
+		Labels:       convertTemplates(r.Labels),
+		Annotations:  convertTemplates(r.Annotations),
+	}
+	if r.For != nil {
+		rule.For = time.Duration(*r.For)
+	}
+	if r.KeepFiringFor != nil {
+		rule.KeepFiringFor = time.Duration(*r.KeepFiringFor)
+	}
+	return rule, nil
+}
+
+func (c *Converter) createQuery(expression string) (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":   queryRefID,
+		"expr":    expression,
+		"instant": true,
+		"range":   false,
+		"datasource": map[string]any{
+			"type": datasources.DS_PROMETHEUS,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:             queryRefID,
+		DatasourceUID:     c.cfg.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(queryTimeRange)},
+		Model:             model,
+	}, nil
+}
+
+func createCondition() (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":      conditionRefID,
+		"type":       "math",
+		"expression": conditionExpression,
+		"datasource": map[string]any{
+			"type": expr.DatasourceType,
+			"uid":  expr.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         conditionRefID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}, nil
+}
+
+// convertTemplates replaces the variables of Prometheus templates that have a different name in Grafana templates.
+func convertTemplates(templates map[string]string) map[string]string {
+	if templates == nil {
+		return nil
+	}
+	result := make(map[string]string, len(templates))
+	for k, v := range templates {
+		result[k] = valueVariable.ReplaceAllString(v, "$$values."+queryRefID+".Value")
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const ruleFile = `
+groups:
+  - name: api
+    interval: 30s
+    rules:
+      - alert: HighLatency
+        expr: histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m]))) > 1
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: warning
+        annotations:
+          summary: "Latency is {{ $value | humanizeDuration }}"
+      - alert: HighLatency
+        expr: histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m]))) > 5
+        labels:
+          severity: critical
+  - name: records
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+        labels:
+          source: prometheus
+`
+
+func TestConvertRuleFile(t *testing.T) {
+	converter, err := NewConverter(Config{DatasourceUID: "prom", DefaultInterval: time.Minute, RecordingRules: true})
+	require.NoError(t, err)
+
+	file, err := ParseRuleFile([]byte(ruleFile))
+	require.NoError(t, err)
+
+	groups, err := converter.ConvertRuleFile(1, "folder", file)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	t.Run("should convert alert rules", func(t *testing.T) {
+		group := groups[0]
+		require.Equal(t, "api", group.Title)
+		require.Equal(t, "folder", group.FolderUID)
+		require.EqualValues(t, 30, group.Interval)
+		require.Len(t, group.Rules, 2)
+
+		rule := group.Rules[0]
+		require.Equal(t, "HighLatency", rule.Title)
+		require.EqualValues(t, 1, rule.OrgID)
+		require.Equal(t, "folder", rule.NamespaceUID)
+		require.Equal(t, "api", rule.RuleGroup)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.EqualValues(t, 30, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, 10*time.Minute, rule.KeepFiringFor)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.KeepLastErrState, rule.ExecErrState)
+		require.Equal(t, map[string]string{"severity": "warning"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "Latency is {{ $values.A.Value | humanizeDuration }}"}, rule.Annotations)
+		require.True(t, rule.Record.IsEmpty())
+
+		require.Equal(t, conditionRefID, rule.Condition)
+		require.Len(t, rule.Data, 2)
+		query := rule.Data[0]
+		require.Equal(t, queryRefID, query.RefID)
+		require.Equal(t, "prom", query.DatasourceUID)
+		require.Equal(t, models.Duration(10*time.Minute), query.RelativeTimeRange.From)
+		var queryModel map[string]any
+		require.NoError(t, json.Unmarshal(query.Model, &queryModel))
+		require.Equal(t, "histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m]))) > 1", queryModel["expr"])
+		require.Equal(t, true, queryModel["instant"])
+
+		condition := rule.Data[1]
+		require.Equal(t, conditionRefID, condition.RefID)
+		require.Equal(t, expr.DatasourceUID, condition.DatasourceUID)
+		isExpression, err := condition.IsExpression()
+		require.NoError(t, err)
+		require.True(t, isExpression)
+
+		duplicate := group.Rules[1]
+		require.Equal(t, "HighLatency (2)", duplicate.Title)
+		require.Equal(t, 2, duplicate.RuleGroupIndex)
+		require.Zero(t, duplicate.For)
+	})
+
+	t.Run("should convert recording rules", func(t *testing.T) {
+		group := groups[1]
+		require.Equal(t, "records", group.Title)
+		require.EqualValues(t, 60, group.Interval)
+		require.Len(t, group.Rules, 1)
+
+		rule := group.Rules[0]
+		require.Equal(t, "job:http_requests:rate5m", rule.Title)
+		require.True(t, rule.IsRecordingRule())
+		require.Equal(t, models.Record{Metric: "job:http_requests:rate5m", From: queryRefID}, rule.Record)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, map[string]string{"source": "prometheus"}, rule.Labels)
+	})
+}
+
+func TestConvertRuleGroup(t *testing.T) {
+	converter, err := NewConverter(Config{DatasourceUID: "prom", DefaultInterval: time.Minute})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		group apimodels.PrometheusRuleGroup
+		err   string
+	}{
+		{
+			name:  "group without name",
+			group: apimodels.PrometheusRuleGroup{Rules: []apimodels.ApiRuleNode{{Alert: "test", Expr: "up == 0"}}},
+			err:   "rule group name cannot be empty",
+		},
+		{
+			name:  "invalid expression",
+			group: apimodels.PrometheusRuleGroup{Name: "test", Rules: []apimodels.ApiRuleNode{{Alert: "test", Expr: "up =="}}},
+			err:   "invalid expression",
+		},
+		{
+			name:  "rule without alert or record",
+			group: apimodels.PrometheusRuleGroup{Name: "test", Rules: []apimodels.ApiRuleNode{{Expr: "up == 0"}}},
+			err:   "exactly one of 'alert' and 'record' must be set",
+		},
+		{
+			name:  "recording rule when recording rules are disabled",
+			group: apimodels.PrometheusRuleGroup{Name: "test", Rules: []apimodels.ApiRuleNode{{Record: "test", Expr: "up"}}},
+			err:   "recording rules are not enabled",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := converter.ConvertRuleGroup(1, "folder", tc.group)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("recording rule with for", func(t *testing.T) {
+		converter, err := NewConverter(Config{DatasourceUID: "prom", DefaultInterval: time.Minute, RecordingRules: true})
+		require.NoError(t, err)
+		forDuration := model.Duration(time.Minute)
+		_, err = converter.ConvertRuleGroup(1, "folder", apimodels.PrometheusRuleGroup{Name: "test", Rules: []apimodels.ApiRuleNode{{Record: "test", Expr: "up", For: &forDuration}}})
+		require.ErrorContains(t, err, "cannot have 'for'")
+	})
+
+	t.Run("duplicate group names", func(t *testing.T) {
+		group := apimodels.PrometheusRuleGroup{Name: "test", Rules: []apimodels.ApiRuleNode{{Alert: "test", Expr: "up == 0"}}}
+		_, err := converter.ConvertRuleFile(1, "folder", apimodels.PrometheusRuleFile{Groups: []apimodels.PrometheusRuleGroup{group, group}})
+		require.ErrorContains(t, err, "defined more than once")
+	})
+}
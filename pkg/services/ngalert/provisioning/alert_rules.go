@@ -145,6 +145,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.checkRuleDependencies(ctx, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	rule.Updated = time.Now()
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
@@ -300,6 +303,13 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, orgID int64, gro
 	if err := service.checkGroupLimits(group); err != nil {
 		return nil, fmt.Errorf("write rejected due to exceeded limits: %w", err)
 	}
+	groupRules := make(models.RulesGroup, 0, len(group.Rules))
+	for i := range group.Rules {
+		groupRules = append(groupRules, &group.Rules[i])
+	}
+	if err := groupRules.ValidateDependencies(); err != nil {
+		return nil, err
+	}
 
 	key := models.AlertRuleGroupKey{
 		OrgID:        orgID,
@@ -415,6 +425,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.checkRuleDependencies(ctx, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
@@ -596,6 +609,31 @@ func withoutNilAlertRules(ptrs []*models.AlertRule) []models.AlertRule {
 	return result
 }
 
+// checkRuleDependencies checks that the rule reads the state of rules of its group only, and that the rules of the group
+// do not read the state of each other in a cycle once the rule is saved.
+func (service *AlertRuleService) checkRuleDependencies(ctx context.Context, rule models.AlertRule) error {
+	if len(rule.GetRuleDependencies()) == 0 {
+		return nil
+	}
+	q := models.ListAlertRulesQuery{
+		OrgID:         rule.OrgID,
+		NamespaceUIDs: []string{rule.NamespaceUID},
+		RuleGroup:     rule.RuleGroup,
+	}
+	stored, err := service.ruleStore.ListAlertRules(ctx, &q)
+	if err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	group := make(models.RulesGroup, 0, len(stored)+1)
+	for _, r := range stored {
+		if r.UID != rule.UID {
+			group = append(group, r)
+		}
+	}
+	group = append(group, &rule)
+	return group.ValidateDependencies()
+}
+
 func (service *AlertRuleService) checkGroupLimits(group models.AlertRuleGroup) error {
 	if service.rulesPerRuleGroupLimit > 0 && int64(len(group.Rules)) > service.rulesPerRuleGroupLimit {
 		service.log.Warn("Large rule group was edited. Large groups are discouraged and may be rejected in the future.",
@@ -589,6 +589,51 @@ func TestCreateAlertRule(t *testing.T) {
 	})
 }
 
+func TestAlertRuleDependencies(t *testing.T) {
+	ruleService := createAlertRuleService(t)
+	var orgID int64 = 1
+
+	t.Run("should create a rule that reads the state of a rule of its group", func(t *testing.T) {
+		upstream, err := ruleService.CreateAlertRule(context.Background(), createTestRule("upstream", "dependencies", orgID, "my-namespace"), models.ProvenanceNone, 0)
+		require.NoError(t, err)
+
+		rule := createTestRule("service", "dependencies", orgID, "my-namespace")
+		rule.Data = append(rule.Data, models.CreateAlertStateExpression("B", upstream.UID))
+		_, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject a rule that reads the state of a rule of another group", func(t *testing.T) {
+		upstream, err := ruleService.CreateAlertRule(context.Background(), createTestRule("upstream", "other-group", orgID, "my-namespace"), models.ProvenanceNone, 0)
+		require.NoError(t, err)
+
+		rule := createTestRule("service", "dependencies", orgID, "my-namespace")
+		rule.Data = append(rule.Data, models.CreateAlertStateExpression("B", upstream.UID))
+		_, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should reject an update that makes rules read the state of each other in a cycle", func(t *testing.T) {
+		upstream, err := ruleService.CreateAlertRule(context.Background(), createTestRule("upstream", "cycle", orgID, "my-namespace"), models.ProvenanceNone, 0)
+		require.NoError(t, err)
+		rule := createTestRule("service", "cycle", orgID, "my-namespace")
+		rule.Data = append(rule.Data, models.CreateAlertStateExpression("B", upstream.UID))
+		service, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+
+		upstream.Data = append(upstream.Data, models.CreateAlertStateExpression("B", service.UID))
+		_, err = ruleService.UpdateAlertRule(context.Background(), upstream, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should reject a group with a rule that reads the state of a rule of another group", func(t *testing.T) {
+		group := createDummyGroup("group-dependencies", orgID)
+		group.Rules[0].Data = append(group.Rules[0].Data, models.CreateAlertStateExpression("B", util.GenerateShortUID()))
+		err := ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
 func createAlertRuleService(t *testing.T) AlertRuleService {
 	t.Helper()
 	sqlStore := db.InitTestDB(t)
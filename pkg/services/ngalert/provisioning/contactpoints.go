@@ -513,6 +513,9 @@ func ValidateContactPoint(ctx context.Context, e apimodels.EmbeddedContactPoint,
 	if err != nil {
 		return err
 	}
+	if integration.Type == notifier.TemplatedHTTPType {
+		return notifier.ValidateTemplatedHTTPIntegration(ctx, &integration, decryptFunc)
+	}
 	_, err = alertingNotify.BuildReceiverConfiguration(ctx, &alertingNotify.APIReceiver{
 		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
 			Integrations: []*alertingNotify.GrafanaIntegrationConfig{&integration},
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// rulesWithDependencies returns the keys of the rules that read the state of other rules, and of the rules which state is read.
+func rulesWithDependencies(rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleKey]struct{} {
+	result := make(map[ngmodels.AlertRuleKey]struct{})
+	for _, rule := range rules {
+		dependencies := rule.GetRuleDependencies()
+		if len(dependencies) == 0 {
+			continue
+		}
+		result[rule.GetKey()] = struct{}{}
+		for _, uid := range dependencies {
+			result[ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: uid}] = struct{}{}
+		}
+	}
+	return result
+}
+
+// orderByDependencies splits the items that are ready to run into chains of items that are evaluated one after another.
+// Items of rules that depend on each other are put into a single chain per rule group, ordered so that every rule is
+// evaluated after the rules which state it reads. Every other item gets a chain of its own.
+func (sch *schedule) orderByDependencies(items []readyToRunItem, dependent map[ngmodels.AlertRuleKey]struct{}) [][]readyToRunItem {
+	chains := make([][]readyToRunItem, 0, len(items))
+	groupChain := make(map[ngmodels.AlertRuleGroupKey]int)
+	for _, item := range items {
+		if _, ok := dependent[item.rule.GetKey()]; !ok {
+			chains = append(chains, []readyToRunItem{item})
+			continue
+		}
+		groupKey := item.rule.GetGroupKey()
+		idx, ok := groupChain[groupKey]
+		if !ok {
+			idx = len(chains)
+			groupChain[groupKey] = idx
+			chains = append(chains, nil)
+		}
+		chains[idx] = append(chains[idx], item)
+	}
+
+	for groupKey, idx := range groupChain {
+		chain := chains[idx]
+		if len(chain) < 2 {
+			continue
+		}
+		group := make(ngmodels.RulesGroup, 0, len(chain))
+		byUID := make(map[string]readyToRunItem, len(chain))
+		for _, item := range chain {
+			group = append(group, item.rule)
+			byUID[item.rule.UID] = item
+		}
+		sorted, err := group.SortByDependencies()
+		if err != nil {
+			sch.log.Warn("Failed to order the rules of the group by their dependencies. Rules will be evaluated in the order of the group", "group", groupKey.String(), "error", err)
+			continue
+		}
+		for i, rule := range sorted {
+			chain[i] = byUID[rule.UID]
+		}
+	}
+	return chains
+}
+
+// evaluateChain sends the first item of the chain to its evaluation routine, and every following item once the
+// evaluation of the previous one is finished.
+func (sch *schedule) evaluateChain(chain []readyToRunItem, tick time.Time) {
+	if len(chain) == 0 {
+		return
+	}
+	item := chain[0]
+	if len(chain) > 1 {
+		item.afterEval = func() {
+			sch.evaluateChain(chain[1:], tick)
+		}
+	}
+
+	key := item.rule.GetKey()
+	success, dropped := item.ruleInfo.eval(&item.evaluation)
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+		// the dropped evaluation is never finished, so the rest of its chain is evaluated right away.
+		if dropped.afterEval != nil {
+			go dropped.afterEval()
+		}
+	}
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
+		if item.afterEval != nil {
+			item.afterEval()
+		}
+	}
+}
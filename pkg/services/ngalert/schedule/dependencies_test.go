@@ -0,0 +1,119 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestOrderByDependencies(t *testing.T) {
+	sch := &schedule{log: log.NewNopLogger()}
+
+	groupKey := ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+	upstream := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey))()
+	service := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey))()
+	service.Data = append(service.Data, ngmodels.CreateAlertStateExpression("STATE", upstream.UID))
+	independent := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey))()
+	other := ngmodels.AlertRuleGen()()
+
+	rules := []*ngmodels.AlertRule{service, independent, upstream, other}
+	dependent := rulesWithDependencies(rules)
+	require.Equal(t, map[ngmodels.AlertRuleKey]struct{}{
+		service.GetKey():  {},
+		upstream.GetKey(): {},
+	}, dependent)
+
+	items := make([]readyToRunItem, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, readyToRunItem{evaluation: evaluation{rule: rule}})
+	}
+
+	chains := sch.orderByDependencies(items, dependent)
+
+	require.Len(t, chains, 3)
+	require.Len(t, chains[0], 2)
+	require.Equal(t, upstream, chains[0][0].rule)
+	require.Equal(t, service, chains[0][1].rule)
+	require.Len(t, chains[1], 1)
+	require.Equal(t, independent, chains[1][0].rule)
+	require.Len(t, chains[2], 1)
+	require.Equal(t, other, chains[2][0].rule)
+}
+
+func TestEvaluateChain(t *testing.T) {
+	sch := &schedule{log: log.NewNopLogger()}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	tick := time.Now()
+	first := readyToRunItem{ruleInfo: newAlertRuleInfo(ctx), evaluation: evaluation{scheduledAt: tick, rule: ngmodels.AlertRuleGen()()}}
+	second := readyToRunItem{ruleInfo: newAlertRuleInfo(ctx), evaluation: evaluation{scheduledAt: tick, rule: ngmodels.AlertRuleGen()()}}
+
+	go sch.evaluateChain([]readyToRunItem{first, second}, tick)
+
+	var e *evaluation
+	select {
+	case e = <-first.ruleInfo.evalCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first rule of the chain was not evaluated")
+	}
+	require.Equal(t, first.rule, e.rule)
+	require.NotNil(t, e.afterEval)
+
+	select {
+	case <-second.ruleInfo.evalCh:
+		t.Fatal("the second rule of the chain was evaluated before the evaluation of the first one was finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	go e.afterEval()
+
+	select {
+	case e = <-second.ruleInfo.evalCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second rule of the chain was not evaluated")
+	}
+	require.Equal(t, second.rule, e.rule)
+	require.Equal(t, tick, e.scheduledAt)
+	require.Nil(t, e.afterEval)
+}
+
+func TestEvaluateChainDroppedEvaluation(t *testing.T) {
+	sch := &schedule{
+		log:     log.NewNopLogger(),
+		metrics: metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetSchedulerMetrics(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ruleInfo := newAlertRuleInfo(ctx)
+	rule := ngmodels.AlertRuleGen()()
+	continued := make(chan struct{})
+	go ruleInfo.eval(&evaluation{scheduledAt: time.Now(), rule: rule, afterEval: func() {
+		close(continued)
+	}})
+	// wait for the evaluation to be pending
+	time.Sleep(100 * time.Millisecond)
+
+	tick := time.Now()
+	go sch.evaluateChain([]readyToRunItem{{ruleInfo: ruleInfo, evaluation: evaluation{scheduledAt: tick, rule: rule}}}, tick)
+
+	select {
+	case e := <-ruleInfo.evalCh:
+		require.Equal(t, tick, e.scheduledAt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rule was not evaluated")
+	}
+	select {
+	case <-continued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the chain of the dropped evaluation was not continued")
+	}
+}
@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// evaluationStatsHistorySize is the number of the most recent evaluations that are kept for each rule.
+const evaluationStatsHistorySize = 20
+
+// evaluationStatsNamespace is the namespace of the key-value store in which the instances of the high availability
+// cluster share the statistics of the rules that they evaluate.
+const evaluationStatsNamespace = "ngalert.evaluation_stats"
+
+// evaluationStatsRegistry keeps the statistics of the evaluations of the rules that are scheduled by this instance.
+// When the evaluation is sharded, it also keeps the statistics of the rules that are evaluated by other instances.
+type evaluationStatsRegistry struct {
+	stats map[models.AlertRuleKey]*models.RuleEvaluationStats
+	// changed contains the rules that were evaluated since the statistics were last saved to the store.
+	changed map[models.AlertRuleKey]struct{}
+	mu      sync.Mutex
+}
+
+func newEvaluationStatsRegistry() *evaluationStatsRegistry {
+	return &evaluationStatsRegistry{
+		stats:   make(map[models.AlertRuleKey]*models.RuleEvaluationStats),
+		changed: make(map[models.AlertRuleKey]struct{}),
+	}
+}
+
+// add records the evaluation of the rule and drops the oldest evaluation if the history is full.
+func (r *evaluationStatsRegistry) add(key models.AlertRuleKey, record models.EvaluationRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[key]
+	if !ok {
+		s = &models.RuleEvaluationStats{Evaluations: make([]models.EvaluationRecord, 0, evaluationStatsHistorySize)}
+		r.stats[key] = s
+	}
+	if len(s.Evaluations) == evaluationStatsHistorySize {
+		copy(s.Evaluations, s.Evaluations[1:])
+		s.Evaluations = s.Evaluations[:len(s.Evaluations)-1]
+	}
+	s.Evaluations = append(s.Evaluations, record)
+	s.Total++
+	if record.Error != "" {
+		s.Errors++
+	}
+	r.changed[key] = struct{}{}
+}
+
+// get returns a copy of the statistics of the rule.
+func (r *evaluationStatsRegistry) get(key models.AlertRuleKey) (models.RuleEvaluationStats, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.stats[key]
+	if !ok {
+		return models.RuleEvaluationStats{}, false
+	}
+	result := *s
+	result.Evaluations = make([]models.EvaluationRecord, len(s.Evaluations))
+	copy(result.Evaluations, s.Evaluations)
+	return result, true
+}
+
+func (r *evaluationStatsRegistry) del(key models.AlertRuleKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stats, key)
+	delete(r.changed, key)
+}
+
+// sync shares the statistics with the other instances of the high availability cluster. It saves the statistics of
+// the owned rules that were evaluated since the last sync to the store, and replaces the statistics of the remote rules
+// with the ones saved by the instances that evaluate them. Saved statistics of rules that do not exist anymore are deleted.
+func (r *evaluationStatsRegistry) sync(ctx context.Context, store kvstore.KVStore, owned map[models.AlertRuleKey]struct{}, remote []*models.AlertRule) error {
+	r.mu.Lock()
+	changed := make(map[models.AlertRuleKey][]byte, len(r.changed))
+	for key := range r.changed {
+		if _, ok := owned[key]; !ok {
+			continue
+		}
+		if s, ok := r.stats[key]; ok {
+			b, err := json.Marshal(s)
+			if err != nil {
+				r.mu.Unlock()
+				return fmt.Errorf("failed to marshal evaluation statistics of rule %s: %w", key.UID, err)
+			}
+			changed[key] = b
+		}
+	}
+	clear(r.changed)
+	r.mu.Unlock()
+
+	var errs []error
+	for key, b := range changed {
+		if err := store.Set(ctx, key.OrgID, evaluationStatsNamespace, key.UID, string(b)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save evaluation statistics of rule %s: %w", key.UID, err))
+		}
+	}
+
+	saved, err := store.GetAll(ctx, kvstore.AllOrganizations, evaluationStatsNamespace)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to read evaluation statistics: %w", err))...)
+	}
+	remoteKeys := make(map[models.AlertRuleKey]struct{}, len(remote))
+	for _, rule := range remote {
+		remoteKeys[rule.GetKey()] = struct{}{}
+	}
+	loaded := make(map[models.AlertRuleKey]*models.RuleEvaluationStats, len(remote))
+	for orgID, values := range saved {
+		for uid, value := range values {
+			key := models.AlertRuleKey{OrgID: orgID, UID: uid}
+			if _, ok := owned[key]; ok {
+				continue
+			}
+			if _, ok := remoteKeys[key]; !ok {
+				if err := store.Del(ctx, orgID, evaluationStatsNamespace, uid); err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete evaluation statistics of rule %s: %w", uid, err))
+				}
+				continue
+			}
+			var s models.RuleEvaluationStats
+			if err := json.Unmarshal([]byte(value), &s); err != nil {
+				errs = append(errs, fmt.Errorf("failed to unmarshal evaluation statistics of rule %s: %w", uid, err))
+				continue
+			}
+			loaded[key] = &s
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range remoteKeys {
+		if s, ok := loaded[key]; ok {
+			r.stats[key] = s
+		} else {
+			delete(r.stats, key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func newEvaluationRecord(evaluatedAt time.Time, dur time.Duration, stats *expr.ExecutionStats, series int, err error) models.EvaluationRecord {
+	record := models.EvaluationRecord{
+		EvaluatedAt:        evaluatedAt,
+		Duration:           dur,
+		DatasourceDuration: stats.DatasourceDuration(),
+		ExpressionDuration: stats.ExpressionDuration(),
+		Series:             series,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// GetEvaluationStats returns the statistics of the recent evaluations of the rule. It returns false if the rule has
+// not been evaluated yet. When the evaluation is sharded, the statistics of the rules that are evaluated by other
+// instances are the ones they saved at the last tick of the scheduler.
+func (sch *schedule) GetEvaluationStats(key models.AlertRuleKey) (models.RuleEvaluationStats, bool) {
+	return sch.evaluationStats.get(key)
+}
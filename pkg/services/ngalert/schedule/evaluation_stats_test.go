@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationStatsRegistry(t *testing.T) {
+	r := newEvaluationStatsRegistry()
+	key := models.AlertRuleKey{OrgID: 1, UID: "test"}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, ok := r.get(key)
+	require.False(t, ok)
+
+	for i := 0; i < evaluationStatsHistorySize+5; i++ {
+		var err error
+		if i%5 == 0 {
+			err = errors.New("failed")
+		}
+		r.add(key, newEvaluationRecord(start.Add(time.Duration(i)*time.Minute), time.Second, &expr.ExecutionStats{}, i, err))
+	}
+
+	stats, ok := r.get(key)
+	require.True(t, ok)
+	require.EqualValues(t, evaluationStatsHistorySize+5, stats.Total)
+	require.EqualValues(t, 5, stats.Errors)
+	require.Len(t, stats.Evaluations, evaluationStatsHistorySize)
+	require.Equal(t, start.Add(5*time.Minute), stats.Evaluations[0].EvaluatedAt)
+	require.Equal(t, "failed", stats.Evaluations[0].Error)
+	require.Equal(t, evaluationStatsHistorySize+4, stats.Evaluations[evaluationStatsHistorySize-1].Series)
+
+	t.Run("get should return a copy", func(t *testing.T) {
+		stats.Evaluations[0].Series = -1
+		actual, _ := r.get(key)
+		require.Equal(t, 5, actual.Evaluations[0].Series)
+	})
+
+	r.del(key)
+	_, ok = r.get(key)
+	require.False(t, ok)
+}
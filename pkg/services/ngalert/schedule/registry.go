@@ -18,6 +18,10 @@ import (
 
 var errRuleDeleted = errors.New("rule deleted")
 
+// errRuleReleased is the reason of stopping the evaluation of a rule that is now evaluated by another instance of the
+// high availability cluster. Unlike errRuleDeleted, the state of the rule is kept in the instance store.
+var errRuleReleased = errors.New("rule is evaluated by another instance")
+
 type alertRuleInfoRegistry struct {
 	mu            sync.Mutex
 	alertRuleInfo map[models.AlertRuleKey]*alertRuleInfo
@@ -130,6 +134,8 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation is finished. It is used to evaluate rules that depend on the rule.
+	afterEval func()
 }
 
 type alertRulesRegistry struct {
@@ -341,12 +347,16 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		writeBytes(tmp)
 	}
 
+	writeString(rule.Record.Metric)
+	writeString(rule.Record.From)
+
 	// fields that do not affect the state.
 	// TODO consider removing fields below from the fingerprint
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(rule.IntervalSeconds)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	writeLabels(rule.Annotations)
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
@@ -418,6 +418,10 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			Record: models.Record{
+				Metric: "test_metric",
+				From:   "1",
+			},
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -456,6 +460,10 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NotificationSettings: []models.NotificationSettings{
 				models.NotificationSettingsGen()(),
 			},
+			Record: models.Record{
+				Metric: "test_metric_2",
+				From:   "2",
+			},
 		}
 
 		excludedFields := map[string]struct{}{
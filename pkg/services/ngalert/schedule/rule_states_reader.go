@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var _ eval.RuleStatesReader = RuleStatesFromStateManager{}
+
+func (sch *schedule) newRuleStatesReader(rule *ngmodels.AlertRule) eval.RuleStatesReader {
+	return &RuleStatesFromStateManager{
+		Manager:     sch.stateManager,
+		GetRule:     sch.schedulableAlertRules.get,
+		GetRecorded: sch.recordedInstances.get,
+		Rule:        rule,
+	}
+}
+
+// RuleStatesFromStateManager implements eval.RuleStatesReader that gets the data from state manager.
+// It returns the states of rules that belong to the same group as Rule. The labels of the instances do not contain
+// the labels that are added by the rule they belong to, so they can be matched with the results of the queries of Rule.
+// Recording rules have no state, the instances of a recording rule are the values it recorded at its last evaluation,
+// returned by GetRecorded.
+type RuleStatesFromStateManager struct {
+	Manager     RuleStateProvider
+	GetRule     func(key ngmodels.AlertRuleKey) *ngmodels.AlertRule
+	GetRecorded func(key ngmodels.AlertRuleKey) []expr.AlertStateInstance
+	Rule        *ngmodels.AlertRule
+}
+
+func (n RuleStatesFromStateManager) Read(ruleUID string) ([]expr.AlertStateInstance, error) {
+	dependency := n.GetRule(ngmodels.AlertRuleKey{OrgID: n.Rule.OrgID, UID: ruleUID})
+	if dependency == nil || dependency.GetGroupKey() != n.Rule.GetGroupKey() {
+		return nil, fmt.Errorf("rule %s does not belong to the group %s", ruleUID, n.Rule.GetGroupKey())
+	}
+	if dependency.IsRecordingRule() {
+		if n.GetRecorded == nil {
+			return nil, nil
+		}
+		return n.GetRecorded(dependency.GetKey()), nil
+	}
+
+	states := n.Manager.GetStatesForRuleUID(n.Rule.OrgID, ruleUID)
+	result := make([]expr.AlertStateInstance, 0, len(states))
+	for _, st := range states {
+		labels := make(data.Labels, len(st.Labels))
+		for k, v := range st.Labels {
+			if isRuleLabel(dependency, k) {
+				continue
+			}
+			labels[k] = v
+		}
+		result = append(result, expr.AlertStateInstance{
+			Labels: labels,
+			Firing: st.State == eval.Alerting,
+		})
+	}
+	return result, nil
+}
+
+// isRuleLabel returns true if the label is added to the alert instances by the rule rather than by its queries.
+func isRuleLabel(rule *ngmodels.AlertRule, key string) bool {
+	if strings.HasPrefix(key, "__") || key == prometheusModel.AlertNameLabel || key == ngmodels.FolderTitleLabel {
+		return true
+	}
+	_, ok := rule.Labels[key]
+	return ok
+}
+
+// RecordedInstances returns the last value of each numeric series of the frames that a recording rule writes, as the
+// instances of the rule. An instance is firing if its value is not zero.
+func RecordedInstances(frames data.Frames) []expr.AlertStateInstance {
+	instances := make([]expr.AlertStateInstance, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			for i := field.Len() - 1; i >= 0; i-- {
+				v, err := field.NullableFloatAt(i)
+				if err != nil || v == nil {
+					continue
+				}
+				value := *v
+				instances = append(instances, expr.AlertStateInstance{
+					Labels: field.Labels.Copy(),
+					Firing: value != 0,
+					Value:  &value,
+				})
+				break
+			}
+		}
+	}
+	return instances
+}
+
+// recordedInstancesRegistry contains the instances of the recording rules recorded at their last evaluation.
+type recordedInstancesRegistry struct {
+	mtx       sync.RWMutex
+	instances map[ngmodels.AlertRuleKey][]expr.AlertStateInstance
+}
+
+func newRecordedInstancesRegistry() *recordedInstancesRegistry {
+	return &recordedInstancesRegistry{instances: make(map[ngmodels.AlertRuleKey][]expr.AlertStateInstance)}
+}
+
+func (r *recordedInstancesRegistry) set(key ngmodels.AlertRuleKey, instances []expr.AlertStateInstance) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.instances[key] = instances
+}
+
+func (r *recordedInstancesRegistry) get(key ngmodels.AlertRuleKey) []expr.AlertStateInstance {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.instances[key]
+}
+
+func (r *recordedInstancesRegistry) del(key ngmodels.AlertRuleKey) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.instances, key)
+}
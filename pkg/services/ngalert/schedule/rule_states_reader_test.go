@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRuleStatesFromStateManager(t *testing.T) {
+	groupKey := ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+	rule := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey))()
+	upstream := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey), ngmodels.WithLabels(data.Labels{"team": "db"}))()
+	other := ngmodels.AlertRuleGen(ngmodels.WithOrgID(groupKey.OrgID))()
+	recording := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey), ngmodels.WithRecord("recorded"))()
+	rules := map[ngmodels.AlertRuleKey]*ngmodels.AlertRule{
+		rule.GetKey():      rule,
+		upstream.GetKey():  upstream,
+		other.GetKey():     other,
+		recording.GetKey(): recording,
+	}
+	recorded := newRecordedInstancesRegistry()
+
+	p := &FakeRuleStateProvider{
+		map[ngmodels.AlertRuleKey][]*state.State{
+			upstream.GetKey(): {
+				{State: eval.Alerting, Labels: data.Labels{"service": "db", "team": "db", "alertname": upstream.Title, "grafana_folder": "folder", "__alert_rule_uid__": upstream.UID}},
+				{State: eval.Pending, Labels: data.Labels{"service": "cache", "team": "db"}},
+				{State: eval.Normal, Labels: data.Labels{"service": "queue", "team": "db"}},
+			},
+		},
+	}
+
+	reader := RuleStatesFromStateManager{
+		Manager: p,
+		GetRule: func(key ngmodels.AlertRuleKey) *ngmodels.AlertRule {
+			return rules[key]
+		},
+		GetRecorded: recorded.get,
+		Rule:        rule,
+	}
+
+	t.Run("should return instances without labels of the rule", func(t *testing.T) {
+		instances, err := reader.Read(upstream.UID)
+		require.NoError(t, err)
+		require.Equal(t, []expr.AlertStateInstance{
+			{Labels: data.Labels{"service": "db"}, Firing: true},
+			{Labels: data.Labels{"service": "cache"}, Firing: false},
+			{Labels: data.Labels{"service": "queue"}, Firing: false},
+		}, instances)
+	})
+
+	t.Run("should return the recorded values of a recording rule", func(t *testing.T) {
+		instances, err := reader.Read(recording.UID)
+		require.NoError(t, err)
+		require.Empty(t, instances)
+
+		recorded.set(recording.GetKey(), RecordedInstances(data.Frames{
+			data.NewFrame("",
+				data.NewField("value", data.Labels{"service": "db"}, []*float64{util.Pointer(1.0), util.Pointer(2.5), nil}),
+				data.NewField("value", data.Labels{"service": "cache"}, []float64{0}),
+				data.NewField("name", nil, []string{"a"}),
+			),
+		}))
+		instances, err = reader.Read(recording.UID)
+		require.NoError(t, err)
+		require.Equal(t, []expr.AlertStateInstance{
+			{Labels: data.Labels{"service": "db"}, Firing: true, Value: util.Pointer(2.5)},
+			{Labels: data.Labels{"service": "cache"}, Firing: false, Value: util.Pointer(0.0)},
+		}, instances)
+	})
+
+	t.Run("should fail if rule is in another group", func(t *testing.T) {
+		_, err := reader.Read(other.UID)
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if rule does not exist", func(t *testing.T) {
+		_, err := reader.Read("unknown")
+		require.Error(t, err)
+	})
+}
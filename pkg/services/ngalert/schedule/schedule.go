@@ -5,14 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -44,6 +49,11 @@ type AlertsSender interface {
 	Send(ctx context.Context, key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter is an interface for a service that writes the results of recording rules.
+type RecordingWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -88,12 +98,30 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// recordingWriter writes the results of recording rules. Recording rules are not evaluated if it is nil.
+	recordingWriter RecordingWriter
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// evaluationStats contains the statistics of the recent evaluations of the scheduled rules.
+	evaluationStats *evaluationStatsRegistry
+	// recordedInstances contains the values recorded by the recording rules, which other rules of their group can read.
+	recordedInstances *recordedInstancesRegistry
+
+	// sharding assigns the rules to the instances of the high availability cluster. All rules are evaluated by this
+	// instance if it is nil.
+	sharding *ruleSharding
+	// evaluationStatsStore is used to share the evaluation statistics with the other instances when the evaluation is sharded.
+	evaluationStatsStore kvstore.KVStore
+	// remoteStatesRefreshInterval is the interval at which the states of the rules evaluated by other instances are
+	// reloaded from the database. remoteStatesRefreshedAt is the tick of the last reload.
+	remoteStatesRefreshInterval time.Duration
+	remoteStatesRefreshedAt     time.Time
+
 	tracer tracing.Tracer
 }
 
@@ -110,6 +138,12 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      RecordingWriter
+	// ClusterMembership enables sharding of the rule evaluation across the instances of the high availability cluster.
+	ClusterMembership ClusterMembership
+	// EvaluationStatsStore is used to share the evaluation statistics with the other instances of the cluster when the
+	// evaluation is sharded.
+	EvaluationStatsStore kvstore.KVStore
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 }
@@ -137,10 +171,19 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		stateManager:          stateManager,
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
+		evaluationStats:       newEvaluationStatsRegistry(),
+		recordedInstances:     newRecordedInstancesRegistry(),
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
 	}
 
+	if cfg.ClusterMembership != nil {
+		sch.sharding = newRuleSharding(cfg.ClusterMembership, cfg.Log)
+		sch.evaluationStatsStore = cfg.EvaluationStatsStore
+		sch.remoteStatesRefreshInterval = defaultRemoteStatesRefreshInterval
+	}
+
 	return &sch
 }
 
@@ -171,6 +214,8 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 		if _, ok := sch.schedulableAlertRules.del(key); !ok {
 			sch.log.Info("Alert rule cannot be removed from the scheduler as it is not scheduled", key.LogContext()...)
 		}
+		sch.evaluationStats.del(key)
+		sch.recordedInstances.del(key)
 		// Delete the rule routine
 		ruleInfo, ok := sch.registry.del(key)
 		if !ok {
@@ -185,6 +230,61 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// updateOwnedRules assigns the rules to the instances of the high availability cluster and stops the routines of the
+// rules that are evaluated by other instances. The evaluation statistics of the rules that are evaluated by other
+// instances are loaded from the database, so that every instance serves the state of all rules. The states of the rules
+// taken over from other instances and of new rules of other instances are loaded at the tick the assignment changes,
+// the states of all rules of other instances are reloaded every remoteStatesRefreshInterval. It returns the rules that
+// are evaluated by this instance, or nil if the evaluation is not sharded.
+func (sch *schedule) updateOwnedRules(ctx context.Context, tick time.Time, alertRules []*ngmodels.AlertRule) map[ngmodels.AlertRuleKey]struct{} {
+	if sch.sharding == nil {
+		return nil
+	}
+	assignment := sch.sharding.assign(alertRules)
+	sch.metrics.OwnedAlertRules.Set(float64(len(assignment.owned)))
+
+	for _, key := range assignment.released {
+		if ruleInfo, ok := sch.registry.del(key); ok {
+			ruleInfo.stop(errRuleReleased)
+		}
+	}
+	if len(assignment.released) > 0 {
+		sch.log.Info("Released rules that are evaluated by other instances", "rules", len(assignment.released))
+	}
+	if len(assignment.removed) > 0 {
+		for _, key := range assignment.removed {
+			sch.evaluationStats.del(key)
+		}
+		n := sch.stateManager.ReleaseStates(assignment.removed)
+		sch.log.Debug("Removed the states of deleted rules that were evaluated by other instances", "rules", len(assignment.removed), "states", n)
+	}
+
+	if len(assignment.acquired) > 0 {
+		sch.log.Info("Acquired rules that were evaluated by other instances", "rules", len(assignment.acquired))
+	}
+	load := slices.Clone(assignment.acquired)
+	if sch.remoteStatesRefreshedAt.IsZero() || tick.Sub(sch.remoteStatesRefreshedAt) >= sch.remoteStatesRefreshInterval {
+		load = append(load, assignment.remote...)
+		sch.remoteStatesRefreshedAt = tick
+	} else {
+		load = append(load, assignment.added...)
+	}
+	if len(load) > 0 {
+		n, err := sch.stateManager.AcquireStates(ctx, load)
+		if err != nil {
+			sch.log.Error("Failed to load the states of rules that are evaluated by other instances", "error", err)
+		}
+		sch.log.Debug("Loaded the states of rules from the database", "rules", len(load), "states", n)
+	}
+
+	if sch.evaluationStatsStore != nil {
+		if err := sch.evaluationStats.sync(ctx, sch.evaluationStatsStore, assignment.owned, assignment.remote); err != nil {
+			sch.log.Error("Failed to share the evaluation statistics with other instances", "error", err)
+		}
+	}
+	return assignment.owned
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -239,11 +339,23 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	owned := sch.updateOwnedRules(ctx, tick, alertRules)
+
+	// rules that depend on each other are evaluated on the same tick, one after another.
+	dependent := rulesWithDependencies(alertRules)
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if owned != nil {
+			if _, ok := owned[key]; !ok {
+				// the rule is evaluated by another instance, its routine is already stopped.
+				delete(registeredDefinitions, key)
+				continue
+			}
+		}
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -268,7 +380,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		offset := jitterOffsetInTicks(item, sch.baseInterval, sch.jitterEvaluations)
+		jitterStrategy := sch.jitterEvaluations
+		if _, ok := dependent[key]; ok && jitterStrategy == JitterByRule {
+			jitterStrategy = JitterByGroup
+		}
+		offset := jitterOffsetInTicks(item, sch.baseInterval, jitterStrategy)
 		isReadyToRun := item.IntervalSeconds != 0 && (tickNum%itemFrequency)-offset == 0
 
 		var folderTitle string
@@ -312,26 +428,18 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	chains := sch.orderByDependencies(readyToRun, dependent)
+
 	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+	if len(chains) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(chains))
 	}
 
-	for i := range readyToRun {
-		item := readyToRun[i]
+	for i := range chains {
+		chain := chains[i]
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleInfo.eval(&item.evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
-			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.evaluateChain(chain, tick)
 		})
 	}
 
@@ -374,22 +482,84 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		notify(states)
 	}
 
+	record := func(ctx context.Context, f fingerprint, attempt int64, e *evaluation, span trace.Span, retry bool) error {
+		logger := logger.New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
+		if sch.recordingWriter == nil {
+			logger.Debug("Skip evaluation of recording rule because recording rules are disabled")
+			return nil
+		}
+		start := sch.clock.Now()
+
+		evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+		evalCtx.RuleStatesReader = sch.newRuleStatesReader(e.rule)
+		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+		var frames data.Frames
+		stats := &expr.ExecutionStats{}
+		if err == nil {
+			var resp *backend.QueryDataResponse
+			resp, err = ruleEval.EvaluateRaw(expr.WithExecutionStats(ctx, stats), e.scheduledAt)
+			if err == nil {
+				frames, err = recordedFrames(resp, e.rule.Record.From)
+			}
+		}
+		dur := sch.clock.Now().Sub(start)
+
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+
+		if ctx.Err() != nil {
+			span.SetStatus(codes.Error, "rule evaluation cancelled")
+			logger.Debug("Skip writing the results because the context has been cancelled")
+			return nil
+		}
+
+		if err == nil {
+			err = sch.recordingWriter.Write(ctx, e.rule.Record.Metric, e.scheduledAt, frames, e.rule.Labels)
+		}
+		if err == nil {
+			// the rules of the group that depend on the recording rule read the recorded values like the backtesting does.
+			sch.recordedInstances.set(e.rule.GetKey(), RecordedInstances(frames))
+		}
+		sch.evaluationStats.add(e.rule.GetKey(), newEvaluationRecord(e.scheduledAt, dur, stats, len(frames), err))
+		if err != nil {
+			evalTotalFailures.Inc()
+			span.SetStatus(codes.Error, "rule evaluation failed")
+			span.RecordError(err)
+			if retry {
+				return fmt.Errorf("failed to record rule: %w", err)
+			}
+			logger.Error("Failed to record rule", "error", err, "duration", dur)
+			return nil
+		}
+
+		logger.Debug("Recording rule evaluated", "frames", len(frames), "duration", dur)
+		span.AddEvent("rule recorded", trace.WithAttributes(
+			attribute.Int64("frames", int64(len(frames))),
+		))
+		return nil
+	}
+
 	evaluate := func(ctx context.Context, f fingerprint, attempt int64, e *evaluation, span trace.Span, retry bool) error {
+		if e.rule.IsRecordingRule() {
+			return record(ctx, f, attempt, e, span, retry)
+		}
 		logger := logger.New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
 		start := sch.clock.Now()
 
 		evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), sch.newLoadedMetricsReader(e.rule))
+		evalCtx.RuleStatesReader = sch.newRuleStatesReader(e.rule)
 		if sch.evaluatorFactory == nil {
 			panic("evalfactory nil")
 		}
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
+		stats := &expr.ExecutionStats{}
 		if err != nil {
 			dur = sch.clock.Now().Sub(start)
 			logger.Error("Failed to build rule evaluator", "error", err)
 		} else {
-			results, err = ruleEval.Evaluate(ctx, e.scheduledAt)
+			results, err = ruleEval.Evaluate(expr.WithExecutionStats(ctx, stats), e.scheduledAt)
 			dur = sch.clock.Now().Sub(start)
 			if err != nil {
 				logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
@@ -405,6 +575,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			return nil
 		}
 
+		evalErr := err
+		if evalErr == nil && results.HasErrors() {
+			evalErr = results.Error()
+		}
+		sch.evaluationStats.add(e.rule.GetKey(), newEvaluationRecord(e.scheduledAt, dur, stats, len(results), evalErr))
+
 		if err != nil || results.HasErrors() {
 			evalTotalFailures.Inc()
 
@@ -491,6 +667,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				if ctx.afterEval != nil {
+					go ctx.afterEval()
+				}
 				continue
 			}
 
@@ -499,6 +678,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.scheduledAt)
+					if ctx.afterEval != nil {
+						go ctx.afterEval()
+					}
 				}()
 
 				for attempt := int64(1); attempt <= sch.maxAttempts; attempt++ {
@@ -578,6 +760,21 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 }
 
+// recordedFrames returns the frames of the query that the recording rule writes.
+func recordedFrames(resp *backend.QueryDataResponse, refID string) (data.Frames, error) {
+	if resp == nil {
+		return nil, errors.New("no response from the query pipeline")
+	}
+	result, ok := resp.Responses[refID]
+	if !ok {
+		return nil, fmt.Errorf("no results of the query %s", refID)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("query %s failed: %w", refID, result.Error)
+	}
+	return result.Frames, nil
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -458,6 +459,16 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 				require.Equal(t, s.Labels, data.Labels(cmd.Labels))
 			})
 
+			t.Run("it should record evaluation stats", func(t *testing.T) {
+				stats, ok := sch.GetEvaluationStats(rule.GetKey())
+				require.True(t, ok)
+				require.EqualValues(t, 1, stats.Total)
+				require.Zero(t, stats.Errors)
+				require.Len(t, stats.Evaluations, 1)
+				require.Equal(t, expectedTime, stats.Evaluations[0].EvaluatedAt)
+				require.Equal(t, 1, stats.Evaluations[0].Series)
+			})
+
 			t.Run("it reports metrics", func(t *testing.T) {
 				// duration metric has 0 values because of mocked clock that do not advance
 				expectedMetric := fmt.Sprintf(
@@ -795,6 +806,117 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		})
 	})
 
+	t.Run("when rule is a recording rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test_metric"))()
+
+		t.Run("it should write the results and not call notifiers", func(t *testing.T) {
+			evalChan := make(chan *evaluation)
+			evalAppliedChan := make(chan time.Time)
+
+			sender := NewSyncAlertsSenderMock()
+			sch, ruleStore, _, reg := createSchedule(evalAppliedChan, sender)
+			writer := &fakeRecordingWriter{}
+			sch.recordingWriter = writer
+			ruleStore.PutRule(context.Background(), rule)
+
+			go func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+			}()
+
+			scheduledAt := sch.clock.Now()
+			evalChan <- &evaluation{
+				scheduledAt: scheduledAt,
+				rule:        rule,
+			}
+
+			waitForTimeChannel(t, evalAppliedChan)
+
+			writes := writer.Writes()
+			require.Len(t, writes, 1)
+			require.Equal(t, "test_metric", writes[0].name)
+			require.Equal(t, scheduledAt, writes[0].t)
+			require.Equal(t, rule.Labels, writes[0].extraLabels)
+			require.Len(t, writes[0].frames, 1)
+			value, err := writes[0].frames[0].Fields[0].FloatAt(0)
+			require.NoError(t, err)
+			require.Equal(t, 1.0, value) // 2 + 2 > 1
+
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+			expectedMetric := fmt.Sprintf(
+				`# HELP grafana_alerting_rule_evaluations_total The total number of rule evaluations.
+        	# TYPE grafana_alerting_rule_evaluations_total counter
+        	grafana_alerting_rule_evaluations_total{org="%[1]d"} 1
+`, rule.OrgID)
+			err = testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetric), "grafana_alerting_rule_evaluations_total")
+			require.NoError(t, err)
+		})
+
+		t.Run("it should increase failure counter if write fails", func(t *testing.T) {
+			evalChan := make(chan *evaluation)
+			evalAppliedChan := make(chan time.Time)
+
+			sch, ruleStore, _, reg := createSchedule(evalAppliedChan, nil)
+			writer := &fakeRecordingWriter{err: errors.New("test")}
+			sch.recordingWriter = writer
+			ruleStore.PutRule(context.Background(), rule)
+
+			go func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+			}()
+
+			evalChan <- &evaluation{
+				scheduledAt: sch.clock.Now(),
+				rule:        rule,
+			}
+
+			waitForTimeChannel(t, evalAppliedChan)
+
+			require.Len(t, writer.Writes(), 1)
+			expectedMetric := fmt.Sprintf(
+				`# HELP grafana_alerting_rule_evaluation_failures_total The total number of rule evaluation failures.
+        	# TYPE grafana_alerting_rule_evaluation_failures_total counter
+        	grafana_alerting_rule_evaluation_failures_total{org="%[1]d"} 1
+`, rule.OrgID)
+			err := testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetric), "grafana_alerting_rule_evaluation_failures_total")
+			require.NoError(t, err)
+		})
+
+		t.Run("it should not evaluate the rule if recording rules are disabled", func(t *testing.T) {
+			evalChan := make(chan *evaluation)
+			evalAppliedChan := make(chan time.Time)
+
+			sch, ruleStore, _, reg := createSchedule(evalAppliedChan, nil)
+			ruleStore.PutRule(context.Background(), rule)
+
+			go func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+			}()
+
+			evalChan <- &evaluation{
+				scheduledAt: sch.clock.Now(),
+				rule:        rule,
+			}
+
+			waitForTimeChannel(t, evalAppliedChan)
+
+			expectedMetric := fmt.Sprintf(
+				`# HELP grafana_alerting_rule_evaluations_total The total number of rule evaluations.
+        	# TYPE grafana_alerting_rule_evaluations_total counter
+        	grafana_alerting_rule_evaluations_total{org="%[1]d"} 0
+`, rule.OrgID)
+			err := testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetric), "grafana_alerting_rule_evaluations_total")
+			require.NoError(t, err)
+		})
+	})
+
 	t.Run("when there are no alerts to send it should not call notifiers", func(t *testing.T) {
 		rule := models.AlertRuleGen(withQueryForState(t, eval.Normal))()
 
@@ -847,7 +969,7 @@ func TestSchedule_deleteAlertRule(t *testing.T) {
 	})
 }
 
-func setupScheduler(t *testing.T, rs *fakeRulesStore, is *state.FakeInstanceStore, registry *prometheus.Registry, senderMock *SyncAlertsSenderMock, evalMock eval.EvaluatorFactory) *schedule {
+func setupScheduler(t *testing.T, rs *fakeRulesStore, is state.InstanceStore, registry *prometheus.Registry, senderMock *SyncAlertsSenderMock, evalMock eval.EvaluatorFactory) *schedule {
 	t.Helper()
 	testTracer := tracing.InitializeTracerForTest()
 
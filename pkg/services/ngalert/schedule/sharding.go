@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultRemoteStatesRefreshInterval is the default interval at which the states of the rules that other members of the
+// cluster evaluate are reloaded from the database.
+const defaultRemoteStatesRefreshInterval = time.Minute
+
+// ClusterMembership provides the members of the high availability cluster that this instance is part of.
+type ClusterMembership interface {
+	// ClusterMembers returns the name of this instance and the sorted names of the live members of the cluster,
+	// including this instance. It returns false if the instance is not part of a cluster.
+	ClusterMembers() (string, []string, bool)
+}
+
+// ruleSharding assigns the rule groups to the members of the high availability cluster, so that every rule is evaluated
+// by exactly one instance. A rule group is assigned to the member with the highest score for the group (rendezvous
+// hashing), so when a member joins or leaves the cluster only the groups of that member move to other members.
+// Rules of a group are always evaluated by the same instance because they can depend on each other.
+type ruleSharding struct {
+	cluster ClusterMembership
+	log     log.Logger
+
+	initialized bool
+	members     []string
+	// owned contains the rules that this instance evaluated after the last assignment.
+	owned map[ngmodels.AlertRuleKey]struct{}
+	// known contains all rules of the last assignment.
+	known map[ngmodels.AlertRuleKey]struct{}
+}
+
+func newRuleSharding(cluster ClusterMembership, logger log.Logger) *ruleSharding {
+	return &ruleSharding{
+		cluster: cluster,
+		log:     logger,
+		owned:   make(map[ngmodels.AlertRuleKey]struct{}),
+		known:   make(map[ngmodels.AlertRuleKey]struct{}),
+	}
+}
+
+// ruleAssignment is the result of the assignment of the rules to the members of the cluster.
+type ruleAssignment struct {
+	// owned contains the rules that this instance evaluates.
+	owned map[ngmodels.AlertRuleKey]struct{}
+	// acquired are the rules that this instance took over from other members since the last assignment.
+	acquired []*ngmodels.AlertRule
+	// released are the rules that other members took over from this instance since the last assignment.
+	released []ngmodels.AlertRuleKey
+	// remote are the rules that other members evaluate.
+	remote []*ngmodels.AlertRule
+	// added are the rules of remote that were not known before this assignment, all of them on the first assignment.
+	added []*ngmodels.AlertRule
+	// removed are the rules that other members evaluated and that were deleted since the last assignment.
+	removed []ngmodels.AlertRuleKey
+}
+
+// assign assigns the rules to the members of the cluster. If the members of the cluster are unknown, or this instance
+// is not one of them, this instance evaluates all rules.
+func (s *ruleSharding) assign(rules []*ngmodels.AlertRule) ruleAssignment {
+	self, members, ok := s.cluster.ClusterMembers()
+	if ok && !slices.Contains(members, self) {
+		s.log.Warn("This instance is not a member of the cluster yet, it evaluates all rules", "instance", self, "members", members)
+		ok = false
+	}
+	if !ok {
+		members = nil
+	}
+	if !s.initialized || !slices.Equal(s.members, members) {
+		s.log.Info("Members of the cluster changed, rules are reassigned", "instance", self, "members", members)
+		s.members = slices.Clone(members)
+	}
+
+	result := ruleAssignment{owned: make(map[ngmodels.AlertRuleKey]struct{}, len(rules))}
+	known := make(map[ngmodels.AlertRuleKey]struct{}, len(rules))
+	for _, rule := range rules {
+		key := rule.GetKey()
+		known[key] = struct{}{}
+		_, wasKnown := s.known[key]
+		_, wasOwned := s.owned[key]
+		if len(members) == 0 || ruleGroupOwner(rule.GetGroupKey(), members) == self {
+			result.owned[key] = struct{}{}
+			if s.initialized && wasKnown && !wasOwned {
+				result.acquired = append(result.acquired, rule)
+			}
+			continue
+		}
+		result.remote = append(result.remote, rule)
+		if !s.initialized || !wasKnown {
+			result.added = append(result.added, rule)
+		}
+		if s.initialized && wasOwned {
+			result.released = append(result.released, key)
+		}
+	}
+	for key := range s.known {
+		if _, ok := known[key]; ok {
+			continue
+		}
+		if _, ok := s.owned[key]; !ok {
+			result.removed = append(result.removed, key)
+		}
+	}
+	s.initialized = true
+	s.owned = result.owned
+	s.known = known
+	return result
+}
+
+// ruleGroupOwner returns the member that evaluates the rule group.
+func ruleGroupOwner(key ngmodels.AlertRuleGroupKey, members []string) string {
+	var owner string
+	var highest uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(member))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(strconv.FormatInt(key.OrgID, 10)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key.NamespaceUID))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key.RuleGroup))
+		if score := h.Sum64(); owner == "" || score > highest {
+			owner, highest = member, score
+		}
+	}
+	return owner
+}
@@ -0,0 +1,483 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+	ok      bool
+}
+
+func (f *fakeClusterMembership) ClusterMembers() (string, []string, bool) {
+	return f.self, f.members, f.ok
+}
+
+func TestRuleSharding(t *testing.T) {
+	rules := ngmodels.GenerateAlertRules(100, ngmodels.AlertRuleGen())
+	groupKey := ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "group"}
+	group := ngmodels.GenerateAlertRules(5, ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey)))
+	rules = append(rules, group...)
+
+	members := []string{"grafana-1", "grafana-2", "grafana-3"}
+	shardings := make([]*ruleSharding, 0, len(members))
+	for _, member := range members {
+		shardings = append(shardings, newRuleSharding(&fakeClusterMembership{self: member, members: members, ok: true}, log.NewNopLogger()))
+	}
+
+	t.Run("every rule should be evaluated by exactly one member", func(t *testing.T) {
+		total := 0
+		for _, s := range shardings {
+			a := s.assign(rules)
+			require.NotEmpty(t, a.owned)
+			require.Empty(t, a.acquired)
+			require.Empty(t, a.released)
+			require.Len(t, a.remote, len(rules)-len(a.owned))
+			total += len(a.owned)
+		}
+		require.Equal(t, len(rules), total)
+	})
+
+	t.Run("rules of a group should be evaluated by the same member", func(t *testing.T) {
+		owner := ruleGroupOwner(groupKey, members)
+		for i, s := range shardings {
+			for _, rule := range group {
+				_, ok := s.owned[rule.GetKey()]
+				require.Equal(t, members[i] == owner, ok)
+			}
+		}
+	})
+
+	t.Run("rules of a member that left should be taken over by the other members", func(t *testing.T) {
+		left := shardings[2].owned
+		remaining := members[:2]
+		for _, s := range shardings[:2] {
+			before := s.owned
+			s.cluster.(*fakeClusterMembership).members = remaining
+			a := s.assign(rules)
+			require.Empty(t, a.released)
+			for key := range before {
+				require.Contains(t, a.owned, key)
+			}
+			for _, rule := range a.acquired {
+				require.Contains(t, left, rule.GetKey())
+			}
+			require.Len(t, a.owned, len(before)+len(a.acquired))
+		}
+		require.Equal(t, len(rules), len(shardings[0].owned)+len(shardings[1].owned))
+	})
+
+	t.Run("rules should be released when the member joins again", func(t *testing.T) {
+		for _, s := range shardings[:2] {
+			s.cluster.(*fakeClusterMembership).members = members
+			a := s.assign(rules)
+			require.Empty(t, a.acquired)
+			for _, key := range a.released {
+				require.Equal(t, members[2], ruleGroupOwner(rules[indexOfRule(rules, key)].GetGroupKey(), members))
+			}
+		}
+	})
+
+	t.Run("deleted rules of other members should be removed", func(t *testing.T) {
+		s := shardings[0]
+		var deleted ngmodels.AlertRuleKey
+		remaining := make([]*ngmodels.AlertRule, 0, len(rules))
+		for _, rule := range rules {
+			if _, ok := s.owned[rule.GetKey()]; !ok && deleted.UID == "" {
+				deleted = rule.GetKey()
+				continue
+			}
+			remaining = append(remaining, rule)
+		}
+		a := s.assign(remaining)
+		require.Equal(t, []ngmodels.AlertRuleKey{deleted}, a.removed)
+		require.Len(t, a.remote, len(remaining)-len(a.owned))
+
+		a = s.assign(rules)
+		require.Empty(t, a.removed)
+	})
+
+	t.Run("all rules should be evaluated if the instance is not in a cluster", func(t *testing.T) {
+		s := shardings[0]
+		before := len(s.owned)
+		s.cluster.(*fakeClusterMembership).ok = false
+		a := s.assign(rules)
+		require.Len(t, a.owned, len(rules))
+		require.Len(t, a.acquired, len(rules)-before)
+		require.Empty(t, a.released)
+		require.Empty(t, a.remote)
+
+		s = newRuleSharding(&fakeClusterMembership{self: "grafana-4", members: members, ok: true}, log.NewNopLogger())
+		a = s.assign(rules)
+		require.Len(t, a.owned, len(rules))
+		require.Empty(t, a.released)
+	})
+}
+
+func TestProcessTicksWithSharding(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+
+	rules := ngmodels.GenerateAlertRules(20, ngmodels.AlertRuleGen(ngmodels.WithInterval(time.Second), ngmodels.WithOrgID(1)))
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	members := []string{"grafana-1", "grafana-2"}
+	cluster := &fakeClusterMembership{self: members[0], members: members, ok: true}
+	sch.sharding = newRuleSharding(cluster, log.NewNopLogger())
+
+	expected := make(map[ngmodels.AlertRuleKey]struct{})
+	for _, rule := range rules {
+		if ruleGroupOwner(rule.GetGroupKey(), members) == cluster.self {
+			expected[rule.GetKey()] = struct{}{}
+		}
+	}
+	require.NotEmpty(t, expected)
+	require.Less(t, len(expected), len(rules))
+
+	tick := time.Time{}.Add(time.Second)
+	scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Empty(t, stopped)
+	require.Len(t, scheduled, len(expected))
+	for _, item := range scheduled {
+		require.Contains(t, expected, item.rule.GetKey())
+	}
+
+	t.Run("should evaluate all rules when the other member leaves", func(t *testing.T) {
+		cluster.members = members[:1]
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped)
+		require.Len(t, scheduled, len(rules))
+	})
+
+	t.Run("should stop the routines of the rules that are evaluated by the member that joins", func(t *testing.T) {
+		cluster.members = members
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped)
+		require.Len(t, scheduled, len(expected))
+		for _, rule := range rules {
+			_, owned := expected[rule.GetKey()]
+			require.Equal(t, owned, sch.registry.exists(rule.GetKey()))
+		}
+		all, _ := sch.schedulableAlertRules.all()
+		require.Len(t, all, len(rules))
+	})
+}
+
+func TestShardingServesStateOfAllRules(t *testing.T) {
+	ctx := context.Background()
+	ruleStore := newFakeRulesStore()
+	instanceStore := newFakeSharedInstanceStore()
+	statsStore := newFakeSharedKVStore()
+	rules := ngmodels.GenerateAlertRules(10, ngmodels.AlertRuleGen(ngmodels.WithInterval(time.Second), ngmodels.WithOrgID(1), ngmodels.WithFor(0)))
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	members := []string{"grafana-1", "grafana-2"}
+	replicas := make([]*schedule, 0, len(members))
+	for _, member := range members {
+		sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+		sch.sharding = newRuleSharding(&fakeClusterMembership{self: member, members: members, ok: true}, log.NewNopLogger())
+		sch.evaluationStatsStore = statsStore
+		replicas = append(replicas, sch)
+	}
+
+	tick := time.Time{}.Add(time.Second)
+	processTick := func() {
+		for _, sch := range replicas {
+			dispatcherGroup, ctx := errgroup.WithContext(ctx)
+			sch.processTick(ctx, dispatcherGroup, tick)
+		}
+		tick = tick.Add(time.Second)
+	}
+	processTick()
+
+	// every replica evaluates the rules it owns, which saves their states to the database
+	for _, sch := range replicas {
+		for _, rule := range rules {
+			if _, ok := sch.sharding.owned[rule.GetKey()]; !ok {
+				continue
+			}
+			sch.stateManager.ProcessEvalResults(ctx, tick, rule, eval.Results{{
+				Instance:    data.Labels{"instance": "a"},
+				State:       eval.Alerting,
+				EvaluatedAt: tick,
+			}}, nil)
+			sch.evaluationStats.add(rule.GetKey(), ngmodels.EvaluationRecord{EvaluatedAt: tick, Series: 1})
+		}
+	}
+	// the statistics that a replica saves at a tick are loaded by the replicas that processed the tick before it at the next tick
+	processTick()
+	processTick()
+
+	t.Run("every replica should serve the states and statistics of all rules", func(t *testing.T) {
+		for i, sch := range replicas {
+			for _, rule := range rules {
+				states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+				require.Lenf(t, states, 1, "replica %s, rule %s", members[i], rule.UID)
+				require.Equal(t, eval.Alerting, states[0].State)
+
+				stats, ok := sch.GetEvaluationStats(rule.GetKey())
+				require.Truef(t, ok, "replica %s, rule %s", members[i], rule.UID)
+				require.Equal(t, int64(1), stats.Total)
+			}
+			require.Len(t, sch.stateManager.GetAll(1), len(rules))
+		}
+	})
+
+	t.Run("replicas should forget the state of deleted rules of other replicas", func(t *testing.T) {
+		deleted := rules[0]
+		ruleStore.DeleteRule(deleted)
+		processTick()
+		owner := ruleGroupOwner(deleted.GetGroupKey(), members)
+		for i, sch := range replicas {
+			// the owner deletes the states when the routine of the rule stops
+			if members[i] == owner {
+				continue
+			}
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(deleted.OrgID, deleted.UID))
+			_, ok := sch.GetEvaluationStats(deleted.GetKey())
+			require.False(t, ok)
+		}
+		_, ok := statsStore.values[deleted.GetKey()]
+		require.False(t, ok)
+	})
+}
+
+func TestShardingRefreshesRemoteStates(t *testing.T) {
+	ctx := context.Background()
+	ruleStore := newFakeRulesStore()
+	instanceStore := newFakeSharedInstanceStore()
+	gen := ngmodels.AlertRuleGen(ngmodels.WithInterval(time.Second), ngmodels.WithOrgID(1), ngmodels.WithFor(0))
+	rules := ngmodels.GenerateAlertRules(20, gen)
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	members := []string{"grafana-1", "grafana-2"}
+	owner := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	owner.sharding = newRuleSharding(&fakeClusterMembership{self: members[1], members: members, ok: true}, log.NewNopLogger())
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	sch.sharding = newRuleSharding(&fakeClusterMembership{self: members[0], members: members, ok: true}, log.NewNopLogger())
+	sch.remoteStatesRefreshInterval = time.Minute
+
+	tick := time.Time{}.Add(time.Second)
+	processTick := func(s *schedule) {
+		dispatcherGroup, ctx := errgroup.WithContext(ctx)
+		s.processTick(ctx, dispatcherGroup, tick)
+	}
+	evaluate := func(rule *ngmodels.AlertRule) {
+		owner.stateManager.ProcessEvalResults(ctx, tick, rule, eval.Results{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: tick,
+		}}, nil)
+	}
+	processTick(owner)
+	processTick(sch)
+	remote := make([]*ngmodels.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		if _, ok := owner.sharding.owned[rule.GetKey()]; ok {
+			remote = append(remote, rule)
+			evaluate(rule)
+		}
+	}
+	require.NotEmpty(t, remote)
+
+	t.Run("should not reload the states of remote rules before the refresh interval", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		instanceStore.resetQueries()
+		processTick(sch)
+		require.Empty(t, instanceStore.queries())
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(remote[0].OrgID, remote[0].UID))
+	})
+
+	t.Run("should load only the states of new remote rules", func(t *testing.T) {
+		var added *ngmodels.AlertRule
+		for added == nil {
+			rule := gen()
+			if ruleGroupOwner(rule.GetGroupKey(), members) == members[1] {
+				added = rule
+			}
+		}
+		ruleStore.PutRule(ctx, added)
+		tick = tick.Add(time.Second)
+		processTick(owner)
+		evaluate(added)
+
+		instanceStore.resetQueries()
+		processTick(sch)
+		require.Equal(t, []ngmodels.ListAlertInstancesQuery{{RuleOrgID: added.OrgID, RuleUID: added.UID}}, instanceStore.queries())
+		require.Len(t, sch.stateManager.GetStatesForRuleUID(added.OrgID, added.UID), 1)
+		remote = append(remote, added)
+	})
+
+	t.Run("should reload the states of all remote rules after the refresh interval", func(t *testing.T) {
+		tick = tick.Add(time.Minute)
+		processTick(sch)
+		for _, rule := range remote {
+			require.Lenf(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID), 1, "rule %s", rule.UID)
+		}
+	})
+}
+
+// fakeSharedInstanceStore is an in-memory instance store that can be shared by the replicas of a test.
+type fakeSharedInstanceStore struct {
+	mtx       sync.Mutex
+	instances map[ngmodels.AlertInstanceKey]ngmodels.AlertInstance
+	listed    []ngmodels.ListAlertInstancesQuery
+}
+
+func (f *fakeSharedInstanceStore) queries() []ngmodels.ListAlertInstancesQuery {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.listed
+}
+
+func (f *fakeSharedInstanceStore) resetQueries() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.listed = nil
+}
+
+func newFakeSharedInstanceStore() *fakeSharedInstanceStore {
+	return &fakeSharedInstanceStore{instances: make(map[ngmodels.AlertInstanceKey]ngmodels.AlertInstance)}
+}
+
+func (f *fakeSharedInstanceStore) FetchOrgIds(_ context.Context) ([]int64, error) {
+	return []int64{1}, nil
+}
+
+func (f *fakeSharedInstanceStore) ListAlertInstances(_ context.Context, q *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.listed = append(f.listed, *q)
+	var result []*ngmodels.AlertInstance
+	for _, instance := range f.instances {
+		if instance.RuleOrgID != q.RuleOrgID || (q.RuleUID != "" && instance.RuleUID != q.RuleUID) {
+			continue
+		}
+		instance := instance
+		result = append(result, &instance)
+	}
+	return result, nil
+}
+
+func (f *fakeSharedInstanceStore) SaveAlertInstance(_ context.Context, instance ngmodels.AlertInstance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.instances[instance.AlertInstanceKey] = instance
+	return nil
+}
+
+func (f *fakeSharedInstanceStore) DeleteAlertInstances(_ context.Context, keys ...ngmodels.AlertInstanceKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, key := range keys {
+		delete(f.instances, key)
+	}
+	return nil
+}
+
+func (f *fakeSharedInstanceStore) DeleteAlertInstancesByRule(_ context.Context, key ngmodels.AlertRuleKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for k := range f.instances {
+		if k.RuleOrgID == key.OrgID && k.RuleUID == key.UID {
+			delete(f.instances, k)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSharedInstanceStore) FullSync(_ context.Context, instances []ngmodels.AlertInstance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.instances = make(map[ngmodels.AlertInstanceKey]ngmodels.AlertInstance, len(instances))
+	for _, instance := range instances {
+		f.instances[instance.AlertInstanceKey] = instance
+	}
+	return nil
+}
+
+// fakeSharedKVStore is an in-memory store of evaluation statistics that can be shared by the replicas of a test.
+type fakeSharedKVStore struct {
+	mtx    sync.Mutex
+	values map[ngmodels.AlertRuleKey]string
+}
+
+func newFakeSharedKVStore() *fakeSharedKVStore {
+	return &fakeSharedKVStore{values: make(map[ngmodels.AlertRuleKey]string)}
+}
+
+func (f *fakeSharedKVStore) Get(_ context.Context, orgID int64, _ string, key string) (string, bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	v, ok := f.values[ngmodels.AlertRuleKey{OrgID: orgID, UID: key}]
+	return v, ok, nil
+}
+
+func (f *fakeSharedKVStore) Set(_ context.Context, orgID int64, _ string, key string, value string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.values[ngmodels.AlertRuleKey{OrgID: orgID, UID: key}] = value
+	return nil
+}
+
+func (f *fakeSharedKVStore) Del(_ context.Context, orgID int64, _ string, key string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.values, ngmodels.AlertRuleKey{OrgID: orgID, UID: key})
+	return nil
+}
+
+func (f *fakeSharedKVStore) Keys(_ context.Context, _ int64, namespace string, _ string) ([]kvstore.Key, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	keys := make([]kvstore.Key, 0, len(f.values))
+	for k := range f.values {
+		keys = append(keys, kvstore.Key{OrgId: k.OrgID, Namespace: namespace, Key: k.UID})
+	}
+	return keys, nil
+}
+
+func (f *fakeSharedKVStore) GetAll(_ context.Context, _ int64, _ string) (map[int64]map[string]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[int64]map[string]string)
+	for k, v := range f.values {
+		if result[k.OrgID] == nil {
+			result[k.OrgID] = make(map[string]string)
+		}
+		result[k.OrgID][k.UID] = v
+	}
+	return result, nil
+}
+
+func indexOfRule(rules []*ngmodels.AlertRule, key ngmodels.AlertRuleKey) int {
+	for i, rule := range rules {
+		if rule.GetKey() == key {
+			return i
+		}
+	}
+	return -1
+}
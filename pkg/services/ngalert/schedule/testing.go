@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	definitions "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	mock "github.com/stretchr/testify/mock"
@@ -86,6 +88,32 @@ func (f *fakeRulesStore) getNamespaceTitle(uid string) string {
 	return "TEST-FOLDER-" + uid
 }
 
+type fakeRecordingWrite struct {
+	name        string
+	t           time.Time
+	frames      data.Frames
+	extraLabels map[string]string
+}
+
+type fakeRecordingWriter struct {
+	mu     sync.Mutex
+	err    error
+	writes []fakeRecordingWrite
+}
+
+func (f *fakeRecordingWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes = append(f.writes, fakeRecordingWrite{name: name, t: t, frames: frames, extraLabels: extraLabels})
+	return f.err
+}
+
+func (f *fakeRecordingWriter) Writes() []fakeRecordingWrite {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.writes)
+}
+
 type SyncAlertsSenderMock struct {
 	*AlertsSenderMock
 	mu sync.Mutex
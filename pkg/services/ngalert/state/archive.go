@@ -0,0 +1,117 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ExportInstances returns the state of all alert instances of the organization from the instance store.
+// Unlike the state cache, which has only the rules evaluated by this replica, the store has the state of all rules.
+func (st *Manager) ExportInstances(ctx context.Context, orgID int64) ([]ngModels.AlertInstance, error) {
+	if st.instanceStore == nil {
+		return nil, errors.New("instance store is not configured")
+	}
+	entries, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert instances: %w", err)
+	}
+	instances := make([]ngModels.AlertInstance, 0, len(entries))
+	for _, entry := range entries {
+		instances = append(instances, *entry)
+	}
+	return instances, nil
+}
+
+// ImportInstances restores the state of alert instances the same way Warm does at startup, and persists them in the instance store.
+// An imported instance replaces the state of the instance with the same labels in the cache. Instances of rules that are not
+// in the map of rules are skipped. It returns the number of imported instances.
+func (st *Manager) ImportInstances(ctx context.Context, instances []ngModels.AlertInstance, rules map[string]*ngModels.AlertRule) (int, error) {
+	imported := 0
+	for _, entry := range instances {
+		rule, ok := rules[entry.RuleUID]
+		if !ok || rule.OrgID != entry.RuleOrgID {
+			continue
+		}
+
+		restored, err := stateFromInstance(entry, rule)
+		if err != nil {
+			return imported, fmt.Errorf("failed to import alert instance of rule %s: %w", entry.RuleUID, err)
+		}
+
+		if st.instanceStore != nil {
+			if err := st.instanceStore.SaveAlertInstance(ctx, entry); err != nil {
+				return imported, fmt.Errorf("failed to save alert instance of rule %s: %w", entry.RuleUID, err)
+			}
+		}
+
+		st.cache.set(restored)
+		imported++
+	}
+	return imported, nil
+}
+
+// stateFromInstance restores the state of the alert instance of the rule.
+func stateFromInstance(entry ngModels.AlertInstance, rule *ngModels.AlertRule) (*State, error) {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache ID: %w", err)
+	}
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse result fingerprint: %w", err)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:       entry.RuleUID,
+		OrgID:              entry.RuleOrgID,
+		CacheID:            cacheID,
+		Labels:             map[string]string(entry.Labels),
+		State:              translateInstanceState(entry.CurrentState),
+		StateReason:        entry.CurrentReason,
+		StartsAt:           entry.CurrentStateSince,
+		EndsAt:             entry.CurrentStateEnd,
+		LastEvaluationTime: entry.LastEvalTime,
+		Annotations:        rule.Annotations,
+		ResultFingerprint:  resultFp,
+		KeepFiringSince:    entry.KeepFiringSince,
+	}, nil
+}
+
+// RelabelInstance moves an alert instance to the given rule. It replaces the labels that Grafana derives from the rule
+// and its folder, so that the instance has the same labels as the alerts of the rule, and recalculates the key of the instance.
+func RelabelInstance(instance ngModels.AlertInstance, rule *ngModels.AlertRule, folderTitle string) (ngModels.AlertInstance, error) {
+	lbs := make(ngModels.InstanceLabels, len(instance.Labels))
+	for k, v := range instance.Labels {
+		lbs[k] = v
+	}
+	if _, ok := lbs[alertingModels.RuleUIDLabel]; ok {
+		lbs[alertingModels.RuleUIDLabel] = rule.UID
+	}
+	if _, ok := lbs[alertingModels.NamespaceUIDLabel]; ok {
+		lbs[alertingModels.NamespaceUIDLabel] = rule.NamespaceUID
+	}
+	if _, ok := lbs[ngModels.FolderTitleLabel]; ok {
+		lbs[ngModels.FolderTitleLabel] = folderTitle
+	}
+
+	_, hash, err := lbs.StringAndHash()
+	if err != nil {
+		return ngModels.AlertInstance{}, err
+	}
+
+	instance.Labels = lbs
+	instance.RuleOrgID = rule.OrgID
+	instance.RuleUID = rule.UID
+	instance.LabelsHash = hash
+	return instance, nil
+}
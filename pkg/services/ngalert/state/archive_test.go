@@ -0,0 +1,80 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestExportImportInstances(t *testing.T) {
+	ctx := context.Background()
+	rule := models.AlertRuleGen(models.WithOrgID(1), models.WithKeepFiringFor(time.Minute))()
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	instance := models.AlertInstance{
+		AlertInstanceKey:  models.AlertInstanceKey{RuleOrgID: 1, RuleUID: rule.UID, LabelsHash: "hash"},
+		Labels:            models.InstanceLabels{"alertname": rule.Title},
+		CurrentState:      models.InstanceStateFiring,
+		CurrentStateSince: since,
+		LastEvalTime:      since.Add(time.Minute),
+		KeepFiringSince:   since.Add(time.Minute),
+	}
+	store := &archiveInstanceStore{instances: []*models.AlertInstance{
+		&instance,
+		{AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: 2, RuleUID: "other", LabelsHash: "hash"}},
+	}}
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: store,
+		Images:        &state.NoopImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}, state.NewNoopPersister())
+
+	t.Run("export should read instances of the organization from the instance store", func(t *testing.T) {
+		instances, err := st.ExportInstances(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []models.AlertInstance{instance}, instances)
+	})
+
+	t.Run("import should restore the time since the instance keeps firing", func(t *testing.T) {
+		imported, err := st.ImportInstances(ctx, []models.AlertInstance{instance}, map[string]*models.AlertRule{rule.UID: rule})
+		require.NoError(t, err)
+		require.Equal(t, 1, imported)
+
+		cacheID, err := instance.Labels.StringKey()
+		require.NoError(t, err)
+		s := st.Get(1, rule.UID, cacheID)
+		require.NotNil(t, s)
+		require.Equal(t, eval.Alerting, s.State)
+		require.Equal(t, instance.KeepFiringSince, s.KeepFiringSince)
+	})
+}
+
+// archiveInstanceStore is an instance store that lists the given instances.
+type archiveInstanceStore struct {
+	state.FakeInstanceStore
+	instances []*models.AlertInstance
+}
+
+func (s *archiveInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	var result []*models.AlertInstance
+	for _, i := range s.instances {
+		if i.RuleOrgID == q.RuleOrgID {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
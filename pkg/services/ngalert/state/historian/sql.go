@@ -0,0 +1,459 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+const (
+	// sqlBatchSize is the maximum number of rows written, or values used in a single IN clause, in one statement.
+	sqlBatchSize = 100
+	// sqlCleanupInterval is how often the SQL backend deletes state history that is older than the retention period.
+	sqlCleanupInterval = 10 * time.Minute
+	// sqlLabelValueMaxLength is the length of the label_value column. Longer values are stored truncated.
+	sqlLabelValueMaxLength = 190
+)
+
+// stateHistoryEntry is a single state transition of an alert instance stored in the alert_state_history table.
+type stateHistoryEntry struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	RuleUID      string `xorm:"rule_uid"`
+	RuleID       int64  `xorm:"rule_id"`
+	RuleTitle    string `xorm:"rule_title"`
+	RuleGroup    string `xorm:"rule_group"`
+	FolderUID    string `xorm:"folder_uid"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	Condition    string `xorm:"rule_condition"`
+	Fingerprint  string `xorm:"fingerprint"`
+	Labels       string `xorm:"labels"`
+	Previous     string `xorm:"previous_state"`
+	Current      string `xorm:"current_state"`
+	Error        string `xorm:"error_message"`
+	Values       string `xorm:"state_values"`
+	// Epoch is the time of the transition in milliseconds.
+	Epoch int64 `xorm:"epoch"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// stateHistoryLabel is a single label of the label set identified by Fingerprint.
+type stateHistoryLabel struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	Fingerprint string `xorm:"fingerprint"`
+	Key         string `xorm:"label_key"`
+	Value       string `xorm:"label_value"`
+}
+
+func (stateHistoryLabel) TableName() string {
+	return "alert_state_history_label"
+}
+
+// SQLBackend is a state.Historian that records state history to dedicated tables in the Grafana database.
+type SQLBackend struct {
+	db        db.DB
+	retention time.Duration
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+
+	cleanupMtx  sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewSQLBackend(store db.DB, retention time.Duration, metrics *metrics.Historian) *SQLBackend {
+	logger := log.New("ngalert.state.historian", "backend", "sql")
+	return &SQLBackend{
+		db:        store,
+		retention: retention,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the Grafana database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries, labelSets := statesToEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.save(ctx, rule.OrgID, entries, labelSets); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+
+		h.deleteExpired(ctx, logger)
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the Grafana database and formats the results into a dataframe
+// of the same shape as the one returned by the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	labelKeys := make([]string, 0, len(query.Labels))
+	for k := range query.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	var entries []stateHistoryEntry
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(stateHistoryEntry{}).
+			Where("org_id = ?", query.OrgID).
+			And("epoch >= ? AND epoch <= ?", query.From.UnixMilli(), query.To.UnixMilli())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		for _, k := range labelKeys {
+			q = q.And("fingerprint IN (SELECT fingerprint FROM alert_state_history_label WHERE org_id = ? AND label_key = ? AND label_value = ?)", query.OrgID, k, truncateLabelValue(query.Labels[k]))
+		}
+		// Take the most recent entries if there are more than the limit, like the Loki backend does.
+		return q.Desc("epoch", "id").Limit(limit).Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+	entries, err = filterTruncatedLabels(entries, query.Labels)
+	if err != nil {
+		return nil, err
+	}
+	return entriesToFrame(entries)
+}
+
+// truncateLabelValue returns the value as it is stored in the label_value column.
+func truncateLabelValue(v string) string {
+	if len(v) <= sqlLabelValueMaxLength {
+		return v
+	}
+	r := []rune(v)
+	if len(r) <= sqlLabelValueMaxLength {
+		return v
+	}
+	return string(r[:sqlLabelValueMaxLength])
+}
+
+// filterTruncatedLabels removes the entries that matched a filter only by the truncated value of a label.
+func filterTruncatedLabels(entries []stateHistoryEntry, filter map[string]string) ([]stateHistoryEntry, error) {
+	truncated := make(map[string]string)
+	for k, v := range filter {
+		if truncateLabelValue(v) != v {
+			truncated[k] = v
+		}
+	}
+	if len(truncated) == 0 {
+		return entries, nil
+	}
+	result := make([]stateHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		var lbls map[string]string
+		if err := json.Unmarshal([]byte(e.Labels), &lbls); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of entry: %w", err)
+		}
+		matches := true
+		for k, v := range truncated {
+			if lbls[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// save writes the entries and the label sets they reference.
+// Entries are written first, so that label sets of new entries are never deleted as orphaned by a concurrent cleanup.
+func (h *SQLBackend) save(ctx context.Context, orgID int64, entries []stateHistoryEntry, labelSets map[string]data.Labels) error {
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(entries); start += sqlBatchSize {
+			batch := entries[start:min(start+sqlBatchSize, len(entries))]
+			if _, err := sess.Insert(&batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := h.saveLabelSets(ctx, orgID, labelSets); err != nil {
+		return fmt.Errorf("failed to save labels: %w", err)
+	}
+	return nil
+}
+
+// saveLabelSets writes the label sets that are not stored yet.
+func (h *SQLBackend) saveLabelSets(ctx context.Context, orgID int64, labelSets map[string]data.Labels) error {
+	fingerprints := make([]string, 0, len(labelSets))
+	for fp, lbls := range labelSets {
+		if len(lbls) > 0 {
+			fingerprints = append(fingerprints, fp)
+		}
+	}
+	sort.Strings(fingerprints)
+
+	existing := make(map[string]struct{}, len(fingerprints))
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(fingerprints); start += sqlBatchSize {
+			var stored []string
+			err := sess.Table(stateHistoryLabel{}).
+				Distinct("fingerprint").
+				Where("org_id = ?", orgID).
+				In("fingerprint", fingerprints[start:min(start+sqlBatchSize, len(fingerprints))]).
+				Find(&stored)
+			if err != nil {
+				return err
+			}
+			for _, fp := range stored {
+				existing[fp] = struct{}{}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, fp := range fingerprints {
+		if _, ok := existing[fp]; ok {
+			continue
+		}
+		rows := make([]stateHistoryLabel, 0, len(labelSets[fp]))
+		for k, v := range labelSets[fp] {
+			rows = append(rows, stateHistoryLabel{OrgID: orgID, Fingerprint: fp, Key: k, Value: truncateLabelValue(v)})
+		}
+		// Every label set is written in its own statement. If another writer stored the same label set in the meantime,
+		// the statement fails as a whole on the unique index and the label set is already complete.
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Insert(&rows)
+			return err
+		})
+		if err != nil && !h.db.GetDialect().IsUniqueConstraintViolation(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExpired deletes state history that is older than the retention period, and label sets that are no longer referenced.
+// It does nothing if it ran less than sqlCleanupInterval ago.
+func (h *SQLBackend) deleteExpired(ctx context.Context, logger log.Logger) {
+	if h.retention <= 0 {
+		return
+	}
+	now := h.clock.Now()
+	h.cleanupMtx.Lock()
+	if now.Sub(h.lastCleanup) < sqlCleanupInterval {
+		h.cleanupMtx.Unlock()
+		return
+	}
+	h.lastCleanup = now
+	h.cleanupMtx.Unlock()
+
+	var deleted, deletedLabels int64
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE epoch < ?", now.Add(-h.retention).UnixMilli())
+		if err != nil {
+			return err
+		}
+		deleted, _ = res.RowsAffected()
+
+		res, err = sess.Exec("DELETE FROM alert_state_history_label WHERE NOT EXISTS (SELECT 1 FROM alert_state_history WHERE alert_state_history.org_id = alert_state_history_label.org_id AND alert_state_history.fingerprint = alert_state_history_label.fingerprint)")
+		if err != nil {
+			return err
+		}
+		deletedLabels, _ = res.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to delete expired alert state history", "error", err)
+		return
+	}
+	logger.Debug("Deleted expired alert state history", "entries", deleted, "labels", deletedLabels)
+}
+
+// statesToEntries builds the rows to store for the state transitions that should be recorded,
+// and returns them together with the label sets of the alert instances, keyed by their fingerprint.
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) ([]stateHistoryEntry, map[string]data.Labels) {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	labelSets := make(map[string]data.Labels)
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		lbls, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		values, err := valuesAsDataBlob(state.State).MarshalJSON()
+		if err != nil {
+			logger.Error("Failed to serialize values of state, skipping", "error", err)
+			continue
+		}
+
+		entry := stateHistoryEntry{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			RuleID:       rule.ID,
+			RuleTitle:    rule.Title,
+			RuleGroup:    rule.Group,
+			FolderUID:    rule.NamespaceUID,
+			DashboardUID: rule.DashboardUID,
+			PanelID:      rule.PanelID,
+			Condition:    rule.Condition,
+			Fingerprint:  labelFingerprint(sanitizedLabels),
+			Labels:       string(lbls),
+			Previous:     state.PreviousFormatted(),
+			Current:      state.Formatted(),
+			Values:       string(values),
+			Epoch:        state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error {
+			entry.Error = state.Error.Error()
+		}
+
+		entries = append(entries, entry)
+		labelSets[entry.Fingerprint] = sanitizedLabels
+	}
+	return entries, labelSets
+}
+
+// entriesToFrame formats entries, sorted from the most recent, into a dataframe sorted by time.
+func entriesToFrame(entries []stateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+
+		var instanceLabels map[string]string
+		if e.Labels != "" {
+			if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal labels of entry: %w", err)
+			}
+		}
+		values := simplejson.New()
+		if e.Values != "" {
+			if err := values.UnmarshalJSON([]byte(e.Values)); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal values of entry: %w", err)
+			}
+		}
+		line, err := json.Marshal(LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.Previous,
+			Current:        e.Current,
+			Error:          e.Error,
+			Values:         values,
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: instanceLabels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry: %w", err)
+		}
+		// The same labels that identify log streams in the Loki backend.
+		streamLbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.FolderUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+
+		times = append(times, time.UnixMilli(e.Epoch))
+		lines = append(lines, line)
+		labels = append(labels, streamLbls)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	return frame, nil
+}
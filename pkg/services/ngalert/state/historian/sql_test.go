@@ -0,0 +1,240 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestStatesToEntries(t *testing.T) {
+	t.Run("skips non-transitory states", func(t *testing.T) {
+		states := singleFromNormal(&state.State{State: eval.Normal})
+
+		entries, labelSets := statesToEntries(createTestRule(), states, log.NewNopLogger())
+
+		require.Empty(t, entries)
+		require.Empty(t, labelSets)
+	})
+
+	t.Run("maps state transitions to entries", func(t *testing.T) {
+		rule := createTestRule()
+		now := time.Now()
+		states := singleFromNormal(&state.State{
+			State:              eval.Error,
+			Error:              fmt.Errorf("oh no"),
+			Labels:             data.Labels{"a": "b", "__private__": "c"},
+			LastEvaluationTime: now,
+		})
+
+		entries, labelSets := statesToEntries(rule, states, log.NewNopLogger())
+
+		require.Len(t, entries, 1)
+		e := entries[0]
+		require.Equal(t, rule.OrgID, e.OrgID)
+		require.Equal(t, rule.UID, e.RuleUID)
+		require.Equal(t, rule.Group, e.RuleGroup)
+		require.Equal(t, rule.NamespaceUID, e.FolderUID)
+		require.Equal(t, "Normal", e.Previous)
+		require.Equal(t, "Error", e.Current)
+		require.Equal(t, "oh no", e.Error)
+		require.Equal(t, now.UnixMilli(), e.Epoch)
+		require.JSONEq(t, `{"a":"b"}`, e.Labels)
+		require.Equal(t, map[string]data.Labels{e.Fingerprint: {"a": "b"}}, labelSets)
+	})
+}
+
+func TestEntriesToFrame(t *testing.T) {
+	entries := []stateHistoryEntry{
+		{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "my-group", FolderUID: "my-folder", Labels: `{"a":"c"}`, Previous: "Alerting", Current: "Normal", Epoch: 2000},
+		{OrgID: 1, RuleUID: "rule-uid", RuleGroup: "my-group", FolderUID: "my-folder", Labels: `{"a":"b"}`, Previous: "Normal", Current: "Alerting", Values: `{"A":1}`, Epoch: 1000},
+	}
+
+	frame, err := entriesToFrame(entries)
+
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, time.UnixMilli(1000), frame.Fields[0].At(0))
+	require.Equal(t, time.UnixMilli(2000), frame.Fields[0].At(1))
+
+	var entry LokiEntry
+	require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+	require.Equal(t, "Alerting", entry.Current)
+	require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+	require.Equal(t, float64(1), entry.Values.Get("A").MustFloat64())
+	require.JSONEq(t, `{"from":"state-history","orgID":"1","group":"my-group","folderUID":"my-folder"}`, string(frame.Fields[2].At(0).(json.RawMessage)))
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	createBackend := func(t *testing.T, retention time.Duration) *SQLBackend {
+		return NewSQLBackend(db.InitTestDB(t), retention, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+	}
+
+	transitions := func(now time.Time, labels ...data.Labels) []state.StateTransition {
+		result := make([]state.StateTransition, 0, len(labels))
+		for _, l := range labels {
+			result = append(result, state.StateTransition{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: l, LastEvaluationTime: now},
+			})
+		}
+		return result
+	}
+
+	t.Run("recorded state transitions are queryable", func(t *testing.T) {
+		sql := createBackend(t, 0)
+		rule := createTestRule()
+		now := time.Now()
+
+		err := <-sql.Record(context.Background(), rule, transitions(now, data.Labels{"a": "b"}, data.Labels{"a": "c"}))
+		require.NoError(t, err)
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:   rule.OrgID,
+			RuleUID: rule.UID,
+			From:    now.Add(-time.Minute),
+			To:      now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+
+		frame, err = sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:   rule.OrgID,
+			RuleUID: "other-rule",
+			From:    now.Add(-time.Minute),
+			To:      now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("queries filter by labels", func(t *testing.T) {
+		sql := createBackend(t, 0)
+		rule := createTestRule()
+		now := time.Now()
+
+		err := <-sql.Record(context.Background(), rule, transitions(now, data.Labels{"a": "b", "x": "y"}, data.Labels{"a": "c", "x": "y"}))
+		require.NoError(t, err)
+		// Label sets that are already stored are not written again.
+		err = <-sql.Record(context.Background(), rule, transitions(now.Add(time.Second), data.Labels{"a": "b", "x": "y"}))
+		require.NoError(t, err)
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:  rule.OrgID,
+			Labels: map[string]string{"a": "b", "x": "y"},
+			From:   now.Add(-time.Minute),
+			To:     now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+
+		frame, err = sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:  rule.OrgID,
+			Labels: map[string]string{"x": "y"},
+			From:   now.Add(-time.Minute),
+			To:     now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+
+		frame, err = sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:  rule.OrgID,
+			Labels: map[string]string{"a": "d"},
+			From:   now.Add(-time.Minute),
+			To:     now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("queries filter by long label values", func(t *testing.T) {
+		sql := createBackend(t, 0)
+		rule := createTestRule()
+		now := time.Now()
+		prefix := strings.Repeat("v", sqlLabelValueMaxLength)
+
+		err := <-sql.Record(context.Background(), rule, transitions(now, data.Labels{"a": prefix + "1"}, data.Labels{"a": prefix + "2"}))
+		require.NoError(t, err)
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:  rule.OrgID,
+			Labels: map[string]string{"a": prefix + "1"},
+			From:   now.Add(-time.Minute),
+			To:     now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("queries return the most recent entries up to the limit", func(t *testing.T) {
+		sql := createBackend(t, 0)
+		rule := createTestRule()
+		now := time.Now()
+
+		for i := 0; i < 3; i++ {
+			err := <-sql.Record(context.Background(), rule, transitions(now.Add(time.Duration(i)*time.Second), data.Labels{"a": "b"}))
+			require.NoError(t, err)
+		}
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID: rule.OrgID,
+			Limit: 2,
+			From:  now.Add(-time.Minute),
+			To:    now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.UnixMilli(now.Add(time.Second).UnixMilli()), frame.Fields[0].At(0))
+		require.Equal(t, time.UnixMilli(now.Add(2*time.Second).UnixMilli()), frame.Fields[0].At(1))
+	})
+
+	t.Run("expired state history is deleted", func(t *testing.T) {
+		sql := createBackend(t, time.Hour)
+		mock := clock.NewMock()
+		mock.Set(time.Now())
+		sql.clock = mock
+		rule := createTestRule()
+		now := mock.Now()
+
+		err := <-sql.Record(context.Background(), rule, transitions(now.Add(-2*time.Hour), data.Labels{"a": "old"}))
+		require.NoError(t, err)
+		mock.Add(sqlCleanupInterval)
+		err = <-sql.Record(context.Background(), rule, transitions(now, data.Labels{"a": "new"}))
+		require.NoError(t, err)
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID: rule.OrgID,
+			From:  now.Add(-3 * time.Hour),
+			To:    now.Add(time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+
+		var labels []stateHistoryLabel
+		err = sql.db.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.Find(&labels)
+		})
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		require.Equal(t, "new", labels[0].Value)
+	})
+}
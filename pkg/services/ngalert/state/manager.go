@@ -207,6 +207,7 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
 				ResultFingerprint:    resultFp,
+				KeepFiringSince:      entry.KeepFiringSince,
 			}
 			statesCount++
 		}
@@ -392,8 +393,13 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 
 	switch result.State {
 	case eval.Normal:
-		logger.Debug("Setting next state", "handler", "resultNormal")
-		resultNormal(currentState, alertRule, result, logger, "")
+		if shouldKeepFiring(currentState, alertRule, result) {
+			logger.Debug("Setting next state", "handler", "resultKeepFiring")
+			resultKeepFiring(currentState, alertRule, result, logger)
+		} else {
+			logger.Debug("Setting next state", "handler", "resultNormal")
+			resultNormal(currentState, alertRule, result, logger, "")
+		}
 	case eval.Alerting:
 		logger.Debug("Setting next state", "handler", "resultAlerting")
 		resultAlerting(currentState, alertRule, result, logger, "")
@@ -416,6 +422,13 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	// The alert keeps firing even though the condition is not met anymore.
+	if currentState.State == eval.Alerting && result.State == eval.Normal {
+		currentState.StateReason = ngModels.StateReasonRecovering
+	} else {
+		currentState.KeepFiringSince = time.Time{}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
@@ -94,6 +94,7 @@ func (a *SyncStatePersister) saveAlertStates(ctx context.Context, states ...Stat
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
 			ResultFingerprint: s.ResultFingerprint.String(),
+			KeepFiringSince:   s.KeepFiringSince,
 		}
 
 		err = a.store.SaveAlertInstance(ctx, instance)
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// maxRulesListedByUID is the maximum number of rules of an organization whose instances are listed rule by rule when
+// their states are acquired. The instances of more rules are listed with a single query for the whole organization.
+const maxRulesListedByUID = 10
+
+// ReleaseStates removes the states of the rules from the cache without resolving them and without deleting them from the
+// instance store. It is used when rules that are evaluated by another instance of the high availability cluster are
+// deleted, because that instance deletes their states from the instance store. It returns the number of released states.
+func (st *Manager) ReleaseStates(keys []ngModels.AlertRuleKey) int {
+	released := 0
+	for _, key := range keys {
+		released += len(st.cache.removeByRuleUID(key.OrgID, key.UID))
+	}
+	return released
+}
+
+// AcquireStates replaces the states of the rules in the cache with the states in the instance store. It is used when this
+// instance takes over the evaluation of the rules from another instance of the high availability cluster, and to refresh
+// the states of the rules that other instances evaluate, which save the states of their rules to the instance store
+// after every evaluation. It returns the number of acquired states.
+func (st *Manager) AcquireStates(ctx context.Context, rules []*ngModels.AlertRule) (int, error) {
+	if st.instanceStore == nil || len(rules) == 0 {
+		return 0, nil
+	}
+
+	rulesByOrg := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		orgRules, ok := rulesByOrg[rule.OrgID]
+		if !ok {
+			orgRules = make(map[string]*ngModels.AlertRule)
+			rulesByOrg[rule.OrgID] = orgRules
+		}
+		orgRules[rule.UID] = rule
+	}
+
+	acquired := 0
+	for orgID, orgRules := range rulesByOrg {
+		instances, err := st.listRulesInstances(ctx, orgID, orgRules)
+		if err != nil {
+			return acquired, err
+		}
+		for uid := range orgRules {
+			st.cache.removeByRuleUID(orgID, uid)
+		}
+		for _, entry := range instances {
+			rule, ok := orgRules[entry.RuleUID]
+			if !ok {
+				continue
+			}
+			s, err := stateFromInstance(*entry, rule)
+			if err != nil {
+				st.log.Error("Failed to restore the state of alert instance, it will be ignored", append(rule.GetKey().LogContext(), "error", err)...)
+				continue
+			}
+			st.cache.set(s)
+			acquired++
+		}
+	}
+	return acquired, nil
+}
+
+// listRulesInstances lists the alert instances of the rules of the organization in the instance store.
+func (st *Manager) listRulesInstances(ctx context.Context, orgID int64, rules map[string]*ngModels.AlertRule) ([]*ngModels.AlertInstance, error) {
+	if len(rules) > maxRulesListedByUID {
+		instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alert instances of organization %d: %w", orgID, err)
+		}
+		return instances, nil
+	}
+	var result []*ngModels.AlertInstance
+	for uid := range rules {
+		instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUID: uid})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alert instances of rule %s in organization %d: %w", uid, orgID, err)
+		}
+		result = append(result, instances...)
+	}
+	return result, nil
+}
@@ -65,6 +65,10 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// KeepFiringSince is the time of the first evaluation at which the condition was not met anymore
+	// while the alert keeps firing for the rule's keep firing duration. It is zero otherwise.
+	KeepFiringSince time.Time
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
@@ -210,6 +214,35 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// shouldKeepFiring returns true if the alert must keep firing even though the result is Normal,
+// because it has not been Normal for the rule's keep firing duration yet.
+func shouldKeepFiring(state *State, rule *models.AlertRule, result eval.Result) bool {
+	if rule.KeepFiringFor <= 0 || state.State != eval.Alerting {
+		return false
+	}
+	if state.KeepFiringSince.IsZero() {
+		return true
+	}
+	return result.EvaluatedAt.Sub(state.KeepFiringSince) < rule.KeepFiringFor
+}
+
+func resultKeepFiring(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
+	if state.KeepFiringSince.IsZero() {
+		state.KeepFiringSince = result.EvaluatedAt
+	}
+	prevEndsAt := state.EndsAt
+	state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+	logger.Debug("Keeping state",
+		"state",
+		state.State,
+		"keep_firing_since",
+		state.KeepFiringSince,
+		"previous_ends_at",
+		prevEndsAt,
+		"next_ends_at",
+		state.EndsAt)
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	switch state.State {
 	case eval.Alerting:
@@ -175,9 +175,11 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				NotificationSettings: r.NotificationSettings,
+				Record:               r.Record,
 			})
 		}
 		if len(newRules) > 0 {
@@ -252,9 +254,11 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				NoDataState:          r.New.NoDataState,
 				ExecErrState:         r.New.ExecErrState,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
 				NotificationSettings: r.New.NotificationSettings,
+				Record:               r.New.Record,
 			})
 		}
 		if len(ruleVersions) > 0 {
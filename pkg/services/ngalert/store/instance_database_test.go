@@ -145,6 +145,34 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, instance.Labels, alerts[0].Labels)
 	})
 
+	t.Run("can save and read keep firing since of alert instance", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		labels := models.InstanceLabels{"test": "testValue"}
+		_, hash, _ := labels.StringAndHash()
+		keepFiringSince := time.Unix(time.Now().Unix(), 0)
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  rule.OrgID,
+				RuleUID:    rule.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:    models.InstanceStateFiring,
+			CurrentReason:   models.StateReasonRecovering,
+			Labels:          labels,
+			KeepFiringSince: keepFiringSince,
+		}
+		err := dbstore.SaveAlertInstance(ctx, instance)
+		require.NoError(t, err)
+
+		alerts, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{
+			RuleOrgID: instance.RuleOrgID,
+			RuleUID:   instance.RuleUID,
+		})
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.True(t, keepFiringSince.Equal(alerts[0].KeepFiringSince))
+	})
+
 	t.Run("can save two instances with same org_id, uid and different labels", func(t *testing.T) {
 		labels := models.InstanceLabels{"test": "testValue"}
 		_, hash, _ := labels.StringAndHash()
@@ -0,0 +1,136 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxErrorBodySize is the maximum number of bytes of the response body that are included in the error.
+const maxErrorBodySize = 1024
+
+// PrometheusWriter writes the results of recording rules to a Prometheus compatible remote write endpoint.
+type PrometheusWriter struct {
+	url               *url.URL
+	basicAuthUsername string
+	basicAuthPassword string
+	timeout           time.Duration
+	client            client.Requester
+	logger            log.Logger
+}
+
+func NewPrometheusWriter(cfg setting.RecordingRuleSettings, requester client.Requester, l log.Logger) (*PrometheusWriter, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("remote write URL must be provided")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	return &PrometheusWriter{
+		url:               u,
+		basicAuthUsername: cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		timeout:           cfg.Timeout,
+		client:            requester,
+		logger:            l,
+	}, nil
+}
+
+// Write writes the latest value of each numeric series of the frames as a sample of the metric with the given name
+// at time t. The extra labels are added to the labels of each series.
+func (w *PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	series := remotewrite.TimeSeriesFromFramesWithName(name, latestSamples(t, frames, extraLabels)...)
+	if len(series) == 0 {
+		w.logger.FromContext(ctx).Debug("No series to write", "metric", name)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return err
+	}
+
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.basicAuthUsername != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUsername, w.basicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("remote write request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	w.logger.FromContext(ctx).Debug("Wrote series", "metric", name, "series", len(series))
+	return nil
+}
+
+// latestSamples converts each numeric field of the frames to a single-row frame with the last non-null value of the
+// field at time t. Recording rules produce one sample per series per evaluation, like Prometheus recording rules do.
+func latestSamples(t time.Time, frames data.Frames, extraLabels map[string]string) []*data.Frame {
+	result := make([]*data.Frame, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			var value *float64
+			for i := field.Len() - 1; i >= 0; i-- {
+				v, err := field.NullableFloatAt(i)
+				if err == nil && v != nil {
+					value = v
+					break
+				}
+			}
+			if value == nil {
+				continue
+			}
+
+			labels := make(data.Labels, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				labels[k] = v
+			}
+			for k, v := range extraLabels {
+				labels[k] = v
+			}
+			result = append(result, data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t}),
+				data.NewField("value", labels, []float64{*value}),
+			))
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+
+	newWriter := func(t *testing.T, handler http.HandlerFunc) *PrometheusWriter {
+		t.Helper()
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		w, err := NewPrometheusWriter(setting.RecordingRuleSettings{
+			URL:               server.URL + "/api/v1/write",
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			Timeout:           time.Second,
+		}, http.DefaultClient, log.NewNopLogger())
+		require.NoError(t, err)
+		return w
+	}
+
+	t.Run("should write latest value of each series", func(t *testing.T) {
+		var received prompb.WriteRequest
+		w := newWriter(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v1/write", r.URL.Path)
+			require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "user", user)
+			require.Equal(t, "password", password)
+
+			compressed, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			b, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			require.NoError(t, proto.Unmarshal(b, &received))
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		frames := data.Frames{
+			data.NewFrame("",
+				data.NewField("Time", nil, []time.Time{now.Add(-time.Minute), now}),
+				data.NewField("Value", data.Labels{"instance": "a"}, []*float64{util.Pointer(1.0), nil}),
+			),
+			data.NewFrame("",
+				data.NewField("B", data.Labels{"instance": "b"}, []float64{2}),
+			),
+			data.NewFrame("",
+				data.NewField("B", data.Labels{"instance": "c"}, []*float64{nil}),
+			),
+		}
+
+		err := w.Write(context.Background(), "test_metric", now, frames, map[string]string{"team": "x"})
+		require.NoError(t, err)
+
+		require.Equal(t, []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "test_metric"},
+					{Name: "instance", Value: "a"},
+					{Name: "team", Value: "x"},
+				},
+				Samples: []prompb.Sample{{Timestamp: now.UnixMilli(), Value: 1}},
+			},
+			{
+				Labels: []prompb.Label{
+					{Name: "__name__", Value: "test_metric"},
+					{Name: "instance", Value: "b"},
+					{Name: "team", Value: "x"},
+				},
+				Samples: []prompb.Sample{{Timestamp: now.UnixMilli(), Value: 2}},
+			},
+		}, received.Timeseries)
+	})
+
+	t.Run("should not send request if there are no series", func(t *testing.T) {
+		w := newWriter(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("unexpected request")
+		})
+		err := w.Write(context.Background(), "test_metric", now, data.Frames{data.NewFrame("")}, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("should return error if endpoint responds with error", func(t *testing.T) {
+		w := newWriter(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("out of order sample"))
+		})
+		frames := data.Frames{data.NewFrame("", data.NewField("A", nil, []float64{1}))}
+		err := w.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "out of order sample")
+	})
+}
@@ -238,6 +238,8 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM alert_rule WHERE org_id = ?",
 			"DELETE FROM alert_rule_tag WHERE EXISTS (SELECT 1 FROM alert WHERE alert.org_id = ? AND alert.id = alert_rule_tag.alert_id)",
 			"DELETE FROM alert_rule_version WHERE rule_org_id = ?",
+			"DELETE FROM alert_state_history WHERE org_id = ?",
+			"DELETE FROM alert_state_history_label WHERE org_id = ?",
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
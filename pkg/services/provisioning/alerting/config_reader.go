@@ -11,15 +11,18 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 type rulesConfigReader struct {
-	log log.Logger
+	log            log.Logger
+	recordingRules bool
 }
 
-func newRulesConfigReader(logger log.Logger) rulesConfigReader {
+func newRulesConfigReader(logger log.Logger, features featuremgmt.FeatureToggles) rulesConfigReader {
 	return rulesConfigReader{
-		log: logger,
+		log:            logger,
+		recordingRules: features.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules),
 	}
 }
 
@@ -45,6 +48,8 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 		}
 		if alertFileV1 != nil {
 			alertFileV1.Filename = file.Name()
+			alertFileV1.Dir = path
+			alertFileV1.RecordingRules = cr.recordingRules
 			alertFile, err := alertFileV1.MapToModel()
 			if err != nil {
 				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
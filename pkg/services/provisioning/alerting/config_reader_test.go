@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 const (
@@ -36,7 +37,7 @@ const (
 )
 
 func TestConfigReader(t *testing.T) {
-	configReader := newRulesConfigReader(log.NewNopLogger())
+	configReader := newRulesConfigReader(log.NewNopLogger(), featuremgmt.WithFeatures())
 	ctx := context.Background()
 	t.Run("a broken YAML file should error", func(t *testing.T) {
 		_, err := configReader.readConfig(ctx, testFileBrokenYAML)
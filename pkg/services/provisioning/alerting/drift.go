@@ -0,0 +1,579 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// DriftKind describes how a resource in the database differs from the provisioning files.
+type DriftKind string
+
+const (
+	// DriftMissing means that the resource is defined in the provisioning files but does not exist.
+	DriftMissing DriftKind = "missing"
+	// DriftModified means that the resource is different from its definition in the provisioning files.
+	DriftModified DriftKind = "modified"
+	// DriftShadowed means that the resource is defined in the provisioning files but it was last changed by
+	// the provisioning API or the UI.
+	DriftShadowed DriftKind = "shadowed"
+	// DriftOrphaned means that the resource was provisioned from a file but is not defined in any provisioning file anymore.
+	DriftOrphaned DriftKind = "orphaned"
+)
+
+// Drift is a difference between a resource in the database and the provisioning files.
+type Drift struct {
+	OrgID int64 `json:"orgId"`
+	// ResourceType is the type of the resource, for example "alertRule" or "contactPoint".
+	ResourceType string `json:"resourceType"`
+	// ResourceID is the UID or the name of the resource.
+	ResourceID string    `json:"resourceId"`
+	Kind       DriftKind `json:"kind"`
+	// Filename is the provisioning file that defines the resource. It is empty for orphaned resources.
+	Filename string `json:"filename,omitempty"`
+	// Provenance is the provenance of the resource in the database.
+	Provenance models.Provenance `json:"provenance,omitempty"`
+	// Diff contains the paths of the fields that are different.
+	Diff []string `json:"diff,omitempty"`
+	// Reverted is true if the difference was reverted by provisioning the files again.
+	Reverted bool `json:"reverted,omitempty"`
+}
+
+// Revertible returns true if provisioning the files again reverts the difference. Orphaned resources are not deleted,
+// and the provenance of alert rules and contact points owned by the provisioning API cannot be changed.
+func (d Drift) Revertible() bool {
+	switch d.Kind {
+	case DriftOrphaned:
+		return false
+	case DriftShadowed:
+		if d.Provenance != models.ProvenanceAPI {
+			return true
+		}
+		return d.ResourceType != (&models.AlertRule{}).ResourceType() &&
+			d.ResourceType != (&definitions.EmbeddedContactPoint{}).ResourceType()
+	}
+	return true
+}
+
+// DriftReport lists the differences between the alerting resources in the database and the provisioning files.
+type DriftReport struct {
+	Drifts []Drift `json:"drifts"`
+	// Reverted is true if at least one of the differences was reverted by provisioning the files again.
+	Reverted bool `json:"reverted"`
+}
+
+// HasDrift returns true if the report contains any difference.
+func (r DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// DetectDrift reads the provisioning files and compares the alert rules, contact points, notification policies,
+// mute timings and templates that they define with the ones in the database.
+// If revert is true and some differences are revertible, the files are provisioned again and the differences that
+// are gone afterwards are marked as reverted. Differences that provisioning cannot revert, see Drift.Revertible, are
+// only reported and do not cause the files to be provisioned again.
+func DetectDrift(ctx context.Context, cfg ProvisionerConfig, revert bool) (DriftReport, error) {
+	logger := log.New("provisioning.alerting.drift")
+	cfgReader := newRulesConfigReader(logger, cfg.Features)
+	files, err := cfgReader.readConfig(ctx, cfg.Path)
+	if err != nil {
+		return DriftReport{}, err
+	}
+
+	report, err := newDriftDetector(cfg).detect(ctx, files)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	for _, drift := range report.Drifts {
+		logger.Warn("Provisioned alerting resource has drifted from the provisioning files",
+			"org", drift.OrgID,
+			"type", drift.ResourceType,
+			"id", drift.ResourceID,
+			"kind", drift.Kind,
+			"file", drift.Filename,
+			"provenance", drift.Provenance,
+			"diff", drift.Diff)
+	}
+
+	if !revert {
+		return report, nil
+	}
+	revertible := 0
+	for _, drift := range report.Drifts {
+		if drift.Revertible() {
+			revertible++
+		}
+	}
+	if revertible == 0 {
+		return report, nil
+	}
+
+	logger.Info("Reverting drift of alerting resources", "count", revertible)
+	if err := Provision(ctx, cfg); err != nil {
+		return report, fmt.Errorf("failed to revert drift: %w", err)
+	}
+	remaining, err := newDriftDetector(cfg).detect(ctx, files)
+	if err != nil {
+		return report, fmt.Errorf("failed to check reverted drift: %w", err)
+	}
+	markReverted(&report, remaining)
+	return report, nil
+}
+
+// markReverted marks the revertible differences of the report that are not in the remaining ones as reverted.
+func markReverted(report *DriftReport, remaining DriftReport) {
+	type driftKey struct {
+		orgID        int64
+		resourceType string
+		resourceID   string
+	}
+	left := make(map[driftKey]struct{}, len(remaining.Drifts))
+	for _, drift := range remaining.Drifts {
+		left[driftKey{drift.OrgID, drift.ResourceType, drift.ResourceID}] = struct{}{}
+	}
+	for i, drift := range report.Drifts {
+		if !drift.Revertible() {
+			continue
+		}
+		if _, ok := left[driftKey{drift.OrgID, drift.ResourceType, drift.ResourceID}]; ok {
+			continue
+		}
+		report.Drifts[i].Reverted = true
+		report.Reverted = true
+	}
+}
+
+type alertRuleReader interface {
+	GetAlertRules(ctx context.Context, orgID int64) ([]*models.AlertRule, map[string]models.Provenance, error)
+}
+
+type contactPointReader interface {
+	GetContactPoints(ctx context.Context, q provisioning.ContactPointQuery, u identity.Requester) ([]definitions.EmbeddedContactPoint, error)
+}
+
+type notificationPolicyReader interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
+}
+
+type muteTimingReader interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error)
+}
+
+type templateReader interface {
+	GetTemplates(ctx context.Context, orgID int64) ([]definitions.NotificationTemplate, error)
+}
+
+type driftDetector struct {
+	dashboardService dashboards.DashboardService
+	rules            alertRuleReader
+	contactPoints    contactPointReader
+	policies         notificationPolicyReader
+	muteTimes        muteTimingReader
+	templates        templateReader
+	drifts           []Drift
+}
+
+func newDriftDetector(cfg ProvisionerConfig) *driftDetector {
+	return &driftDetector{
+		dashboardService: cfg.DashboardService,
+		rules:            &cfg.RuleService,
+		contactPoints:    &cfg.ContactPointService,
+		policies:         &cfg.NotificiationPolicyService,
+		muteTimes:        &cfg.MuteTimingService,
+		templates:        &cfg.TemplateService,
+	}
+}
+
+// fileResource is a resource defined in a provisioning file.
+type fileResource[T any] struct {
+	filename string
+	resource T
+}
+
+func (d *driftDetector) detect(ctx context.Context, files []*AlertingFile) (DriftReport, error) {
+	rules := map[int64]map[string]fileResource[models.AlertRule]{}
+	contactPoints := map[int64]map[string]fileResource[definitions.EmbeddedContactPoint]{}
+	policies := map[int64]fileResource[definitions.Route]{}
+	muteTimes := map[int64]map[string]fileResource[definitions.MuteTimeInterval]{}
+	templates := map[int64]map[string]fileResource[definitions.NotificationTemplate]{}
+	orgs := map[int64]struct{}{}
+
+	// Later files override the resources of the earlier ones like during provisioning.
+	for _, file := range files {
+		for _, group := range file.Groups {
+			orgs[group.OrgID] = struct{}{}
+			folderUID, err := getFolderUID(ctx, d.dashboardService, group.FolderTitle, group.OrgID)
+			if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+				return DriftReport{}, err
+			}
+			for _, rule := range group.Rules {
+				rule.NamespaceUID = folderUID
+				rule.RuleGroup = group.Title
+				rule.IntervalSeconds = group.Interval
+				if err := rule.SetDashboardAndPanelFromAnnotations(); err != nil {
+					return DriftReport{}, err
+				}
+				addFileResource(rules, group.OrgID, rule.UID, file.Filename, rule)
+			}
+		}
+		for _, cp := range file.ContactPoints {
+			orgs[cp.OrgID] = struct{}{}
+			for _, c := range cp.ContactPoints {
+				addFileResource(contactPoints, cp.OrgID, c.UID, file.Filename, c)
+			}
+		}
+		for _, np := range file.Policies {
+			orgs[np.OrgID] = struct{}{}
+			policies[np.OrgID] = fileResource[definitions.Route]{filename: file.Filename, resource: np.Policy}
+		}
+		for _, mt := range file.MuteTimes {
+			orgs[mt.OrgID] = struct{}{}
+			addFileResource(muteTimes, mt.OrgID, mt.MuteTime.Name, file.Filename, mt.MuteTime)
+		}
+		for _, tmpl := range file.Templates {
+			orgs[tmpl.OrgID] = struct{}{}
+			addFileResource(templates, tmpl.OrgID, tmpl.Data.Name, file.Filename, tmpl.Data)
+		}
+	}
+
+	orgIDs := make([]int64, 0, len(orgs))
+	for orgID := range orgs {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	for _, orgID := range orgIDs {
+		if err := d.detectRules(ctx, orgID, rules[orgID]); err != nil {
+			return DriftReport{}, fmt.Errorf("alert rules: %w", err)
+		}
+		if err := d.detectContactPoints(ctx, orgID, contactPoints[orgID]); err != nil {
+			return DriftReport{}, fmt.Errorf("contact points: %w", err)
+		}
+		policy, ok := policies[orgID]
+		if err := d.detectPolicies(ctx, orgID, policy, ok); err != nil {
+			return DriftReport{}, fmt.Errorf("notification policies: %w", err)
+		}
+		if err := d.detectMuteTimes(ctx, orgID, muteTimes[orgID]); err != nil {
+			return DriftReport{}, fmt.Errorf("mute times: %w", err)
+		}
+		if err := d.detectTemplates(ctx, orgID, templates[orgID]); err != nil {
+			return DriftReport{}, fmt.Errorf("text templates: %w", err)
+		}
+	}
+
+	if d.drifts == nil {
+		d.drifts = []Drift{}
+	}
+	return DriftReport{Drifts: d.drifts}, nil
+}
+
+func addFileResource[T any](m map[int64]map[string]fileResource[T], orgID int64, id, filename string, resource T) {
+	if m[orgID] == nil {
+		m[orgID] = map[string]fileResource[T]{}
+	}
+	m[orgID][id] = fileResource[T]{filename: filename, resource: resource}
+}
+
+// compare adds a drift if the stored resource is not owned by the files or is different from the expected one.
+func (d *driftDetector) compare(orgID int64, resourceType, id, filename string, provenance models.Provenance, diff []string) {
+	kind := DriftModified
+	if provenance != models.ProvenanceFile {
+		kind = DriftShadowed
+	} else if len(diff) == 0 {
+		return
+	}
+	d.drifts = append(d.drifts, Drift{
+		OrgID:        orgID,
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Kind:         kind,
+		Filename:     filename,
+		Provenance:   provenance,
+		Diff:         diff,
+	})
+}
+
+func (d *driftDetector) add(orgID int64, resourceType, id, filename string, kind DriftKind, provenance models.Provenance) {
+	d.drifts = append(d.drifts, Drift{
+		OrgID:        orgID,
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Kind:         kind,
+		Filename:     filename,
+		Provenance:   provenance,
+	})
+}
+
+func (d *driftDetector) detectRules(ctx context.Context, orgID int64, expected map[string]fileResource[models.AlertRule]) error {
+	resourceType := (&models.AlertRule{}).ResourceType()
+	stored, provenances, err := d.rules.GetAlertRules(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*models.AlertRule, len(stored))
+	for _, rule := range stored {
+		existing[rule.UID] = rule
+		if _, ok := expected[rule.UID]; !ok && provenances[rule.UID] == models.ProvenanceFile {
+			d.add(orgID, resourceType, rule.UID, "", DriftOrphaned, models.ProvenanceFile)
+		}
+	}
+
+	ignore := append(store.AlertRuleFieldsToIgnoreInDiff[:], "RuleGroupIndex")
+	for _, uid := range sortedKeys(expected) {
+		e := expected[uid]
+		rule, ok := existing[uid]
+		if !ok {
+			d.add(orgID, resourceType, uid, e.filename, DriftMissing, models.ProvenanceNone)
+			continue
+		}
+		d.compare(orgID, resourceType, uid, e.filename, provenances[uid], rule.Diff(&e.resource, ignore...).Paths())
+	}
+	return nil
+}
+
+func (d *driftDetector) detectContactPoints(ctx context.Context, orgID int64, expected map[string]fileResource[definitions.EmbeddedContactPoint]) error {
+	resourceType := (&definitions.EmbeddedContactPoint{}).ResourceType()
+	stored, err := d.contactPoints.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: orgID}, nil)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]definitions.EmbeddedContactPoint, len(stored))
+	for _, cp := range stored {
+		existing[cp.UID] = cp
+		if _, ok := expected[cp.UID]; !ok && models.Provenance(cp.Provenance) == models.ProvenanceFile {
+			d.add(orgID, resourceType, cp.UID, "", DriftOrphaned, models.ProvenanceFile)
+		}
+	}
+
+	for _, uid := range sortedKeys(expected) {
+		e := expected[uid]
+		cp, ok := existing[uid]
+		if !ok {
+			d.add(orgID, resourceType, uid, e.filename, DriftMissing, models.ProvenanceNone)
+			continue
+		}
+		diff, err := diffContactPoint(e.resource, cp)
+		if err != nil {
+			return err
+		}
+		d.compare(orgID, resourceType, uid, e.filename, models.Provenance(cp.Provenance), diff)
+	}
+	return nil
+}
+
+// diffContactPoint compares the contact points. Secure settings are redacted in the stored contact point and are not compared.
+func diffContactPoint(expected, stored definitions.EmbeddedContactPoint) ([]string, error) {
+	var diff []string
+	if expected.Name != stored.Name {
+		diff = append(diff, "name")
+	}
+	if expected.Type != stored.Type {
+		diff = append(diff, "type")
+	}
+	if expected.DisableResolveMessage != stored.DisableResolveMessage {
+		diff = append(diff, "disableResolveMessage")
+	}
+	expectedSettings, err := toJSONValue(expected.Settings)
+	if err != nil {
+		return nil, err
+	}
+	storedSettings, err := toJSONValue(stored.Settings)
+	if err != nil {
+		return nil, err
+	}
+	e, _ := expectedSettings.(map[string]any)
+	s, _ := storedSettings.(map[string]any)
+	for k, v := range s {
+		if v == definitions.RedactedValue {
+			delete(s, k)
+			delete(e, k)
+		}
+	}
+	collectJSONDiff("settings", e, s, &diff)
+	return diff, nil
+}
+
+func (d *driftDetector) detectPolicies(ctx context.Context, orgID int64, expected fileResource[definitions.Route], defined bool) error {
+	resourceType := (&definitions.Route{}).ResourceType()
+	tree, err := d.policies.GetPolicyTree(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	provenance := models.Provenance(tree.Provenance)
+	if !defined {
+		if provenance == models.ProvenanceFile {
+			d.add(orgID, resourceType, tree.ResourceID(), "", DriftOrphaned, provenance)
+		}
+		return nil
+	}
+	tree.Provenance = ""
+	expected.resource.Provenance = ""
+	diff, err := diffJSON("", expected.resource, tree)
+	if err != nil {
+		return err
+	}
+	d.compare(orgID, resourceType, tree.ResourceID(), expected.filename, provenance, diff)
+	return nil
+}
+
+func (d *driftDetector) detectMuteTimes(ctx context.Context, orgID int64, expected map[string]fileResource[definitions.MuteTimeInterval]) error {
+	resourceType := (&definitions.MuteTimeInterval{}).ResourceType()
+	stored, err := d.muteTimes.GetMuteTimings(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]definitions.MuteTimeInterval, len(stored))
+	for _, mt := range stored {
+		existing[mt.Name] = mt
+		if _, ok := expected[mt.Name]; !ok && models.Provenance(mt.Provenance) == models.ProvenanceFile {
+			d.add(orgID, resourceType, mt.Name, "", DriftOrphaned, models.ProvenanceFile)
+		}
+	}
+
+	for _, name := range sortedKeys(expected) {
+		e := expected[name]
+		mt, ok := existing[name]
+		if !ok {
+			d.add(orgID, resourceType, name, e.filename, DriftMissing, models.ProvenanceNone)
+			continue
+		}
+		diff, err := diffJSON("", e.resource.MuteTimeInterval, mt.MuteTimeInterval)
+		if err != nil {
+			return err
+		}
+		d.compare(orgID, resourceType, name, e.filename, models.Provenance(mt.Provenance), diff)
+	}
+	return nil
+}
+
+func (d *driftDetector) detectTemplates(ctx context.Context, orgID int64, expected map[string]fileResource[definitions.NotificationTemplate]) error {
+	resourceType := (&definitions.NotificationTemplate{}).ResourceType()
+	stored, err := d.templates.GetTemplates(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]definitions.NotificationTemplate, len(stored))
+	for _, tmpl := range stored {
+		existing[tmpl.Name] = tmpl
+		if _, ok := expected[tmpl.Name]; !ok && models.Provenance(tmpl.Provenance) == models.ProvenanceFile {
+			d.add(orgID, resourceType, tmpl.Name, "", DriftOrphaned, models.ProvenanceFile)
+		}
+	}
+
+	for _, name := range sortedKeys(expected) {
+		e := expected[name]
+		tmpl, ok := existing[name]
+		if !ok {
+			d.add(orgID, resourceType, name, e.filename, DriftMissing, models.ProvenanceNone)
+			continue
+		}
+		var diff []string
+		if tmpl.Template != e.resource.Template {
+			diff = append(diff, "template")
+		}
+		d.compare(orgID, resourceType, name, e.filename, models.Provenance(tmpl.Provenance), diff)
+	}
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffJSON compares the JSON representations of the values and returns the paths of the fields that are different.
+func diffJSON(path string, expected, actual any) ([]string, error) {
+	e, err := toJSONValue(expected)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toJSONValue(actual)
+	if err != nil {
+		return nil, err
+	}
+	var diff []string
+	collectJSONDiff(path, e, a, &diff)
+	return diff, nil
+}
+
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// collectJSONDiff appends the paths of the fields that are different in the unmarshalled JSON values.
+// Empty objects and arrays are equal to missing values.
+func collectJSONDiff(path string, expected, actual any, diff *[]string) {
+	if isEmptyJSONValue(expected) && isEmptyJSONValue(actual) {
+		return
+	}
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			*diff = append(*diff, path)
+			return
+		}
+		keys := make(map[string]struct{}, len(e)+len(a))
+		for k := range e {
+			keys[k] = struct{}{}
+		}
+		for k := range a {
+			keys[k] = struct{}{}
+		}
+		for _, k := range sortedKeys(keys) {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			collectJSONDiff(p, e[k], a[k], diff)
+		}
+	case []any:
+		a, ok := actual.([]any)
+		if !ok || len(a) != len(e) {
+			*diff = append(*diff, path)
+			return
+		}
+		for i := range e {
+			collectJSONDiff(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], diff)
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			*diff = append(*diff, path)
+		}
+	}
+}
+
+func isEmptyJSONValue(v any) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(value) == 0
+	case []any:
+		return len(value) == 0
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+func TestDiffJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected any
+		actual   any
+		diff     []string
+	}{
+		{
+			name:     "equal values",
+			expected: map[string]any{"a": 1, "b": []string{"x"}},
+			actual:   map[string]any{"b": []string{"x"}, "a": 1},
+		},
+		{
+			name:     "empty values are equal to missing ones",
+			expected: map[string]any{"a": []string{}, "b": map[string]any{}},
+			actual:   map[string]any{"c": nil},
+		},
+		{
+			name:     "different nested values",
+			expected: map[string]any{"routes": []any{map[string]any{"receiver": "a", "group_by": []string{"x"}}}},
+			actual:   map[string]any{"routes": []any{map[string]any{"receiver": "b", "group_by": []string{"x"}}}},
+			diff:     []string{"routes[0].receiver"},
+		},
+		{
+			name:     "arrays of different length",
+			expected: map[string]any{"routes": []any{"a", "b"}},
+			actual:   map[string]any{"routes": []any{"a"}},
+			diff:     []string{"routes"},
+		},
+		{
+			name:     "added and removed fields",
+			expected: map[string]any{"a": 1},
+			actual:   map[string]any{"b": 1},
+			diff:     []string{"a", "b"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := diffJSON("", tc.expected, tc.actual)
+			require.NoError(t, err)
+			require.Equal(t, tc.diff, diff)
+		})
+	}
+}
+
+func TestDiffContactPoint(t *testing.T) {
+	expected := definitions.EmbeddedContactPoint{
+		UID:  "slack",
+		Name: "Slack",
+		Type: "slack",
+		Settings: simplejson.NewFromAny(map[string]any{
+			"recipient": "#alerts",
+			"token":     "secret",
+		}),
+	}
+
+	t.Run("should ignore redacted secure settings", func(t *testing.T) {
+		stored := expected
+		stored.Settings = simplejson.NewFromAny(map[string]any{
+			"recipient": "#alerts",
+			"token":     definitions.RedactedValue,
+		})
+		diff, err := diffContactPoint(expected, stored)
+		require.NoError(t, err)
+		require.Empty(t, diff)
+	})
+
+	t.Run("should report changed fields and settings", func(t *testing.T) {
+		stored := expected
+		stored.Name = "Other"
+		stored.DisableResolveMessage = true
+		stored.Settings = simplejson.NewFromAny(map[string]any{
+			"recipient": "#incident",
+			"token":     definitions.RedactedValue,
+		})
+		diff, err := diffContactPoint(expected, stored)
+		require.NoError(t, err)
+		require.Equal(t, []string{"name", "disableResolveMessage", "settings.recipient"}, diff)
+	})
+}
+
+func TestDriftDetectorCompare(t *testing.T) {
+	t.Run("should not report equal resources provisioned from files", func(t *testing.T) {
+		d := &driftDetector{}
+		d.compare(1, "template", "tmpl", "file.yaml", models.ProvenanceFile, nil)
+		require.Empty(t, d.drifts)
+	})
+
+	t.Run("should report modified resources", func(t *testing.T) {
+		d := &driftDetector{}
+		d.compare(1, "template", "tmpl", "file.yaml", models.ProvenanceFile, []string{"template"})
+		require.Equal(t, []Drift{{
+			OrgID:        1,
+			ResourceType: "template",
+			ResourceID:   "tmpl",
+			Kind:         DriftModified,
+			Filename:     "file.yaml",
+			Provenance:   models.ProvenanceFile,
+			Diff:         []string{"template"},
+		}}, d.drifts)
+	})
+
+	t.Run("should report resources with another provenance as shadowed", func(t *testing.T) {
+		for _, provenance := range []models.Provenance{models.ProvenanceAPI, models.ProvenanceNone} {
+			d := &driftDetector{}
+			d.compare(1, "template", "tmpl", "file.yaml", provenance, nil)
+			require.Len(t, d.drifts, 1)
+			require.Equal(t, DriftShadowed, d.drifts[0].Kind)
+			require.Equal(t, provenance, d.drifts[0].Provenance)
+		}
+	})
+}
+
+func TestDriftDetectorDetectRules(t *testing.T) {
+	rule := func(uid, title string) models.AlertRule {
+		return models.AlertRule{
+			UID:             uid,
+			OrgID:           1,
+			Title:           title,
+			NamespaceUID:    "folder",
+			RuleGroup:       "group",
+			IntervalSeconds: 60,
+		}
+	}
+	expected := map[string]fileResource[models.AlertRule]{
+		"equal":    {filename: "rules.yaml", resource: rule("equal", "Equal")},
+		"modified": {filename: "rules.yaml", resource: rule("modified", "Modified")},
+		"shadowed": {filename: "rules.yaml", resource: rule("shadowed", "Shadowed")},
+		"missing":  {filename: "rules.yaml", resource: rule("missing", "Missing")},
+	}
+	equal, modified, shadowed, orphaned, other := rule("equal", "Equal"), rule("modified", "Changed"), rule("shadowed", "Shadowed"), rule("orphaned", "Orphaned"), rule("other", "Other")
+	equal.Version, equal.RuleGroupIndex = 5, 2
+	rules := &fakeAlertRuleReader{
+		rules: []*models.AlertRule{&equal, &modified, &shadowed, &orphaned, &other},
+		provenances: map[string]models.Provenance{
+			"equal":    models.ProvenanceFile,
+			"modified": models.ProvenanceFile,
+			"shadowed": models.ProvenanceAPI,
+			"orphaned": models.ProvenanceFile,
+		},
+	}
+
+	d := &driftDetector{rules: rules}
+	require.NoError(t, d.detectRules(context.Background(), 1, expected))
+	require.Equal(t, []Drift{
+		{OrgID: 1, ResourceType: "alertRule", ResourceID: "orphaned", Kind: DriftOrphaned, Provenance: models.ProvenanceFile},
+		{OrgID: 1, ResourceType: "alertRule", ResourceID: "missing", Kind: DriftMissing, Filename: "rules.yaml", Provenance: models.ProvenanceNone},
+		{OrgID: 1, ResourceType: "alertRule", ResourceID: "modified", Kind: DriftModified, Filename: "rules.yaml", Provenance: models.ProvenanceFile, Diff: []string{"Title"}},
+		{OrgID: 1, ResourceType: "alertRule", ResourceID: "shadowed", Kind: DriftShadowed, Filename: "rules.yaml", Provenance: models.ProvenanceAPI, Diff: []string{}},
+	}, d.drifts)
+}
+
+func TestDriftDetectorDetect(t *testing.T) {
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "folder", IsFolder: true}, nil)
+
+	newDetector := func() *driftDetector {
+		return &driftDetector{
+			dashboardService: dashboardService,
+			rules: &fakeAlertRuleReader{
+				rules: []*models.AlertRule{{
+					UID:             "rule",
+					OrgID:           1,
+					Title:           "Rule",
+					NamespaceUID:    "folder",
+					RuleGroup:       "group",
+					IntervalSeconds: 60,
+				}},
+				provenances: map[string]models.Provenance{"rule": models.ProvenanceFile},
+			},
+			contactPoints: &fakeContactPointReader{contactPoints: []definitions.EmbeddedContactPoint{
+				{UID: "email", Name: "Email", Type: "email", Provenance: string(models.ProvenanceFile)},
+			}},
+			policies:  &fakeNotificationPolicyReader{tree: definitions.Route{Receiver: "Email"}},
+			muteTimes: &fakeMuteTimingReader{},
+			templates: &fakeTemplateReader{templates: []definitions.NotificationTemplate{
+				{Name: "tmpl", Template: "{{ define \"tmpl\" }}stored{{ end }}", Provenance: definitions.Provenance(models.ProvenanceAPI)},
+			}},
+		}
+	}
+	group := func(title string, rules ...models.AlertRule) models.AlertRuleGroupWithFolderTitle {
+		return models.AlertRuleGroupWithFolderTitle{
+			AlertRuleGroup: &models.AlertRuleGroup{Title: title, Interval: 60, Rules: rules},
+			OrgID:          1,
+			FolderTitle:    "Folder",
+		}
+	}
+
+	rule := func(title string) models.AlertRule {
+		return models.AlertRule{UID: "rule", OrgID: 1, Title: title}
+	}
+	contactPoints := []ContactPoint{{OrgID: 1, ContactPoints: []definitions.EmbeddedContactPoint{{UID: "email", Name: "Email", Type: "email"}}}}
+
+	t.Run("should report no drift when the files match the database", func(t *testing.T) {
+		files := []*AlertingFile{{
+			Filename:      "alerting.yaml",
+			Groups:        []models.AlertRuleGroupWithFolderTitle{group("group", rule("Rule"))},
+			ContactPoints: contactPoints,
+		}}
+		d := newDetector()
+		d.templates = &fakeTemplateReader{}
+		report, err := d.detect(context.Background(), files)
+		require.NoError(t, err)
+		require.False(t, report.HasDrift())
+		require.NotNil(t, report.Drifts)
+	})
+
+	t.Run("should compare with the resources of the last file that defines them", func(t *testing.T) {
+		files := []*AlertingFile{
+			{
+				Filename:      "first.yaml",
+				Groups:        []models.AlertRuleGroupWithFolderTitle{group("group", rule("Old"))},
+				ContactPoints: contactPoints,
+			},
+			{
+				Filename: "second.yaml",
+				Groups:   []models.AlertRuleGroupWithFolderTitle{group("group", rule("Rule"))},
+				Templates: []Template{{OrgID: 1, Data: definitions.NotificationTemplate{
+					Name:     "tmpl",
+					Template: "{{ define \"tmpl\" }}file{{ end }}",
+				}}},
+			},
+		}
+		report, err := newDetector().detect(context.Background(), files)
+		require.NoError(t, err)
+		require.Equal(t, []Drift{{
+			OrgID:        1,
+			ResourceType: "template",
+			ResourceID:   "tmpl",
+			Kind:         DriftShadowed,
+			Filename:     "second.yaml",
+			Provenance:   models.ProvenanceAPI,
+			Diff:         []string{"template"},
+		}}, report.Drifts)
+	})
+}
+
+func TestDriftRevertible(t *testing.T) {
+	testCases := []struct {
+		drift      Drift
+		revertible bool
+	}{
+		{drift: Drift{ResourceType: "alertRule", Kind: DriftMissing}, revertible: true},
+		{drift: Drift{ResourceType: "alertRule", Kind: DriftModified, Provenance: models.ProvenanceFile}, revertible: true},
+		{drift: Drift{ResourceType: "alertRule", Kind: DriftShadowed, Provenance: models.ProvenanceNone}, revertible: true},
+		{drift: Drift{ResourceType: "alertRule", Kind: DriftShadowed, Provenance: models.ProvenanceAPI}, revertible: false},
+		{drift: Drift{ResourceType: "contactPoint", Kind: DriftShadowed, Provenance: models.ProvenanceAPI}, revertible: false},
+		{drift: Drift{ResourceType: "template", Kind: DriftShadowed, Provenance: models.ProvenanceAPI}, revertible: true},
+		{drift: Drift{ResourceType: "template", Kind: DriftOrphaned, Provenance: models.ProvenanceFile}, revertible: false},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.revertible, tc.drift.Revertible(), "%s %s %s", tc.drift.ResourceType, tc.drift.Kind, tc.drift.Provenance)
+	}
+}
+
+func TestMarkReverted(t *testing.T) {
+	t.Run("should mark the revertible drifts that are gone", func(t *testing.T) {
+		report := DriftReport{Drifts: []Drift{
+			{OrgID: 1, ResourceType: "template", ResourceID: "fixed", Kind: DriftModified, Provenance: models.ProvenanceFile},
+			{OrgID: 1, ResourceType: "template", ResourceID: "failed", Kind: DriftModified, Provenance: models.ProvenanceFile},
+			{OrgID: 1, ResourceType: "alertRule", ResourceID: "api", Kind: DriftShadowed, Provenance: models.ProvenanceAPI},
+		}}
+		remaining := DriftReport{Drifts: []Drift{
+			{OrgID: 1, ResourceType: "template", ResourceID: "failed", Kind: DriftModified, Provenance: models.ProvenanceFile},
+		}}
+		markReverted(&report, remaining)
+		require.True(t, report.Reverted)
+		require.True(t, report.Drifts[0].Reverted)
+		require.False(t, report.Drifts[1].Reverted)
+		require.False(t, report.Drifts[2].Reverted)
+	})
+
+	t.Run("should not report reverted when no drift is gone", func(t *testing.T) {
+		report := DriftReport{Drifts: []Drift{
+			{OrgID: 1, ResourceType: "template", ResourceID: "failed", Kind: DriftModified, Provenance: models.ProvenanceFile},
+		}}
+		markReverted(&report, DriftReport{Drifts: report.Drifts})
+		require.False(t, report.Reverted)
+		require.False(t, report.Drifts[0].Reverted)
+	})
+}
+
+type fakeAlertRuleReader struct {
+	rules       []*models.AlertRule
+	provenances map[string]models.Provenance
+}
+
+func (f *fakeAlertRuleReader) GetAlertRules(_ context.Context, _ int64) ([]*models.AlertRule, map[string]models.Provenance, error) {
+	return f.rules, f.provenances, nil
+}
+
+type fakeContactPointReader struct {
+	contactPoints []definitions.EmbeddedContactPoint
+}
+
+func (f *fakeContactPointReader) GetContactPoints(_ context.Context, _ provisioning.ContactPointQuery, _ identity.Requester) ([]definitions.EmbeddedContactPoint, error) {
+	return f.contactPoints, nil
+}
+
+type fakeNotificationPolicyReader struct {
+	tree definitions.Route
+}
+
+func (f *fakeNotificationPolicyReader) GetPolicyTree(_ context.Context, _ int64) (definitions.Route, error) {
+	return f.tree, nil
+}
+
+type fakeMuteTimingReader struct {
+	muteTimes []definitions.MuteTimeInterval
+}
+
+func (f *fakeMuteTimingReader) GetMuteTimings(_ context.Context, _ int64) ([]definitions.MuteTimeInterval, error) {
+	return f.muteTimes, nil
+}
+
+type fakeTemplateReader struct {
+	templates []definitions.NotificationTemplate
+}
+
+func (f *fakeTemplateReader) GetTemplates(_ context.Context, _ int64) ([]definitions.NotificationTemplate, error) {
+	return f.templates, nil
+}
@@ -0,0 +1,124 @@
+package alerting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// defaultPrometheusRulesInterval is the evaluation interval of the Prometheus rule groups that do not specify one.
+// It is the default global evaluation interval of Prometheus.
+const defaultPrometheusRulesInterval = "1m"
+
+// PrometheusRulesV1 provisions the rule groups of Prometheus rule files as Grafana-managed alert rules.
+type PrometheusRulesV1 struct {
+	OrgID         values.Int64Value    `json:"orgId" yaml:"orgId"`
+	Folder        values.StringValue   `json:"folder" yaml:"folder"`
+	DatasourceUID values.StringValue   `json:"datasourceUid" yaml:"datasourceUid"`
+	Interval      values.StringValue   `json:"interval" yaml:"interval"`
+	Files         []values.StringValue `json:"files" yaml:"files"`
+}
+
+// mapToModel reads and converts the rule files. Relative paths and glob patterns of the files are resolved against dir.
+// The rules get UIDs that are derived from the organization, folder, group and title, so provisioning the same files
+// again updates the rules. Groups with recording rules are converted only if recordingRules is true.
+func (p *PrometheusRulesV1) mapToModel(dir string, recordingRules bool) ([]models.AlertRuleGroupWithFolderTitle, error) {
+	orgID := p.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	folderTitle := p.Folder.Value()
+	if strings.TrimSpace(folderTitle) == "" {
+		return nil, errors.New("prometheus rules have no folder set")
+	}
+	datasourceUID := p.DatasourceUID.Value()
+	if strings.TrimSpace(datasourceUID) == "" {
+		return nil, errors.New("prometheus rules have no data source set")
+	}
+	intervalValue := strings.TrimSpace(p.Interval.Value())
+	if intervalValue == "" {
+		intervalValue = defaultPrometheusRulesInterval
+	}
+	interval, err := model.ParseDuration(intervalValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval of prometheus rules: %w", err)
+	}
+	if len(p.Files) == 0 {
+		return nil, errors.New("prometheus rules have no files set")
+	}
+
+	var ruleFile apimodels.PrometheusRuleFile
+	for _, pattern := range p.Files {
+		path := pattern.Value()
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of prometheus rule files '%s': %w", pattern.Value(), err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no prometheus rule files match '%s'", pattern.Value())
+		}
+		for _, filename := range matches {
+			// nolint:gosec
+			// The path comes from the provisioning file.
+			b, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			f, err := prom.ParseRuleFile(b)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+			}
+			ruleFile.Groups = append(ruleFile.Groups, f.Groups...)
+		}
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		DatasourceUID:   datasourceUID,
+		DefaultInterval: time.Duration(interval),
+		RecordingRules:  recordingRules,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The UID of the folder is not known until the folder is created by the provisioner.
+	groups, err := converter.ConvertRuleFile(orgID, "", ruleFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert prometheus rules: %w", err)
+	}
+
+	result := make([]models.AlertRuleGroupWithFolderTitle, 0, len(groups))
+	for _, g := range groups {
+		group := g
+		for i := range group.Rules {
+			group.Rules[i].UID = prometheusRuleUID(orgID, folderTitle, group.Title, group.Rules[i].Title)
+		}
+		result = append(result, models.AlertRuleGroupWithFolderTitle{
+			AlertRuleGroup: &group,
+			OrgID:          orgID,
+			FolderTitle:    folderTitle,
+		})
+	}
+	return result, nil
+}
+
+// prometheusRuleUID returns a UID that identifies the rule by its organization, folder, group and title.
+func prometheusRuleUID(orgID int64, folder, group, title string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s", orgID, folder, group, title)))
+	return hex.EncodeToString(sum[:])[:util.MaxUIDLength]
+}
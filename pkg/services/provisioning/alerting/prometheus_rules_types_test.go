@@ -0,0 +1,148 @@
+package alerting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const prometheusRuleFile = `
+groups:
+  - name: api
+    interval: 30s
+    rules:
+      - alert: HighLatency
+        expr: latency_seconds > 1
+        for: 5m
+        labels:
+          severity: warning
+  - name: hosts
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+`
+
+func TestPrometheusRules(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "prometheus"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prometheus", "rules.yaml"), []byte(prometheusRuleFile), 0o600))
+
+	parse := func(t *testing.T, config string) PrometheusRulesV1 {
+		t.Helper()
+		var p PrometheusRulesV1
+		require.NoError(t, yaml.Unmarshal([]byte(config), &p))
+		return p
+	}
+
+	t.Run("should convert the groups of the files", func(t *testing.T) {
+		p := parse(t, `
+orgId: 2
+folder: Prometheus
+datasourceUid: prom
+files:
+  - prometheus/*.yaml
+`)
+		groups, err := p.mapToModel(dir, true)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+
+		api := groups[0]
+		require.Equal(t, "api", api.Title)
+		require.Equal(t, "Prometheus", api.FolderTitle)
+		require.EqualValues(t, 2, api.OrgID)
+		require.EqualValues(t, 30, api.Interval)
+		require.Len(t, api.Rules, 1)
+		rule := api.Rules[0]
+		require.Equal(t, "HighLatency", rule.Title)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, prometheusRuleUID(2, "Prometheus", "api", "HighLatency"), rule.UID)
+		require.Equal(t, "prom", rule.Data[0].DatasourceUID)
+
+		hosts := groups[1]
+		require.Equal(t, "hosts", hosts.Title)
+		require.EqualValues(t, 60, hosts.Interval, "groups without interval should get the default one")
+	})
+
+	t.Run("should generate the same UIDs every time", func(t *testing.T) {
+		p := parse(t, `
+folder: Prometheus
+datasourceUid: prom
+files:
+  - prometheus/rules.yaml
+`)
+		first, err := p.mapToModel(dir, true)
+		require.NoError(t, err)
+		second, err := p.mapToModel(dir, true)
+		require.NoError(t, err)
+		uids := func(groups []models.AlertRuleGroupWithFolderTitle) []string {
+			var result []string
+			for _, g := range groups {
+				for _, r := range g.Rules {
+					require.NotEmpty(t, r.UID)
+					result = append(result, r.UID)
+				}
+			}
+			return result
+		}
+		require.Equal(t, uids(first), uids(second))
+	})
+
+	t.Run("should use the interval of the config for groups without interval", func(t *testing.T) {
+		p := parse(t, `
+folder: Prometheus
+datasourceUid: prom
+interval: 2m
+files:
+  - prometheus/rules.yaml
+`)
+		groups, err := p.mapToModel(dir, true)
+		require.NoError(t, err)
+		require.EqualValues(t, 30, groups[0].Interval)
+		require.EqualValues(t, 120, groups[1].Interval)
+	})
+
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "without folder",
+			config: "datasourceUid: prom\nfiles: [prometheus/rules.yaml]",
+			err:    "no folder set",
+		},
+		{
+			name:   "without data source",
+			config: "folder: Prometheus\nfiles: [prometheus/rules.yaml]",
+			err:    "no data source set",
+		},
+		{
+			name:   "without files",
+			config: "folder: Prometheus\ndatasourceUid: prom",
+			err:    "no files set",
+		},
+		{
+			name:   "with files that do not exist",
+			config: "folder: Prometheus\ndatasourceUid: prom\nfiles: [unknown/*.yaml]",
+			err:    "no prometheus rule files match",
+		},
+		{
+			name:   "with the same file twice",
+			config: "folder: Prometheus\ndatasourceUid: prom\nfiles: [prometheus/rules.yaml, prometheus/*.yaml]",
+			err:    "defined more than once",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("should fail "+tc.name, func(t *testing.T) {
+			p := parse(t, tc.config)
+			_, err := p.mapToModel(dir, true)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
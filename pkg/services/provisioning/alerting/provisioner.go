@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -18,11 +19,12 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	Features                   featuremgmt.FeatureToggles
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	logger := log.New("provisioning.alerting")
-	cfgReader := newRulesConfigReader(logger)
+	cfgReader := newRulesConfigReader(logger, cfg.Features)
 	files, err := cfgReader.readConfig(ctx, cfg.Path)
 	if err != nil {
 		return err
@@ -51,7 +51,7 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 				"folder", group.FolderTitle,
 				"folderUID", folderUID,
 				"name", group.Title)
-			for _, rule := range group.Rules {
+			for _, rule := range orderByDependencies(group.Rules) {
 				rule.NamespaceUID = folderUID
 				rule.RuleGroup = group.Title
 				err = prov.provisionRule(ctx, group.OrgID, rule)
@@ -95,15 +95,28 @@ func (prov *defaultAlertRuleProvisioner) provisionRule(
 	return err
 }
 
+// orderByDependencies returns the rules so that every rule is provisioned after the rules of the file which state it
+// reads. If the rules cannot be ordered, they are provisioned in the order of the file, and the rule service rejects the
+// rules with invalid dependencies.
+func orderByDependencies(rules []alert_models.AlertRule) []alert_models.AlertRule {
+	group := make(alert_models.RulesGroup, 0, len(rules))
+	for i := range rules {
+		group = append(group, &rules[i])
+	}
+	sorted, err := group.SortByDependencies()
+	if err != nil {
+		return rules
+	}
+	result := make([]alert_models.AlertRule, 0, len(sorted))
+	for _, rule := range sorted {
+		result = append(result, *rule)
+	}
+	return result
+}
+
 func (prov *defaultAlertRuleProvisioner) getOrCreateFolderUID(
 	ctx context.Context, folderName string, orgID int64) (string, error) {
-	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Provisioning).Inc()
-	cmd := &dashboards.GetDashboardQuery{
-		Title:    &folderName,
-		FolderID: util.Pointer(int64(0)), // nolint:staticcheck
-		OrgID:    orgID,
-	}
-	cmdResult, err := prov.dashboardService.GetDashboard(ctx, cmd)
+	folderUID, err := getFolderUID(ctx, prov.dashboardService, folderName, orgID)
 	if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
 		return "", err
 	}
@@ -123,6 +136,23 @@ func (prov *defaultAlertRuleProvisioner) getOrCreateFolderUID(
 		return dbDash.UID, nil
 	}
 
+	return folderUID, nil
+}
+
+// getFolderUID returns the UID of the folder with the title in the root folder of the organization.
+// It returns dashboards.ErrDashboardNotFound if the folder does not exist.
+func getFolderUID(ctx context.Context, dashboardService dashboards.DashboardService, folderName string, orgID int64) (string, error) {
+	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Provisioning).Inc()
+	cmd := &dashboards.GetDashboardQuery{
+		Title:    &folderName,
+		FolderID: util.Pointer(int64(0)), // nolint:staticcheck
+		OrgID:    orgID,
+	}
+	cmdResult, err := dashboardService.GetDashboard(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+
 	if !cmdResult.IsFolder {
 		return "", fmt.Errorf("got invalid response. expected folder, found dashboard")
 	}
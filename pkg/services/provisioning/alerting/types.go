@@ -30,7 +30,11 @@ type AlertingFile struct {
 
 type AlertingFileV1 struct {
 	configVersion
-	Filename            string
+	Filename string
+	// Dir is the directory of the file. Paths in the file are relative to it.
+	Dir string `json:"-" yaml:"-"`
+	// RecordingRules permits provisioning Prometheus recording rules.
+	RecordingRules      bool                    `json:"-" yaml:"-"`
 	Groups              []AlertRuleGroupV1      `json:"groups" yaml:"groups"`
 	DeleteRules         []RuleDeleteV1          `json:"deleteRules" yaml:"deleteRules"`
 	ContactPoints       []ContactPointV1        `json:"contactPoints" yaml:"contactPoints"`
@@ -41,6 +45,7 @@ type AlertingFileV1 struct {
 	DeleteMuteTimes     []DeleteMuteTimeV1      `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
 	Templates           []TemplateV1            `json:"templates" yaml:"templates"`
 	DeleteTemplates     []DeleteTemplateV1      `json:"deleteTemplates" yaml:"deleteTemplates"`
+	PrometheusRules     []PrometheusRulesV1     `json:"prometheusRules" yaml:"prometheusRules"`
 }
 
 func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
@@ -128,6 +133,13 @@ func (fileV1 *AlertingFileV1) mapRules(alertingFile *AlertingFile) error {
 		}
 		alertingFile.Groups = append(alertingFile.Groups, group)
 	}
+	for _, promRulesV1 := range fileV1.PrometheusRules {
+		groups, err := promRulesV1.mapToModel(fileV1.Dir, fileV1.RecordingRules)
+		if err != nil {
+			return err
+		}
+		alertingFile.Groups = append(alertingFile.Groups, groups...)
+	}
 	for _, ruleDeleteV1 := range fileV1.DeleteRules {
 		orgID := ruleDeleteV1.OrgID.Value()
 		if orgID < 1 {
@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -21,10 +22,11 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	TriggerGitSync(name string, body []byte, signature string) error
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -39,8 +41,8 @@ func (provider *Provisioner) HasDashboardSources() bool {
 	return len(provider.fileReaders) > 0
 }
 
-// New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
+// New returns a new DashboardProvisioner. Git repositories are checked out into dataPath.
+func New(ctx context.Context, configDirectory string, dataPath string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger, orgService: orgService}
 	configs, err := cfgReader.readConfig(ctx)
@@ -48,7 +50,7 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 		return nil, fmt.Errorf("%v: %w", "Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService)
+	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService, dataPath)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "Failed to initialize file readers", err)
 	}
@@ -76,6 +78,11 @@ func (provider *Provisioner) Provision(ctx context.Context) error {
 				provider.log.Warn("Failed to provision config", "name", reader.Cfg.Name, "error", err)
 				return nil
 			}
+			if errors.Is(err, ErrGitSync) {
+				// the git repository can't be reached yet, keep provisioning the other configs
+				provider.log.Warn("Failed to provision config", "name", reader.Cfg.Name, "error", err)
+				continue
+			}
 
 			return fmt.Errorf("failed to provision config %v: %w", reader.Cfg.Name, err)
 		}
@@ -130,12 +137,25 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// TriggerGitSync makes the git provider with the specified name provision the dashboards of its repository
+// without waiting for the next poll. The signature must be the HMAC-SHA256 of the body with the webhook secret
+// of the provider.
+func (provider *Provisioner) TriggerGitSync(name string, body []byte, signature string) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == name && reader.git != nil && reader.webhookSecret != "" {
+			return reader.triggerSync(body, signature)
+		}
+	}
+	return ErrGitProviderNotFound
+}
+
 func getFileReaders(
 	configs []*config,
 	logger log.Logger,
 	service dashboards.DashboardProvisioningService,
 	store utils.DashboardStore,
 	folderService folder.Service,
+	dataPath string,
 ) ([]*FileReader, error) {
 	var readers []*FileReader
 
@@ -153,6 +173,19 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+				dataPath,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -67,3 +67,8 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// TriggerGitSync not implemented for mocks
+func (dpm *ProvisionerMock) TriggerGitSync(name string, body []byte, signature string) error {
+	return ErrGitProviderNotFound
+}
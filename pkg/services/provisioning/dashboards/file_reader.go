@@ -43,6 +43,12 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// git is set for readers of the git type, which provision dashboards from a checkout of a git repository.
+	git           *gitRepository
+	webhookSecret string
+	trigger       chan struct{}
+	commitSHA     string
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -85,6 +91,10 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 			if err := fr.walkDisk(ctx); err != nil {
 				fr.log.Error("failed to search for dashboards", "error", err)
 			}
+		case <-fr.trigger:
+			if err := fr.walkDisk(ctx); err != nil {
+				fr.log.Error("failed to search for dashboards", "error", err)
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -94,6 +104,14 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	if fr.git != nil {
+		commitSHA, err := fr.syncGit(ctx)
+		if err != nil {
+			return err
+		}
+		fr.commitSHA = commitSHA
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -304,6 +322,10 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 			Name:       fr.Cfg.Name,
 			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
+			CommitSHA:  fr.commitSHA,
+		}
+		if fr.commitSHA != "" {
+			dash.Message = "Provisioned from commit " + fr.commitSHA
 		}
 		_, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(ctx, dash, dp)
 		if err != nil {
@@ -0,0 +1,216 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+var (
+	// ErrGitSync is returned when a git repository can't be cloned or pulled and there is no previous checkout.
+	ErrGitSync = errors.New("failed to sync git repository")
+	// ErrGitProviderNotFound is returned when there is no git provider with a webhook for a name.
+	ErrGitProviderNotFound = errors.New("git dashboard provider with webhook not found")
+	// ErrInvalidWebhookSignature is returned when the signature of a webhook request doesn't match its body.
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+const gitCommandTimeout = 5 * time.Minute
+
+// gitRepository keeps a checkout of a branch of a git repository in a local directory.
+// It uses the git command, which must be installed on the Grafana host.
+type gitRepository struct {
+	url    string
+	branch string
+	dir    string
+	log    log.Logger
+}
+
+// NewDashboardGitReader returns a new FileReader which provisions the dashboards of a git repository
+// based on `config`. The repository is checked out into dataPath.
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service, dataPath string) (*FileReader, error) {
+	repoURL, _ := cfg.Options["url"].(string)
+	if repoURL == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+	if _, err := os.Stat(repoURL); err == nil {
+		// Local repositories are cloned with their absolute path as the URL of the origin remote.
+		if repoURL, err = filepath.Abs(repoURL); err != nil {
+			return nil, err
+		}
+	}
+	branch, _ := cfg.Options["branch"].(string)
+	if strings.HasPrefix(branch, "-") {
+		// The branch is passed to git commands, it must not be parsed as an option.
+		return nil, fmt.Errorf("failed to load dashboards, branch param must not start with '-'")
+	}
+	subpath, _ := cfg.Options["path"].(string)
+	if subpath != "" && !filepath.IsLocal(subpath) {
+		return nil, fmt.Errorf("failed to load dashboards, path param must be a relative path inside the repository")
+	}
+	webhookSecret, _ := cfg.Options["webhookSecret"].(string)
+
+	foldersFromFilesStructure, _ := cfg.Options["foldersFromFilesStructure"].(bool)
+	if foldersFromFilesStructure && cfg.Folder != "" && cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+	}
+
+	dir := filepath.Join(dataPath, "provisioning", "dashboards", "git", safeDirName(cfg.Name))
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         filepath.Join(dir, subpath),
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		folderService:                folderService,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		usageTracker:                 newUsageTracker(),
+		git: &gitRepository{
+			url:    repoURL,
+			branch: branch,
+			dir:    dir,
+			log:    log,
+		},
+		webhookSecret: webhookSecret,
+		trigger:       make(chan struct{}, 1),
+	}, nil
+}
+
+// sync clones the repository, or pulls the latest commit of the branch into an existing checkout,
+// and returns the SHA of the checked out commit.
+func (r *gitRepository) sync(ctx context.Context) (string, error) {
+	if r.isCheckedOut(ctx) {
+		ref := r.branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		r.log.Debug("Pulling git repository", "url", r.redactedURL(), "ref", ref)
+		if _, err := r.run(ctx, r.dir, "fetch", "--depth", "1", "--", "origin", ref); err != nil {
+			return "", err
+		}
+		if _, err := r.run(ctx, r.dir, "reset", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+		if _, err := r.run(ctx, r.dir, "clean", "-ffdx"); err != nil {
+			return "", err
+		}
+		return r.head(ctx)
+	}
+
+	r.log.Info("Cloning git repository", "url", r.redactedURL(), "branch", r.branch)
+	if err := os.RemoveAll(r.dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.dir), 0750); err != nil {
+		return "", err
+	}
+	args := []string{"clone", "--depth", "1", "--single-branch"}
+	if r.branch != "" {
+		args = append(args, "--branch", r.branch)
+	}
+	if _, err := r.run(ctx, "", append(args, "--", r.url, r.dir)...); err != nil {
+		return "", err
+	}
+	return r.head(ctx)
+}
+
+// isCheckedOut returns true if the directory has a checkout of the repository URL.
+func (r *gitRepository) isCheckedOut(ctx context.Context) bool {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err != nil {
+		return false
+	}
+	remoteURL, err := r.run(ctx, r.dir, "remote", "get-url", "origin")
+	return err == nil && remoteURL == r.url
+}
+
+// head returns the SHA of the checked out commit.
+func (r *gitRepository) head(ctx context.Context) (string, error) {
+	return r.run(ctx, r.dir, "rev-parse", "HEAD")
+}
+
+func (r *gitRepository) run(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never wait for credentials on a terminal, they must be part of the URL or the git configuration.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// The output can contain the URL of the repository, including its credentials.
+		output := strings.ReplaceAll(strings.TrimSpace(stderr.String()), r.url, r.redactedURL())
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, output)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redactedURL returns the repository URL without its password, so it can be logged.
+func (r *gitRepository) redactedURL() string {
+	u, err := url.Parse(r.url)
+	if err != nil || u.User == nil {
+		return r.url
+	}
+	return u.Redacted()
+}
+
+// syncGit syncs the git repository of the reader and returns the SHA of the checked out commit. If the
+// repository can't be reached, it keeps provisioning from the previous checkout.
+func (fr *FileReader) syncGit(ctx context.Context) (string, error) {
+	commitSHA, err := fr.git.sync(ctx)
+	if err == nil {
+		return commitSHA, nil
+	}
+	fr.log.Error("Failed to sync git repository", "url", fr.git.redactedURL(), "error", err)
+	if !fr.git.isCheckedOut(ctx) {
+		return "", fmt.Errorf("%w: %w", ErrGitSync, err)
+	}
+	return fr.git.head(ctx)
+}
+
+// triggerSync makes the reader provision the dashboards of its git repository without waiting for the
+// next poll, if the signature is the hex encoded HMAC-SHA256 of the body with the webhook secret.
+func (fr *FileReader) triggerSync(body []byte, signature string) error {
+	mac := hmac.New(sha256.New, []byte(fr.webhookSecret))
+	_, _ = mac.Write(body)
+	expected := mac.Sum(nil)
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !hmac.Equal(expected, actual) {
+		return ErrInvalidWebhookSignature
+	}
+
+	select {
+	case fr.trigger <- struct{}{}:
+	default:
+		// A sync is already pending.
+	}
+	return nil
+}
+
+func safeDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
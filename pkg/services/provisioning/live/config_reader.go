@@ -0,0 +1,102 @@
+package live
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*liveAsConfig, error) {
+	var configs []*liveAsConfig
+	cr.log.Debug("Looking for live pipeline provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read live pipeline provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing live pipeline provisioning file", "path", path, "file.Name", file.Name())
+			config, err := cr.parseLiveConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse file %s: %w", file.Name(), err)
+			}
+
+			if config != nil {
+				configs = append(configs, config)
+			}
+		}
+	}
+
+	if err := validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseLiveConfig(path string, file fs.DirEntry) (*liveAsConfig, error) {
+	filename, err := filepath.Abs(filepath.Join(path, file.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *liveAsConfigV1
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToLiveFromConfig()
+}
+
+func validateRequiredFields(configs []*liveAsConfig) error {
+	var errStrings []string
+	for _, cfg := range configs {
+		for index, rule := range cfg.ChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("channel rule item %d in configuration doesn't contain required field pattern", index+1))
+			}
+		}
+		for index, rule := range cfg.DeleteChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("delete channel rule item %d in configuration doesn't contain required field pattern", index+1))
+			}
+		}
+		for index, writeConfig := range cfg.WriteConfigs {
+			if writeConfig.UID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("write config item %d in configuration doesn't contain required field uid", index+1))
+			}
+		}
+		for index, writeConfig := range cfg.DeleteWriteConfigs {
+			if writeConfig.UID == "" {
+				errStrings = append(errStrings, fmt.Sprintf("delete write config item %d in configuration doesn't contain required field uid", index+1))
+			}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return fmt.Errorf("%s", strings.Join(errStrings, "\n"))
+	}
+
+	return nil
+}
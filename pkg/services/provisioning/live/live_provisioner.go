@@ -0,0 +1,133 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// Provision scans a directory for provisioning config files
+// and provisions the live pipeline channel rules and write configs in those files.
+func Provision(ctx context.Context, configDirectory string, storage pipeline.Storage, secretsService secrets.Service) error {
+	logger := log.New("provisioning.live")
+	lp := LiveProvisioner{
+		log:            logger,
+		cfgProvider:    &configReader{log: logger},
+		storage:        storage,
+		secretsService: secretsService,
+	}
+	return lp.applyChanges(ctx, configDirectory)
+}
+
+// LiveProvisioner is responsible for provisioning live pipeline channel rules
+// and write configs based on configuration read by the `configReader`
+type LiveProvisioner struct {
+	log            log.Logger
+	cfgProvider    *configReader
+	storage        pipeline.Storage
+	secretsService secrets.Service
+}
+
+func (lp *LiveProvisioner) apply(ctx context.Context, cfg *liveAsConfig) error {
+	if err := lp.deleteChannelRules(ctx, cfg.DeleteChannelRules); err != nil {
+		return err
+	}
+	if err := lp.deleteWriteConfigs(ctx, cfg.DeleteWriteConfigs); err != nil {
+		return err
+	}
+
+	imports := map[int64]*pipeline.ImportCmd{}
+	getImport := func(orgID int64) *pipeline.ImportCmd {
+		if _, ok := imports[orgID]; !ok {
+			imports[orgID] = &pipeline.ImportCmd{}
+		}
+		return imports[orgID]
+	}
+	for _, writeConfig := range cfg.WriteConfigs {
+		cmd := getImport(writeConfig.OrgID)
+		cmd.WriteConfigs = append(cmd.WriteConfigs, pipeline.WriteConfigUpdateCmd{
+			UID:            writeConfig.UID,
+			Settings:       writeConfig.Settings,
+			SecureSettings: writeConfig.SecureSettings,
+		})
+	}
+	for _, rule := range cfg.ChannelRules {
+		cmd := getImport(rule.OrgID)
+		cmd.ChannelRules = append(cmd.ChannelRules, pipeline.ChannelRuleUpdateCmd{
+			Pattern:  rule.Pattern,
+			Settings: rule.Settings,
+		})
+	}
+
+	orgIDs := make([]int64, 0, len(imports))
+	for orgID := range imports {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	for _, orgID := range orgIDs {
+		cmd := imports[orgID]
+		lp.log.Info("Updating live pipeline from configuration", "orgId", orgID, "channelRules", len(cmd.ChannelRules), "writeConfigs", len(cmd.WriteConfigs))
+		if err := pipeline.ImportOrg(ctx, lp.storage, lp.secretsService, orgID, *cmd); err != nil {
+			return fmt.Errorf("failed to provision live pipeline of org %d: %w", orgID, err)
+		}
+	}
+
+	return nil
+}
+
+func (lp *LiveProvisioner) deleteChannelRules(ctx context.Context, rules []*deleteChannelRuleConfig) error {
+	for _, rule := range rules {
+		existing, err := lp.storage.ListChannelRules(ctx, rule.OrgID)
+		if err != nil {
+			return err
+		}
+		for _, r := range existing {
+			if r.Pattern != rule.Pattern {
+				continue
+			}
+			lp.log.Info("Deleting live channel rule from configuration", "orgId", rule.OrgID, "pattern", rule.Pattern)
+			if err := lp.storage.DeleteChannelRule(ctx, rule.OrgID, pipeline.ChannelRuleDeleteCmd{Pattern: rule.Pattern}); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (lp *LiveProvisioner) deleteWriteConfigs(ctx context.Context, writeConfigs []*deleteWriteConfigConfig) error {
+	for _, writeConfig := range writeConfigs {
+		_, ok, err := lp.storage.GetWriteConfig(ctx, writeConfig.OrgID, pipeline.WriteConfigGetCmd{UID: writeConfig.UID})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		lp.log.Info("Deleting live write config from configuration", "orgId", writeConfig.OrgID, "uid", writeConfig.UID)
+		if err := lp.storage.DeleteWriteConfig(ctx, writeConfig.OrgID, pipeline.WriteConfigDeleteCmd{UID: writeConfig.UID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (lp *LiveProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := lp.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := lp.apply(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package live
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+const (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	deleteConfigs     = "./testdata/test-configs/delete"
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	missingFields     = "./testdata/test-configs/missing-fields"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+)
+
+func TestConfigReader(t *testing.T) {
+	reader := &configReader{log: log.New("test logger")}
+
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip invalid directory", func(t *testing.T) {
+		cfg, err := reader.readConfig(emptyFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Missing required fields should return error", func(t *testing.T) {
+		_, err := reader.readConfig(missingFields)
+		require.EqualError(t, err, "channel rule item 1 in configuration doesn't contain required field pattern\n"+
+			"write config item 1 in configuration doesn't contain required field uid")
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("PROM_PASSWORD", "secret")
+
+		cfg, err := reader.readConfig(correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].WriteConfigs, 1)
+		writeConfig := cfg[0].WriteConfigs[0]
+		require.Equal(t, int64(2), writeConfig.OrgID)
+		require.Equal(t, "prom", writeConfig.UID)
+		require.Equal(t, pipeline.WriteSettings{
+			Endpoint:  "http://prometheus:9090/api/v1/write",
+			BasicAuth: &pipeline.BasicAuth{User: "grafana"},
+		}, writeConfig.Settings)
+		require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, writeConfig.SecureSettings)
+
+		require.Len(t, cfg[0].ChannelRules, 2)
+		rule := cfg[0].ChannelRules[0]
+		require.Equal(t, int64(2), rule.OrgID)
+		require.Equal(t, "stream/factory/telemetry", rule.Pattern)
+		require.Equal(t, pipeline.ConverterTypeJsonAuto, rule.Settings.Converter.Type)
+		require.Equal(t, &pipeline.RemoteWriteOutputConfig{UID: "prom", SampleMilliseconds: 1000}, rule.Settings.FrameOutputters[0].RemoteWriteOutputConfig)
+		require.Equal(t, int64(1), cfg[0].ChannelRules[1].OrgID)
+	})
+}
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	secretsService := fakes.NewFakeSecretsService()
+	storage := &pipeline.FileStorage{DataPath: t.TempDir(), SecretsService: secretsService}
+	t.Setenv("PROM_PASSWORD", "secret")
+
+	t.Run("should create channel rules and write configs", func(t *testing.T) {
+		err := Provision(ctx, correctProperties, storage, secretsService)
+		require.NoError(t, err)
+
+		rules, err := storage.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, "stream/factory/telemetry", rules[0].Pattern)
+
+		rules, err = storage.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, "stream/factory/events", rules[0].Pattern)
+
+		writeConfig, ok, err := storage.GetWriteConfig(ctx, 2, pipeline.WriteConfigGetCmd{UID: "prom"})
+		require.NoError(t, err)
+		require.True(t, ok)
+		secureSettings, err := secretsService.DecryptJsonData(ctx, writeConfig.SecureSettings)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, secureSettings)
+	})
+
+	t.Run("should update existing channel rules and write configs", func(t *testing.T) {
+		err := Provision(ctx, correctProperties, storage, secretsService)
+		require.NoError(t, err)
+
+		rules, err := storage.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		writeConfigs, err := storage.ListWriteConfigs(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, writeConfigs, 1)
+	})
+
+	t.Run("should delete channel rules and write configs", func(t *testing.T) {
+		err := Provision(ctx, deleteConfigs, storage, secretsService)
+		require.NoError(t, err)
+
+		rules, err := storage.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, rules)
+		writeConfigs, err := storage.ListWriteConfigs(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, writeConfigs)
+
+		rules, err = storage.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+	})
+}
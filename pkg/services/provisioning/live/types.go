@@ -0,0 +1,142 @@
+package live
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// liveAsConfig is a normalized data object for live pipeline config data. Any config version should be mappable
+// to this type.
+type liveAsConfig struct {
+	ChannelRules       []*channelRuleFromConfig
+	DeleteChannelRules []*deleteChannelRuleConfig
+	WriteConfigs       []*writeConfigFromConfig
+	DeleteWriteConfigs []*deleteWriteConfigConfig
+}
+
+type channelRuleFromConfig struct {
+	OrgID    int64
+	Pattern  string
+	Settings pipeline.ChannelRuleSettings
+}
+
+type deleteChannelRuleConfig struct {
+	OrgID   int64
+	Pattern string
+}
+
+type writeConfigFromConfig struct {
+	OrgID          int64
+	UID            string
+	Settings       pipeline.WriteSettings
+	SecureSettings map[string]string
+}
+
+type deleteWriteConfigConfig struct {
+	OrgID int64
+	UID   string
+}
+
+// liveAsConfigV1 is a mapping for version 1 configs. This is mapped to its normalised version.
+type liveAsConfigV1 struct {
+	APIVersion         values.Int64Value            `json:"apiVersion" yaml:"apiVersion"`
+	ChannelRules       []*channelRuleFromConfigV1   `json:"channelRules" yaml:"channelRules"`
+	DeleteChannelRules []*deleteChannelRuleConfigV1 `json:"deleteChannelRules" yaml:"deleteChannelRules"`
+	WriteConfigs       []*writeConfigFromConfigV1   `json:"writeConfigs" yaml:"writeConfigs"`
+	DeleteWriteConfigs []*deleteWriteConfigConfigV1 `json:"deleteWriteConfigs" yaml:"deleteWriteConfigs"`
+}
+
+type channelRuleFromConfigV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Pattern  values.StringValue `json:"pattern" yaml:"pattern"`
+	Settings values.JSONValue   `json:"settings" yaml:"settings"`
+}
+
+type deleteChannelRuleConfigV1 struct {
+	OrgID   values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Pattern values.StringValue `json:"pattern" yaml:"pattern"`
+}
+
+type writeConfigFromConfigV1 struct {
+	OrgID          values.Int64Value     `json:"orgId" yaml:"orgId"`
+	UID            values.StringValue    `json:"uid" yaml:"uid"`
+	Settings       values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+}
+
+type deleteWriteConfigConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+// mapToLiveFromConfig maps config syntax to a normalized liveAsConfig object. Settings have the same
+// structure as in the live pipeline HTTP API.
+func (cfg *liveAsConfigV1) mapToLiveFromConfig() (*liveAsConfig, error) {
+	r := &liveAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, rule := range cfg.ChannelRules {
+		var settings pipeline.ChannelRuleSettings
+		if err := remarshal(rule.Settings.Value(), &settings); err != nil {
+			return nil, fmt.Errorf("invalid settings of channel rule %q: %w", rule.Pattern.Value(), err)
+		}
+		r.ChannelRules = append(r.ChannelRules, &channelRuleFromConfig{
+			OrgID:    orgIDOrDefault(rule.OrgID.Value()),
+			Pattern:  rule.Pattern.Value(),
+			Settings: settings,
+		})
+	}
+
+	for _, rule := range cfg.DeleteChannelRules {
+		r.DeleteChannelRules = append(r.DeleteChannelRules, &deleteChannelRuleConfig{
+			OrgID:   orgIDOrDefault(rule.OrgID.Value()),
+			Pattern: rule.Pattern.Value(),
+		})
+	}
+
+	for _, writeConfig := range cfg.WriteConfigs {
+		var settings pipeline.WriteSettings
+		if err := remarshal(writeConfig.Settings.Value(), &settings); err != nil {
+			return nil, fmt.Errorf("invalid settings of write config %q: %w", writeConfig.UID.Value(), err)
+		}
+		r.WriteConfigs = append(r.WriteConfigs, &writeConfigFromConfig{
+			OrgID:          orgIDOrDefault(writeConfig.OrgID.Value()),
+			UID:            writeConfig.UID.Value(),
+			Settings:       settings,
+			SecureSettings: writeConfig.SecureSettings.Value(),
+		})
+	}
+
+	for _, writeConfig := range cfg.DeleteWriteConfigs {
+		r.DeleteWriteConfigs = append(r.DeleteWriteConfigs, &deleteWriteConfigConfig{
+			OrgID: orgIDOrDefault(writeConfig.OrgID.Value()),
+			UID:   writeConfig.UID.Value(),
+		})
+	}
+
+	return r, nil
+}
+
+func orgIDOrDefault(orgID int64) int64 {
+	if orgID < 1 {
+		return 1
+	}
+	return orgID
+}
+
+// remarshal converts interpolated YAML values to the JSON based types of the live pipeline.
+func remarshal(from map[string]any, to any) error {
+	if from == nil {
+		return nil
+	}
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -16,7 +17,9 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -27,6 +30,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	prov_live "github.com/grafana/grafana/pkg/services/provisioning/live"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -53,6 +57,7 @@ func ProvideService(
 	quotaService quota.Service,
 	secrectService secrets.Service,
 	orgService org.Service,
+	features featuremgmt.FeatureToggles,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -65,7 +70,9 @@ func ProvideService(
 		provisionNotifiers:           notifiers.Provision,
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
+		provisionLive:                prov_live.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		detectAlertingDrift:          prov_alerting.DetectDrift,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -78,6 +85,7 @@ func ProvideService(
 		log:                          log.New("provisioning"),
 		orgService:                   orgService,
 		folderService:                folderService,
+		features:                     features,
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -89,7 +97,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.Cfg.DataPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -105,8 +113,10 @@ type ProvisioningService interface {
 	ProvisionNotifications(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	DetectAlertingDrift(ctx context.Context, revert bool) (prov_alerting.DriftReport, error)
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	TriggerDashboardGitSync(name string, body []byte, signature string) error
 }
 
 // Used for testing purposes
@@ -149,8 +159,11 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers           func(context.Context, *setting.Cfg, string, notifiers.Manager, org.Service, encryption.Internal, *notifications.NotificationService) error
 	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
+	provisionLive                func(context.Context, string, pipeline.Storage, secrets.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	detectAlertingDrift          func(context.Context, prov_alerting.ProvisionerConfig, bool) (prov_alerting.DriftReport, error)
 	mutex                        sync.Mutex
+	alertingMutex                sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
 	datasourceService            datasourceservice.DataSourceService
@@ -161,6 +174,7 @@ type ProvisioningServiceImpl struct {
 	quotaService                 quota.Service
 	secretService                secrets.Service
 	folderService                folder.Service
+	features                     featuremgmt.FeatureToggles
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -176,6 +190,12 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionLive(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision live pipeline", "error", err)
+		return err
+	}
+
 	err = ps.ProvisionNotifications(ctx)
 	if err != nil {
 		ps.log.Error("Failed to provision alert notifications", "error", err)
@@ -205,6 +225,10 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	if drift := ps.Cfg.UnifiedAlerting.ProvisioningDrift; drift.CheckInterval > 0 {
+		go ps.checkAlertingDrift(ctx, drift.CheckInterval, drift.Revert)
+	}
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -247,6 +271,25 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+// ProvisionLive provisions the channel rules and write configs of the live pipeline. It does nothing
+// if the live pipeline is disabled.
+func (ps *ProvisioningServiceImpl) ProvisionLive(ctx context.Context) error {
+	if ps.features == nil || !ps.features.IsEnabledGlobally(featuremgmt.FlagLivePipeline) {
+		return nil
+	}
+	livePath := filepath.Join(ps.Cfg.ProvisioningPath, "live")
+	storage := &pipeline.FileStorage{
+		DataPath:       ps.Cfg.DataPath,
+		SecretsService: ps.secretService,
+	}
+	if err := ps.provisionLive(ctx, livePath, storage, ps.secretService); err != nil {
+		err = fmt.Errorf("%v: %w", "live pipeline provisioning error", err)
+		ps.log.Error("Failed to provision live pipeline", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) error {
 	alertNotificationsPath := filepath.Join(ps.Cfg.ProvisioningPath, "notifiers")
 	if err := ps.provisionNotifiers(ctx, ps.Cfg, alertNotificationsPath, ps.alertingService, ps.orgService, ps.EncryptionService, ps.NotificationService); err != nil {
@@ -259,7 +302,7 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) e
 
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.Cfg.DataPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -280,7 +323,52 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 	return nil
 }
 
+// ProvisionAlerting provisions the alerting resources from files. It holds its own lock rather than the lock of
+// dashboard provisioning, so reloads and drift reverts don't wait for slow dashboard sources like git repositories.
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	ps.alertingMutex.Lock()
+	defer ps.alertingMutex.Unlock()
+	return ps.provisionAlerting(ctx, ps.alertingProvisionerConfig())
+}
+
+// DetectAlertingDrift compares the alerting resources provisioned from files with the provisioning files.
+// If revert is true and there are differences, the files are provisioned again.
+func (ps *ProvisioningServiceImpl) DetectAlertingDrift(ctx context.Context, revert bool) (prov_alerting.DriftReport, error) {
+	// A revert must not run concurrently with a reload of the files.
+	ps.alertingMutex.Lock()
+	defer ps.alertingMutex.Unlock()
+	return ps.detectAlertingDrift(ctx, ps.alertingProvisionerConfig(), revert)
+}
+
+// checkAlertingDrift periodically detects and optionally reverts the drift of the alerting resources
+// provisioned from files, until the context is cancelled.
+func (ps *ProvisioningServiceImpl) checkAlertingDrift(ctx context.Context, interval time.Duration, revert bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := ps.DetectAlertingDrift(ctx, revert)
+			if err != nil {
+				ps.log.Error("Failed to check drift of provisioned alerting resources", "error", err)
+				continue
+			}
+			if report.HasDrift() {
+				reverted := 0
+				for _, drift := range report.Drifts {
+					if drift.Reverted {
+						reverted++
+					}
+				}
+				ps.log.Warn("Provisioned alerting resources have drifted from the provisioning files", "count", len(report.Drifts), "reverted", reverted)
+			}
+		}
+	}
+}
+
+func (ps *ProvisioningServiceImpl) alertingProvisionerConfig() prov_alerting.ProvisionerConfig {
 	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
@@ -306,7 +394,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
-	cfg := prov_alerting.ProvisionerConfig{
+	return prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
 		DashboardService:           ps.dashboardService,
@@ -315,8 +403,8 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		Features:                   ps.features,
 	}
-	return ps.provisionAlerting(ctx, cfg)
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
@@ -327,6 +415,11 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// TriggerDashboardGitSync makes the git dashboard provider with the specified name sync its repository.
+func (ps *ProvisioningServiceImpl) TriggerDashboardGitSync(name string, body []byte, signature string) error {
+	return ps.dashboardProvisioner.TriggerGitSync(name, body, signature)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
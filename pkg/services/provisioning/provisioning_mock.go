@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -9,8 +13,10 @@ type Calls struct {
 	ProvisionNotifications              []any
 	ProvisionDashboards                 []any
 	ProvisionAlerting                   []any
+	DetectAlertingDrift                 []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	TriggerDashboardGitSync             []any
 	Run                                 []any
 }
 
@@ -21,8 +27,10 @@ type ProvisioningServiceMock struct {
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
 	ProvisionDashboardsFunc                 func() error
+	DetectAlertingDriftFunc                 func(ctx context.Context, revert bool) (alerting.DriftReport, error)
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	TriggerDashboardGitSyncFunc             func(name string, body []byte, signature string) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -77,6 +85,14 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) DetectAlertingDrift(ctx context.Context, revert bool) (alerting.DriftReport, error) {
+	mock.Calls.DetectAlertingDrift = append(mock.Calls.DetectAlertingDrift, revert)
+	if mock.DetectAlertingDriftFunc != nil {
+		return mock.DetectAlertingDriftFunc(ctx, revert)
+	}
+	return alerting.DriftReport{}, nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -93,6 +109,14 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) TriggerDashboardGitSync(name string, body []byte, signature string) error {
+	mock.Calls.TriggerDashboardGitSync = append(mock.Calls.TriggerDashboardGitSync, name)
+	if mock.TriggerDashboardGitSyncFunc != nil {
+		return mock.TriggerDashboardGitSyncFunc(name, body, signature)
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
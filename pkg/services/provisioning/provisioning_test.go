@@ -11,11 +11,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	dashboardstore "github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/org"
+	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
 func TestProvisioningServiceImpl(t *testing.T) {
@@ -108,6 +112,44 @@ func TestProvisioningServiceImpl(t *testing.T) {
 
 		assert.True(t, errors.Is(serviceTest.serviceError, provisioningErr))
 	})
+
+	t.Run("Alerting provisioning should not wait for dashboard provisioning", func(t *testing.T) {
+		serviceTest := setup(t)
+		serviceTest.service.provisionAlerting = func(context.Context, prov_alerting.ProvisionerConfig) error {
+			return nil
+		}
+		// Dashboard provisioning holds its lock while it syncs the dashboard sources.
+		serviceTest.service.mutex.Lock()
+		defer serviceTest.service.mutex.Unlock()
+
+		done := make(chan error)
+		go func() {
+			done <- serviceTest.service.ProvisionAlerting(context.Background())
+		}()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(serviceTest.waitTimeout):
+			t.Fatal("alerting provisioning waited for dashboard provisioning")
+		}
+	})
+
+	t.Run("Live provisioning should run only when the live pipeline is enabled", func(t *testing.T) {
+		serviceTest := setup(t)
+		calls := 0
+		serviceTest.service.provisionLive = func(context.Context, string, pipeline.Storage, secrets.Service) error {
+			calls++
+			return nil
+		}
+
+		serviceTest.service.features = featuremgmt.WithFeatures()
+		require.NoError(t, serviceTest.service.ProvisionLive(context.Background()))
+		assert.Equal(t, 0, calls)
+
+		serviceTest.service.features = featuremgmt.WithFeatures(featuremgmt.FlagLivePipeline)
+		require.NoError(t, serviceTest.service.ProvisionLive(context.Background()))
+		assert.Equal(t, 1, calls)
+	})
 }
 
 type serviceTestStruct struct {
@@ -140,7 +182,7 @@ func setup(t *testing.T) *serviceTestStruct {
 	searchStub := searchV2.NewStubSearchService()
 
 	service, err := newProvisioningServiceImpl(
-		func(context.Context, string, dashboardstore.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (dashboards.DashboardProvisioner, error) {
+		func(context.Context, string, string, dashboardstore.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (dashboards.DashboardProvisioner, error) {
 			return serviceTest.mock, nil
 		},
 		nil,
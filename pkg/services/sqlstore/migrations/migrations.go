@@ -123,6 +123,10 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddAlertingScopeRemovalMigration(mg)
 
 	accesscontrol.AddOrphanedMigrations(mg)
+
+	ualert.AddRecordingRuleColumns(mg)
+	ualert.AddStateHistoryMigrations(mg)
+	ualert.AddKeepFiringForColumns(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,67 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddStateHistoryMigrations creates the tables used by the SQL state history backend.
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "folder_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "rule_condition", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "epoch", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "epoch"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "rule_uid", "epoch"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "epoch"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "fingerprint"}, Type: migrator.IndexType},
+			{Cols: []string{"epoch"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id and epoch columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and epoch columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on org_id, dashboard_uid, panel_id and epoch columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+	mg.AddMigration("add index in alert_state_history on org_id and fingerprint columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[3]))
+	mg.AddMigration("add index in alert_state_history on epoch column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[4]))
+
+	// Labels of alert instances are stored once per label set, identified by its fingerprint,
+	// so that history can be filtered by labels without scanning the label sets of every entry.
+	stateHistoryLabel := migrator.Table{
+		Name: "alert_state_history_label",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "label_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			// Values are truncated to the length of the column, so that they can be part of an index.
+			{Name: "label_value", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "fingerprint", "label_key"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "label_key", "label_value"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history_label table", migrator.NewAddTableMigration(stateHistoryLabel))
+	mg.AddMigration("add unique index in alert_state_history_label on org_id, fingerprint and label_key columns", migrator.NewAddIndexMigration(stateHistoryLabel, stateHistoryLabel.Indices[0]))
+	mg.AddMigration("add index in alert_state_history_label on org_id, label_key and label_value columns", migrator.NewAddIndexMigration(stateHistoryLabel, stateHistoryLabel.Indices[1]))
+}
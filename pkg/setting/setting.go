@@ -436,6 +436,12 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveManagedStreamReplayMaxFrames is a maximum number of recent frames
+	// kept per managed stream channel to be sent to new subscribers.
+	LiveManagedStreamReplayMaxFrames int
+	// LiveManagedStreamReplayMaxAge is a maximum age of recent frames kept
+	// per managed stream channel. 0 means no age limit.
+	LiveManagedStreamReplayMaxAge time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -1989,6 +1995,11 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	cfg.LiveHAPrefix = section.Key("ha_prefix").MustString("")
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveManagedStreamReplayMaxFrames = section.Key("managed_stream_replay_max_frames").MustInt(100)
+	if cfg.LiveManagedStreamReplayMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_replay_max_frames", cfg.LiveManagedStreamReplayMaxFrames)
+	}
+	cfg.LiveManagedStreamReplayMaxAge = section.Key("managed_stream_replay_max_age").MustDuration(5 * time.Minute)
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
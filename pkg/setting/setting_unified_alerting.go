@@ -63,6 +63,8 @@ const (
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
 	lokiDefaultMaxQueryLength     = 721 * time.Hour // 30d1h, matches the default value in Loki
+	recordingRulesDefaultTimeout  = 10 * time.Second
+	sqlHistoryDefaultRetention    = 30 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -82,6 +84,7 @@ type UnifiedAlertingSettings struct {
 	HARedisPassword                string
 	HARedisDB                      int
 	HARedisMaxConns                int
+	HAEvaluationSharding           bool
 	InitializationTimeout          time.Duration
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
@@ -99,6 +102,8 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                RecordingRuleSettings
+	ProvisioningDrift             ProvisioningDriftSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	Upgrade                       UnifiedAlertingUpgradeSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -140,9 +145,33 @@ type UnifiedAlertingStateHistorySettings struct {
 	LokiBasicAuthPassword string
 	LokiBasicAuthUsername string
 	LokiMaxQueryLength    time.Duration
-	MultiPrimary          string
-	MultiSecondaries      []string
-	ExternalLabels        map[string]string
+	// SQLRetention is the period for which state history is kept by the "sql" backend.
+	// State history is kept forever if it is zero.
+	SQLRetention     time.Duration
+	MultiPrimary     string
+	MultiSecondaries []string
+	ExternalLabels   map[string]string
+}
+
+// RecordingRuleSettings contains the configuration of the remote write endpoint
+// that receives the results of Grafana-managed recording rules.
+type RecordingRuleSettings struct {
+	Enabled bool
+	URL     string
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth
+	// if one of them is set.
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// ProvisioningDriftSettings contains the configuration of the periodic check for differences
+// between file-provisioned alerting resources and the provisioning files.
+type ProvisioningDriftSettings struct {
+	// CheckInterval is the interval of the check. The check is disabled if it is zero.
+	CheckInterval time.Duration
+	// Revert controls whether the provisioning files are applied again when differences are found.
+	Revert bool
 }
 
 type UnifiedAlertingUpgradeSettings struct {
@@ -273,6 +302,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -416,12 +446,34 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
 		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
 		LokiMaxQueryLength:    stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
+		SQLRetention:          stateHistory.Key("sql_retention").MustDuration(sqlHistoryDefaultRetention),
 		MultiPrimary:          stateHistory.Key("primary").MustString(""),
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules = RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+	}
+	if uaCfg.RecordingRules.Enabled && uaCfg.RecordingRules.URL == "" {
+		return errors.New("setting 'url' in section 'unified_alerting.recording_rules' is required when recording rules are enabled")
+	}
+
+	provisioningDrift := iniFile.Section("unified_alerting.provisioning_drift")
+	uaCfg.ProvisioningDrift = ProvisioningDriftSettings{
+		CheckInterval: provisioningDrift.Key("check_interval").MustDuration(0),
+		Revert:        provisioningDrift.Key("revert").MustBool(false),
+	}
+	if uaCfg.ProvisioningDrift.CheckInterval < 0 {
+		return errors.New("setting 'check_interval' in section 'unified_alerting.provisioning_drift' cannot be negative")
+	}
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
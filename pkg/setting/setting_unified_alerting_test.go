@@ -76,6 +76,37 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 			require.Equal(t, SchedulerBaseInterval, cfg.UnifiedAlerting.BaseInterval)
 		})
 	})
+
+	t.Run("should read recording rules settings", func(t *testing.T) {
+		require.False(t, cfg.UnifiedAlerting.RecordingRules.Enabled)
+		require.Equal(t, recordingRulesDefaultTimeout, cfg.UnifiedAlerting.RecordingRules.Timeout)
+
+		s, err := cfg.Raw.NewSection("unified_alerting.recording_rules")
+		require.NoError(t, err)
+		_, err = s.NewKey("enabled", "true")
+		require.NoError(t, err)
+
+		t.Run("and fail if url is not specified", func(t *testing.T) {
+			require.Error(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		})
+
+		_, err = s.NewKey("url", "http://localhost:9090/api/v1/write")
+		require.NoError(t, err)
+		_, err = s.NewKey("basic_auth_username", "user")
+		require.NoError(t, err)
+		_, err = s.NewKey("timeout", "1m")
+		require.NoError(t, err)
+
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+		require.Equal(t, RecordingRuleSettings{
+			Enabled:           true,
+			URL:               "http://localhost:9090/api/v1/write",
+			BasicAuthUsername: "user",
+			Timeout:           time.Minute,
+		}, cfg.UnifiedAlerting.RecordingRules)
+
+		cfg.Raw.DeleteSection("unified_alerting.recording_rules")
+	})
 }
 
 func TestUnifiedAlertingSettings(t *testing.T) {
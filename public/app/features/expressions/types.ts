@@ -68,6 +68,22 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
+  {
+    value: 'diff_abs',
+    label: 'Absolute difference',
+    description: 'Get the absolute difference between the last and first value',
+  },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of values that are not null' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of all values' },
+  { value: ReducerID.variance, label: 'Variance', description: 'Get the variance of all values' },
+  { value: 'p50', label: '50th percentile', description: 'Get the 50th percentile of all values' },
+  { value: 'p75', label: '75th percentile', description: 'Get the 75th percentile of all values' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile of all values' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile of all values' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile of all values' },
 ];
 
 export enum ReducerMode {
@@ -48,6 +48,7 @@ export interface DashboardMeta {
   canMakeEditable?: boolean;
   provisioned?: boolean;
   provisionedExternalId?: string;
+  provisionedCommitSha?: string;
   isStarred?: boolean;
   showSettings?: boolean;
   expires?: string;